@@ -0,0 +1,83 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/vitessio/arewefastyet/go/exec"
+	"github.com/vitessio/arewefastyet/go/storage/psdb"
+)
+
+func ConfigCmd() *cobra.Command {
+	var dbConfig psdb.Config
+	cmd := &cobra.Command{
+		Use:   "config <execution UUID> [<execution UUID>]",
+		Short: "List or diff the configuration snapshots of executions",
+		Long: `List the configuration snapshot of an execution. If a second execution is given,
+only the configuration keys whose values differ between both executions are listed.`,
+		Example: "arewefastyet exec config 2f3d8a1c-0b6a-4c4e-9a51-3f7a2c1c6e2b 7c1e5a9d-4f2b-4d3a-8e6c-1b2a3c4d5e6f",
+		Args:    cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := dbConfig.NewClient()
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			if len(args) == 1 {
+				snapshot, err := exec.GetConfigSnapshot(client, args[0])
+				if err != nil {
+					return err
+				}
+				for _, key := range sortedKeys(snapshot) {
+					fmt.Fprintf(cmd.OutOrStdout(), "%s: %s\n", key, snapshot[key])
+				}
+				return nil
+			}
+
+			diff, err := exec.DiffConfig(client, args[0], args[1])
+			if err != nil {
+				return err
+			}
+			keys := make([]string, 0, len(diff))
+			for key := range diff {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s: %q -> %q\n", key, diff[key].Left, diff[key].Right)
+			}
+			return nil
+		},
+	}
+	dbConfig.AddToCommand(cmd)
+	return cmd
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
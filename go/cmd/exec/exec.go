@@ -60,5 +60,6 @@ It handles the creation, configuration, and cleanup of the infrastructure.`,
 	}
 
 	ex.AddToCommand(cmd)
+	cmd.AddCommand(ConfigCmd())
 	return cmd
 }
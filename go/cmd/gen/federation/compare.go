@@ -0,0 +1,101 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package federation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/vitessio/arewefastyet/go/storage/psdb"
+	"github.com/vitessio/arewefastyet/go/tools/federation"
+)
+
+const (
+	flagRemoteName     = "remote-name"
+	flagRemoteOrg      = "remote-planetscale-db-org"
+	flagRemoteUser     = "remote-planetscale-db-user"
+	flagRemotePassword = "remote-planetscale-db-password"
+	flagRemoteHost     = "remote-planetscale-db-host"
+	flagRemoteDatabase = "remote-planetscale-db-database"
+	flagRemoteBranch   = "remote-planetscale-db-branch"
+	flagLocalName      = "local-name"
+	flagFormat         = "format"
+)
+
+// CompareCmd returns the command comparing an execution of this deployment with an
+// execution of another arewefastyet deployment, reached through its database.
+func CompareCmd() *cobra.Command {
+	var localCfg, remoteCfg psdb.Config
+	var localName, remoteName, format string
+
+	cmd := &cobra.Command{
+		Use:   "federated_compare <local-uuid> <remote-uuid>",
+		Short: "Compare an execution of this deployment with an execution of another deployment.",
+		Long: `Compare an execution of this deployment with an execution of another arewefastyet deployment, using the database of each deployment.
+The differences of setup between both executions, such as the provider or the configuration, are listed as caveats. Only macrobenchmarks are supported.`,
+		Example: "arewefastyet gen federated_compare <local-uuid> <remote-uuid> --remote-name lab --remote-planetscale-db-host remote.example.com --remote-planetscale-db-user user --remote-planetscale-db-password password --remote-planetscale-db-database arewefastyet",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "markdown" && format != "json" {
+				return fmt.Errorf("unknown format %q, expected markdown or json", format)
+			}
+			localClient, err := localCfg.NewClient()
+			if err != nil {
+				return err
+			}
+			defer localClient.Close()
+			remoteClient, err := remoteCfg.NewClient()
+			if err != nil {
+				return err
+			}
+			defer remoteClient.Close()
+
+			comparison, err := federation.Compare(
+				federation.Deployment{Name: localName, Client: localClient},
+				federation.Deployment{Name: remoteName, Client: remoteClient},
+				args[0], args[1],
+			)
+			if err != nil {
+				return err
+			}
+			if format == "json" {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(comparison)
+			}
+			fmt.Print(comparison.Markdown())
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&localName, flagLocalName, "local", "Name of this deployment in the caveats.")
+	cmd.Flags().StringVar(&remoteName, flagRemoteName, "remote", "Name of the remote deployment in the caveats.")
+	cmd.Flags().StringVar(&format, flagFormat, "markdown", "Output format of the comparison, either markdown or json.")
+	localCfg.AddToCommand(cmd)
+	cmd.Flags().StringVar(&remoteCfg.Org, flagRemoteOrg, "", "Name of the PlanetscaleDB organization of the remote deployment.")
+	cmd.Flags().StringVar(&remoteCfg.User, flagRemoteUser, "", "Username used to authenticate to the PlanetscaleDB of the remote deployment.")
+	cmd.Flags().StringVar(&remoteCfg.Password, flagRemotePassword, "", "Password used to authenticate to the PlanetscaleDB of the remote deployment.")
+	cmd.Flags().StringVar(&remoteCfg.Host, flagRemoteHost, "", "Hostname of the PlanetscaleDB database of the remote deployment. A read replica is enough.")
+	cmd.Flags().StringVar(&remoteCfg.Database, flagRemoteDatabase, "", "PlanetscaleDB database name of the remote deployment.")
+	cmd.Flags().StringVar(&remoteCfg.Branch, flagRemoteBranch, "main", "PlanetscaleDB branch of the remote deployment.")
+	_ = cmd.MarkFlagRequired(flagRemoteHost)
+	return cmd
+}
@@ -21,6 +21,8 @@ package gen
 import (
 	"github.com/spf13/cobra"
 	"github.com/vitessio/arewefastyet/go/cmd/gen/doc"
+	"github.com/vitessio/arewefastyet/go/cmd/gen/federation"
+	"github.com/vitessio/arewefastyet/go/cmd/gen/influx"
 	"github.com/vitessio/arewefastyet/go/cmd/gen/metrics"
 	"github.com/vitessio/arewefastyet/go/cmd/gen/report"
 )
@@ -35,5 +37,7 @@ func GenCmd() *cobra.Command {
 	cmd.AddCommand(doc.GenerateDoc())
 	cmd.AddCommand(report.GenerateReport())
 	cmd.AddCommand(metrics.GenExecMetricsCmd())
+	cmd.AddCommand(influx.ImportCmd())
+	cmd.AddCommand(federation.CompareCmd())
 	return cmd
 }
@@ -0,0 +1,114 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package influx
+
+import (
+	"errors"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/vitessio/arewefastyet/go/exec/stats"
+	"github.com/vitessio/arewefastyet/go/storage/influxdb"
+)
+
+const (
+	flagPrecision = "import-precision"
+	flagBatchSize = "import-batch-size"
+	flagExecUUID  = "import-exec-uuid"
+)
+
+// ImportCmd returns the command re-ingesting an export of InfluxDB in the line protocol
+// into the stats remote database.
+func ImportCmd() *cobra.Command {
+	statsCfg := &stats.RemoteDBConfig{}
+	var precision string
+	var batchSize int
+	var execUUID string
+
+	cmd := &cobra.Command{
+		Use:   "influx_import <file>",
+		Short: "Re-ingest an InfluxDB export in the line protocol into the stats remote database.",
+		Long: `Re-ingest an InfluxDB export in the line protocol, such as the one of influx_inspect export, into the stats remote database.
+The measurements, tags and timestamps of the points are preserved, importing the same export again has no effect.
+When the stats remote database appends duplicates, the points without execution are tagged with the one of --import-exec-uuid.`,
+		Example: "arewefastyet gen influx_import export.lp --stats-remote-db-host localhost --stats-remote-db-port 8086 --stats-remote-db-database arewefastyet",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !statsCfg.IsValid() {
+				return errors.New(influxdb.ErrorInvalidConfiguration)
+			}
+			exportPrecision, err := influxdb.ParsePrecision(precision)
+			if err != nil {
+				return err
+			}
+			if batchSize <= 0 {
+				batchSize = 1
+			}
+
+			file, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			if err := statsCfg.VerifySchema(); err != nil {
+				return err
+			}
+			client, err := statsCfg.NewInfluxClient()
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+			client.SetExecution(execUUID)
+
+			imported := 0
+			batch := make([]influxdb.Point, 0, batchSize)
+			flush := func() error {
+				if len(batch) == 0 {
+					return nil
+				}
+				if err := client.WritePoints(batch); err != nil {
+					return err
+				}
+				imported += len(batch)
+				log.Printf("imported %d points", imported)
+				batch = batch[:0]
+				return nil
+			}
+			err = influxdb.ReadLineProtocol(file, exportPrecision, func(point influxdb.Point) error {
+				batch = append(batch, point)
+				if len(batch) < batchSize {
+					return nil
+				}
+				return flush()
+			})
+			if err != nil {
+				return err
+			}
+			return flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&precision, flagPrecision, "ns", "Precision of the timestamps of the export, either ns, us, ms or s.")
+	cmd.Flags().IntVar(&batchSize, flagBatchSize, 5000, "Number of points written to the stats remote database at once.")
+	cmd.Flags().StringVar(&execUUID, flagExecUUID, "", "Execution with which the points without execution are tagged, when the stats remote database appends duplicates.")
+	statsCfg.AddToCommand(cmd)
+	return cmd
+}
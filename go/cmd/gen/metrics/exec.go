@@ -39,7 +39,7 @@ func GenExecMetricsCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			clientMetrics, err := metricsDBConfig.NewClient()
+			clientMetrics, err := metricsDBConfig.NewClientWithRetry()
 			if err != nil {
 				return err
 			}
@@ -43,7 +43,7 @@ func GenerateReport() *cobra.Command {
 				return err
 			}
 
-			metricsClient, err := metricsCfg.NewClient()
+			metricsClient, err := metricsCfg.NewClientWithRetry()
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,36 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+package genericbench
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// GenericBenchCmd handles subcommands related to running generic benchmarks.
+func GenericBenchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "genericbench <command>",
+		Short:   "Top level command to manage generic benchmarks",
+		Aliases: []string{"gb"},
+		Long:    "Top level command to manage generic benchmarks, whose command and result format are defined in the configuration",
+	}
+
+	cmd.AddCommand(run())
+
+	return cmd
+}
@@ -0,0 +1,44 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+package genericbench
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vitessio/arewefastyet/go/storage/psdb"
+	"github.com/vitessio/arewefastyet/go/tools/genericbench"
+)
+
+func run() *cobra.Command {
+	var gbcfg genericbench.Config
+	gbcfg.DatabaseConfig = &psdb.Config{}
+
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Run a generic benchmark and store its metrics in the database configuration provided.",
+		Long: `Run the command of a generic benchmark and extract its metrics from the command's output using the result regular expression.
+Each named capturing group of the regular expression is a metric. The metrics are saved to the database if the configuration is provided.`,
+		Example: `arewefastyet genericbench run --genericbench-command "./bench.sh" --genericbench-result-regex "qps: (?P<qps>[0-9.]+)"`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return genericbench.Run(gbcfg)
+		},
+	}
+
+	gbcfg.AddToCommand(cmd)
+
+	return cmd
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/vitessio/arewefastyet/go/cmd/exec"
 	"github.com/vitessio/arewefastyet/go/cmd/gen"
+	"github.com/vitessio/arewefastyet/go/cmd/genericbench"
 	"github.com/vitessio/arewefastyet/go/cmd/macrobench"
 	"github.com/vitessio/arewefastyet/go/cmd/microbench"
 	"github.com/vitessio/arewefastyet/go/cmd/web"
@@ -61,6 +62,7 @@ func init() {
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 	rootCmd.AddCommand(microbench.MicroBenchCmd())
 	rootCmd.AddCommand(macrobench.MacroBenchCmd())
+	rootCmd.AddCommand(genericbench.GenericBenchCmd())
 	rootCmd.AddCommand(web.WebCmd())
 	rootCmd.AddCommand(exec.ExecCmd())
 	rootCmd.AddCommand(gen.GenCmd())
@@ -0,0 +1,145 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+package exec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"regexp"
+
+	"github.com/vitessio/arewefastyet/go/infra/ansible"
+	"github.com/vitessio/arewefastyet/go/storage"
+)
+
+const (
+	// cleanUpMacrobenchPlaybook is the playbook, of the Ansible directory, that stops the
+	// benchmarked cluster of a macrobenchmark and removes the network impairment of its hosts.
+	cleanUpMacrobenchPlaybook = "clean_macrobench.yml"
+
+	// cleanUpLogFile is the name of the file, in the Exec's directory, in which
+	// the output of the playbook run by CleanUp is written.
+	cleanUpLogFile = "cleanup.log"
+
+	// keySkipPostCleanup makes the macrobenchmark playbook skip cleanUpMacrobenchPlaybook, see Exec.SkipPostCleanup.
+	keySkipPostCleanup = "skip_post_cleanup"
+)
+
+// ProvisionsInfrastructure returns true if the executions of the given benchmark type provision
+// infrastructure on the hosts of their inventory, which must be cleaned up. Only macrobenchmarks do.
+func ProvisionsInfrastructure(typeOf string) bool {
+	return typeOf != "micro" && typeOf != "generic"
+}
+
+// ErrNothingToCleanUp is returned by CleanUp for the benchmark types that do not provision
+// infrastructure, and thus have no clean playbook.
+var ErrNothingToCleanUp = errors.New("the benchmark type does not provision infrastructure")
+
+// cleanUpPlaybookOf returns the clean playbook of the given benchmark type.
+func cleanUpPlaybookOf(typeOf string) (string, error) {
+	if !ProvisionsInfrastructure(typeOf) {
+		return "", ErrNothingToCleanUp
+	}
+	return cleanUpMacrobenchPlaybook, nil
+}
+
+// ErrNoExecutionDirectory is returned by CleanUp when the directory of an execution,
+// holding its copy of the Ansible files, is no longer on this host.
+var ErrNoExecutionDirectory = errors.New("the execution directory is not on this host")
+
+// playRecapHost matches the hosts listed in the PLAY RECAP of an Ansible playbook.
+var playRecapHost = regexp.MustCompile(`(?m)^(\S+)\s+:\s+ok=\d+`)
+
+// CleanUpReport describes the infrastructure torn down by CleanUp.
+type CleanUpReport struct {
+	UUID      string   `json:"uuid"`
+	Inventory string   `json:"inventory"`
+	Playbook  string   `json:"playbook"`
+	Hosts     []string `json:"hosts"`
+	Log       string   `json:"log"`
+}
+
+// CleanUp tears down the infrastructure of the execution execUUID, for instance when the process
+// running it was killed before Ansible cleaned it up. The clean playbook of the benchmark type typeOf,
+// from the execution's copy of the Ansible directory, is run against the inventory the execution saved,
+// see saveInventory. The SSH configuration of ansibleCfg is used to connect to the hosts, its files are
+// ignored. ErrNothingToCleanUp is returned if typeOf does not provision infrastructure.
+func CleanUp(ctx context.Context, client storage.SQLClient, execUUID, typeOf string, ansibleCfg ansible.Config) (*CleanUpReport, error) {
+	playbook, err := cleanUpPlaybookOf(typeOf)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := GetMetadata(client, execUUID)
+	if err != nil {
+		return nil, err
+	}
+	dirPath := metadata[MetadataDirPath]
+	if dirPath == "" {
+		return nil, ErrNoInventory
+	}
+	rootDir := path.Join(dirPath, ansibleDir)
+	inventory := path.Join(dirPath, effectiveInventoryFile)
+	for _, file := range []string{rootDir, inventory} {
+		if _, err := os.Stat(file); errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNoExecutionDirectory
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	logFile, err := os.OpenFile(path.Join(dirPath, cleanUpLogFile), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+	defer logFile.Close()
+	var stdout bytes.Buffer
+
+	ansibleCfg.RootDir = rootDir
+	ansibleCfg.InventoryFiles = []string{inventory}
+	ansibleCfg.PlaybookFiles = []string{playbook}
+	ansibleCfg.ExtraVars = map[string]interface{}{
+		keyExecUUID: execUUID,
+		// the impairment is removed whether the execution had one or not, as
+		// its removal is allowed to fail by the playbook
+		keyNetworkImpairment: map[string]interface{}{},
+	}
+	ansibleCfg.SetOutputs(io.MultiWriter(logFile, &stdout), logFile)
+	err = ansible.RunContext(ctx, &ansibleCfg)
+	if err != nil {
+		return nil, err
+	}
+	return &CleanUpReport{
+		UUID:      execUUID,
+		Inventory: inventory,
+		Playbook:  ansibleCfg.PlaybookFiles[0],
+		Hosts:     playRecapHosts(stdout.String()),
+		Log:       logFile.Name(),
+	}, nil
+}
+
+// playRecapHosts returns the hosts listed in the PLAY RECAP of the output of an Ansible playbook.
+func playRecapHosts(output string) []string {
+	var hosts []string
+	for _, match := range playRecapHost.FindAllStringSubmatch(output, -1) {
+		hosts = append(hosts, match[1])
+	}
+	return hosts
+}
@@ -0,0 +1,73 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+package exec
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/google/uuid"
+	"github.com/vitessio/arewefastyet/go/infra/ansible"
+)
+
+func TestPlayRecapHosts(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{
+			name:   "no recap",
+			output: "PLAY [Stop prometheus] ***\n",
+		},
+		{
+			name: "recap",
+			output: "TASK [Teardown etcd] ***\nok: [10.0.0.1]\n\nPLAY RECAP ***\n" +
+				"10.0.0.1                   : ok=12   changed=4    unreachable=0    failed=0\n" +
+				"10.0.0.2                   : ok=0    changed=0    unreachable=1    failed=0\n",
+			want: []string{"10.0.0.1", "10.0.0.2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qt.Assert(t, playRecapHosts(tt.output), qt.DeepEquals, tt.want)
+		})
+	}
+}
+
+func TestExec_prepareAnsibleForExecutionSkipPostCleanup(t *testing.T) {
+	for _, skip := range []bool{false, true} {
+		c := qt.New(t)
+		e := &Exec{UUID: uuid.New(), SkipPostCleanup: skip, AnsibleConfig: ansible.Config{ExtraVars: map[string]interface{}{}}}
+		e.prepareAnsibleForExecution()
+		_, ok := e.AnsibleConfig.ExtraVars[keySkipPostCleanup]
+		c.Assert(ok, qt.Equals, skip)
+	}
+}
+
+func TestCleanUpPlaybookOf(t *testing.T) {
+	c := qt.New(t)
+	playbook, err := cleanUpPlaybookOf("oltp")
+	c.Assert(err, qt.IsNil)
+	c.Assert(playbook, qt.Equals, cleanUpMacrobenchPlaybook)
+
+	for _, typeOf := range []string{"micro", "generic"} {
+		_, err = cleanUpPlaybookOf(typeOf)
+		c.Assert(err, qt.Equals, ErrNothingToCleanUp)
+	}
+}
@@ -25,6 +25,7 @@ import (
 
 const (
 	flagRootExec             = "exec-root-dir"
+	flagDirTemplate          = "exec-dir-template"
 	flagGitRefExec           = "exec-git-ref"
 	flagSourceExec           = "exec-source"
 	flagExecType             = "exec-type"
@@ -32,10 +33,27 @@ const (
 	flagExecPullNB           = "exec-pull-nb"
 	flagGolangVersion        = "exec-go-version"
 	flagServerAddress        = "exec-server-address"
+	flagExecComponent        = "exec-component"
+	flagExecLabels           = "exec-labels"
+	flagExecPreRunScript     = "exec-pre-run-script"
+	flagExecMySQLConfig      = "exec-mysql-config"
+	flagExecDuration         = "exec-duration"
+	flagExecWarmupDuration   = "exec-warmup-duration"
+	flagExecClients          = "exec-clients"
+	flagExecProvider         = "exec-provider"
+	flagExecBenchmarkEnv     = "exec-benchmark-env"
+	flagExecProfile          = "exec-profile"
+	flagExecLogMaxSize       = "exec-log-max-size"
+	flagExecLogMaxFiles      = "exec-log-max-files"
+	flagExecNetworkDelay     = "exec-network-delay"
+	flagExecNetworkJitter    = "exec-network-jitter"
+	flagExecNetworkLoss      = "exec-network-loss"
+	flagExecSanityBounds     = "exec-sanity-bounds"
 )
 
 func (e *Exec) AddToViper(v *viper.Viper) (err error) {
 	_ = v.UnmarshalKey(flagRootExec, &e.rootDir)
+	_ = v.UnmarshalKey(flagDirTemplate, &e.dirTemplate)
 	_ = v.UnmarshalKey(flagGitRefExec, &e.GitRef)
 	_ = v.UnmarshalKey(flagSourceExec, &e.Source)
 	_ = v.UnmarshalKey(flagExecType, &e.TypeOf)
@@ -43,15 +61,33 @@ func (e *Exec) AddToViper(v *viper.Viper) (err error) {
 	_ = v.UnmarshalKey(flagExecPullNB, &e.PullNB)
 	_ = v.UnmarshalKey(flagGolangVersion, &e.GolangVersion)
 	_ = v.UnmarshalKey(flagServerAddress, &e.ServerAddress)
+	_ = v.UnmarshalKey(flagExecComponent, &e.Component)
+	_ = v.UnmarshalKey(flagExecLabels, &e.Labels)
+	_ = v.UnmarshalKey(flagExecPreRunScript, &e.PreRunScript)
+	_ = v.UnmarshalKey(flagExecMySQLConfig, &e.MySQLConfig)
+	_ = v.UnmarshalKey(flagExecBenchmarkEnv, &e.BenchmarkEnv)
+	_ = v.UnmarshalKey(flagExecDuration, &e.Duration)
+	_ = v.UnmarshalKey(flagExecWarmupDuration, &e.WarmupDuration)
+	_ = v.UnmarshalKey(flagExecClients, &e.Clients)
+	_ = v.UnmarshalKey(flagExecProvider, &e.Provider)
+	_ = v.UnmarshalKey(flagExecProfile, &e.Profile)
+	_ = v.UnmarshalKey(flagExecLogMaxSize, &e.LogMaxSize)
+	_ = v.UnmarshalKey(flagExecLogMaxFiles, &e.LogMaxFiles)
+	_ = v.UnmarshalKey(flagExecNetworkDelay, &e.NetworkImpairment.Delay)
+	_ = v.UnmarshalKey(flagExecNetworkJitter, &e.NetworkImpairment.Jitter)
+	_ = v.UnmarshalKey(flagExecNetworkLoss, &e.NetworkImpairment.Loss)
+	_ = v.UnmarshalKey(flagExecSanityBounds, &e.SanityBounds)
 
 	e.AnsibleConfig.AddToViper(v)
 	e.configDB.AddToViper(v)
 	e.statsRemoteDBConfig.AddToViper(v)
+	e.tracingConfig.AddToViper(v)
 	return nil
 }
 
 func (e *Exec) AddToCommand(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&e.rootDir, flagRootExec, "", "Path to the root directory of exec.")
+	cmd.Flags().StringVar(&e.dirTemplate, flagDirTemplate, "", "Template used to name the directory of an execution, relative to the exec directory. Available fields are {{.UUID}}, {{.Type}}, {{.Source}}, {{.GitRef}} and {{.Date}}. Defaults to the execution's UUID.")
 	cmd.Flags().StringVar(&e.GitRef, flagGitRefExec, "", "Git reference on which the benchmarks will run.")
 	cmd.Flags().StringVar(&e.Source, flagSourceExec, "", "Name of the source that triggered the execution.")
 	cmd.Flags().StringVar(&e.TypeOf, flagExecType, "", "Defines the execution type (oltp, tpcc, micro).")
@@ -59,16 +95,50 @@ func (e *Exec) AddToCommand(cmd *cobra.Command) {
 	cmd.Flags().IntVar(&e.PullNB, flagExecPullNB, 0, "Defines the number of the pull request against which to execute.")
 	cmd.Flags().StringVar(&e.GolangVersion, flagGolangVersion, "1.17", "Defines the golang version that will be used by this execution.")
 	cmd.Flags().StringVar(&e.ServerAddress, flagServerAddress, "", "The IP address of the server on which the benchmark will be executed.")
+	cmd.Flags().StringVar(&e.Component, flagExecComponent, "", "Vitess component (vtgate, vttablet, ...) the execution focuses on.")
+	cmd.Flags().StringVar(&e.PreRunScript, flagExecPreRunScript, "", "Path to a script copied to and executed on the remote hosts before the benchmark. The execution fails if the script fails.")
+	cmd.Flags().StringToStringVar(&e.MySQLConfig, flagExecMySQLConfig, map[string]string{}, "MySQL options added to the my.cnf of the remote hosts, overriding the default ones (e.g. innodb_buffer_pool_size=1G,innodb_flush_log_at_trx_commit=2).")
+	cmd.Flags().StringToStringVar(&e.BenchmarkEnv, flagExecBenchmarkEnv, map[string]string{}, "Environment variables passed to the benchmark process, vtgate and vttablet on the remote hosts (e.g. GOGC=200,GOMAXPROCS=8). Reserved variables such as PATH cannot be overridden.")
+	cmd.Flags().IntVar(&e.Duration, flagExecDuration, 0, "Duration, in seconds, of the run step of macrobenchmarks. Zero keeps the duration of the macrobenchmark configuration.")
+	cmd.Flags().IntVar(&e.WarmupDuration, flagExecWarmupDuration, 0, "Duration, in seconds, of the warm up step of macrobenchmarks. Zero keeps the duration of the macrobenchmark configuration.")
+	cmd.Flags().IntVar(&e.Clients, flagExecClients, 0, "Number of concurrent clients (sysbench threads) of macrobenchmarks. Zero keeps the number of clients of the macrobenchmark configuration. Executions with different numbers of clients are not compared.")
+	cmd.Flags().StringVar(&e.Provider, flagExecProvider, "", "Infrastructure provider (e.g. equinix, aws) of the servers the benchmark runs on. Used to compare the results of a git ref across providers.")
+	cmd.Flags().BoolVar(&e.Profile, flagExecProfile, false, "Collect a CPU profile of vtgate during the run step of macrobenchmarks, downloadable from the API of the web server. Profiling adds overhead to the benchmark.")
+	cmd.Flags().IntVar(&e.LogMaxSize, flagExecLogMaxSize, 0, "Maximum size, in megabytes, of the stdout and stderr log files of an execution before they are rotated. Zero disables the rotation.")
+	cmd.Flags().IntVar(&e.LogMaxFiles, flagExecLogMaxFiles, 3, "Number of rotated log files kept in addition to the current one when exec-log-max-size is set.")
+	cmd.Flags().DurationVar(&e.NetworkImpairment.Delay, flagExecNetworkDelay, 0, "Latency added with tc/netem to the packets of the remote hosts during macrobenchmarks (e.g. 20ms). Impaired executions are not compared against clean ones.")
+	cmd.Flags().DurationVar(&e.NetworkImpairment.Jitter, flagExecNetworkJitter, 0, "Variation of the latency added by exec-network-delay.")
+	cmd.Flags().Float64Var(&e.NetworkImpairment.Loss, flagExecNetworkLoss, 0, "Percentage of the packets of the remote hosts dropped with tc/netem during macrobenchmarks.")
+	cmd.Flags().StringToStringVar(&e.SanityBounds, flagExecSanityBounds, map[string]string{}, "Plausible range, as min:max, of metrics of the macrobenchmark results (e.g. qps.total=1:,latency=:1000). Executions with results out of range are marked invalid and excluded from comparisons.")
+	cmd.Flags().StringToStringVar(&e.Labels, flagExecLabels, map[string]string{}, "Labels attached to the execution, such as the name of an experiment (e.g. experiment=foo,ticket=123).")
 
 	_ = viper.BindPFlag(flagRootExec, cmd.Flags().Lookup(flagRootExec))
+	_ = viper.BindPFlag(flagDirTemplate, cmd.Flags().Lookup(flagDirTemplate))
 	_ = viper.BindPFlag(flagGitRefExec, cmd.Flags().Lookup(flagGitRefExec))
 	_ = viper.BindPFlag(flagSourceExec, cmd.Flags().Lookup(flagSourceExec))
 	_ = viper.BindPFlag(flagExecType, cmd.Flags().Lookup(flagExecType))
 	_ = viper.BindPFlag(flagExecPullNB, cmd.Flags().Lookup(flagExecPullNB))
 	_ = viper.BindPFlag(flagGolangVersion, cmd.Flags().Lookup(flagGolangVersion))
 	_ = viper.BindPFlag(flagServerAddress, cmd.Flags().Lookup(flagServerAddress))
+	_ = viper.BindPFlag(flagExecComponent, cmd.Flags().Lookup(flagExecComponent))
+	_ = viper.BindPFlag(flagExecLabels, cmd.Flags().Lookup(flagExecLabels))
+	_ = viper.BindPFlag(flagExecPreRunScript, cmd.Flags().Lookup(flagExecPreRunScript))
+	_ = viper.BindPFlag(flagExecMySQLConfig, cmd.Flags().Lookup(flagExecMySQLConfig))
+	_ = viper.BindPFlag(flagExecBenchmarkEnv, cmd.Flags().Lookup(flagExecBenchmarkEnv))
+	_ = viper.BindPFlag(flagExecDuration, cmd.Flags().Lookup(flagExecDuration))
+	_ = viper.BindPFlag(flagExecWarmupDuration, cmd.Flags().Lookup(flagExecWarmupDuration))
+	_ = viper.BindPFlag(flagExecClients, cmd.Flags().Lookup(flagExecClients))
+	_ = viper.BindPFlag(flagExecProvider, cmd.Flags().Lookup(flagExecProvider))
+	_ = viper.BindPFlag(flagExecProfile, cmd.Flags().Lookup(flagExecProfile))
+	_ = viper.BindPFlag(flagExecLogMaxSize, cmd.Flags().Lookup(flagExecLogMaxSize))
+	_ = viper.BindPFlag(flagExecLogMaxFiles, cmd.Flags().Lookup(flagExecLogMaxFiles))
+	_ = viper.BindPFlag(flagExecNetworkDelay, cmd.Flags().Lookup(flagExecNetworkDelay))
+	_ = viper.BindPFlag(flagExecNetworkJitter, cmd.Flags().Lookup(flagExecNetworkJitter))
+	_ = viper.BindPFlag(flagExecNetworkLoss, cmd.Flags().Lookup(flagExecNetworkLoss))
+	_ = viper.BindPFlag(flagExecSanityBounds, cmd.Flags().Lookup(flagExecSanityBounds))
 
 	e.AnsibleConfig.AddToPersistentCommand(cmd)
 	e.statsRemoteDBConfig.AddToCommand(cmd)
+	e.tracingConfig.AddToCommand(cmd)
 	e.configDB.AddToCommand(cmd)
 }
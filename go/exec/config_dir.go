@@ -0,0 +1,108 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// mergedConfigFile is the name of the file, in the directory of an execution, holding
+// the configuration merged from a configuration directory.
+const mergedConfigFile = "config.yaml"
+
+// ConfigFiles returns the configuration files of dir sorted by name. Only the files
+// whose extension is supported by viper are returned, sub-directories are ignored.
+func ConfigFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() || !IsConfigFile(entry.Name()) {
+			continue
+		}
+		files = append(files, path.Join(dir, entry.Name()))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// IsConfigFile returns true if name has an extension of configuration file supported by viper.
+func IsConfigFile(name string) bool {
+	ext := strings.TrimPrefix(filepath.Ext(name), ".")
+	for _, supported := range viper.SupportedExts {
+		if ext == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// isConfigDir returns true if pathConfig points to a directory of configuration files.
+func isConfigDir(pathConfig string) bool {
+	info, err := os.Stat(pathConfig)
+	return err == nil && info.IsDir()
+}
+
+// ReadConfig reads the configuration located at pathConfig into v. If pathConfig is
+// a directory, all its configuration files are merged in the order of their names,
+// the settings of a file overriding the ones of the files read before it.
+func ReadConfig(v *viper.Viper, pathConfig string) error {
+	if !isConfigDir(pathConfig) {
+		v.SetConfigFile(pathConfig)
+		return v.ReadInConfig()
+	}
+	files, err := ConfigFiles(pathConfig)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		v.SetConfigFile(file)
+		if err := v.MergeInConfig(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeConfigDir writes the configuration merged from the Exec's configuration directory
+// to a single file in the Exec's directory, and uses this file from then on, as Ansible
+// expects a single configuration file. Nothing is done if the Exec does not use a directory.
+func (e *Exec) mergeConfigDir() error {
+	if e.configPath == "" || !isConfigDir(e.configPath) {
+		return nil
+	}
+	v := viper.New()
+	if err := ReadConfig(v, e.configPath); err != nil {
+		return err
+	}
+	merged := path.Join(e.dirPath, mergedConfigFile)
+	if err := v.WriteConfigAs(merged); err != nil {
+		return err
+	}
+	e.configPath = merged
+	return nil
+}
@@ -0,0 +1,81 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/spf13/viper"
+)
+
+func writeConfigFiles(c *qt.C, dir string, files map[string]string) {
+	for name, content := range files {
+		c.Assert(os.WriteFile(path.Join(dir, name), []byte(content), 0644), qt.IsNil)
+	}
+}
+
+func TestConfigFiles(t *testing.T) {
+	c := qt.New(t)
+	dir := t.TempDir()
+	writeConfigFiles(c, dir, map[string]string{"b.yaml": "", "a.json": "{}", "README.md": ""})
+	c.Assert(os.Mkdir(path.Join(dir, "c.yaml"), 0755), qt.IsNil)
+
+	files, err := ConfigFiles(dir)
+	c.Assert(err, qt.IsNil)
+	c.Assert(files, qt.DeepEquals, []string{path.Join(dir, "a.json"), path.Join(dir, "b.yaml")})
+}
+
+func TestReadConfig(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  map[string]interface{}
+	}{
+		{name: "single file", files: map[string]string{"config.yaml": "exec-type: oltp\n"}, want: map[string]interface{}{"exec-type": "oltp"}},
+		{name: "files are merged", files: map[string]string{"a.yaml": "exec-type: oltp\n", "b.yaml": "exec-go-version: \"1.17\"\n"}, want: map[string]interface{}{"exec-type": "oltp", "exec-go-version": "1.17"}},
+		{name: "later files override", files: map[string]string{"a.yaml": "exec-type: oltp\n", "b.yaml": "exec-type: tpcc\n"}, want: map[string]interface{}{"exec-type": "tpcc"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			dir := t.TempDir()
+			writeConfigFiles(c, dir, tt.files)
+
+			v := viper.New()
+			c.Assert(ReadConfig(v, dir), qt.IsNil)
+			c.Assert(v.AllSettings(), qt.DeepEquals, tt.want)
+		})
+	}
+}
+
+func TestMergeConfigDir(t *testing.T) {
+	c := qt.New(t)
+	configDir := t.TempDir()
+	writeConfigFiles(c, configDir, map[string]string{"a.yaml": "exec-type: oltp\n", "b.yaml": "exec-type: tpcc\n"})
+	e := &Exec{configPath: configDir, dirPath: t.TempDir()}
+
+	c.Assert(e.mergeConfigDir(), qt.IsNil)
+	c.Assert(e.configPath, qt.Equals, path.Join(e.dirPath, mergedConfigFile))
+	snapshot, err := readConfigSnapshot(e.configPath)
+	c.Assert(err, qt.IsNil)
+	c.Assert(snapshot, qt.DeepEquals, map[string]string{"exec-type": "tpcc"})
+}
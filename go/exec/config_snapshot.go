@@ -0,0 +1,147 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/vitessio/arewefastyet/go/storage"
+)
+
+// MetadataConfig is the metadata key storing the snapshot of the configuration
+// file used by an execution, as a JSON object of flattened keys.
+const MetadataConfig = "config"
+
+// redactedConfigValue replaces the values of the secrets in configuration snapshots.
+const redactedConfigValue = "<redacted>"
+
+// secretConfigKeys are the substrings identifying the configuration keys holding secrets.
+var secretConfigKeys = []string{"password", "token", "secret"}
+
+// ConfigDiff is the difference of a configuration key between two executions.
+// An empty value means the key is not set in the execution's configuration.
+type ConfigDiff struct {
+	Left  string `json:"left"`
+	Right string `json:"right"`
+}
+
+// GetConfigSnapshot returns the configuration snapshot of the execution execUUID,
+// indexed by the flattened configuration keys. The snapshot is empty if the
+// execution did not use any configuration file.
+func GetConfigSnapshot(client storage.SQLClient, execUUID string) (map[string]string, error) {
+	metadata, err := GetMetadata(client, execUUID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := map[string]string{}
+	if metadata[MetadataConfig] == "" {
+		return snapshot, nil
+	}
+	err = json.Unmarshal([]byte(metadata[MetadataConfig]), &snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// DiffConfig compares the configuration snapshots of the executions leftUUID and
+// rightUUID, and returns the keys whose values differ along with both values.
+func DiffConfig(client storage.SQLClient, leftUUID, rightUUID string) (map[string]ConfigDiff, error) {
+	left, err := GetConfigSnapshot(client, leftUUID)
+	if err != nil {
+		return nil, err
+	}
+	right, err := GetConfigSnapshot(client, rightUUID)
+	if err != nil {
+		return nil, err
+	}
+	return DiffConfigSnapshots(left, right), nil
+}
+
+// DiffConfigSnapshots returns the keys whose values differ between the configuration
+// snapshots left and right, which may come from different databases.
+func DiffConfigSnapshots(left, right map[string]string) map[string]ConfigDiff {
+	diff := map[string]ConfigDiff{}
+	for key, value := range left {
+		if right[key] != value {
+			diff[key] = ConfigDiff{Left: value, Right: right[key]}
+		}
+	}
+	for key, value := range right {
+		if _, ok := left[key]; !ok {
+			diff[key] = ConfigDiff{Right: value}
+		}
+	}
+	return diff
+}
+
+// readConfigSnapshot reads the configuration file at path and flattens it.
+// The values of the keys holding secrets are redacted.
+func readConfigSnapshot(path string) (map[string]string, error) {
+	v := viper.New()
+	if err := ReadConfig(v, path); err != nil {
+		return nil, err
+	}
+	snapshot := map[string]string{}
+	flattenConfig("", v.AllSettings(), snapshot)
+	return snapshot, nil
+}
+
+func flattenConfig(prefix string, settings map[string]interface{}, snapshot map[string]string) {
+	for key, value := range settings {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			flattenConfig(key, nested, snapshot)
+			continue
+		}
+		snapshot[key] = fmt.Sprint(value)
+		for _, secret := range secretConfigKeys {
+			if strings.Contains(key, secret) {
+				snapshot[key] = redactedConfigValue
+				break
+			}
+		}
+	}
+}
+
+// insertConfigSnapshot persists the snapshot of the Exec's configuration file, if any.
+// The snapshot is returned, it is nil if the Exec does not use a configuration file.
+func (e *Exec) insertConfigSnapshot() (map[string]string, error) {
+	if e.configPath == "" {
+		return nil, nil
+	}
+	snapshot, err := readConfigSnapshot(e.configPath)
+	if err != nil {
+		return nil, err
+	}
+	value, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	err = SetMetadata(e.clientDB, e.UUID.String(), MetadataConfig, string(value))
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,63 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestDiffConfigSnapshots(t *testing.T) {
+	tests := []struct {
+		name        string
+		left, right map[string]string
+		want        map[string]ConfigDiff
+	}{
+		{name: "equal", left: map[string]string{"exec-type": "oltp"}, right: map[string]string{"exec-type": "oltp"}, want: map[string]ConfigDiff{}},
+		{name: "changed value", left: map[string]string{"exec-type": "oltp"}, right: map[string]string{"exec-type": "tpcc"}, want: map[string]ConfigDiff{"exec-type": {Left: "oltp", Right: "tpcc"}}},
+		{name: "missing on the right", left: map[string]string{"exec-go-version": "1.17"}, right: map[string]string{}, want: map[string]ConfigDiff{"exec-go-version": {Left: "1.17"}}},
+		{name: "missing on the left", left: map[string]string{}, right: map[string]string{"exec-go-version": "1.17"}, want: map[string]ConfigDiff{"exec-go-version": {Right: "1.17"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qt.Assert(t, DiffConfigSnapshots(tt.left, tt.right), qt.DeepEquals, tt.want)
+		})
+	}
+}
+
+func TestFlattenConfig(t *testing.T) {
+	c := qt.New(t)
+	settings := map[string]interface{}{
+		"exec-type":                "oltp",
+		"ansible-playbook-files":   []interface{}{"macrobench.yml"},
+		"planetscale-db-password":  "pass",
+		"exec-mysql-config":        map[string]interface{}{"innodb_doublewrite": "0"},
+		"stats-remote-db-password": "pass",
+	}
+	snapshot := map[string]string{}
+	flattenConfig("", settings, snapshot)
+	c.Assert(snapshot, qt.DeepEquals, map[string]string{
+		"exec-type":                            "oltp",
+		"ansible-playbook-files":               "[macrobench.yml]",
+		"planetscale-db-password":              redactedConfigValue,
+		"exec-mysql-config.innodb_doublewrite": "0",
+		"stats-remote-db-password":             redactedConfigValue,
+	})
+}
@@ -0,0 +1,32 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"errors"
+
+	"github.com/vitessio/arewefastyet/go/infra/ansible"
+)
+
+// IsInfraError returns true if err was caused by the infrastructure on which
+// the execution ran, rather than by the benchmarked code. Executions failing
+// with such errors can be retried without blaming the git reference.
+func IsInfraError(err error) bool {
+	return errors.Is(err, ansible.ErrHostUnreachable)
+}
@@ -0,0 +1,49 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/vitessio/arewefastyet/go/infra/ansible"
+)
+
+func TestIsInfraError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "Nil error", err: nil, want: false},
+		{name: "Code error", err: errors.New("benchmark failed"), want: false},
+		{name: "Unreachable host", err: ansible.ErrHostUnreachable, want: true},
+		{name: "Wrapped unreachable host", err: fmt.Errorf("execution step error: %w", ansible.ErrHostUnreachable), want: true},
+		{name: "Unreachable host exit code", err: fmt.Errorf("execution step error: %w", &ansible.PlaybookError{ExitCode: 3}), want: true},
+		{name: "Failed host exit code", err: &ansible.PlaybookError{ExitCode: 2}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			c.Assert(IsInfraError(tt.err), qt.Equals, tt.want)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// eventsMeasurement is the InfluxDB measurement in which the
+	// execution events are written. Dashboards use it to annotate
+	// the boundaries of executions on their charts.
+	eventsMeasurement = "events"
+
+	eventStart  = "start"
+	eventFinish = "finish"
+)
+
+// eventTags returns the tags of the event points written for the Exec.
+func (e *Exec) eventTags(event string) map[string]string {
+	return map[string]string{
+		"exec_uuid": e.UUID.String(),
+		"type":      e.TypeOf,
+		"git_ref":   e.GitRef,
+		"event":     event,
+	}
+}
+
+// writeEvent writes an event point to the stats remote database, if it is configured.
+// Failures are written to the Exec's standard error but do not fail the execution.
+func (e *Exec) writeEvent(event, status string) {
+	if !e.statsRemoteDBConfig.IsValid() {
+		return
+	}
+	client, err := e.statsRemoteDBConfig.NewInfluxClient()
+	if err != nil {
+		_, _ = fmt.Fprintf(e.stderr, "could not write %s event: %v\n", event, err)
+		return
+	}
+	defer client.Close()
+
+	fields := map[string]interface{}{
+		"status": status,
+	}
+	err = client.Write(eventsMeasurement, e.eventTags(event), fields, time.Now())
+	if err != nil {
+		_, _ = fmt.Fprintf(e.stderr, "could not write %s event: %v\n", event, err)
+	}
+}
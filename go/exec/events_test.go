@@ -0,0 +1,38 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/google/uuid"
+)
+
+func TestExec_eventTags(t *testing.T) {
+	c := qt.New(t)
+	e := &Exec{UUID: uuid.New(), TypeOf: "oltp", GitRef: "abc"}
+
+	c.Assert(e.eventTags(eventStart), qt.DeepEquals, map[string]string{
+		"exec_uuid": e.UUID.String(),
+		"type":      "oltp",
+		"git_ref":   "abc",
+		"event":     eventStart,
+	})
+}
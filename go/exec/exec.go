@@ -19,6 +19,7 @@
 package exec
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -29,12 +30,14 @@ import (
 	"os"
 	"path"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/spf13/viper"
 	"github.com/vitessio/arewefastyet/go/exec/stats"
 	"github.com/vitessio/arewefastyet/go/infra/ansible"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -57,6 +60,10 @@ const (
 	// keyGoVersion defines the golang version to use for the execution.
 	keyGoVersion = "golang_gover"
 
+	// keyExecComponent is the name of the key that stores the vitess
+	// component the execution focuses on.
+	keyExecComponent = "arewefastyet_exec_component"
+
 	stderrFile = "exec-stderr.log"
 	stdoutFile = "exec-stdout.log"
 
@@ -64,6 +71,9 @@ const (
 	ErrorExecutionTimeout = "execution timeout"
 )
 
+// ErrExecutionCanceled is returned by ExecuteWithContext when the execution is canceled.
+var ErrExecutionCanceled = errors.New("execution canceled")
+
 type Exec struct {
 	UUID          uuid.UUID
 	AnsibleConfig ansible.Config
@@ -76,22 +86,99 @@ type Exec struct {
 	StartedAt  *time.Time
 	FinishedAt *time.Time
 
+	// Error is the error that made the execution fail, it is only
+	// recorded for the executions in StatusPrepareFailed.
+	Error string
+
 	// Defines the type of execution (oltp, tpcc, micro, ...)
 	TypeOf string
 
+	// Component is the vitess component (vtgate, vttablet, ...) this execution
+	// focuses on. It is used to attribute results to a component.
+	Component string
+
+	// Provider is the infrastructure provider (equinix, aws, ...) of the servers
+	// the execution runs on. It is stored in the execution's metadata and allows
+	// to compare the results of the same git ref across providers.
+	Provider string
+
+	// PreRunScript is the path to a script that is copied to the remote hosts and
+	// executed before the benchmark. The execution fails if the script fails.
+	PreRunScript string
+
+	// Labels are arbitrary key/value pairs attached to the execution,
+	// such as the name of an experiment or the ID of a ticket.
+	Labels map[string]string
+
+	// MySQLConfig contains MySQL options (e.g. innodb_buffer_pool_size) that
+	// are added to the my.cnf of the remote hosts, overriding the default ones.
+	MySQLConfig map[string]string
+
+	// BenchmarkEnv contains environment variables (e.g. GOGC) that are passed to the
+	// benchmark process and to vtgate and vttablet on the remote hosts, see checkBenchmarkEnv.
+	BenchmarkEnv map[string]string
+
+	// Profile enables the collection of a CPU profile of vtgate during the run step
+	// of macrobenchmarks, the profile is then stored along with the execution.
+	Profile bool
+
+	// NetworkImpairment is applied to the network interfaces of the remote hosts
+	// before macrobenchmarks, it is disabled by default.
+	NetworkImpairment NetworkImpairment
+
+	// SkipPostCleanup leaves the infrastructure of macrobenchmarks up once they are
+	// over, it must then be torn down with CleanUp.
+	SkipPostCleanup bool
+
+	// LogMaxSize is the maximum size, in megabytes, of the stdout and stderr files set
+	// by SetOutputToDefaultPath before they are rotated, keeping LogMaxFiles rotated files.
+	// A zero LogMaxSize disables the rotation.
+	LogMaxSize  int
+	LogMaxFiles int
+
+	// Duration and WarmupDuration are the durations, in seconds, of the run and
+	// warm up steps of macrobenchmarks. Zero keeps the durations of the
+	// macrobenchmark configuration file.
+	Duration       int
+	WarmupDuration int
+
+	// Clients is the number of concurrent clients (sysbench threads) of macrobenchmarks.
+	// Zero keeps the number of clients of the macrobenchmark configuration file.
+	Clients int
+
+	// SanityBounds maps canonical metrics of the macrobenchmark results to their
+	// plausible range, as "min:max". The execution is marked as StatusInvalid if
+	// its results are out of range, see macrobench.ParseSanityBounds.
+	SanityBounds map[string]string
+
 	// PullNB defines the pull request number linked to this execution.
 	PullNB int
 
+	// Attempt is the number of the attempt this execution represents, starting at 1.
+	// RetriesLeft is the number of attempts that remain if this execution fails.
+	Attempt     int
+	RetriesLeft int
+
 	// Configuration used to interact with the SQL database.
 	configDB *psdb.Config
 
 	// Client to communicate with the SQL database.
-	clientDB *psdb.Client
+	clientDB storage.SQLClient
 
 	// Configuration used to authenticate and insert execution stats
 	// data to a remote database system.
 	statsRemoteDBConfig stats.RemoteDBConfig
 
+	// tracingConfig configures the export of the execution as an OpenTelemetry
+	// trace, tracer creates its spans once Execute is called.
+	tracingConfig TracingConfig
+	tracer        trace.Tracer
+
+	// preparedAt and preparedUntil are the boundaries of the Prepare step,
+	// which is added to the execution's trace.
+	preparedAt    time.Time
+	preparedUntil time.Time
+
 	// rootDir represents the parent directory of the Exec.
 	// From there, the Exec's unique directory named Exec.dirPath will
 	// be created once Exec.Prepare is called.
@@ -101,6 +188,10 @@ type Exec struct {
 	// files, and logs are kept.
 	dirPath string
 
+	// dirTemplate is an optional text/template used to name Exec.dirPath.
+	// It defaults to the execution's UUID when empty.
+	dirTemplate string
+
 	stdout io.Writer
 	stderr io.Writer
 
@@ -116,6 +207,9 @@ type Exec struct {
 
 	// ServerAddress is the IP address on which the benchmark will be executed.
 	ServerAddress string
+
+	// hooks lists the Hook registered for each HookStep using RegisterHook.
+	hooks map[HookStep][]Hook
 }
 
 const (
@@ -124,6 +218,13 @@ const (
 	SourcePullRequestBase = "cron_pr_base"
 	SourceTag             = "cron_tags_"
 	SourceReleaseBranch   = "cron_"
+	SourceMerge           = "merge"
+	SourceMergeParent     = "merge_parent"
+	SourceSync            = "sync"
+	SourceSyncBaseline    = "sync_baseline"
+	SourceBisect          = "cron_bisect"
+	SourceBackfill        = "backfill"
+	SourceGolden          = "golden"
 )
 
 // SetStdout sets the standard output of Exec.
@@ -144,12 +245,12 @@ func (e *Exec) SetOutputToDefaultPath() error {
 	if !e.prepared {
 		return errors.New(ErrorNotPrepared)
 	}
-	outFile, err := os.OpenFile(path.Join(e.dirPath, stdoutFile), os.O_RDWR|os.O_CREATE, 0755)
+	outFile, err := e.openOutputFile(stdoutFile)
 	if err != nil {
 		return err
 	}
 
-	errFile, err := os.OpenFile(path.Join(e.dirPath, stderrFile), os.O_RDWR|os.O_CREATE, 0755)
+	errFile, err := e.openOutputFile(stderrFile)
 	if err != nil {
 		return err
 	}
@@ -160,6 +261,18 @@ func (e *Exec) SetOutputToDefaultPath() error {
 	return nil
 }
 
+// openOutputFile opens the given output file of Exec.dirPath, wrapped in a rotatingWriter
+// when Exec.LogMaxSize is set.
+func (e *Exec) openOutputFile(name string) (io.Writer, error) {
+	if e.LogMaxSize <= 0 {
+		return os.OpenFile(path.Join(e.dirPath, name), os.O_RDWR|os.O_CREATE, 0755)
+	}
+	if e.LogMaxFiles < 0 {
+		return nil, fmt.Errorf("invalid number of rotated log files %d, must be positive", e.LogMaxFiles)
+	}
+	return newRotatingWriter(path.Join(e.dirPath, name), int64(e.LogMaxSize)*1024*1024, e.LogMaxFiles)
+}
+
 // Prepare prepares the Exec for a future Execution.
 func (e *Exec) Prepare() error {
 	// Returns if the execution is already prepared
@@ -168,13 +281,20 @@ func (e *Exec) Prepare() error {
 	}
 
 	var err error
+	e.preparedAt = time.Now()
 	defer func() {
 		if !e.createdInDB {
 			return
 		}
-		e.handleStepEnd(err)
+		e.handlePrepareEnd(err)
 	}()
 
+	e.AnsibleConfig.UseFilesOfType(e.TypeOf)
+	err = e.AnsibleConfig.Validate()
+	if err != nil {
+		return err
+	}
+
 	e.clientDB, err = e.configDB.NewClient()
 	if err != nil {
 		return err
@@ -182,7 +302,7 @@ func (e *Exec) Prepare() error {
 
 	// insert new exec in SQL
 	if _, err = e.clientDB.Insert(
-		"INSERT INTO execution(uuid, status, source, git_ref, type, pull_nb, go_version) VALUES(?, ?, ?, ?, ?, ?, ?)",
+		"INSERT INTO execution(uuid, status, source, git_ref, type, pull_nb, go_version, component, attempt, retries_left) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
 		e.UUID.String(),
 		StatusCreated,
 		e.Source,
@@ -190,19 +310,69 @@ func (e *Exec) Prepare() error {
 		e.TypeOf,
 		e.PullNB,
 		e.GolangVersion,
+		e.Component,
+		e.Attempt,
+		e.RetriesLeft,
 	); err != nil {
 		return err
 	}
 	e.createdInDB = true
 
+	err = e.insertLabels()
+	if err != nil {
+		return err
+	}
+
+	err = checkBenchmarkEnv(e.BenchmarkEnv)
+	if err != nil {
+		return err
+	}
+
+	err = e.NetworkImpairment.check()
+	if err != nil {
+		return err
+	}
+
+	_, err = macrobench.ParseSanityBounds(e.SanityBounds)
+	if err != nil {
+		return err
+	}
+
+	err = e.insertMetadata()
+	if err != nil {
+		return err
+	}
+
 	err = e.prepareDirectories()
 	if err != nil {
 		return err
 	}
 
+	err = SetMetadata(e.clientDB, e.UUID.String(), MetadataDirPath, e.dirPath)
+	if err != nil {
+		return err
+	}
+
+	err = e.resolvePreRunScript()
+	if err != nil {
+		return err
+	}
+
 	if e.configPath == "" {
 		e.configPath = viper.ConfigFileUsed()
 	}
+	err = e.mergeConfigDir()
+	if err != nil {
+		return err
+	}
+	// fail fast if the stats remote database is configured but cannot be used
+	if e.statsRemoteDBConfig.IsValid() {
+		err = e.statsRemoteDBConfig.VerifySchema()
+		if err != nil {
+			return fmt.Errorf("invalid stats remote database: %w", err)
+		}
+	}
+
 	e.AnsibleConfig.ExtraVars = map[string]interface{}{}
 	e.statsRemoteDBConfig.AddToAnsible(&e.AnsibleConfig)
 	if e.PullNB != 0 {
@@ -216,31 +386,68 @@ func (e *Exec) Prepare() error {
 	}
 
 	e.prepared = true
+	e.runHooks(HookPostPrepare)
+	e.preparedUntil = time.Now()
 	return nil
 }
 
 // ExecuteWithTimeout will call execution's Execute method with the given timeout.
 func (e Exec) ExecuteWithTimeout(timeout time.Duration) (err error) {
+	return e.ExecuteWithContext(context.Background(), timeout)
+}
+
+// ExecuteWithContext works like ExecuteWithTimeout, and cancels the execution if ctx is done
+// before it completes: its Ansible playbook is stopped, it is marked as StatusCanceled and
+// ErrExecutionCanceled is returned. When the execution is canceled or times out, it only
+// returns once its playbook exited and its infrastructure was cleaned up, see cleanUpStopped.
+func (e Exec) ExecuteWithContext(ctx context.Context, timeout time.Duration) (err error) {
 	defer func() {
 		e.handleStepEnd(err)
 	}()
-	errs := make(chan error)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errs := make(chan error, 1)
 
 	go func() {
-		errs <- e.Execute()
+		errs <- e.ExecuteContext(ctx)
 	}()
 
 	select {
 	case err = <-errs:
+		if err != nil && ctx.Err() != nil {
+			err = ErrExecutionCanceled
+			e.cleanUpStopped()
+		}
 		return
+	case <-ctx.Done():
+		err = ErrExecutionCanceled
 	case <-time.After(timeout):
 		err = errors.New(ErrorExecutionTimeout)
-		return
+	}
+	// the next execution must not start on hosts still running this one
+	cancel()
+	<-errs
+	e.cleanUpStopped()
+	return
+}
+
+// cleanUpStopped tears down the infrastructure of the execution once its playbook was
+// stopped, since the playbook did not reach its own cleanup. It is done regardless of
+// SkipPostCleanup, as the infrastructure of a stopped execution is never compared.
+func (e Exec) cleanUpStopped() {
+	_, err := CleanUp(context.Background(), e.clientDB, e.UUID.String(), e.TypeOf, e.AnsibleConfig)
+	if err != nil && !errors.Is(err, ErrNothingToCleanUp) && !errors.Is(err, ErrNoInventory) && !errors.Is(err, ErrNoExecutionDirectory) {
+		_, _ = fmt.Fprintf(e.stderr, "could not clean up the stopped execution: %v\n", err)
 	}
 }
 
 // Execute will provision infra, configure Ansible files, and run the given Ansible config.
 func (e *Exec) Execute() (err error) {
+	return e.ExecuteContext(context.Background())
+}
+
+// ExecuteContext works like Execute, the Ansible playbook is stopped if ctx is done.
+func (e *Exec) ExecuteContext(ctx context.Context) (err error) {
 	defer func() {
 		e.handleStepEnd(err)
 	}()
@@ -251,13 +458,27 @@ func (e *Exec) Execute() (err error) {
 	if _, err := e.clientDB.Insert("UPDATE execution SET started_at = CURRENT_TIME, status = ? WHERE uuid = ?", StatusStarted, e.UUID.String()); err != nil {
 		return err
 	}
+	e.writeEvent(eventStart, StatusStarted)
+	traceCtx, endTrace := e.startTrace()
+	defer func() {
+		status := StatusFinished
+		if err != nil {
+			status = StatusFailed
+		}
+		e.writeEvent(eventFinish, status)
+		endTrace(err)
+	}()
 
 	// TODO: optimize tokenization of Ansible files.
+	endProvision := e.startSpan(traceCtx, spanProvision)
 	err = ansible.AddIPsToFiles([]string{e.ServerAddress}, e.AnsibleConfig)
-	if err != nil {
-		return err
+	if err == nil {
+		err = ansible.AddLocalConfigPathToFiles(e.configPath, e.AnsibleConfig)
+	}
+	if err == nil {
+		err = e.saveInventory()
 	}
-	err = ansible.AddLocalConfigPathToFiles(e.configPath, e.AnsibleConfig)
+	endProvision(err)
 	if err != nil {
 		return err
 	}
@@ -265,7 +486,17 @@ func (e *Exec) Execute() (err error) {
 	e.prepareAnsibleForExecution()
 
 	// Run the given config on Ansible
-	err = ansible.Run(&e.AnsibleConfig)
+	endAnsible := e.startSpan(traceCtx, spanAnsible)
+	err = ansible.RunContext(ctx, &e.AnsibleConfig)
+	endAnsible(err)
+
+	if err == nil && e.Profile {
+		e.storeProfile()
+	}
+
+	endHooks := e.startSpan(traceCtx, spanHooks)
+	e.runHooks(HookPostExecute)
+	endHooks(nil)
 	if err != nil {
 		return err
 	}
@@ -278,6 +509,34 @@ func (e *Exec) prepareAnsibleForExecution() {
 	e.AnsibleConfig.ExtraVars[keyExecSource] = e.Source
 	e.AnsibleConfig.ExtraVars[keyExecutionType] = e.TypeOf
 	e.AnsibleConfig.ExtraVars[keyGoVersion] = e.GolangVersion
+	e.AnsibleConfig.ExtraVars[keyExecComponent] = e.Component
+	if e.PreRunScript != "" {
+		e.AnsibleConfig.ExtraVars[keyPreRunScript] = e.PreRunScript
+	}
+	if len(e.MySQLConfig) > 0 {
+		e.AnsibleConfig.ExtraVars[keyMySQLConfig] = e.MySQLConfig
+	}
+	if len(e.BenchmarkEnv) > 0 {
+		e.AnsibleConfig.ExtraVars[keyBenchmarkEnv] = e.BenchmarkEnv
+	}
+	if e.Profile {
+		e.AnsibleConfig.ExtraVars[keyProfileDir] = e.dirPath
+	}
+	if e.NetworkImpairment.Enabled() {
+		e.AnsibleConfig.ExtraVars[keyNetworkImpairment] = e.NetworkImpairment.extraVars()
+	}
+	if e.Duration > 0 {
+		e.AnsibleConfig.ExtraVars[keyDuration] = e.Duration
+	}
+	if e.WarmupDuration > 0 {
+		e.AnsibleConfig.ExtraVars[keyWarmupDuration] = e.WarmupDuration
+	}
+	if e.Clients > 0 {
+		e.AnsibleConfig.ExtraVars[keyClients] = e.Clients
+	}
+	if e.SkipPostCleanup {
+		e.AnsibleConfig.ExtraVars[keySkipPostCleanup] = true
+	}
 
 	// not adding the -planner_version flag to ansible if we did not specify it or if using the default value
 	if e.VtgatePlannerVersion == string(macrobench.Gen4FallbackPlanner) {
@@ -286,8 +545,8 @@ func (e *Exec) prepareAnsibleForExecution() {
 }
 
 func (e *Exec) Success() error {
-	// checking if the execution has not already failed
-	rows, err := e.clientDB.Select("SELECT uuid FROM execution WHERE uuid = ? AND status = ?", e.UUID.String(), StatusFailed)
+	// checking if the execution has not already failed, failed to prepare or timed out
+	rows, err := e.clientDB.Select("SELECT uuid FROM execution WHERE uuid = ? AND status IN (?, ?, ?, ?)", e.UUID.String(), StatusFailed, StatusTimedOut, StatusPrepareFailed, StatusCanceled)
 	if err != nil {
 		return err
 	}
@@ -295,21 +554,45 @@ func (e *Exec) Success() error {
 	if rows.Next() {
 		return nil
 	}
+
+	// the results are checked first so that the execution is never seen as finished,
+	// and compared, before being marked as invalid
+	err = e.checkResults()
+	if err != nil {
+		return err
+	}
 	_, err = e.clientDB.Insert("UPDATE execution SET finished_at = CURRENT_TIME, status = ? WHERE uuid = ?", StatusFinished, e.UUID.String())
 	return err
 }
 
-func (e *Exec) handleStepEnd(err error) {
+// handlePrepareEnd marks the execution as StatusPrepareFailed and records
+// err if Prepare failed, distinguishing it from the failures of the benchmark.
+func (e *Exec) handlePrepareEnd(err error) {
 	if err != nil {
-		_, _ = e.clientDB.Insert("UPDATE execution SET finished_at = CURRENT_TIME, status = ? WHERE uuid = ?", StatusFailed, e.UUID.String())
+		e.Status = StatusPrepareFailed
+		e.Error = err.Error()
+		_, _ = e.clientDB.Insert("UPDATE execution SET finished_at = CURRENT_TIME, status = ?, error = ? WHERE uuid = ?", StatusPrepareFailed, e.Error, e.UUID.String())
+	}
+}
+
+func (e *Exec) handleStepEnd(err error) {
+	if err == nil {
+		return
+	}
+	if errors.Is(err, ErrExecutionCanceled) {
+		_, _ = e.clientDB.Insert("UPDATE execution SET finished_at = CURRENT_TIME, status = ? WHERE uuid = ?", StatusCanceled, e.UUID.String())
+		return
 	}
+	// the playbook of a canceled execution fails once stopped, it must stay canceled
+	_, _ = e.clientDB.Insert("UPDATE execution SET finished_at = CURRENT_TIME, status = ? WHERE uuid = ? AND status <> ?", StatusFailed, e.UUID.String(), StatusCanceled)
 }
 
 // NewExec creates a new *Exec with an autogenerated uuid.UUID as well
 // as a constructed infra.Infra.
 func NewExec() (*Exec, error) {
 	ex := Exec{
-		UUID:  uuid.New(),
+		UUID:    uuid.New(),
+		Attempt: 1,
 
 		// By default Exec prints os.Stdout and os.Stderr.
 		// This can be changed later by explicitly using
@@ -326,6 +609,8 @@ func NewExec() (*Exec, error) {
 
 // NewExecWithConfig will create a new Exec using the NewExec method, and will
 // use viper.Viper to apply the configuration located at pathConfig.
+// pathConfig is either a configuration file or a directory of configuration
+// files that are merged together, see ReadConfig.
 func NewExecWithConfig(pathConfig string) (*Exec, error) {
 	e, err := NewExec()
 	if err != nil {
@@ -333,8 +618,7 @@ func NewExecWithConfig(pathConfig string) (*Exec, error) {
 	}
 	v := viper.New()
 
-	v.SetConfigFile(pathConfig)
-	if err := v.ReadInConfig(); err != nil {
+	if err := ReadConfig(v, pathConfig); err != nil {
 		return nil, err
 	}
 
@@ -386,6 +670,211 @@ func GetRecentExecutions(client storage.SQLClient) ([]*Exec, error) {
 	return res, nil
 }
 
+// MarkStuckExecutionsAsTimedOut marks all the executions that have been in the StatusStarted
+// status for longer than maxDuration as StatusTimedOut. The executions that were marked are returned.
+func MarkStuckExecutionsAsTimedOut(client storage.SQLClient, maxDuration time.Duration) ([]*Exec, error) {
+	query := "SELECT uuid, status, git_ref, started_at, finished_at, source, type, pull_nb, go_version FROM execution WHERE status = ? AND started_at < DATE_SUB(NOW(), INTERVAL ? SECOND)"
+	result, err := client.Select(query, StatusStarted, int64(maxDuration.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	var res []*Exec
+	for result.Next() {
+		var eUUID string
+		exec := &Exec{}
+		err = result.Scan(&eUUID, &exec.Status, &exec.GitRef, &exec.StartedAt, &exec.FinishedAt, &exec.Source, &exec.TypeOf, &exec.PullNB, &exec.GolangVersion)
+		if err != nil {
+			return nil, err
+		}
+		exec.UUID, err = uuid.Parse(eUUID)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, exec)
+	}
+
+	for _, exec := range res {
+		// the status is checked again in case the execution ended in the meantime
+		_, err = client.Insert("UPDATE execution SET finished_at = CURRENT_TIME, status = ? WHERE uuid = ? AND status = ?", StatusTimedOut, exec.UUID.String(), StatusStarted)
+		if err != nil {
+			return nil, err
+		}
+		exec.Status = StatusTimedOut
+	}
+	return res, nil
+}
+
+// MarkAbandonedExecutionsAsTimedOut marks the executions of the given git ref, source, type and
+// pull request number that are in the StatusStarted status as StatusTimedOut. It is meant for the
+// executions that were abandoned, for instance because the server running them stopped.
+func MarkAbandonedExecutionsAsTimedOut(client storage.SQLClient, gitRef, source, typeOf string, pullNB int) error {
+	_, err := client.Insert("UPDATE execution SET finished_at = CURRENT_TIME, status = ? WHERE status = ? AND git_ref = ? AND source = ? AND type = ? AND pull_nb = ?",
+		StatusTimedOut, StatusStarted, gitRef, source, typeOf, pullNB)
+	return err
+}
+
+// SoftDelete marks the execution execUUID as deleted. Its record and results are kept,
+// but they are no longer used by comparisons and are no longer picked as a baseline.
+// The execution can be restored with Restore.
+func SoftDelete(client storage.SQLClient, execUUID string) error {
+	_, err := client.Insert("UPDATE execution SET deleted_at = CURRENT_TIMESTAMP WHERE uuid = ? AND deleted_at IS NULL", execUUID)
+	return err
+}
+
+// Restore undoes the soft deletion of the execution execUUID, see SoftDelete.
+func Restore(client storage.SQLClient, execUUID string) error {
+	_, err := client.Insert("UPDATE execution SET deleted_at = NULL WHERE uuid = ?", execUUID)
+	return err
+}
+
+// FindFailed returns the most recent executions that did not succeed: the ones that
+// failed, timed out or failed to be prepared. If source is not empty, only the executions
+// of this source are returned.
+func FindFailed(client storage.SQLClient, source string) ([]*Exec, error) {
+	query := "SELECT uuid, status, git_ref, started_at, finished_at, source, type, pull_nb, go_version, IFNULL(error, '') " +
+		"FROM execution WHERE status IN (?, ?, ?)"
+	args := []interface{}{StatusFailed, StatusTimedOut, StatusPrepareFailed}
+	if source != "" {
+		query += " AND source = ?"
+		args = append(args, source)
+	}
+	query += " ORDER BY finished_at DESC LIMIT 50"
+	return selectFailed(client, query, args...)
+}
+
+// FindFailedBetween returns the executions that failed, timed out, or failed before starting
+// between from and to, ordered by the time they ended. The executions can be restricted to a
+// single source and type, all of them are returned if source or typeOf is empty.
+func FindFailedBetween(client storage.SQLClient, from, to time.Time, source, typeOf string) ([]*Exec, error) {
+	query := "SELECT uuid, status, git_ref, started_at, finished_at, source, type, pull_nb, go_version, IFNULL(error, '') " +
+		"FROM execution WHERE status IN (?, ?, ?) AND finished_at BETWEEN ? AND ? AND deleted_at IS NULL"
+	args := []interface{}{StatusFailed, StatusTimedOut, StatusPrepareFailed, from.UTC(), to.UTC()}
+	if source != "" {
+		query += " AND source = ?"
+		args = append(args, source)
+	}
+	if typeOf != "" {
+		query += " AND type = ?"
+		args = append(args, typeOf)
+	}
+	query += " ORDER BY finished_at"
+	return selectFailed(client, query, args...)
+}
+
+// selectFailed runs a query selecting failed executions, as done by FindFailed.
+func selectFailed(client storage.SQLClient, query string, args ...interface{}) ([]*Exec, error) {
+	result, err := client.Select(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	var res []*Exec
+	for result.Next() {
+		var eUUID string
+		exec := &Exec{}
+		err = result.Scan(&eUUID, &exec.Status, &exec.GitRef, &exec.StartedAt, &exec.FinishedAt, &exec.Source, &exec.TypeOf, &exec.PullNB, &exec.GolangVersion, &exec.Error)
+		if err != nil {
+			return nil, err
+		}
+		exec.UUID, err = uuid.Parse(eUUID)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, exec)
+	}
+	return res, nil
+}
+
+// CountFinishedExecutionsSince returns the number of executions that finished successfully since the given time.
+func CountFinishedExecutionsSince(client storage.SQLClient, since time.Time) (int, error) {
+	result, err := client.Select("SELECT COUNT(uuid) FROM execution WHERE status = ? AND finished_at >= ?", StatusFinished, since.UTC())
+	if err != nil {
+		return 0, err
+	}
+	defer result.Close()
+
+	var count int
+	if result.Next() {
+		err = result.Scan(&count)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+// GetAverageDurations returns the average duration of the executions of each type
+// that finished successfully since the given time.
+func GetAverageDurations(client storage.SQLClient, since time.Time) (map[string]time.Duration, error) {
+	query := "SELECT type, AVG(TIMESTAMPDIFF(SECOND, started_at, finished_at)) FROM execution " +
+		"WHERE status = ? AND finished_at >= ? AND started_at IS NOT NULL AND deleted_at IS NULL GROUP BY type"
+	result, err := client.Select(query, StatusFinished, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	durations := map[string]time.Duration{}
+	for result.Next() {
+		var typeOf string
+		var seconds float64
+		if err := result.Scan(&typeOf, &seconds); err != nil {
+			return nil, err
+		}
+		durations[typeOf] = time.Duration(seconds * float64(time.Second))
+	}
+	return durations, nil
+}
+
+// GetExecution returns the execution identified by the given UUID, or
+// nil if it does not exist.
+func GetExecution(client storage.SQLClient, execUUID uuid.UUID) (*Exec, error) {
+	query := "SELECT uuid, status, git_ref, started_at, finished_at, source, type, pull_nb, go_version FROM execution WHERE uuid = ?"
+	result, err := client.Select(query, execUUID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+	if !result.Next() {
+		return nil, nil
+	}
+	var eUUID string
+	exec := &Exec{}
+	err = result.Scan(&eUUID, &exec.Status, &exec.GitRef, &exec.StartedAt, &exec.FinishedAt, &exec.Source, &exec.TypeOf, &exec.PullNB, &exec.GolangVersion)
+	if err != nil {
+		return nil, err
+	}
+	exec.UUID, err = uuid.Parse(eUUID)
+	if err != nil {
+		return nil, err
+	}
+	return exec, nil
+}
+
+// GetProvisionDuration returns how long the infrastructure of the execution execUUID took to
+// be provisioned, see the provision package. False is returned if it is unknown, which is the
+// case of the executions whose benchmark did not start.
+func GetProvisionDuration(client storage.SQLClient, execUUID string) (time.Duration, bool, error) {
+	result, err := client.Select("SELECT provision_duration FROM execution WHERE uuid = ?", execUUID)
+	if err != nil {
+		return 0, false, err
+	}
+	defer result.Close()
+	var seconds sql.NullInt64
+	if result.Next() {
+		if err := result.Scan(&seconds); err != nil {
+			return 0, false, err
+		}
+	}
+	if !seconds.Valid {
+		return 0, false, nil
+	}
+	return time.Duration(seconds.Int64) * time.Second, true, nil
+}
+
 func GetFinishedExecution(client storage.SQLClient, gitRef, source, benchmarkType, plannerVersion string, pullNb int) (string, error) {
 	var eUUID string
 	var result *sql.Rows
@@ -393,11 +882,11 @@ func GetFinishedExecution(client storage.SQLClient, gitRef, source, benchmarkTyp
 	query := ""
 	if plannerVersion == "" {
 		// no plannerVersion, meaning we are dealing with a micro benchmark
-		query = "SELECT e.uuid FROM execution e WHERE e.source = ? AND e.status = ? AND e.type = ? AND e.git_ref = ? AND e.pull_nb = ? ORDER BY e.finished_at DESC LIMIT 1"
+		query = "SELECT e.uuid FROM execution e WHERE e.source = ? AND e.status = ? AND e.type = ? AND e.git_ref = ? AND e.pull_nb = ? AND e.deleted_at IS NULL ORDER BY e.finished_at DESC LIMIT 1"
 		result, err = client.Select(query, source, StatusFinished, benchmarkType, gitRef, pullNb)
 	} else {
 		// we have a plannerVersion, meaning we are dealing with a macro benchmark
-		query = "SELECT e.uuid FROM execution e, macrobenchmark m WHERE e.uuid = m.exec_uuid AND m.vtgate_planner_version = ? AND e.source = ? AND e.status = ? AND e.type = ? AND e.git_ref = ? AND e.pull_nb = ? ORDER BY e.finished_at DESC LIMIT 1"
+		query = "SELECT e.uuid FROM execution e, macrobenchmark m WHERE e.uuid = m.exec_uuid AND m.vtgate_planner_version = ? AND e.source = ? AND e.status = ? AND e.type = ? AND e.git_ref = ? AND e.pull_nb = ? AND e.deleted_at IS NULL ORDER BY e.finished_at DESC LIMIT 1"
 		result, err = client.Select(query, plannerVersion, source, StatusFinished, benchmarkType, gitRef, pullNb)
 	}
 	if err != nil {
@@ -413,11 +902,151 @@ func GetFinishedExecution(client storage.SQLClient, gitRef, source, benchmarkTyp
 	return eUUID, nil
 }
 
-// GetPreviousFromSourceMicrobenchmark gets the previous execution from the same source for microbenchmarks
-func GetPreviousFromSourceMicrobenchmark(client storage.SQLClient, source, gitRef string) (execUUID, gitRefOut string, err error) {
+// FindFinishedOfGitRefs returns the finished executions of the given git refs, with their git ref,
+// source, type, pull request number and, for the macrobenchmarks, vtgate planner version set.
+// It finds the executions of many git refs at once, where GetFinishedExecution finds a single one.
+func FindFinishedOfGitRefs(client storage.SQLClient, gitRefs []string) ([]*Exec, error) {
+	if len(gitRefs) == 0 {
+		return nil, nil
+	}
+	query := "SELECT DISTINCT e.git_ref, e.source, e.type, e.pull_nb, IFNULL(m.vtgate_planner_version, '') FROM execution e " +
+		"LEFT JOIN macrobenchmark m ON e.uuid = m.exec_uuid WHERE e.status = ? AND e.deleted_at IS NULL " +
+		"AND e.git_ref IN (?" + strings.Repeat(", ?", len(gitRefs)-1) + ")"
+	args := []interface{}{StatusFinished}
+	for _, gitRef := range gitRefs {
+		args = append(args, gitRef)
+	}
+	result, err := client.Select(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	var res []*Exec
+	for result.Next() {
+		exec := &Exec{}
+		err = result.Scan(&exec.GitRef, &exec.Source, &exec.TypeOf, &exec.PullNB, &exec.VtgatePlannerVersion)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, exec)
+	}
+	return res, nil
+}
+
+// GetLatestFinishedExecutionFromSource returns the UUID and git ref of the latest finished execution
+// of the given source and type. The plannerVersion must be empty for microbenchmarks.
+// Empty strings are returned if there is no such execution.
+func GetLatestFinishedExecutionFromSource(client storage.SQLClient, source, benchmarkType, plannerVersion string) (execUUID, gitRef string, err error) {
+	var result *sql.Rows
+	if plannerVersion == "" {
+		query := "SELECT e.uuid, e.git_ref FROM execution e WHERE e.source = ? AND e.status = ? AND e.type = ? AND e.deleted_at IS NULL ORDER BY e.finished_at DESC LIMIT 1"
+		result, err = client.Select(query, source, StatusFinished, benchmarkType)
+	} else {
+		query := "SELECT e.uuid, e.git_ref FROM execution e, macrobenchmark m WHERE e.uuid = m.exec_uuid AND m.vtgate_planner_version = ? AND e.source = ? AND e.status = ? AND e.type = ? AND e.deleted_at IS NULL ORDER BY e.finished_at DESC LIMIT 1"
+		result, err = client.Select(query, plannerVersion, source, StatusFinished, benchmarkType)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	defer result.Close()
+	if result.Next() {
+		err = result.Scan(&execUUID, &gitRef)
+		if err != nil {
+			return "", "", err
+		}
+	}
+	return execUUID, gitRef, nil
+}
+
+// GetLatestFinishedExecutionForProvider returns the UUID of the latest finished execution of
+// gitRef for the given benchmark type that ran on provider. An empty UUID is returned
+// if there is no such execution.
+func GetLatestFinishedExecutionForProvider(client storage.SQLClient, gitRef, benchmarkType, provider string) (execUUID string, err error) {
+	query := "SELECT e.uuid FROM execution e, execution_metadata m WHERE e.uuid = m.exec_uuid AND m.metadata_key = ? AND m.metadata_value = ? " +
+		"AND e.git_ref = ? AND e.status = ? AND e.type = ? AND e.deleted_at IS NULL ORDER BY e.finished_at DESC LIMIT 1"
+	result, err := client.Select(query, MetadataProvider, provider, gitRef, StatusFinished, benchmarkType)
+	if err != nil {
+		return "", err
+	}
+	defer result.Close()
+	if result.Next() {
+		err = result.Scan(&execUUID)
+		if err != nil {
+			return "", err
+		}
+	}
+	return execUUID, nil
+}
+
+// ErrNotEnoughExecutions is returned by GetNthFinished when there are fewer finished executions than requested.
+var ErrNotEnoughExecutions = errors.New("not enough finished executions")
+
+// GetNthFinished returns the git ref and UUID of the nth most recent finished execution of the given
+// source and type, n starting at 1 for the most recent one. Soft-deleted executions and executions whose
+// benchmark exited with a non-zero code are not counted. ErrNotEnoughExecutions is returned if there
+// are fewer than n such executions.
+func GetNthFinished(client storage.SQLClient, source, typeOf string, n int) (gitRef, execUUID string, err error) {
+	if n < 1 {
+		return "", "", fmt.Errorf("invalid execution rank %d, it must start at 1", n)
+	}
+	query := "SELECT e.git_ref, e.uuid FROM execution e WHERE e.source = ? AND e.type = ? AND e.status = ? " +
+		"AND e.deleted_at IS NULL AND IFNULL(e.benchmark_exit_code, 0) = 0 ORDER BY e.finished_at DESC LIMIT 1 OFFSET ?"
+	result, err := client.Select(query, source, typeOf, StatusFinished, n-1)
+	if err != nil {
+		return "", "", err
+	}
+	defer result.Close()
+	if !result.Next() {
+		return "", "", fmt.Errorf("%w: source %s has fewer than %d finished %s executions", ErrNotEnoughExecutions, source, n, typeOf)
+	}
+	err = result.Scan(&gitRef, &execUUID)
+	if err != nil {
+		return "", "", err
+	}
+	return gitRef, execUUID, nil
+}
+
+// DistinctSources returns the distinct sources of the executions, sorted alphabetically.
+func DistinctSources(client storage.SQLClient) ([]string, error) {
+	return distinctValues(client, "source")
+}
+
+// DistinctTypes returns the distinct benchmark types of the executions, sorted alphabetically.
+func DistinctTypes(client storage.SQLClient) ([]string, error) {
+	return distinctValues(client, "type")
+}
+
+// distinctValues returns the distinct non-empty values of the given column of the
+// execution table, which must be indexed for the query to remain cheap.
+func distinctValues(client storage.SQLClient, column string) ([]string, error) {
+	query := fmt.Sprintf("SELECT DISTINCT %[1]s FROM execution WHERE %[1]s IS NOT NULL AND %[1]s != '' ORDER BY %[1]s", column)
+	result, err := client.Select(query)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	values := []string{}
+	for result.Next() {
+		var value string
+		err = result.Scan(&value)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// GetPreviousFromSourceMicrobenchmark gets the previous execution from the same source for microbenchmarks.
+// Executions older than maxAge are ignored, unless maxAge is zero.
+func GetPreviousFromSourceMicrobenchmark(client storage.SQLClient, source, gitRef string, maxAge time.Duration) (execUUID, gitRefOut string, err error) {
 	query := "SELECT e.uuid, e.git_ref FROM execution e WHERE e.source = ? AND e.status = 'finished' AND " +
-		"e.type = \"micro\" AND e.git_ref != ? ORDER BY e.started_at DESC LIMIT 1"
-	result, err := client.Select(query, source, gitRef)
+		"e.type = \"micro\" AND e.git_ref != ? AND e.deleted_at IS NULL AND IFNULL(e.benchmark_exit_code, 0) = 0"
+	args := []interface{}{source, gitRef}
+	query, args = withMaxAge(query, args, maxAge)
+	result, err := client.Select(query+" ORDER BY e.started_at DESC LIMIT 1", args...)
 	if err != nil {
 		return
 	}
@@ -431,11 +1060,14 @@ func GetPreviousFromSourceMicrobenchmark(client storage.SQLClient, source, gitRe
 	return
 }
 
-// GetPreviousFromSourceMacrobenchmark gets the previous execution from the same source with the sane plannerVersion for macrobenchmarks
-func GetPreviousFromSourceMacrobenchmark(client storage.SQLClient, source, typeOf, plannerVersion, gitRef string) (execUUID, gitRefOut string, err error) {
+// GetPreviousFromSourceMacrobenchmark gets the previous execution from the same source with the sane plannerVersion for macrobenchmarks.
+// Executions older than maxAge are ignored, unless maxAge is zero.
+func GetPreviousFromSourceMacrobenchmark(client storage.SQLClient, source, typeOf, plannerVersion, gitRef string, maxAge time.Duration) (execUUID, gitRefOut string, err error) {
 	query := "SELECT e.uuid, e.git_ref FROM execution e, macrobenchmark m WHERE e.source = ? AND e.status = 'finished' AND " +
-		"e.type = ? AND e.git_ref != ? AND m.exec_uuid = e.uuid AND m.vtgate_planner_version = ? ORDER BY e.started_at DESC LIMIT 1"
-	result, err := client.Select(query, source, typeOf, gitRef, plannerVersion)
+		"e.type = ? AND e.git_ref != ? AND m.exec_uuid = e.uuid AND m.vtgate_planner_version = ? AND e.deleted_at IS NULL AND IFNULL(e.benchmark_exit_code, 0) = 0"
+	args := []interface{}{source, typeOf, gitRef, plannerVersion}
+	query, args = withMaxAge(query, args, maxAge)
+	result, err := client.Select(query+" ORDER BY e.started_at DESC LIMIT 1", args...)
 	if err != nil {
 		return
 	}
@@ -449,10 +1081,19 @@ func GetPreviousFromSourceMacrobenchmark(client storage.SQLClient, source, typeO
 	return
 }
 
+// withMaxAge restricts the given query on the execution table to the executions
+// that started less than maxAge ago. The query is untouched if maxAge is zero.
+func withMaxAge(query string, args []interface{}, maxAge time.Duration) (string, []interface{}) {
+	if maxAge <= 0 {
+		return query, args
+	}
+	return query + " AND e.started_at >= ?", append(args, time.Now().Add(-maxAge))
+}
+
 // GetLatestCronJobForMicrobenchmarks will fetch and return the commit sha for which
 // the last cron job for microbenchmarks was run
 func GetLatestCronJobForMicrobenchmarks(client storage.SQLClient) (gitSha string, err error) {
-	query := "select git_ref from execution where source = \"cron\" and status = \"finished\" and type = \"micro\" order by started_at desc limit 1"
+	query := "select git_ref from execution where source = \"cron\" and status = \"finished\" and type = \"micro\" and deleted_at is null order by started_at desc limit 1"
 	rows, err := client.Select(query)
 	if err != nil {
 		return "", err
@@ -469,7 +1110,7 @@ func GetLatestCronJobForMicrobenchmarks(client storage.SQLClient) (gitSha string
 // GetLatestCronJobForMacrobenchmarks will fetch and return the commit sha for which
 // the last cron job for macrobenchmarks was run
 func GetLatestCronJobForMacrobenchmarks(client storage.SQLClient) (gitSha string, err error) {
-	query := "select git_ref from execution where source = \"cron\" and status = \"finished\" and ( type = \"oltp\" or type = \"tpcc\" ) order by started_at desc limit 1"
+	query := "select git_ref from execution where source = \"cron\" and status = \"finished\" and ( type = \"oltp\" or type = \"tpcc\" ) and deleted_at is null order by started_at desc limit 1"
 	rows, err := client.Select(query)
 	if err != nil {
 		return "", err
@@ -0,0 +1,197 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/google/uuid"
+	"github.com/vitessio/arewefastyet/go/storage/mysql/mysqltest"
+)
+
+func TestMarkAbandonedExecutionsAsTimedOut(t *testing.T) {
+	c := qt.New(t)
+	client := mysqltest.New(t)
+
+	insert := func(status, gitRef string) uuid.UUID {
+		execUUID := uuid.New()
+		_, err := client.Insert("INSERT INTO execution(uuid, status, source, git_ref, type, pull_nb) VALUES(?, ?, 'cron', ?, 'oltp', 0)", execUUID.String(), status, gitRef)
+		c.Assert(err, qt.IsNil)
+		return execUUID
+	}
+	abandoned := insert(StatusStarted, "abandoned")
+	finished := insert(StatusFinished, "abandoned")
+	other := insert(StatusStarted, "other")
+
+	c.Assert(MarkAbandonedExecutionsAsTimedOut(client, "abandoned", "cron", "oltp", 0), qt.IsNil)
+
+	for execUUID, want := range map[uuid.UUID]string{abandoned: StatusTimedOut, finished: StatusFinished, other: StatusStarted} {
+		e, err := GetExecution(client, execUUID)
+		c.Assert(err, qt.IsNil)
+		c.Assert(e.Status, qt.Equals, want)
+	}
+}
+
+func TestGetNthFinished(t *testing.T) {
+	c := qt.New(t)
+	client := mysqltest.New(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	insert := func(gitRef, status, typeOf string, age time.Duration, exitCode int, deleted bool) string {
+		execUUID := uuid.New().String()
+		_, err := client.Insert("INSERT INTO execution(uuid, status, source, git_ref, type, pull_nb, finished_at, benchmark_exit_code) VALUES(?, ?, 'cron', ?, ?, 0, ?, ?)",
+			execUUID, status, gitRef, typeOf, now.Add(-age), exitCode)
+		c.Assert(err, qt.IsNil)
+		if deleted {
+			c.Assert(SoftDelete(client, execUUID), qt.IsNil)
+		}
+		return execUUID
+	}
+	latest := insert("latest", StatusFinished, "oltp", time.Hour, 0, false)
+	insert("failed", StatusFailed, "oltp", 2*time.Hour, 0, false)
+	insert("exited", StatusFinished, "oltp", 3*time.Hour, 1, false)
+	insert("deleted", StatusFinished, "oltp", 4*time.Hour, 0, true)
+	insert("other type", StatusFinished, "tpcc", 5*time.Hour, 0, false)
+	oldest := insert("oldest", StatusFinished, "oltp", 6*time.Hour, 0, false)
+
+	tests := []struct {
+		name       string
+		n          int
+		wantGitRef string
+		wantUUID   string
+		wantErr    error
+	}{
+		{name: "most recent", n: 1, wantGitRef: "latest", wantUUID: latest},
+		{name: "skips the executions that do not count", n: 2, wantGitRef: "oldest", wantUUID: oldest},
+		{name: "fewer executions than requested", n: 3, wantErr: ErrNotEnoughExecutions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			gitRef, execUUID, err := GetNthFinished(client, SourceCron, "oltp", tt.n)
+			if tt.wantErr != nil {
+				c.Assert(errors.Is(err, tt.wantErr), qt.IsTrue)
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			c.Assert(gitRef, qt.Equals, tt.wantGitRef)
+			c.Assert(execUUID, qt.Equals, tt.wantUUID)
+		})
+	}
+
+	_, _, err := GetNthFinished(client, SourceCron, "oltp", 0)
+	c.Assert(err, qt.ErrorMatches, "invalid execution rank 0, it must start at 1")
+}
+
+func TestDistinctSourcesAndTypes(t *testing.T) {
+	c := qt.New(t)
+	client := mysqltest.New(t)
+
+	sources, err := DistinctSources(client)
+	c.Assert(err, qt.IsNil)
+	c.Assert(sources, qt.DeepEquals, []string{})
+
+	for _, execution := range []struct{ source, typeOf string }{
+		{source: SourceCron, typeOf: "oltp"},
+		{source: SourceCron, typeOf: "tpcc"},
+		{source: SourceTag + "14.0.0", typeOf: "oltp"},
+		{source: "", typeOf: "micro"},
+	} {
+		_, err := client.Insert("INSERT INTO execution(uuid, status, source, git_ref, type, pull_nb) VALUES(?, ?, ?, 'ref', ?, 0)",
+			uuid.New().String(), StatusFinished, execution.source, execution.typeOf)
+		c.Assert(err, qt.IsNil)
+	}
+
+	sources, err = DistinctSources(client)
+	c.Assert(err, qt.IsNil)
+	c.Assert(sources, qt.DeepEquals, []string{SourceCron, SourceTag + "14.0.0"})
+
+	types, err := DistinctTypes(client)
+	c.Assert(err, qt.IsNil)
+	c.Assert(types, qt.DeepEquals, []string{"micro", "oltp", "tpcc"})
+}
+
+func TestFindFinishedOfGitRefs(t *testing.T) {
+	c := qt.New(t)
+	client := mysqltest.New(t)
+
+	executions, err := FindFinishedOfGitRefs(client, nil)
+	c.Assert(err, qt.IsNil)
+	c.Assert(executions, qt.HasLen, 0)
+
+	insert := func(status, gitRef, typeOf, planner string) {
+		execUUID := uuid.New().String()
+		_, err := client.Insert("INSERT INTO execution(uuid, status, source, git_ref, type, pull_nb) VALUES(?, ?, 'cron', ?, ?, 0)", execUUID, status, gitRef, typeOf)
+		c.Assert(err, qt.IsNil)
+		if planner != "" {
+			_, err = client.Insert("INSERT INTO macrobenchmark(exec_uuid, commit, source, vtgate_planner_version) VALUES(?, ?, 'cron', ?)", execUUID, gitRef, planner)
+			c.Assert(err, qt.IsNil)
+		}
+	}
+	insert(StatusFinished, "a", "micro", "")
+	insert(StatusFinished, "a", "oltp", "Gen4")
+	insert(StatusStarted, "b", "micro", "")
+	insert(StatusFinished, "c", "micro", "")
+
+	executions, err = FindFinishedOfGitRefs(client, []string{"a", "b"})
+	c.Assert(err, qt.IsNil)
+	got := map[string]string{}
+	for _, e := range executions {
+		c.Assert(e.GitRef, qt.Equals, "a")
+		got[e.TypeOf] = e.VtgatePlannerVersion
+	}
+	c.Assert(got, qt.DeepEquals, map[string]string{"micro": "", "oltp": "Gen4"})
+}
+
+func TestExec_handlePrepareEnd(t *testing.T) {
+	c := qt.New(t)
+	client := mysqltest.New(t)
+
+	insert := func() *Exec {
+		e := &Exec{UUID: uuid.New(), clientDB: client}
+		_, err := client.Insert("INSERT INTO execution(uuid, status, source, git_ref, type, pull_nb, go_version) VALUES(?, ?, 'cron', 'ref', 'oltp', 0, '')", e.UUID.String(), StatusStarted)
+		c.Assert(err, qt.IsNil)
+		return e
+	}
+
+	prepared := insert()
+	prepared.handlePrepareEnd(nil)
+	got, err := GetExecution(client, prepared.UUID)
+	c.Assert(err, qt.IsNil)
+	c.Assert(got.Status, qt.Equals, StatusStarted)
+
+	failed := insert()
+	failed.handlePrepareEnd(errors.New("could not create the execution directory"))
+	c.Assert(failed.Status, qt.Equals, StatusPrepareFailed)
+	c.Assert(failed.Error, qt.Equals, "could not create the execution directory")
+	got, err = GetExecution(client, failed.UUID)
+	c.Assert(err, qt.IsNil)
+	c.Assert(got.Status, qt.Equals, StatusPrepareFailed)
+	c.Assert(got.FinishedAt, qt.Not(qt.IsNil))
+
+	// the error is listed along with the failed executions
+	failedExecutions, err := FindFailedBetween(client, time.Now().Add(-24*time.Hour), time.Now().Add(24*time.Hour), "", "")
+	c.Assert(err, qt.IsNil)
+	c.Assert(failedExecutions, qt.HasLen, 1)
+	c.Assert(failedExecutions[0].UUID, qt.Equals, failed.UUID)
+	c.Assert(failedExecutions[0].Error, qt.Equals, "could not create the execution directory")
+}
@@ -0,0 +1,53 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+// Package exitcode records the exit code of the command that runs the benchmark
+// of an execution. A benchmark that ran but exited with a non-zero code may have
+// produced results, those are kept but are not used by comparisons.
+package exitcode
+
+import (
+	"errors"
+	"os/exec"
+
+	"github.com/vitessio/arewefastyet/go/storage"
+)
+
+// FromError returns the exit code of the command that returned err, and whether
+// err comes from the command's non-zero exit. Other errors, such as a missing
+// executable, prevented the command from running and do not have an exit code.
+func FromError(err error) (int, bool) {
+	if err == nil {
+		return 0, true
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), true
+	}
+	return 0, false
+}
+
+// Record stores the exit code of the benchmark command of the execution execUUID.
+// Nothing is stored if the benchmark is not linked to an execution.
+func Record(client storage.SQLClient, execUUID string, code int) error {
+	if execUUID == "" {
+		return nil
+	}
+	_, err := client.Insert("UPDATE execution SET benchmark_exit_code = ? WHERE uuid = ?", code, execUUID)
+	return err
+}
@@ -0,0 +1,51 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exitcode
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestFromError(t *testing.T) {
+	exitErr := exec.Command("sh", "-c", "exit 3").Run()
+	_, notFoundErr := exec.LookPath("arewefastyet-command-that-does-not-exist")
+
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantOK   bool
+	}{
+		{name: "no error", err: nil, wantCode: 0, wantOK: true},
+		{name: "non-zero exit", err: exitErr, wantCode: 3, wantOK: true},
+		{name: "command not found", err: notFoundErr, wantCode: 0, wantOK: false},
+		{name: "other error", err: errors.New("failure"), wantCode: 0, wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, ok := FromError(tt.err)
+			qt.Assert(t, code, qt.Equals, tt.wantCode)
+			qt.Assert(t, ok, qt.Equals, tt.wantOK)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/vitessio/arewefastyet/go/storage"
+)
+
+type (
+	// HookStep defines at which step of the execution's lifecycle a Hook runs.
+	HookStep string
+
+	// Hook is a function called during the lifecycle of an Exec. It receives the
+	// client used to communicate with the SQL database and the execution's UUID,
+	// which allows to record additional information along with the execution.
+	Hook func(client storage.SQLClient, execUUID uuid.UUID) error
+)
+
+const (
+	// HookPostPrepare hooks run once Exec.Prepare has successfully prepared the execution.
+	HookPostPrepare = HookStep("post_prepare")
+
+	// HookPostExecute hooks run once Exec.Execute has run Ansible, whether it failed or not.
+	HookPostExecute = HookStep("post_execute")
+)
+
+// RegisterHook registers a new Hook that will be called at the given step.
+// Hooks are called in the order they were registered.
+func (e *Exec) RegisterHook(step HookStep, hook Hook) {
+	if e.hooks == nil {
+		e.hooks = map[HookStep][]Hook{}
+	}
+	e.hooks[step] = append(e.hooks[step], hook)
+}
+
+// runHooks calls every Hook registered for the given step. The failure of a Hook
+// is written to the Exec's standard error but does not fail the execution.
+func (e *Exec) runHooks(step HookStep) {
+	for i, hook := range e.hooks[step] {
+		if err := hook(e.clientDB, e.UUID); err != nil {
+			_, _ = fmt.Fprintf(e.stderr, "%s hook #%d failed: %v\n", step, i, err)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/google/uuid"
+	"github.com/vitessio/arewefastyet/go/storage"
+)
+
+func TestExec_runHooks(t *testing.T) {
+	c := qt.New(t)
+
+	stderr := &bytes.Buffer{}
+	e := &Exec{UUID: uuid.New(), stderr: stderr}
+
+	var called []string
+	e.RegisterHook(HookPostPrepare, func(_ storage.SQLClient, execUUID uuid.UUID) error {
+		c.Assert(execUUID, qt.Equals, e.UUID)
+		called = append(called, "first")
+		return errors.New("hook error")
+	})
+	e.RegisterHook(HookPostPrepare, func(_ storage.SQLClient, _ uuid.UUID) error {
+		called = append(called, "second")
+		return nil
+	})
+	e.RegisterHook(HookPostExecute, func(_ storage.SQLClient, _ uuid.UUID) error {
+		called = append(called, "post_execute")
+		return nil
+	})
+
+	e.runHooks(HookPostPrepare)
+	c.Assert(called, qt.DeepEquals, []string{"first", "second"})
+	c.Assert(stderr.String(), qt.Equals, "post_prepare hook #0 failed: hook error\n")
+
+	e.runHooks(HookPostExecute)
+	c.Assert(called, qt.DeepEquals, []string{"first", "second", "post_execute"})
+}
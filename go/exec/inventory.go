@@ -0,0 +1,173 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/vitessio/arewefastyet/go/storage"
+)
+
+const (
+	// MetadataDirPath is the metadata key storing the directory of an execution
+	// on the host that ran it, see Exec.dirPath.
+	MetadataDirPath = "dir_path"
+
+	// effectiveInventoryFile is the name of the file, in the Exec's directory, in
+	// which the inventories Ansible ran with are saved, see saveInventory.
+	effectiveInventoryFile = "inventory.yml"
+)
+
+// ErrNoInventory is returned by GetInventory when the inventory of an execution was not saved.
+var ErrNoInventory = errors.New("the execution has no saved inventory")
+
+var (
+	// yamlKeyLine matches the YAML lines holding a key, e.g. "  stats_remote_db_password: foo"
+	// or "  - token: bar". The first group is the indentation of the key, the second the key
+	// along with its quotes and colon, and the third the key itself.
+	yamlKeyLine = regexp.MustCompile(`^([ \t]*(?:-[ \t]+)*)(["']?([^\s:#"']+)["']?[ \t]*:)(?:[ \t].*)?$`)
+
+	// iniVariable matches the INI host variables, e.g. "ansible_password=foo" or "api_key='foo bar'".
+	// The first group is the variable with its equal sign, and the second its name.
+	iniVariable = regexp.MustCompile(`(\b([^\s=]+)=)("[^"]*"|'[^']*'|\S+)`)
+
+	// secretNameParts are the substrings of the variable names holding a secret, and
+	// secretNameWords the words, delimited by underscores, dashes or dots, of such names.
+	secretNameParts = []string{"password", "passwd", "secret", "token", "private", "credential"}
+	secretNameWords = []string{"pass", "pwd", "key", "apikey", "auth"}
+)
+
+// isSecretName returns true if the variable name indicates it holds a secret,
+// e.g. "stats_remote_db_password", "api_key" or "ansible_ssh_private_key_file".
+func isSecretName(name string) bool {
+	name = strings.ToLower(name)
+	for _, part := range secretNameParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	words := strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' || r == '.' })
+	for _, word := range words {
+		for _, secret := range secretNameWords {
+			if word == secret {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// saveInventory saves the inventories Ansible runs with, once the IPs and configuration
+// paths have been inserted into them, to the Exec's directory. The saved copy is not
+// redacted, GetInventory redacts it before it is served.
+func (e *Exec) saveInventory() error {
+	var inventory bytes.Buffer
+	for _, file := range e.AnsibleConfig.InventoryFiles {
+		if !path.IsAbs(file) {
+			file = path.Join(e.AnsibleConfig.RootDir, file)
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&inventory, "# %s\n", path.Base(file))
+		inventory.Write(content)
+		if !bytes.HasSuffix(content, []byte("\n")) {
+			inventory.WriteString("\n")
+		}
+	}
+	return os.WriteFile(path.Join(e.dirPath, effectiveInventoryFile), inventory.Bytes(), 0644)
+}
+
+// GetInventory returns the inventories the execution execUUID ran with, with their secrets
+// redacted. ErrNoInventory is returned if they were not saved, or are no longer on this host.
+func GetInventory(client storage.SQLClient, execUUID string) ([]byte, error) {
+	metadata, err := GetMetadata(client, execUUID)
+	if err != nil {
+		return nil, err
+	}
+	dirPath := metadata[MetadataDirPath]
+	if dirPath == "" {
+		return nil, ErrNoInventory
+	}
+	content, err := os.ReadFile(path.Join(dirPath, effectiveInventoryFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoInventory
+	}
+	if err != nil {
+		return nil, err
+	}
+	return RedactInventory(content), nil
+}
+
+// RedactInventory replaces the values of the variables of an Ansible inventory, in the
+// YAML or INI format, whose name indicates they hold a secret, see isSecretName. The whole
+// value of a YAML key is redacted, including its nested values and multi-line scalars.
+func RedactInventory(content []byte) []byte {
+	lines := strings.SplitAfter(string(content), "\n")
+	var redacted strings.Builder
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		match := yamlKeyLine.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+		if match == nil || !isSecretName(match[3]) {
+			redacted.WriteString(iniVariable.ReplaceAllStringFunc(line, redactINIVariable))
+			continue
+		}
+		redacted.WriteString(match[1] + match[2] + " " + redactedConfigValue)
+		if strings.HasSuffix(line, "\n") {
+			redacted.WriteString("\n")
+		}
+		// the value continues on the following lines that are blank, more indented than
+		// the key, or list items at the indentation of the key
+		indent := len(match[1])
+		for i+1 < len(lines) && yamlContinuesValue(lines[i+1:], indent) {
+			i++
+		}
+	}
+	return []byte(redacted.String())
+}
+
+// yamlContinuesValue returns true if the first of the given lines is part of the value
+// of a YAML key indented by indent. A blank line is part of the value if the next
+// non-blank line is.
+func yamlContinuesValue(lines []string, indent int) bool {
+	for _, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		if strings.TrimSpace(trimmed) == "" {
+			continue
+		}
+		lineIndent := len(line) - len(trimmed)
+		return lineIndent > indent || (lineIndent == indent && strings.HasPrefix(trimmed, "-"))
+	}
+	return false
+}
+
+func redactINIVariable(variable string) string {
+	match := iniVariable.FindStringSubmatch(variable)
+	if !isSecretName(match[2]) {
+		return variable
+	}
+	return match[1] + redactedConfigValue
+}
@@ -0,0 +1,113 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/vitessio/arewefastyet/go/infra/ansible"
+)
+
+func TestRedactInventory(t *testing.T) {
+	tests := []struct {
+		name      string
+		inventory string
+		want      string
+	}{
+		{
+			name:      "no secret",
+			inventory: "all:\n  vars:\n    keyspace: main\n",
+			want:      "all:\n  vars:\n    keyspace: main\n",
+		},
+		{
+			name:      "YAML secrets",
+			inventory: "all:\n  vars:\n    stats_remote_db_password: foo\n    slack_token: \"bar\"\n    keyspace: main\n",
+			want:      "all:\n  vars:\n    stats_remote_db_password: <redacted>\n    slack_token: <redacted>\n    keyspace: main\n",
+		},
+		{
+			name:      "YAML keys",
+			inventory: "all:\n  vars:\n    api_key: foo\n    ansible_ssh_private_key_file: /root/.ssh/id_rsa\n    db_pass: bar\n    keyspace: main\n",
+			want:      "all:\n  vars:\n    api_key: <redacted>\n    ansible_ssh_private_key_file: <redacted>\n    db_pass: <redacted>\n    keyspace: main\n",
+		},
+		{
+			name:      "YAML nested secret",
+			inventory: "database:\n  db_password:\n    - foo\n    - bar\n  keyspace: main\n",
+			want:      "database:\n  db_password: <redacted>\n  keyspace: main\n",
+		},
+		{
+			name:      "YAML secret mapping",
+			inventory: "secrets:\n  db: foo\n  api: bar\nkeyspace: main\n",
+			want:      "secrets: <redacted>\nkeyspace: main\n",
+		},
+		{
+			name:      "YAML list at the key indentation",
+			inventory: "db_password:\n- foo\nkeyspace: main\n",
+			want:      "db_password: <redacted>\nkeyspace: main\n",
+		},
+		{
+			name:      "YAML block scalar",
+			inventory: "all:\n  vars:\n    ssh_private_key: |\n      -----BEGIN KEY-----\n\n      abcdef\n    keyspace: main\n",
+			want:      "all:\n  vars:\n    ssh_private_key: <redacted>\n    keyspace: main\n",
+		},
+		{
+			name:      "YAML secret in a list item",
+			inventory: "users:\n  - token: foo\n    name: bar\n",
+			want:      "users:\n  - token: <redacted>\n    name: bar\n",
+		},
+		{
+			name:      "INI secrets",
+			inventory: "[vtgate]\n10.0.0.1 ansible_user=root ansible_password=foo api_token=bar ansible_ssh_private_key_file=/root/key\n",
+			want:      "[vtgate]\n10.0.0.1 ansible_user=root ansible_password=<redacted> api_token=<redacted> ansible_ssh_private_key_file=<redacted>\n",
+		},
+		{
+			name:      "INI quoted secret",
+			inventory: "[all:vars]\napi_key=\"foo bar\"\n",
+			want:      "[all:vars]\napi_key=<redacted>\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redacted := string(RedactInventory([]byte(tt.inventory)))
+			qt.Assert(t, redacted, qt.Equals, tt.want)
+			for _, secret := range []string{"foo", "bar", "id_rsa", "BEGIN", "abcdef"} {
+				if !strings.Contains(tt.want, secret) {
+					qt.Assert(t, redacted, qt.Not(qt.Contains), secret)
+				}
+			}
+		})
+	}
+}
+
+func TestExec_saveInventory(t *testing.T) {
+	c := qt.New(t)
+	root, dirPath := t.TempDir(), t.TempDir()
+	c.Assert(os.WriteFile(path.Join(root, "macrobench_inventory.yml"), []byte("all:\n  hosts:\n    10.0.0.1:"), 0644), qt.IsNil)
+	c.Assert(os.WriteFile(path.Join(root, "extra_inventory.yml"), []byte("all:\n  vars:\n    db_password: foo\n"), 0644), qt.IsNil)
+
+	e := &Exec{dirPath: dirPath, AnsibleConfig: ansible.Config{RootDir: root, InventoryFiles: []string{"macrobench_inventory.yml", path.Join(root, "extra_inventory.yml")}}}
+	c.Assert(e.saveInventory(), qt.IsNil)
+
+	content, err := os.ReadFile(path.Join(dirPath, effectiveInventoryFile))
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(content), qt.Equals, "# macrobench_inventory.yml\nall:\n  hosts:\n    10.0.0.1:\n# extra_inventory.yml\nall:\n  vars:\n    db_password: foo\n")
+}
@@ -0,0 +1,98 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"github.com/google/uuid"
+	"github.com/vitessio/arewefastyet/go/storage"
+)
+
+// AddLabel sets the label key to value on the execution execUUID.
+// If the execution already has a label with the same key, its value is replaced.
+func AddLabel(client storage.SQLClient, execUUID, key, value string) error {
+	query := "INSERT INTO execution_label(exec_uuid, label_key, label_value) VALUES(?, ?, ?) ON DUPLICATE KEY UPDATE label_value = ?"
+	_, err := client.Insert(query, execUUID, key, value, value)
+	return err
+}
+
+// GetLabels returns the labels of the execution execUUID, indexed by their key.
+func GetLabels(client storage.SQLClient, execUUID string) (map[string]string, error) {
+	result, err := client.Select("SELECT label_key, label_value FROM execution_label WHERE exec_uuid = ?", execUUID)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	labels := map[string]string{}
+	for result.Next() {
+		var key, value string
+		err = result.Scan(&key, &value)
+		if err != nil {
+			return nil, err
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
+// FindByLabel returns the executions that have the label key, the most recent ones first.
+// If value is not empty, only the executions whose label is equal to value are returned.
+func FindByLabel(client storage.SQLClient, key, value string) ([]*Exec, error) {
+	query := "SELECT e.uuid, e.status, e.git_ref, e.started_at, e.finished_at, e.source, e.type, e.pull_nb, e.go_version " +
+		"FROM execution e, execution_label l WHERE e.uuid = l.exec_uuid AND l.label_key = ?"
+	args := []interface{}{key}
+	if value != "" {
+		query += " AND l.label_value = ?"
+		args = append(args, value)
+	}
+	query += " ORDER BY e.started_at DESC LIMIT 50"
+
+	result, err := client.Select(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	var res []*Exec
+	for result.Next() {
+		var eUUID string
+		exec := &Exec{}
+		err = result.Scan(&eUUID, &exec.Status, &exec.GitRef, &exec.StartedAt, &exec.FinishedAt, &exec.Source, &exec.TypeOf, &exec.PullNB, &exec.GolangVersion)
+		if err != nil {
+			return nil, err
+		}
+		exec.UUID, err = uuid.Parse(eUUID)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, exec)
+	}
+	return res, nil
+}
+
+// insertLabels persists the labels of the Exec.
+func (e *Exec) insertLabels() error {
+	for key, value := range e.Labels {
+		err := AddLabel(e.clientDB, e.UUID.String(), key, value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
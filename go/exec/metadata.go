@@ -0,0 +1,220 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vitessio/arewefastyet/go/storage"
+)
+
+const (
+	// MetadataMySQLConfig is the metadata key storing the MySQL configuration
+	// an execution used on top of the default one, see FormatMySQLConfig.
+	MetadataMySQLConfig = "mysql_config"
+
+	// keyMySQLConfig is the name of the key that stores the MySQL
+	// configuration templated into the my.cnf of the remote hosts.
+	keyMySQLConfig = "arewefastyet_mysql_config"
+
+	// MetadataBenchmarkEnv is the metadata key storing the environment variables
+	// passed to the benchmark process, see FormatBenchmarkEnv.
+	MetadataBenchmarkEnv = "benchmark_env"
+
+	// keyBenchmarkEnv is the name of the key that stores the environment
+	// variables set on the benchmark process, vtgate and vttablet of the remote hosts.
+	keyBenchmarkEnv = "arewefastyet_benchmark_env"
+
+	// reservedBenchmarkEnvPrefix is the prefix of the environment variables
+	// used internally by arewefastyet, which cannot be set by BenchmarkEnv.
+	reservedBenchmarkEnvPrefix = "AREWEFASTYET_"
+
+	// MetadataDuration and MetadataWarmupDuration are the metadata keys storing
+	// the duration, in seconds, of the run and warm up steps of a macrobenchmark.
+	MetadataDuration       = "duration"
+	MetadataWarmupDuration = "warmup_duration"
+
+	// keyDuration and keyWarmupDuration are the names of the keys that store the
+	// durations overriding the ones of the macrobenchmark configuration file.
+	keyDuration       = "arewefastyet_duration"
+	keyWarmupDuration = "arewefastyet_warmup_duration"
+
+	// configKeyDuration and configKeyWarmupDuration are the keys of the macrobenchmark
+	// configuration file defining the default durations.
+	configKeyDuration       = "macrobench_run_time"
+	configKeyWarmupDuration = "macrobench_warmup_time"
+
+	// MetadataClients is the metadata key storing the number of concurrent
+	// clients (sysbench threads) of a macrobenchmark.
+	MetadataClients = "clients"
+
+	// keyClients is the name of the key that stores the number of clients
+	// overriding the one of the macrobenchmark configuration file.
+	keyClients = "arewefastyet_clients"
+
+	// configKeyClients is the key of the macrobenchmark configuration file
+	// defining the default number of clients.
+	configKeyClients = "macrobench_all_threads"
+
+	// MetadataProvider is the metadata key storing the infrastructure
+	// provider the execution ran on, see Exec.Provider.
+	MetadataProvider = "provider"
+
+	// MetadataNetworkImpairment is the metadata key storing the network impairment
+	// applied to the remote hosts, see NetworkImpairment.String.
+	MetadataNetworkImpairment = "network_impairment"
+
+	// keyNetworkImpairment is the name of the key that stores the netem rules
+	// applied by the network_impairment playbook.
+	keyNetworkImpairment = "arewefastyet_network_impairment"
+)
+
+// SetMetadata sets the metadata key to value on the execution execUUID.
+// Unlike labels, metadata is set by arewefastyet to describe how the
+// execution ran. An existing value for the same key is replaced.
+func SetMetadata(client storage.SQLClient, execUUID, key, value string) error {
+	query := "INSERT INTO execution_metadata(exec_uuid, metadata_key, metadata_value) VALUES(?, ?, ?) ON DUPLICATE KEY UPDATE metadata_value = ?"
+	_, err := client.Insert(query, execUUID, key, value, value)
+	return err
+}
+
+// GetMetadata returns the metadata of the execution execUUID, indexed by their key.
+func GetMetadata(client storage.SQLClient, execUUID string) (map[string]string, error) {
+	result, err := client.Select("SELECT metadata_key, metadata_value FROM execution_metadata WHERE exec_uuid = ?", execUUID)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	metadata := map[string]string{}
+	for result.Next() {
+		var key, value string
+		err = result.Scan(&key, &value)
+		if err != nil {
+			return nil, err
+		}
+		metadata[key] = value
+	}
+	return metadata, nil
+}
+
+// reservedBenchmarkEnv lists the environment variables the benchmark process
+// relies on, which cannot be overridden by BenchmarkEnv.
+var reservedBenchmarkEnv = []string{"PATH", "HOME", "USER", "SHELL", "GOPATH", "GOROOT"}
+
+// FormatMySQLConfig formats the given MySQL configuration as a sorted list of
+// key=value pairs, making two equal configurations have the same representation.
+func FormatMySQLConfig(config map[string]string) string {
+	return formatPairs(config)
+}
+
+// FormatBenchmarkEnv formats the given environment variables like FormatMySQLConfig.
+func FormatBenchmarkEnv(env map[string]string) string {
+	return formatPairs(env)
+}
+
+func formatPairs(values map[string]string) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+values[key])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// checkBenchmarkEnv returns an error if env overrides a reserved environment variable.
+func checkBenchmarkEnv(env map[string]string) error {
+	for name := range env {
+		if strings.HasPrefix(strings.ToUpper(name), reservedBenchmarkEnvPrefix) {
+			return fmt.Errorf("the benchmark environment variable %s is reserved: the %s prefix is used internally", name, reservedBenchmarkEnvPrefix)
+		}
+		for _, reserved := range reservedBenchmarkEnv {
+			if name == reserved {
+				return fmt.Errorf("the benchmark environment variable %s is reserved", name)
+			}
+		}
+	}
+	return nil
+}
+
+// insertMetadata persists the metadata describing how the Exec runs.
+func (e *Exec) insertMetadata() error {
+	metadata := map[string]string{}
+	if len(e.MySQLConfig) > 0 {
+		metadata[MetadataMySQLConfig] = FormatMySQLConfig(e.MySQLConfig)
+	}
+	if len(e.BenchmarkEnv) > 0 {
+		metadata[MetadataBenchmarkEnv] = FormatBenchmarkEnv(e.BenchmarkEnv)
+	}
+	if e.Provider != "" {
+		metadata[MetadataProvider] = e.Provider
+	}
+	if e.NetworkImpairment.Enabled() {
+		metadata[MetadataNetworkImpairment] = e.NetworkImpairment.String()
+	}
+
+	snapshot, err := e.insertConfigSnapshot()
+	if err != nil {
+		return err
+	}
+	for key, value := range durationsMetadata(e.Duration, e.WarmupDuration, snapshot) {
+		metadata[key] = value
+	}
+	if value, ok := overriddenValue(e.Clients, snapshot, configKeyClients); ok {
+		metadata[MetadataClients] = value
+	}
+
+	for key, value := range metadata {
+		err = SetMetadata(e.clientDB, e.UUID.String(), key, value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// durationsMetadata returns the durations a macrobenchmark runs with: the given
+// durations, or the ones of the configuration snapshot if they are not set.
+func durationsMetadata(duration, warmupDuration int, snapshot map[string]string) map[string]string {
+	metadata := map[string]string{}
+	if value, ok := overriddenValue(duration, snapshot, configKeyDuration); ok {
+		metadata[MetadataDuration] = value
+	}
+	if value, ok := overriddenValue(warmupDuration, snapshot, configKeyWarmupDuration); ok {
+		metadata[MetadataWarmupDuration] = value
+	}
+	return metadata
+}
+
+// overriddenValue returns value if it is set, or the value of configKey in the
+// configuration snapshot otherwise. False is returned if neither is set.
+func overriddenValue(value int, snapshot map[string]string, configKey string) (string, bool) {
+	if value > 0 {
+		return strconv.Itoa(value), true
+	}
+	configValue, ok := snapshot[configKey]
+	return configValue, ok
+}
@@ -0,0 +1,107 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestFormatMySQLConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]string
+		want   string
+	}{
+		{name: "empty", config: map[string]string{}, want: ""},
+		{name: "single option", config: map[string]string{"innodb_doublewrite": "1"}, want: "innodb_doublewrite=1"},
+		{name: "sorted options", config: map[string]string{"innodb_io_capacity": "2000", "innodb_doublewrite": "1"}, want: "innodb_doublewrite=1,innodb_io_capacity=2000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qt.Assert(t, FormatMySQLConfig(tt.config), qt.Equals, tt.want)
+		})
+	}
+}
+
+func TestCheckBenchmarkEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "empty", env: nil},
+		{name: "allowed variables", env: map[string]string{"GOGC": "200", "GOMAXPROCS": "8"}},
+		{name: "reserved variable", env: map[string]string{"PATH": "/tmp"}, wantErr: "the benchmark environment variable PATH is reserved"},
+		{name: "internal prefix", env: map[string]string{"arewefastyet_exec_uuid": "foo"}, wantErr: "the benchmark environment variable arewefastyet_exec_uuid is reserved: the AREWEFASTYET_ prefix is used internally"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkBenchmarkEnv(tt.env)
+			if tt.wantErr == "" {
+				qt.Assert(t, err, qt.IsNil)
+				return
+			}
+			qt.Assert(t, err, qt.ErrorMatches, tt.wantErr)
+		})
+	}
+}
+
+func TestDurationsMetadata(t *testing.T) {
+	snapshot := map[string]string{"macrobench_run_time": "900", "macrobench_warmup_time": "10"}
+	tests := []struct {
+		name           string
+		duration       int
+		warmupDuration int
+		snapshot       map[string]string
+		want           map[string]string
+	}{
+		{name: "no configuration", snapshot: nil, want: map[string]string{}},
+		{name: "configuration durations", snapshot: snapshot, want: map[string]string{"duration": "900", "warmup_duration": "10"}},
+		{name: "overridden durations", duration: 60, warmupDuration: 5, snapshot: snapshot, want: map[string]string{"duration": "60", "warmup_duration": "5"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qt.Assert(t, durationsMetadata(tt.duration, tt.warmupDuration, tt.snapshot), qt.DeepEquals, tt.want)
+		})
+	}
+}
+
+func TestOverriddenValue(t *testing.T) {
+	snapshot := map[string]string{"macrobench_all_threads": "100"}
+	tests := []struct {
+		name     string
+		value    int
+		snapshot map[string]string
+		want     string
+		wantOk   bool
+	}{
+		{name: "no configuration", snapshot: nil},
+		{name: "configuration value", snapshot: snapshot, want: "100", wantOk: true},
+		{name: "overridden value", value: 8, snapshot: snapshot, want: "8", wantOk: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := overriddenValue(tt.value, tt.snapshot, "macrobench_all_threads")
+			qt.Assert(t, ok, qt.Equals, tt.wantOk)
+			qt.Assert(t, got, qt.Equals, tt.want)
+		})
+	}
+}
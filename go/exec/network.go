@@ -0,0 +1,78 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// NetworkImpairment describes the tc/netem rules applied to the loopback and default
+// interfaces of the remote hosts during a macrobenchmark, to study vitess under a
+// degraded network.
+type NetworkImpairment struct {
+	// Delay is the latency added to each packet, varying by up to Jitter.
+	Delay  time.Duration
+	Jitter time.Duration
+
+	// Loss is the percentage, between 0 and 100, of packets dropped.
+	Loss float64
+}
+
+// Enabled returns true if the NetworkImpairment degrades the network.
+func (ni NetworkImpairment) Enabled() bool {
+	return ni.Delay > 0 || ni.Loss > 0
+}
+
+// String formats the NetworkImpairment like FormatMySQLConfig, it is
+// empty if the NetworkImpairment is not enabled.
+func (ni NetworkImpairment) String() string {
+	if !ni.Enabled() {
+		return ""
+	}
+	return formatPairs(map[string]string{
+		"delay":  ni.Delay.String(),
+		"jitter": ni.Jitter.String(),
+		"loss":   strconv.FormatFloat(ni.Loss, 'f', -1, 64) + "%",
+	})
+}
+
+func (ni NetworkImpairment) check() error {
+	if ni.Delay < 0 || ni.Jitter < 0 {
+		return errors.New("the network delay and jitter cannot be negative")
+	}
+	if ni.Jitter > 0 && ni.Delay == 0 {
+		return errors.New("a network jitter requires a network delay")
+	}
+	if ni.Loss < 0 || ni.Loss > 100 {
+		return errors.New("the network loss must be a percentage between 0 and 100")
+	}
+	return nil
+}
+
+// extraVars returns the NetworkImpairment as expected by the network_impairment playbook.
+// The delay and jitter are given in microseconds, tc accepting sub-millisecond values.
+func (ni NetworkImpairment) extraVars() map[string]interface{} {
+	return map[string]interface{}{
+		"delay_us":     ni.Delay.Microseconds(),
+		"jitter_us":    ni.Jitter.Microseconds(),
+		"loss_percent": ni.Loss,
+	}
+}
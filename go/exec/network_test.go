@@ -0,0 +1,76 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestNetworkImpairment_String(t *testing.T) {
+	tests := []struct {
+		name       string
+		impairment NetworkImpairment
+		want       string
+	}{
+		{name: "disabled", impairment: NetworkImpairment{}, want: ""},
+		{name: "delay", impairment: NetworkImpairment{Delay: 20 * time.Millisecond, Jitter: 5 * time.Millisecond}, want: "delay=20ms,jitter=5ms,loss=0%"},
+		{name: "loss", impairment: NetworkImpairment{Loss: 0.5}, want: "delay=0s,jitter=0s,loss=0.5%"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qt.Assert(t, tt.impairment.String(), qt.Equals, tt.want)
+		})
+	}
+}
+
+func TestNetworkImpairment_check(t *testing.T) {
+	tests := []struct {
+		name       string
+		impairment NetworkImpairment
+		wantErr    string
+	}{
+		{name: "disabled", impairment: NetworkImpairment{}},
+		{name: "valid", impairment: NetworkImpairment{Delay: 20 * time.Millisecond, Jitter: 5 * time.Millisecond, Loss: 1}},
+		{name: "negative delay", impairment: NetworkImpairment{Delay: -time.Millisecond}, wantErr: "the network delay and jitter cannot be negative"},
+		{name: "jitter without delay", impairment: NetworkImpairment{Jitter: time.Millisecond}, wantErr: "a network jitter requires a network delay"},
+		{name: "loss above 100", impairment: NetworkImpairment{Loss: 101}, wantErr: "the network loss must be a percentage between 0 and 100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.impairment.check()
+			if tt.wantErr == "" {
+				qt.Assert(t, err, qt.IsNil)
+				return
+			}
+			qt.Assert(t, err, qt.ErrorMatches, tt.wantErr)
+		})
+	}
+}
+
+func TestNetworkImpairment_extraVars(t *testing.T) {
+	impairment := NetworkImpairment{Delay: 1500 * time.Microsecond, Jitter: 250 * time.Microsecond, Loss: 0.5}
+	qt.Assert(t, impairment.extraVars(), qt.DeepEquals, map[string]interface{}{
+		"delay_us":     int64(1500),
+		"jitter_us":    int64(250),
+		"loss_percent": 0.5,
+	})
+}
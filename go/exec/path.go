@@ -19,10 +19,15 @@
 package exec
 
 import (
+	"bytes"
+	"fmt"
 	"github.com/google/uuid"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
 )
 
 const (
@@ -44,6 +49,56 @@ func createDirFromUUID(uuid uuid.UUID, root string) (dirPath string, err error)
 	return dirPath, nil
 }
 
+// dirTemplateData holds the execution fields that can be used in the template
+// defining the name of an execution's directory (see flagDirTemplate).
+type dirTemplateData struct {
+	UUID   string
+	Type   string
+	Source string
+	GitRef string
+	Date   string
+}
+
+// createDirFromTemplate evaluates tmpl using data and creates the resulting directory
+// under root's exec directory. The resulting path must stay within root's exec
+// directory and must not exist yet, ensuring each execution has its own directory.
+func createDirFromTemplate(tmpl string, data dirTemplateData, root string) (dirPath string, err error) {
+	t, err := template.New("dir").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+	var name bytes.Buffer
+	err = t.Execute(&name, data)
+	if err != nil {
+		return "", err
+	}
+
+	execRoot, err := filepath.Abs(path.Join(root, execDir))
+	if err != nil {
+		return "", err
+	}
+	dirPath, err = filepath.Abs(path.Join(execRoot, name.String()))
+	if err != nil {
+		return "", err
+	}
+	if dirPath == execRoot || !strings.HasPrefix(dirPath, execRoot+string(filepath.Separator)) {
+		return "", fmt.Errorf("directory template %q resolves outside of %s", tmpl, execRoot)
+	}
+
+	err = os.MkdirAll(filepath.Dir(dirPath), 0755)
+	if err != nil {
+		return "", err
+	}
+	err = os.Mkdir(dirPath, 0755)
+	if err != nil {
+		if os.IsExist(err) {
+			return "", fmt.Errorf("directory %s already exists, the directory template must produce unique paths", dirPath)
+		}
+		return "", err
+	}
+	return dirPath, nil
+}
+
 func createSubDir(rootDir, subDir string, call func(dir string) error) error {
 	subDir = path.Join(rootDir, subDir)
 	subdirAbs, err := filepath.Abs(subDir)
@@ -75,7 +130,19 @@ func (e *Exec) copyAllDirs() error {
 }
 
 func (e *Exec) prepareDirectories() error {
-	dirPath, err := createDirFromUUID(e.UUID, e.rootDir)
+	var dirPath string
+	var err error
+	if e.dirTemplate == "" {
+		dirPath, err = createDirFromUUID(e.UUID, e.rootDir)
+	} else {
+		dirPath, err = createDirFromTemplate(e.dirTemplate, dirTemplateData{
+			UUID:   e.UUID.String(),
+			Type:   e.TypeOf,
+			Source: e.Source,
+			GitRef: e.GitRef,
+			Date:   time.Now().UTC().Format("2006-01-02"),
+		}, e.rootDir)
+	}
 	if err != nil {
 		return err
 	}
@@ -71,3 +71,38 @@ func Test_createDirFromUUID(t *testing.T) {
 		})
 	}
 }
+
+func Test_createDirFromTemplate(t *testing.T) {
+	data := dirTemplateData{UUID: uuid.New().String(), Type: "oltp", Source: "cron", Date: "2021-10-12"}
+	tests := []struct {
+		name    string
+		tmpl    string
+		want    string
+		wantErr bool
+	}{
+		{name: "UUID only", tmpl: "{{.UUID}}", want: data.UUID},
+		{name: "Date, type and UUID", tmpl: "{{.Date}}/{{.Type}}-{{.UUID}}", want: "2021-10-12/oltp-" + data.UUID},
+		{name: "Unknown field", tmpl: "{{.Unknown}}", wantErr: true},
+		{name: "Outside of root", tmpl: "../{{.UUID}}", wantErr: true},
+		{name: "Empty result", tmpl: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			root := c.TempDir()
+
+			gotDirPath, err := createDirFromTemplate(tt.tmpl, data, root)
+			if tt.wantErr == true {
+				c.Assert(err, qt.Not(qt.IsNil))
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			wantPath, _ := filepath.Abs(path.Join(root, execDir, tt.want))
+			c.Assert(gotDirPath, qt.Equals, wantPath)
+
+			// the same path cannot be used twice
+			_, err = createDirFromTemplate(tt.tmpl, data, root)
+			c.Assert(err, qt.Not(qt.IsNil))
+		})
+	}
+}
@@ -0,0 +1,50 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// keyPreRunScript is the name of the key that stores the path to the
+// script executed on the remote hosts before running the benchmark.
+const keyPreRunScript = "arewefastyet_pre_run_script"
+
+// resolvePreRunScript makes the path to the Exec's pre-run script absolute
+// and ensures it points to an existing regular file.
+func (e *Exec) resolvePreRunScript() error {
+	if e.PreRunScript == "" {
+		return nil
+	}
+	path, err := filepath.Abs(e.PreRunScript)
+	if err != nil {
+		return fmt.Errorf("invalid pre-run script %s: %w", e.PreRunScript, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("invalid pre-run script %s: %w", e.PreRunScript, err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("invalid pre-run script %s: not a regular file", e.PreRunScript)
+	}
+	e.PreRunScript = path
+	return nil
+}
@@ -0,0 +1,61 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestExec_resolvePreRunScript(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "pre_run.sh")
+	err := ioutil.WriteFile(script, []byte("#!/bin/sh\nsysctl -w vm.swappiness=1\n"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		script  string
+		want    string
+		wantErr bool
+	}{
+		{name: "No script", script: "", want: ""},
+		{name: "Existing script", script: script, want: script},
+		{name: "Missing script", script: filepath.Join(dir, "missing.sh"), wantErr: true},
+		{name: "Directory", script: dir, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			e := &Exec{PreRunScript: tt.script}
+			err := e.resolvePreRunScript()
+			if tt.wantErr {
+				c.Assert(err, qt.Not(qt.IsNil))
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			c.Assert(e.PreRunScript, qt.Equals, tt.want)
+		})
+	}
+}
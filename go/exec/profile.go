@@ -0,0 +1,79 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/vitessio/arewefastyet/go/storage"
+)
+
+const (
+	// ProfileVTGate is the name of the CPU profile of vtgate collected during
+	// the run step of macrobenchmarks, see Exec.Profile.
+	ProfileVTGate = "vtgate"
+
+	// keyProfileDir is the name of the key that stores the local directory in
+	// which the playbooks fetch the profiles collected on the remote hosts.
+	keyProfileDir = "arewefastyet_profile_dir"
+
+	profileFileExtension = ".pprof"
+)
+
+// storeProfile stores the profile fetched by the playbooks in the Exec's directory.
+// Failing to store it does not fail the execution, as the benchmark itself succeeded.
+func (e *Exec) storeProfile() {
+	content, err := os.ReadFile(path.Join(e.dirPath, ProfileVTGate+profileFileExtension))
+	if err == nil {
+		err = insertProfile(e.clientDB, e.UUID.String(), ProfileVTGate, content)
+	} else if errors.Is(err, os.ErrNotExist) {
+		err = errors.New("no profile was collected")
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(e.stderr, "could not store the %s profile: %v\n", ProfileVTGate, err)
+	}
+}
+
+func insertProfile(client storage.SQLClient, execUUID, name string, content []byte) error {
+	query := "INSERT INTO execution_profile(exec_uuid, name, content) VALUES(?, ?, ?) ON DUPLICATE KEY UPDATE content = ?"
+	_, err := client.Insert(query, execUUID, name, content, content)
+	return err
+}
+
+// GetProfile returns the profile name of the execution execUUID, in the pprof format.
+// A nil profile is returned if the execution has no such profile.
+func GetProfile(client storage.SQLClient, execUUID, name string) ([]byte, error) {
+	result, err := client.Select("SELECT content FROM execution_profile WHERE exec_uuid = ? AND name = ?", execUUID, name)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	var content []byte
+	if result.Next() {
+		err = result.Scan(&content)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return content, nil
+}
@@ -0,0 +1,58 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"bytes"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/google/uuid"
+	"github.com/vitessio/arewefastyet/go/infra/ansible"
+)
+
+func TestExec_prepareAnsibleForExecutionProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile bool
+	}{
+		{name: "profiling disabled", profile: false},
+		{name: "profiling enabled", profile: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			e := &Exec{UUID: uuid.New(), Profile: tt.profile, dirPath: "/tmp/exec", AnsibleConfig: ansible.Config{ExtraVars: map[string]interface{}{}}}
+			e.prepareAnsibleForExecution()
+			dir, ok := e.AnsibleConfig.ExtraVars[keyProfileDir]
+			c.Assert(ok, qt.Equals, tt.profile)
+			if tt.profile {
+				c.Assert(dir, qt.Equals, "/tmp/exec")
+			}
+		})
+	}
+}
+
+func TestExec_storeProfileMissing(t *testing.T) {
+	c := qt.New(t)
+	var stderr bytes.Buffer
+	e := &Exec{UUID: uuid.New(), dirPath: t.TempDir(), stderr: &stderr}
+	e.storeProfile()
+	c.Assert(stderr.String(), qt.Equals, "could not store the vtgate profile: no profile was collected\n")
+}
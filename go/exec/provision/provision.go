@@ -0,0 +1,38 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+// Package provision records how long the infrastructure of an execution took to be
+// provisioned, from the start of the execution until its benchmark command starts.
+// It covers the preparation of the Ansible files and the setup of the benchmarked
+// cluster by Ansible, and is stored separately from the duration of the execution.
+package provision
+
+import (
+	"github.com/vitessio/arewefastyet/go/storage"
+)
+
+// RecordEnd stores the provisioning duration of the execution execUUID, whose provisioning
+// ends when its benchmark starts. The duration is measured by the database, from the time
+// the execution started, so that it does not depend on the clocks of the hosts. Nothing is
+// stored if the benchmark is not linked to an execution.
+func RecordEnd(client storage.SQLClient, execUUID string) error {
+	if execUUID == "" {
+		return nil
+	}
+	_, err := client.Insert("UPDATE execution SET provision_duration = TIMESTAMPDIFF(SECOND, started_at, CURRENT_TIMESTAMP) WHERE uuid = ? AND started_at IS NOT NULL", execUUID)
+	return err
+}
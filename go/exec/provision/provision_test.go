@@ -0,0 +1,51 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+package provision_test
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/vitessio/arewefastyet/go/exec"
+	"github.com/vitessio/arewefastyet/go/exec/provision"
+	"github.com/vitessio/arewefastyet/go/storage/mysql/mysqltest"
+)
+
+func TestRecordEnd(t *testing.T) {
+	c := qt.New(t)
+	client := mysqltest.New(t)
+
+	_, err := client.Insert("INSERT INTO execution(uuid, status, started_at) VALUES ('started', 'started', DATE_SUB(CURRENT_TIMESTAMP, INTERVAL 90 SECOND))")
+	c.Assert(err, qt.IsNil)
+	_, err = client.Insert("INSERT INTO execution(uuid, status) VALUES ('created', 'created')")
+	c.Assert(err, qt.IsNil)
+
+	c.Assert(provision.RecordEnd(client, "started"), qt.IsNil)
+	c.Assert(provision.RecordEnd(client, "created"), qt.IsNil)
+	c.Assert(provision.RecordEnd(client, ""), qt.IsNil)
+
+	duration, ok, err := exec.GetProvisionDuration(client, "started")
+	c.Assert(err, qt.IsNil)
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(duration >= 90*time.Second && duration < 2*time.Minute, qt.IsTrue, qt.Commentf("duration: %s", duration))
+
+	_, ok, err = exec.GetProvisionDuration(client, "created")
+	c.Assert(err, qt.IsNil)
+	c.Assert(ok, qt.IsFalse)
+}
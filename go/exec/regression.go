@@ -0,0 +1,101 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"time"
+
+	"github.com/vitessio/arewefastyet/go/storage"
+)
+
+// Regression is a regression event that was observed when comparing an
+// execution against its baseline. A regression stays open until a later
+// execution of the same source, type and planner version returns to the
+// baseline, at which point ResolvedAt and ResolvedByExecUUID are set.
+type Regression struct {
+	ID             int
+	Source         string
+	BenchmarkType  string
+	PlannerVersion string
+
+	// ExecUUID and GitRef point to the execution that regressed.
+	ExecUUID string
+	GitRef   string
+
+	// BaselineExecUUID and BaselineGitRef point to the execution
+	// against which the regression was observed.
+	BaselineExecUUID string
+	BaselineGitRef   string
+
+	// Metrics contains the regressed metrics as they are reported
+	// in the regression notification.
+	Metrics string
+
+	CreatedAt          *time.Time
+	ResolvedAt         *time.Time
+	ResolvedByExecUUID string
+}
+
+// InsertRegression persists a new open Regression.
+func InsertRegression(client storage.SQLClient, r Regression) error {
+	query := "INSERT INTO regression(source, type, planner_version, exec_uuid, git_ref, baseline_exec_uuid, baseline_git_ref, metrics) VALUES(?, ?, ?, ?, ?, ?, ?, ?)"
+	_, err := client.Insert(query, r.Source, r.BenchmarkType, r.PlannerVersion, r.ExecUUID, r.GitRef, r.BaselineExecUUID, r.BaselineGitRef, r.Metrics)
+	return err
+}
+
+// GetOpenRegressions returns all the regressions that have not been resolved yet,
+// the most recent ones first.
+func GetOpenRegressions(client storage.SQLClient) ([]Regression, error) {
+	query := "SELECT id, source, type, planner_version, exec_uuid, git_ref, baseline_exec_uuid, baseline_git_ref, metrics, created_at " +
+		"FROM regression WHERE resolved_at IS NULL ORDER BY created_at DESC"
+	return getRegressions(client, query)
+}
+
+// GetOpenRegressionsFor returns the open regressions for the given source, type and planner version.
+func GetOpenRegressionsFor(client storage.SQLClient, source, typeOf, plannerVersion string) ([]Regression, error) {
+	query := "SELECT id, source, type, planner_version, exec_uuid, git_ref, baseline_exec_uuid, baseline_git_ref, metrics, created_at " +
+		"FROM regression WHERE resolved_at IS NULL AND source = ? AND type = ? AND planner_version = ? ORDER BY created_at DESC"
+	return getRegressions(client, query, source, typeOf, plannerVersion)
+}
+
+func getRegressions(client storage.SQLClient, query string, args ...interface{}) ([]Regression, error) {
+	rows, err := client.Select(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []Regression
+	for rows.Next() {
+		var r Regression
+		err = rows.Scan(&r.ID, &r.Source, &r.BenchmarkType, &r.PlannerVersion, &r.ExecUUID, &r.GitRef, &r.BaselineExecUUID, &r.BaselineGitRef, &r.Metrics, &r.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, r)
+	}
+	return res, nil
+}
+
+// ResolveRegression marks the regression identified by id as resolved by
+// the execution execUUID.
+func ResolveRegression(client storage.SQLClient, id int, execUUID string) error {
+	_, err := client.Insert("UPDATE regression SET resolved_at = CURRENT_TIME, resolved_by_exec_uuid = ? WHERE id = ? AND resolved_at IS NULL", execUUID, id)
+	return err
+}
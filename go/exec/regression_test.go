@@ -0,0 +1,70 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/vitessio/arewefastyet/go/storage/mysql/mysqltest"
+)
+
+func TestRegressions(t *testing.T) {
+	c := qt.New(t)
+	client := mysqltest.New(t)
+
+	regressions := []Regression{
+		{Source: SourceCron, BenchmarkType: "oltp", PlannerVersion: "V3", ExecUUID: "a", GitRef: "a", BaselineExecUUID: "base", BaselineGitRef: "base", Metrics: "- TPS decreased by 10.00%"},
+		{Source: SourceCron, BenchmarkType: "oltp", PlannerVersion: "Gen4", ExecUUID: "b", GitRef: "b", BaselineExecUUID: "base", BaselineGitRef: "base"},
+		{Source: SourceCron, BenchmarkType: "tpcc", PlannerVersion: "V3", ExecUUID: "c", GitRef: "c", BaselineExecUUID: "base", BaselineGitRef: "base"},
+	}
+	for _, r := range regressions {
+		c.Assert(InsertRegression(client, r), qt.IsNil)
+	}
+
+	open, err := GetOpenRegressions(client)
+	c.Assert(err, qt.IsNil)
+	c.Assert(open, qt.HasLen, 3)
+
+	open, err = GetOpenRegressionsFor(client, SourceCron, "oltp", "V3")
+	c.Assert(err, qt.IsNil)
+	c.Assert(open, qt.HasLen, 1)
+	c.Assert(open[0].ExecUUID, qt.Equals, "a")
+	c.Assert(open[0].Metrics, qt.Equals, "- TPS decreased by 10.00%")
+	c.Assert(open[0].CreatedAt, qt.Not(qt.IsNil))
+
+	// a resolved regression is not open anymore, and cannot be resolved twice
+	c.Assert(ResolveRegression(client, open[0].ID, "d"), qt.IsNil)
+	c.Assert(ResolveRegression(client, open[0].ID, "e"), qt.IsNil)
+	open, err = GetOpenRegressionsFor(client, SourceCron, "oltp", "V3")
+	c.Assert(err, qt.IsNil)
+	c.Assert(open, qt.HasLen, 0)
+
+	rows, err := client.Select("SELECT resolved_by_exec_uuid FROM regression WHERE exec_uuid = 'a' AND resolved_at IS NOT NULL")
+	c.Assert(err, qt.IsNil)
+	defer rows.Close()
+	c.Assert(rows.Next(), qt.IsTrue)
+	var resolvedBy string
+	c.Assert(rows.Scan(&resolvedBy), qt.IsNil)
+	c.Assert(resolvedBy, qt.Equals, "d")
+
+	open, err = GetOpenRegressions(client)
+	c.Assert(err, qt.IsNil)
+	c.Assert(open, qt.HasLen, 2)
+}
@@ -0,0 +1,102 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// rotatingWriter is an io.Writer writing to a file that is rotated once its size would exceed
+// maxSize bytes. The rotated files are renamed with a numeric suffix, path.1 being the most
+// recent one, and only the last maxFiles of them are kept. The current file always holds the
+// most recent output, a single write larger than maxSize is written as is.
+type rotatingWriter struct {
+	mu       sync.Mutex
+	path     string
+	maxSize  int64
+	maxFiles int
+	file     *os.File
+	size     int64
+}
+
+func newRotatingWriter(path string, maxSize int64, maxFiles int) (*rotatingWriter, error) {
+	w := &rotatingWriter{path: path, maxSize: maxSize, maxFiles: maxFiles}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0755)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	w.file = file
+	w.size = info.Size()
+	return w, nil
+}
+
+func (w *rotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		err := w.rotate()
+		if err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the rotated files by one, drops the oldest one and starts a new current file.
+func (w *rotatingWriter) rotate() error {
+	err := w.file.Close()
+	if err != nil {
+		return err
+	}
+	if w.maxFiles > 0 {
+		_ = os.Remove(w.rotatedPath(w.maxFiles))
+	}
+	for i := w.maxFiles - 1; i >= 1; i-- {
+		err = os.Rename(w.rotatedPath(i), w.rotatedPath(i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if w.maxFiles > 0 {
+		err = os.Rename(w.path, w.rotatedPath(1))
+		if err != nil {
+			return err
+		}
+	}
+	w.file, err = os.OpenFile(w.path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	w.size = 0
+	return nil
+}
+
+func (w *rotatingWriter) rotatedPath(i int) string {
+	return fmt.Sprintf("%s.%d", w.path, i)
+}
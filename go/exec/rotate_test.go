@@ -0,0 +1,71 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestRotatingWriter(t *testing.T) {
+	c := qt.New(t)
+	logPath := path.Join(t.TempDir(), stdoutFile)
+	w, err := newRotatingWriter(logPath, 10, 2)
+	c.Assert(err, qt.IsNil)
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n", "a very long line\n"} {
+		_, err = w.Write([]byte(line))
+		c.Assert(err, qt.IsNil)
+	}
+
+	// The oldest output is dropped, while the most recent one is always kept,
+	// even when a single write is larger than the maximum size.
+	want := map[string]string{
+		logPath:        "a very long line\n",
+		logPath + ".1": "fourth\n",
+		logPath + ".2": "third\n",
+	}
+	for file, content := range want {
+		got, err := os.ReadFile(file)
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(got), qt.Equals, content)
+	}
+	_, err = os.Stat(logPath + ".3")
+	c.Assert(os.IsNotExist(err), qt.IsTrue)
+}
+
+func TestRotatingWriterWithoutRotatedFiles(t *testing.T) {
+	c := qt.New(t)
+	logPath := path.Join(t.TempDir(), stderrFile)
+	c.Assert(os.WriteFile(logPath, []byte("previous\n"), 0644), qt.IsNil)
+
+	w, err := newRotatingWriter(logPath, 10, 0)
+	c.Assert(err, qt.IsNil)
+	_, err = w.Write([]byte("current\n"))
+	c.Assert(err, qt.IsNil)
+
+	got, err := os.ReadFile(logPath)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(got), qt.Equals, "current\n")
+	_, err = os.Stat(logPath + ".1")
+	c.Assert(os.IsNotExist(err), qt.IsTrue)
+}
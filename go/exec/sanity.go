@@ -0,0 +1,85 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/vitessio/arewefastyet/go/exec/verification"
+	"github.com/vitessio/arewefastyet/go/tools/macrobench"
+)
+
+// ErrInvalidResults is returned by Success when the results of the execution are implausible:
+// they are out of its SanityBounds, or its macrobenchmark failed its verification query. The
+// execution is then marked as StatusInvalid.
+var ErrInvalidResults = errors.New("implausible results")
+
+// checkResults marks the execution as StatusInvalid if the results of its macrobenchmark are
+// implausible, so that it is not used in comparisons. The results are implausible if they are
+// out of the SanityBounds, if there are none while bounds are set, or if the macrobenchmark
+// recorded the failure of its verification query, see verification.Record.
+func (e *Exec) checkResults() error {
+	macroType := macrobench.Type(e.TypeOf)
+	if macroType != macrobench.OLTP && macroType != macrobench.TPCC {
+		return nil
+	}
+	metadata, err := GetMetadata(e.clientDB, e.UUID.String())
+	if err != nil {
+		return err
+	}
+	var problems []string
+	if failure := metadata[verification.MetadataFailure]; failure != "" {
+		problems = append(problems, failure)
+	}
+	violations, err := e.checkSanityBounds(macroType)
+	if err != nil {
+		return err
+	}
+	problems = append(problems, violations...)
+	if len(problems) == 0 {
+		return nil
+	}
+
+	e.Status = StatusInvalid
+	e.Error = strings.Join(problems, ", ")
+	_, err = e.clientDB.Insert("UPDATE execution SET finished_at = CURRENT_TIME, status = ?, error = ? WHERE uuid = ?", StatusInvalid, e.Error, e.UUID.String())
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidResults, e.Error)
+}
+
+// checkSanityBounds returns the violations of the SanityBounds by the results of the
+// macrobenchmark, or a violation if it has no results. Nothing is checked without bounds.
+func (e *Exec) checkSanityBounds(macroType macrobench.Type) ([]string, error) {
+	if len(e.SanityBounds) == 0 {
+		return nil, nil
+	}
+	bounds, err := macrobench.ParseSanityBounds(e.SanityBounds)
+	if err != nil {
+		return nil, err
+	}
+	results, err := macrobench.GetResultsForExecution(macroType, e.UUID.String(), e.clientDB)
+	if err != nil {
+		return nil, err
+	}
+	return results.CheckSanityBounds(bounds)
+}
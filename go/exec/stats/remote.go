@@ -22,15 +22,19 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/vitessio/arewefastyet/go/infra/ansible"
+	"github.com/vitessio/arewefastyet/go/storage/influxdb"
 	"strings"
 )
 
 const (
-	statsRemoteDBHost     = "stats-remote-db-host"
-	statsRemoteDBDatabase = "stats-remote-db-database"
-	statsRemoteDBPort     = "stats-remote-db-port"
-	statsRemoteDBUser     = "stats-remote-db-user"
-	statsRemoteDBPassword = "stats-remote-db-password"
+	statsRemoteDBHost       = "stats-remote-db-host"
+	statsRemoteDBDatabase   = "stats-remote-db-database"
+	statsRemoteDBPort       = "stats-remote-db-port"
+	statsRemoteDBUser       = "stats-remote-db-user"
+	statsRemoteDBPassword   = "stats-remote-db-password"
+	statsRemoteDBPrecision  = "stats-remote-db-precision"
+	statsRemoteDBDuplicates = "stats-remote-db-duplicates"
+	statsRemoteDBMaxRetries = "stats-remote-db-max-retries"
 )
 
 type RemoteDBConfig struct {
@@ -39,6 +43,21 @@ type RemoteDBConfig struct {
 	User     string
 	Password string
 	DbName   string
+
+	// Precision is the precision of the timestamps written to the
+	// stats remote database, either "ns", "us", "ms" or "s".
+	Precision string
+
+	// Duplicates is the strategy handling the points written again to the stats
+	// remote database, either influxdb.DuplicatesOverwrite or influxdb.DuplicatesAppend.
+	// Only the points re-ingested by the influx_import command are affected: the stats
+	// of the executions are written by Telegraf, and their events and microbenchmark
+	// results are always tagged with their execution.
+	Duplicates string
+
+	// MaxRetries is the number of times the stats remote database is
+	// retried when it cannot be reached while creating a client.
+	MaxRetries int
 }
 
 func (rdbcfg *RemoteDBConfig) AddToViper(v *viper.Viper) {
@@ -47,6 +66,9 @@ func (rdbcfg *RemoteDBConfig) AddToViper(v *viper.Viper) {
 	_ = v.UnmarshalKey(statsRemoteDBDatabase, &rdbcfg.DbName)
 	_ = v.UnmarshalKey(statsRemoteDBUser, &rdbcfg.User)
 	_ = v.UnmarshalKey(statsRemoteDBPassword, &rdbcfg.Password)
+	_ = v.UnmarshalKey(statsRemoteDBPrecision, &rdbcfg.Precision)
+	_ = v.UnmarshalKey(statsRemoteDBDuplicates, &rdbcfg.Duplicates)
+	_ = v.UnmarshalKey(statsRemoteDBMaxRetries, &rdbcfg.MaxRetries)
 }
 
 func (rdbcfg *RemoteDBConfig) AddToCommand(cmd *cobra.Command) {
@@ -55,22 +77,54 @@ func (rdbcfg *RemoteDBConfig) AddToCommand(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&rdbcfg.DbName, statsRemoteDBDatabase, "", "Name of the stats remote database.")
 	cmd.Flags().StringVar(&rdbcfg.User, statsRemoteDBUser, "", "User used to connect to the stats remote database")
 	cmd.Flags().StringVar(&rdbcfg.Password, statsRemoteDBPassword, "", "Password to authenticate the stats remote database.")
+	cmd.Flags().StringVar(&rdbcfg.Precision, statsRemoteDBPrecision, "ns", "Precision of the timestamps written to the stats remote database, either ns, us, ms or s.")
+	cmd.Flags().StringVar(&rdbcfg.Duplicates, statsRemoteDBDuplicates, influxdb.DuplicatesOverwrite, "Strategy handling the points re-ingested into the stats remote database by gen influx_import, either overwrite (the point with the same tags and timestamp is replaced) or append (the points are tagged with their execution). It has no effect on the other commands.")
+	cmd.Flags().IntVar(&rdbcfg.MaxRetries, statsRemoteDBMaxRetries, 3, "Number of times the stats remote database is retried, with an exponential backoff, when it cannot be reached while creating a client.")
 
 	_ = viper.BindPFlag(statsRemoteDBHost, cmd.Flags().Lookup(statsRemoteDBHost))
 	_ = viper.BindPFlag(statsRemoteDBPort, cmd.Flags().Lookup(statsRemoteDBPort))
 	_ = viper.BindPFlag(statsRemoteDBDatabase, cmd.Flags().Lookup(statsRemoteDBDatabase))
 	_ = viper.BindPFlag(statsRemoteDBUser, cmd.Flags().Lookup(statsRemoteDBUser))
 	_ = viper.BindPFlag(statsRemoteDBPassword, cmd.Flags().Lookup(statsRemoteDBPassword))
+	_ = viper.BindPFlag(statsRemoteDBPrecision, cmd.Flags().Lookup(statsRemoteDBPrecision))
+	_ = viper.BindPFlag(statsRemoteDBDuplicates, cmd.Flags().Lookup(statsRemoteDBDuplicates))
+	_ = viper.BindPFlag(statsRemoteDBMaxRetries, cmd.Flags().Lookup(statsRemoteDBMaxRetries))
 }
 
-func (rdbcfg RemoteDBConfig) valid() bool {
+// IsValid returns true if the stats remote database is configured.
+func (rdbcfg RemoteDBConfig) IsValid() bool {
 	return rdbcfg.Host != "" && rdbcfg.Port != "" && rdbcfg.DbName != ""
 }
 
+// NewInfluxClient creates a new influxdb.Client connected to the stats remote database.
+// An *influxdb.UnreachableError is returned if the database cannot be reached after
+// MaxRetries retries.
+func (rdbcfg RemoteDBConfig) NewInfluxClient() (*influxdb.Client, error) {
+	return rdbcfg.influxConfig().NewClientWithRetry()
+}
+
+// VerifySchema ensures the stats remote database exists, creating it if it is missing.
+func (rdbcfg RemoteDBConfig) VerifySchema() error {
+	return rdbcfg.influxConfig().VerifySchema(true)
+}
+
+func (rdbcfg RemoteDBConfig) influxConfig() influxdb.Config {
+	return influxdb.Config{
+		Host:       rdbcfg.Host,
+		Port:       rdbcfg.Port,
+		User:       rdbcfg.User,
+		Password:   rdbcfg.Password,
+		Database:   rdbcfg.DbName,
+		Precision:  rdbcfg.Precision,
+		Duplicates: rdbcfg.Duplicates,
+		MaxRetries: rdbcfg.MaxRetries,
+	}
+}
+
 // AddToAnsible will add the stats remote database configuration
 // to the list of Ansible ExtraVars.
 func (rdbcfg RemoteDBConfig) AddToAnsible(ansibleCfg *ansible.Config) {
-	if !rdbcfg.valid() {
+	if !rdbcfg.IsValid() {
 		return
 	}
 	ansibleCfg.ExtraVars[strings.ReplaceAll(statsRemoteDBHost, "-", "_")] = rdbcfg.Host
@@ -23,4 +23,21 @@ const (
 	StatusStarted  = "started"
 	StatusFailed   = "failed"
 	StatusFinished = "finished"
+
+	// StatusTimedOut is used for executions that stayed in the
+	// StatusStarted status for too long, see MarkStuckExecutionsAsTimedOut.
+	StatusTimedOut = "timed_out"
+
+	// StatusPrepareFailed is used for executions that failed in Prepare,
+	// before the benchmark was started. The error is recorded in the
+	// execution's row, see Exec.Error.
+	StatusPrepareFailed = "prepare_failed"
+
+	// StatusInvalid is used for executions that finished with results out of
+	// their sanity bounds, they are excluded from comparisons, see Exec.SanityBounds.
+	StatusInvalid = "invalid"
+
+	// StatusCanceled is used for executions that were canceled while running,
+	// see Exec.ExecuteWithContext.
+	StatusCanceled = "canceled"
 )
@@ -0,0 +1,217 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	flagTracingEndpoint = "exec-otlp-endpoint"
+	flagTracingInsecure = "exec-otlp-insecure"
+
+	tracerName = "github.com/vitessio/arewefastyet/go/exec"
+
+	spanExecution = "execution"
+	spanPrepare   = "prepare"
+	spanProvision = "provision"
+	spanAnsible   = "ansible"
+	spanHooks     = "post_execute_hooks"
+	spanCompare   = "compare"
+)
+
+// TracingConfig configures the export of the executions as OpenTelemetry traces.
+// Each execution is a trace whose ID is the execution's UUID, with a span per phase.
+type TracingConfig struct {
+	// Endpoint is the host and port of the OTLP/HTTP endpoint the traces are exported to.
+	// Tracing is disabled if it is empty.
+	Endpoint string
+
+	// Insecure disables TLS when exporting to Endpoint.
+	Insecure bool
+}
+
+func (cfg TracingConfig) IsValid() bool {
+	return cfg.Endpoint != ""
+}
+
+func (cfg *TracingConfig) AddToViper(v *viper.Viper) {
+	_ = v.UnmarshalKey(flagTracingEndpoint, &cfg.Endpoint)
+	_ = v.UnmarshalKey(flagTracingInsecure, &cfg.Insecure)
+}
+
+func (cfg *TracingConfig) AddToCommand(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&cfg.Endpoint, flagTracingEndpoint, "", "Host and port of the OTLP/HTTP endpoint to which the executions are exported as traces. Tracing is disabled if empty.")
+	cmd.Flags().BoolVar(&cfg.Insecure, flagTracingInsecure, false, "Export the traces to the OTLP endpoint without TLS.")
+
+	_ = viper.BindPFlag(flagTracingEndpoint, cmd.Flags().Lookup(flagTracingEndpoint))
+	_ = viper.BindPFlag(flagTracingInsecure, cmd.Flags().Lookup(flagTracingInsecure))
+}
+
+// executionIDGenerator generates the IDs of the spans of an execution's trace. The trace ID
+// and the ID of the root span are derived from the execution's UUID, which allows spans
+// created by another process, such as the comparison, to be attached to the same trace.
+type executionIDGenerator struct {
+	execUUID uuid.UUID
+}
+
+func (g executionIDGenerator) NewIDs(_ context.Context) (trace.TraceID, trace.SpanID) {
+	return traceID(g.execUUID), rootSpanID(g.execUUID)
+}
+
+func (g executionIDGenerator) NewSpanID(_ context.Context, _ trace.TraceID) trace.SpanID {
+	var sid trace.SpanID
+	_, _ = rand.Read(sid[:])
+	return sid
+}
+
+// traceID returns the ID of the trace of the execution execUUID.
+func traceID(execUUID uuid.UUID) trace.TraceID {
+	return trace.TraceID(execUUID)
+}
+
+// rootSpanID returns the ID of the root span of the trace of the execution execUUID.
+func rootSpanID(execUUID uuid.UUID) trace.SpanID {
+	var sid trace.SpanID
+	copy(sid[:], execUUID[8:])
+	return sid
+}
+
+// newTracerProvider returns the provider of the tracers of the execution execUUID.
+// A no-op provider is returned if tracing is not configured.
+func newTracerProvider(cfg TracingConfig, execUUID uuid.UUID) (trace.TracerProvider, func(), error) {
+	if !cfg.IsValid() {
+		return trace.NewNoopTracerProvider(), func() {}, nil
+	}
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithIDGenerator(executionIDGenerator{execUUID: execUUID}),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String("arewefastyet"))),
+	)
+	shutdown := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = provider.Shutdown(ctx)
+	}
+	return provider, shutdown, nil
+}
+
+// endSpan ends span, recording err if it is not nil.
+func endSpan(span trace.Span, err error, opts ...trace.SpanEndOption) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End(opts...)
+}
+
+// spanAttributes returns the attributes describing the Exec on its root span.
+func (e *Exec) spanAttributes() []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("exec_uuid", e.UUID.String()),
+		attribute.String("git_ref", e.GitRef),
+		attribute.String("source", e.Source),
+		attribute.String("type", e.TypeOf),
+	}
+}
+
+// startTrace starts the root span of the Exec's trace, along with a child span covering
+// Prepare, which ran before the root span started. The returned function ends the root
+// span with the error of the execution and exports the trace.
+func (e *Exec) startTrace() (context.Context, func(err error)) {
+	provider, shutdown, err := newTracerProvider(e.tracingConfig, e.UUID)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.stderr, "could not export the execution's trace: %v\n", err)
+		provider, shutdown = trace.NewNoopTracerProvider(), func() {}
+	}
+	e.tracer = provider.Tracer(tracerName)
+
+	start := time.Now()
+	if !e.preparedAt.IsZero() {
+		start = e.preparedAt
+	}
+	ctx, root := e.tracer.Start(context.Background(), spanExecution, trace.WithTimestamp(start), trace.WithAttributes(e.spanAttributes()...))
+	if !e.preparedAt.IsZero() {
+		_, prepare := e.tracer.Start(ctx, spanPrepare, trace.WithTimestamp(e.preparedAt))
+		endSpan(prepare, nil, trace.WithTimestamp(e.preparedUntil))
+	}
+	return ctx, func(err error) {
+		endSpan(root, err)
+		shutdown()
+	}
+}
+
+// startSpan starts a child span of the Exec's trace, the returned function ends it.
+func (e *Exec) startSpan(ctx context.Context, name string) func(err error) {
+	_, span := e.tracer.Start(ctx, name)
+	return func(err error) {
+		endSpan(span, err)
+	}
+}
+
+// TraceComparison exports a span covering the comparison of the execution execUUID
+// to the trace of the execution. The comparison starts when TraceComparison is called
+// and ends when the returned function is called.
+func TraceComparison(cfg TracingConfig, execUUID string) func(err error) {
+	if !cfg.IsValid() {
+		return func(error) {}
+	}
+	id, err := uuid.Parse(execUUID)
+	if err != nil {
+		return func(error) {}
+	}
+	provider, shutdown, err := newTracerProvider(cfg, id)
+	if err != nil {
+		return func(error) {}
+	}
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID(id),
+		SpanID:     rootSpanID(id),
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), parent)
+	_, span := provider.Tracer(tracerName).Start(ctx, spanCompare, trace.WithAttributes(attribute.String("exec_uuid", execUUID)))
+	return func(err error) {
+		endSpan(span, err)
+		shutdown()
+	}
+}
@@ -0,0 +1,67 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package exec
+
+import (
+	"context"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/google/uuid"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestExecutionIDGenerator(t *testing.T) {
+	c := qt.New(t)
+	execUUID := uuid.MustParse("0f8fad5b-d9cb-469f-a165-70867728950e")
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSyncer(exporter),
+		sdktrace.WithIDGenerator(executionIDGenerator{execUUID: execUUID}),
+	)
+	tracer := provider.Tracer(tracerName)
+
+	ctx, root := tracer.Start(context.Background(), spanExecution)
+	_, child := tracer.Start(ctx, spanAnsible)
+	child.End()
+	root.End()
+
+	// a span started by another process under the root span belongs to the same trace
+	parent := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID(execUUID), SpanID: rootSpanID(execUUID), TraceFlags: trace.FlagsSampled, Remote: true})
+	_, compare := tracer.Start(trace.ContextWithRemoteSpanContext(context.Background(), parent), spanCompare)
+	compare.End()
+
+	spans := exporter.GetSpans()
+	c.Assert(spans, qt.HasLen, 3)
+	c.Assert(spans[1].Name, qt.Equals, spanExecution)
+	c.Assert(spans[1].SpanContext.TraceID().String(), qt.Equals, "0f8fad5bd9cb469fa16570867728950e")
+	c.Assert(spans[1].SpanContext.SpanID().String(), qt.Equals, "a16570867728950e")
+	for _, span := range []tracetest.SpanStub{spans[0], spans[2]} {
+		c.Assert(span.SpanContext.TraceID(), qt.Equals, spans[1].SpanContext.TraceID())
+		c.Assert(span.Parent.SpanID(), qt.Equals, spans[1].SpanContext.SpanID())
+	}
+}
+
+func TestTraceComparisonWithoutEndpoint(t *testing.T) {
+	end := TraceComparison(TracingConfig{}, uuid.NewString())
+	end(nil)
+}
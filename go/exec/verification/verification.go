@@ -0,0 +1,129 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+// Package verification checks that the benchmark of an execution actually exercised the
+// benchmarked cluster, by running a query against the cluster once the benchmark ran and
+// comparing its result with the expected one. An execution failing its verification is
+// marked as invalid and is not used by comparisons.
+package verification
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vitessio/arewefastyet/go/storage"
+)
+
+// MetadataFailure is the metadata key storing why the verification of an execution failed.
+const MetadataFailure = "verification_failure"
+
+// comparators are the operators that can prefix an expected result, the longest first.
+var comparators = []string{">=", "<=", "!=", ">", "<", "="}
+
+// Run runs query against db and returns its result, which must be a single value.
+func Run(db *sql.DB, query string) (string, error) {
+	rows, err := db.Query(query)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	if len(columns) != 1 {
+		return "", fmt.Errorf("the verification query must return a single column, got %d", len(columns))
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return "", err
+		}
+		return "", errors.New("the verification query returned no row")
+	}
+	var result sql.NullString
+	if err := rows.Scan(&result); err != nil {
+		return "", err
+	}
+	if rows.Next() {
+		return "", errors.New("the verification query must return a single row")
+	}
+	return result.String, rows.Err()
+}
+
+// Check compares the result of the verification query with expected, and returns why they do
+// not match, or an empty string if they do. The expected result can be prefixed by one of >=, <=,
+// !=, >, < or =, in which case both are compared as numbers. Otherwise, they must be equal.
+func Check(result, expected string) (string, error) {
+	expected = strings.TrimSpace(expected)
+	comparator := ""
+	for _, c := range comparators {
+		if strings.HasPrefix(expected, c) {
+			comparator = c
+			break
+		}
+	}
+	if comparator == "" {
+		if result == expected {
+			return "", nil
+		}
+		return fmt.Sprintf("the verification query returned %q, expected %q", result, expected), nil
+	}
+
+	want, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimPrefix(expected, comparator)), 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid expected verification result %q: %w", expected, err)
+	}
+	got, err := strconv.ParseFloat(strings.TrimSpace(result), 64)
+	if err != nil {
+		return fmt.Sprintf("the verification query returned %q, expected a number %s", result, expected), nil
+	}
+	var ok bool
+	switch comparator {
+	case ">=":
+		ok = got >= want
+	case "<=":
+		ok = got <= want
+	case "!=":
+		ok = got != want
+	case ">":
+		ok = got > want
+	case "<":
+		ok = got < want
+	case "=":
+		ok = got == want
+	}
+	if ok {
+		return "", nil
+	}
+	return fmt.Sprintf("the verification query returned %s, expected %s", result, expected), nil
+}
+
+// Record stores why the verification of the execution execUUID failed. Nothing is
+// stored if the benchmark is not linked to an execution.
+func Record(client storage.SQLClient, execUUID, failure string) error {
+	if execUUID == "" {
+		return nil
+	}
+	query := "INSERT INTO execution_metadata(exec_uuid, metadata_key, metadata_value) VALUES(?, ?, ?) ON DUPLICATE KEY UPDATE metadata_value = ?"
+	_, err := client.Insert(query, execUUID, MetadataFailure, failure, failure)
+	return err
+}
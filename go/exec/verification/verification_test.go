@@ -0,0 +1,58 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package verification
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   string
+		expected string
+		want     string
+		wantErr  string
+	}{
+		{name: "equal", result: "10000000", expected: "10000000"},
+		{name: "not equal", result: "0", expected: "10000000", want: `the verification query returned "0", expected "10000000"`},
+		{name: "greater", result: "12", expected: "> 0"},
+		{name: "not greater", result: "0", expected: ">0", want: "the verification query returned 0, expected >0"},
+		{name: "greater or equal", result: "5", expected: ">=5"},
+		{name: "not lower", result: "5", expected: "<5", want: "the verification query returned 5, expected <5"},
+		{name: "different", result: "1", expected: "!=0"},
+		{name: "numerically equal", result: "1.0", expected: "=1"},
+		{name: "not a number", result: "abc", expected: ">0", want: `the verification query returned "abc", expected a number >0`},
+		{name: "invalid expected", result: "1", expected: ">abc", wantErr: `invalid expected verification result ">abc": .*`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			got, err := Check(tt.result, tt.expected)
+			if tt.wantErr != "" {
+				c.Assert(err, qt.ErrorMatches, tt.wantErr)
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			c.Assert(got, qt.Equals, tt.want)
+		})
+	}
+}
@@ -21,7 +21,7 @@ package ansible
 import (
 	"context"
 	"errors"
-	"github.com/apenella/go-ansible/pkg/execute"
+	"fmt"
 	"github.com/apenella/go-ansible/pkg/options"
 	"github.com/apenella/go-ansible/pkg/playbook"
 	"github.com/otiai10/copy"
@@ -30,6 +30,8 @@ import (
 	"io"
 	"os"
 	"path"
+	"strconv"
+	"time"
 )
 
 const (
@@ -38,13 +40,63 @@ const (
 	flagAnsibleRoot    = "ansible-root-directory"
 	flagInventoryFiles = "ansible-inventory-files"
 	flagPlaybookFiles  = "ansible-playbook-files"
+	flagTypeFiles      = "ansible-type-files"
+	flagSSHUser        = "ansible-ssh-user"
+	flagSSHPrivateKey  = "ansible-ssh-private-key"
+	flagForks          = "ansible-forks"
+
+	flagSSHTimeout             = "ansible-ssh-timeout"
+	flagSSHServerAliveInterval = "ansible-ssh-server-alive-interval"
+	flagSSHControlPersist      = "ansible-ssh-control-persist"
+
+	defaultSSHUser = "root"
+
+	// defaultSSHControlPersist is the ControlPersist used by Ansible when its ssh_args are not set.
+	defaultSSHControlPersist = 60 * time.Second
 )
 
+// ErrHostUnreachable is matched by the PlaybookError returned by Run when Ansible could
+// not reach one or more hosts.
+// Such failures are caused by the infrastructure and not by the benchmarked code.
+var ErrHostUnreachable = errors.New("one or more host unreachable")
+
+// TypeFiles defines the inventory and playbook files used by a type of
+// benchmark, overriding the default ones of Config. The SSH user and
+// private key used to connect to the hosts can be overridden too.
+type TypeFiles struct {
+	InventoryFiles []string `mapstructure:"inventory-files"`
+	PlaybookFiles  []string `mapstructure:"playbook-files"`
+	SSHUser        string   `mapstructure:"ssh-user"`
+	SSHPrivateKey  string   `mapstructure:"ssh-private-key"`
+}
+
 type Config struct {
 	RootDir        string
 	InventoryFiles []string
 	PlaybookFiles  []string
 
+	// TypeFiles maps a type of benchmark (micro, oltp, tpcc, ...) to the
+	// Ansible files it uses. It can only be set from a configuration file.
+	TypeFiles map[string]TypeFiles
+
+	// SSHUser is the user Ansible connects as, it defaults to root.
+	// SSHPrivateKey is the path to the private key used to connect,
+	// if empty the default key of the SSH agent is used.
+	SSHUser       string
+	SSHPrivateKey string
+
+	// Forks is the number of hosts Ansible configures in parallel.
+	// If zero, the default of Ansible is used.
+	Forks int
+
+	// SSHTimeout is the timeout of the SSH connections to the hosts, SSHServerAliveInterval
+	// is the interval at which keepalives are sent over them, and SSHControlPersist is the
+	// duration during which an idle control connection is kept open. If zero, the defaults
+	// of Ansible are used.
+	SSHTimeout             time.Duration
+	SSHServerAliveInterval time.Duration
+	SSHControlPersist      time.Duration
+
 	stdout io.Writer
 	stderr io.Writer
 
@@ -57,16 +109,76 @@ func (c *Config) AddToViper(v *viper.Viper) {
 	_ = v.UnmarshalKey(flagAnsibleRoot, &c.RootDir)
 	_ = v.UnmarshalKey(flagInventoryFiles, &c.InventoryFiles)
 	_ = v.UnmarshalKey(flagPlaybookFiles, &c.PlaybookFiles)
+	_ = v.UnmarshalKey(flagTypeFiles, &c.TypeFiles)
+	_ = v.UnmarshalKey(flagSSHUser, &c.SSHUser)
+	_ = v.UnmarshalKey(flagSSHPrivateKey, &c.SSHPrivateKey)
+	_ = v.UnmarshalKey(flagForks, &c.Forks)
+	_ = v.UnmarshalKey(flagSSHTimeout, &c.SSHTimeout)
+	_ = v.UnmarshalKey(flagSSHServerAliveInterval, &c.SSHServerAliveInterval)
+	_ = v.UnmarshalKey(flagSSHControlPersist, &c.SSHControlPersist)
 }
 
 func (c *Config) AddToPersistentCommand(cmd *cobra.Command) {
 	cmd.PersistentFlags().StringVar(&c.RootDir, flagAnsibleRoot, "", "Root directory of Ansible")
 	cmd.PersistentFlags().StringSliceVar(&c.InventoryFiles, flagInventoryFiles, []string{}, "List of inventory files used by Ansible")
 	cmd.PersistentFlags().StringSliceVar(&c.PlaybookFiles, flagPlaybookFiles, []string{}, "List of playbook files used by Ansible")
+	cmd.PersistentFlags().StringVar(&c.SSHUser, flagSSHUser, defaultSSHUser, "User used by Ansible to connect to the hosts")
+	cmd.PersistentFlags().StringVar(&c.SSHPrivateKey, flagSSHPrivateKey, "", "Path to the private key used by Ansible to connect to the hosts")
+	cmd.PersistentFlags().IntVar(&c.Forks, flagForks, 0, "Number of hosts configured in parallel by Ansible, the default of Ansible is used if zero")
+	cmd.PersistentFlags().DurationVar(&c.SSHTimeout, flagSSHTimeout, 0, "Timeout of the SSH connections to the hosts, the default of Ansible is used if zero")
+	cmd.PersistentFlags().DurationVar(&c.SSHServerAliveInterval, flagSSHServerAliveInterval, 0, "Interval at which SSH keepalives are sent to the hosts, no keepalive is sent if zero")
+	cmd.PersistentFlags().DurationVar(&c.SSHControlPersist, flagSSHControlPersist, 0, "Duration during which an idle SSH control connection is kept open, the default of Ansible is used if zero")
 
 	_ = viper.BindPFlag(flagAnsibleRoot, cmd.Flags().Lookup(flagAnsibleRoot))
 	_ = viper.BindPFlag(flagInventoryFiles, cmd.Flags().Lookup(flagInventoryFiles))
 	_ = viper.BindPFlag(flagPlaybookFiles, cmd.Flags().Lookup(flagPlaybookFiles))
+	_ = viper.BindPFlag(flagSSHUser, cmd.Flags().Lookup(flagSSHUser))
+	_ = viper.BindPFlag(flagSSHPrivateKey, cmd.Flags().Lookup(flagSSHPrivateKey))
+	_ = viper.BindPFlag(flagForks, cmd.Flags().Lookup(flagForks))
+	_ = viper.BindPFlag(flagSSHTimeout, cmd.Flags().Lookup(flagSSHTimeout))
+	_ = viper.BindPFlag(flagSSHServerAliveInterval, cmd.Flags().Lookup(flagSSHServerAliveInterval))
+	_ = viper.BindPFlag(flagSSHControlPersist, cmd.Flags().Lookup(flagSSHControlPersist))
+}
+
+// UseFilesOfType replaces the inventory and playbook files, and the SSH user
+// and private key of Config by the ones defined for the given type of benchmark
+// in TypeFiles, if any.
+func (c *Config) UseFilesOfType(typeOf string) {
+	files, ok := c.TypeFiles[typeOf]
+	if !ok {
+		return
+	}
+	if len(files.InventoryFiles) > 0 {
+		c.InventoryFiles = files.InventoryFiles
+	}
+	if len(files.PlaybookFiles) > 0 {
+		c.PlaybookFiles = files.PlaybookFiles
+	}
+	if files.SSHUser != "" {
+		c.SSHUser = files.SSHUser
+	}
+	if files.SSHPrivateKey != "" {
+		c.SSHPrivateKey = files.SSHPrivateKey
+	}
+}
+
+// Validate ensures that the inventory and playbook files of Config, including
+// the ones of every type in TypeFiles, exist. Relative paths are resolved from RootDir.
+func (c Config) Validate() error {
+	files := append(append([]string{}, c.InventoryFiles...), c.PlaybookFiles...)
+	for _, typeFiles := range c.TypeFiles {
+		files = append(files, typeFiles.InventoryFiles...)
+		files = append(files, typeFiles.PlaybookFiles...)
+	}
+	for _, file := range files {
+		if !path.IsAbs(file) {
+			file = path.Join(c.RootDir, file)
+		}
+		if _, err := os.Stat(file); err != nil {
+			return fmt.Errorf("invalid Ansible file: %w", err)
+		}
+	}
+	return nil
 }
 
 func applyRootToFiles(root string, files *[]string) {
@@ -88,19 +200,67 @@ func inventoryFilesToString(invFiles []string) string {
 	return res
 }
 
-func Run(c *Config) error {
-	applyRootToFiles(c.RootDir, &c.PlaybookFiles)
-	applyRootToFiles(c.RootDir, &c.InventoryFiles)
-
-	ansiblePlaybookConnectionOptions := &options.AnsibleConnectionOptions{
-		User:          "root",
+func (c Config) connectionOptions() *options.AnsibleConnectionOptions {
+	user := c.SSHUser
+	if user == "" {
+		user = defaultSSHUser
+	}
+	return &options.AnsibleConnectionOptions{
+		User:          user,
+		PrivateKey:    c.SSHPrivateKey,
 		SSHCommonArgs: "-o StrictHostKeyChecking=no",
+		Timeout:       int(c.SSHTimeout.Seconds()),
 	}
+}
 
-	ansiblePlaybookOptions := &playbook.AnsiblePlaybookOptions{
+// sshArgs returns the ssh_args Ansible uses to connect to the hosts, or an empty string
+// if neither the keepalives nor the ControlPersist are configured. They replace the default
+// ssh_args of Ansible, as ssh ignores the options given again in the common arguments.
+func (c Config) sshArgs() string {
+	if c.SSHServerAliveInterval <= 0 && c.SSHControlPersist <= 0 {
+		return ""
+	}
+	controlPersist := c.SSHControlPersist
+	if controlPersist <= 0 {
+		controlPersist = defaultSSHControlPersist
+	}
+	args := fmt.Sprintf("-C -o ControlMaster=auto -o ControlPersist=%ds", int(controlPersist.Seconds()))
+	if c.SSHServerAliveInterval > 0 {
+		args += fmt.Sprintf(" -o ServerAliveInterval=%d", int(c.SSHServerAliveInterval.Seconds()))
+	}
+	return args
+}
+
+func (c Config) playbookOptions() *playbook.AnsiblePlaybookOptions {
+	opts := &playbook.AnsiblePlaybookOptions{
 		Inventory: inventoryFilesToString(c.InventoryFiles),
 		ExtraVars: c.ExtraVars,
 	}
+	if sshArgs := c.sshArgs(); sshArgs != "" {
+		opts.ExtraVars = make(map[string]interface{}, len(c.ExtraVars)+1)
+		for key, value := range c.ExtraVars {
+			opts.ExtraVars[key] = value
+		}
+		opts.ExtraVars["ansible_ssh_args"] = sshArgs
+	}
+	if c.Forks > 0 {
+		opts.Forks = strconv.Itoa(c.Forks)
+	}
+	return opts
+}
+
+func Run(c *Config) error {
+	return RunContext(context.Background(), c)
+}
+
+// RunContext works like Run, the playbook is stopped if ctx is done before it completes.
+func RunContext(ctx context.Context, c *Config) error {
+	applyRootToFiles(c.RootDir, &c.PlaybookFiles)
+	applyRootToFiles(c.RootDir, &c.InventoryFiles)
+
+	ansiblePlaybookConnectionOptions := c.connectionOptions()
+
+	ansiblePlaybookOptions := c.playbookOptions()
 
 	ansiblePlaybookPrivilegeEscalationOptions := &options.AnsiblePrivilegeEscalationOptions{
 		Become: true,
@@ -111,18 +271,9 @@ func Run(c *Config) error {
 		ConnectionOptions:          ansiblePlaybookConnectionOptions,
 		PrivilegeEscalationOptions: ansiblePlaybookPrivilegeEscalationOptions,
 		Options:                    ansiblePlaybookOptions,
-		Exec: execute.NewDefaultExecute(
-			execute.WithShowDuration(),
-			execute.WithWrite(c.stdout),
-			execute.WithWriteError(c.stderr),
-		),
-	}
-
-	err := plb.Run(context.TODO())
-	if err != nil {
-		return err
+		Exec:                       &playbookExecutor{stdout: c.stdout, stderr: c.stderr},
 	}
-	return nil
+	return plb.Run(ctx)
 }
 
 func (c *Config) CopyRootDirectory(directory string) error {
@@ -146,7 +297,7 @@ func (c *Config) SetStderr(stderr *os.File) {
 	c.stderr = stderr
 }
 
-func (c *Config) SetOutputs(stdout, stderr *os.File) {
+func (c *Config) SetOutputs(stdout, stderr io.Writer) {
 	c.stdout = stdout
 	c.stderr = stderr
 }
\ No newline at end of file
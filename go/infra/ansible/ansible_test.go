@@ -19,11 +19,15 @@
 package ansible
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	qt "github.com/frankban/quicktest"
 	"io/ioutil"
 	"os"
 	"path"
 	"testing"
+	"time"
 )
 
 func TestConfig_MoveRootFolder(t *testing.T) {
@@ -74,3 +78,178 @@ func TestConfig_MoveRootFolder(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_UseFilesOfType(t *testing.T) {
+	cfg := Config{
+		InventoryFiles: []string{"inventory.yml"},
+		PlaybookFiles:  []string{"playbook.yml"},
+		TypeFiles: map[string]TypeFiles{
+			"micro": {InventoryFiles: []string{"micro_inventory.yml"}, PlaybookFiles: []string{"micro.yml"}},
+			"tpcc":  {PlaybookFiles: []string{"tpcc.yml"}},
+		},
+	}
+	tests := []struct {
+		typeOf        string
+		wantInventory []string
+		wantPlaybook  []string
+	}{
+		{typeOf: "micro", wantInventory: []string{"micro_inventory.yml"}, wantPlaybook: []string{"micro.yml"}},
+		{typeOf: "tpcc", wantInventory: []string{"inventory.yml"}, wantPlaybook: []string{"tpcc.yml"}},
+		{typeOf: "oltp", wantInventory: []string{"inventory.yml"}, wantPlaybook: []string{"playbook.yml"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.typeOf, func(t *testing.T) {
+			c := qt.New(t)
+			cfg := cfg
+			cfg.UseFilesOfType(tt.typeOf)
+			c.Assert(cfg.InventoryFiles, qt.DeepEquals, tt.wantInventory)
+			c.Assert(cfg.PlaybookFiles, qt.DeepEquals, tt.wantPlaybook)
+		})
+	}
+}
+
+func TestConfig_connectionOptions(t *testing.T) {
+	tests := []struct {
+		name           string
+		cfg            Config
+		typeOf         string
+		wantUser       string
+		wantPrivateKey string
+	}{
+		{name: "default user", cfg: Config{}, wantUser: "root"},
+		{name: "configured user and key", cfg: Config{SSHUser: "ubuntu", SSHPrivateKey: "/keys/id_rsa"}, wantUser: "ubuntu", wantPrivateKey: "/keys/id_rsa"},
+		{
+			name: "user of type",
+			cfg: Config{
+				SSHUser:   "ubuntu",
+				TypeFiles: map[string]TypeFiles{"micro": {SSHUser: "ec2-user", SSHPrivateKey: "/keys/aws"}},
+			},
+			typeOf:         "micro",
+			wantUser:       "ec2-user",
+			wantPrivateKey: "/keys/aws",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			cfg := tt.cfg
+			cfg.UseFilesOfType(tt.typeOf)
+			opts := cfg.connectionOptions()
+			c.Assert(opts.User, qt.Equals, tt.wantUser)
+			c.Assert(opts.PrivateKey, qt.Equals, tt.wantPrivateKey)
+		})
+	}
+}
+
+func TestConfig_playbookOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       Config
+		wantForks string
+	}{
+		{name: "default forks", cfg: Config{}, wantForks: ""},
+		{name: "configured forks", cfg: Config{Forks: 10}, wantForks: "10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			opts := tt.cfg.playbookOptions()
+			c.Assert(opts.Forks, qt.Equals, tt.wantForks)
+		})
+	}
+}
+
+func TestConfig_sshArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{name: "defaults of Ansible", cfg: Config{SSHTimeout: time.Minute}, want: ""},
+		{name: "keepalives", cfg: Config{SSHServerAliveInterval: 30 * time.Second}, want: "-C -o ControlMaster=auto -o ControlPersist=60s -o ServerAliveInterval=30"},
+		{name: "control persist", cfg: Config{SSHControlPersist: 10 * time.Minute}, want: "-C -o ControlMaster=auto -o ControlPersist=600s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qt.Assert(t, tt.cfg.sshArgs(), qt.Equals, tt.want)
+		})
+	}
+}
+
+func TestConfig_SSHOptions(t *testing.T) {
+	c := qt.New(t)
+	cfg := Config{
+		SSHTimeout:             time.Minute,
+		SSHServerAliveInterval: 30 * time.Second,
+		ExtraVars:              map[string]interface{}{"arewefastyet_clients": 10},
+	}
+	c.Assert(cfg.connectionOptions().Timeout, qt.Equals, 60)
+
+	opts := cfg.playbookOptions()
+	c.Assert(opts.ExtraVars, qt.DeepEquals, map[string]interface{}{
+		"arewefastyet_clients": 10,
+		"ansible_ssh_args":     "-C -o ControlMaster=auto -o ControlPersist=60s -o ServerAliveInterval=30",
+	})
+	c.Assert(cfg.ExtraVars, qt.HasLen, 1)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	root := t.TempDir()
+	for _, file := range []string{"inventory.yml", "playbook.yml", "micro.yml"} {
+		err := ioutil.WriteFile(path.Join(root, file), []byte("---\n"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{name: "Existing files", cfg: Config{RootDir: root, InventoryFiles: []string{"inventory.yml"}, PlaybookFiles: []string{path.Join(root, "playbook.yml")}}},
+		{name: "Existing type files", cfg: Config{RootDir: root, TypeFiles: map[string]TypeFiles{"micro": {PlaybookFiles: []string{"micro.yml"}}}}},
+		{name: "Missing file", cfg: Config{RootDir: root, PlaybookFiles: []string{"missing.yml"}}, wantErr: true},
+		{name: "Missing type file", cfg: Config{RootDir: root, TypeFiles: map[string]TypeFiles{"tpcc": {InventoryFiles: []string{"tpcc_inventory.yml"}}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			err := tt.cfg.Validate()
+			if tt.wantErr {
+				c.Assert(err, qt.Not(qt.IsNil))
+				return
+			}
+			c.Assert(err, qt.IsNil)
+		})
+	}
+}
+
+func TestPlaybookExecutor_Execute(t *testing.T) {
+	tests := []struct {
+		name            string
+		command         []string
+		wantExitCode    int
+		wantUnreachable bool
+	}{
+		{name: "Success", command: []string{"sh", "-c", "echo ok"}},
+		{name: "Failed host", command: []string{"sh", "-c", "exit 2"}, wantExitCode: 2},
+		{name: "Unreachable host", command: []string{"sh", "-c", "exit 3"}, wantExitCode: 3, wantUnreachable: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			var stdout, stderr bytes.Buffer
+			e := &playbookExecutor{stdout: &stdout, stderr: &stderr}
+			err := e.Execute(context.Background(), tt.command, nil)
+			if tt.wantExitCode == 0 {
+				c.Assert(err, qt.IsNil)
+				c.Assert(stdout.String(), qt.Contains, "ok")
+				return
+			}
+			var playbookErr *PlaybookError
+			c.Assert(errors.As(err, &playbookErr), qt.IsTrue)
+			c.Assert(playbookErr.ExitCode, qt.Equals, tt.wantExitCode)
+			c.Assert(errors.Is(err, ErrHostUnreachable), qt.Equals, tt.wantUnreachable)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package ansible
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	osexec "os/exec"
+	"time"
+
+	"github.com/apenella/go-ansible/pkg/execute"
+	"github.com/apenella/go-ansible/pkg/stdoutcallback"
+	"github.com/apenella/go-ansible/pkg/stdoutcallback/results"
+)
+
+// PlaybookError is returned by Run when the ansible-playbook command exits with
+// a non-zero code. It matches ErrHostUnreachable with errors.Is when the code
+// reports that Ansible could not reach one or more hosts.
+type PlaybookError struct {
+	ExitCode int
+	err      error
+}
+
+func (e *PlaybookError) Error() string {
+	return fmt.Sprintf("ansible-playbook exited with code %d: %v", e.ExitCode, e.err)
+}
+
+func (e *PlaybookError) Unwrap() error {
+	return e.err
+}
+
+func (e *PlaybookError) Is(target error) bool {
+	return target == ErrHostUnreachable && e.ExitCode == execute.AnsiblePlaybookErrorCodeOneOrMoreHostUnreachable
+}
+
+// playbookExecutor runs the ansible-playbook command like execute.DefaultExecute,
+// but keeps its exit code in a PlaybookError instead of formatting it in the
+// error message.
+type playbookExecutor struct {
+	stdout, stderr io.Writer
+}
+
+func (e *playbookExecutor) Execute(ctx context.Context, command []string, resultsFunc stdoutcallback.StdoutCallbackResultsFunc, _ ...execute.ExecuteOptions) error {
+	if resultsFunc == nil {
+		resultsFunc = results.DefaultStdoutCallbackResults
+	}
+	stdout, stderr := e.stdout, e.stderr
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+
+	cmd := osexec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stderr = stderr
+	cmdStdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	// the output must be read entirely before waiting for the command
+	resultsErr := resultsFunc(ctx, cmdStdout, stdout)
+	if err := cmd.Wait(); err != nil {
+		var exitErr *osexec.ExitError
+		if ctx.Err() == nil && errors.As(err, &exitErr) {
+			return &PlaybookError{ExitCode: exitErr.ExitCode(), err: err}
+		}
+		return err
+	}
+	if resultsErr != nil {
+		return resultsErr
+	}
+	fmt.Fprintf(stdout, "Duration: %s\n", time.Since(start).String())
+	return nil
+}
@@ -0,0 +1,84 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vitessio/arewefastyet/go/exec"
+	"github.com/vitessio/arewefastyet/go/slack"
+	"github.com/vitessio/arewefastyet/go/tools/git"
+	"github.com/vitessio/arewefastyet/go/tools/macrobench"
+)
+
+// metadataAnomalies is the metadata key storing the anomalous metrics of an execution, see notifyAnomalies.
+const metadataAnomalies = "anomalies"
+
+// notifyAnomalies notifies Slack of the metrics of the execution execUUID that fall
+// outside of the series formed by the previous executions of the same source, even
+// if the comparison against the previous execution does not show a regression. The
+// anomalies are also recorded in the metadataAnomalies metadata of the execution.
+// Only macrobenchmarks are checked, and only if web-anomaly-threshold is set.
+func (s *Server) notifyAnomalies(identifier executionIdentifier, execUUID string) {
+	if s.anomalyThreshold <= 0 || identifier.BenchmarkType == "micro" {
+		return
+	}
+	anomalies, err := macrobench.GetAnomaliesForExecution(s.readDB(), macrobench.Type(identifier.BenchmarkType), identifier.Source,
+		macrobench.PlannerVersion(identifier.PlannerVersion), execUUID, s.anomalyHistoryDays, s.anomalyThreshold)
+	if err != nil {
+		slog.Error(err)
+		return
+	}
+	if len(anomalies) == 0 {
+		return
+	}
+	slog.Warnf("Execution %s of %+v is anomalous: %+v", execUUID, identifier, anomalies)
+	if err := exec.SetMetadata(s.dbClient, execUUID, metadataAnomalies, formatAnomaliesMetadata(anomalies)); err != nil {
+		slog.Error(err)
+	}
+	msg := slack.TextMessage{Content: formatAnomalies(identifier, anomalies, s.anomalyThreshold, s.anomalyHistoryDays)}
+	if err := msg.Send(s.slackConfig); err != nil {
+		slog.Error(err)
+	}
+}
+
+// formatAnomaliesMetadata formats the anomalies stored in the metadataAnomalies metadata of an execution.
+func formatAnomaliesMetadata(anomalies []macrobench.Anomaly) string {
+	formatted := make([]string, 0, len(anomalies))
+	for _, anomaly := range anomalies {
+		formatted = append(formatted, fmt.Sprintf("%s: %.2f (z-score %.2f)", anomaly.Metric, anomaly.Value, anomaly.ZScore))
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// formatAnomalies formats the Slack message notifying the anomalies of the execution
+// of the given identifier.
+func formatAnomalies(identifier executionIdentifier, anomalies []macrobench.Anomaly, threshold float64, historyDays int) string {
+	content := fmt.Sprintf("*Anomaly detected.*\nThe %s benchmark of <https://github.com/vitessio/vitess/commit/%s|%s> from source %s is more than %g standard deviations "+
+		"away from the executions of the last %d days:\n",
+		identifier.BenchmarkType, identifier.GitRef, git.ShortenSHAN(identifier.GitRef, notificationShortSHALength), identifier.Source, threshold, historyDays)
+	for _, anomaly := range anomalies {
+		content += fmt.Sprintf("- %s: %.2f (z-score %.2f)\n", anomaly.Metric, anomaly.Value, anomaly.ZScore)
+	}
+	if identifier.PlannerVersion != "" {
+		content += fmt.Sprintf("Query planner: %s\n", identifier.PlannerVersion)
+	}
+	return content
+}
@@ -0,0 +1,45 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/vitessio/arewefastyet/go/tools/macrobench"
+)
+
+func TestFormatAnomalies(t *testing.T) {
+	identifier := executionIdentifier{GitRef: "4a70d3d226113282554b393a97f893d133486b94", Source: "cron", BenchmarkType: "oltp", PlannerVersion: "V3"}
+	anomalies := []macrobench.Anomaly{
+		{Metric: "total QPS", Value: 80, ZScore: -12.649},
+		{Metric: "latency", Value: 20, ZScore: 18.257},
+	}
+	qt.Assert(t, formatAnomalies(identifier, anomalies, 3, 30), qt.Equals, "*Anomaly detected.*\nThe oltp benchmark of "+
+		"<https://github.com/vitessio/vitess/commit/4a70d3d226113282554b393a97f893d133486b94|4a70d3d226> from source cron is more than 3 standard deviations "+
+		"away from the executions of the last 30 days:\n- total QPS: 80.00 (z-score -12.65)\n- latency: 20.00 (z-score 18.26)\nQuery planner: V3\n")
+}
+
+func TestFormatAnomaliesMetadata(t *testing.T) {
+	anomalies := []macrobench.Anomaly{
+		{Metric: "total QPS", Value: 80, ZScore: -12.649},
+		{Metric: "latency", Value: 20, ZScore: 18.257},
+	}
+	qt.Assert(t, formatAnomaliesMetadata(anomalies), qt.Equals, "total QPS: 80.00 (z-score -12.65), latency: 20.00 (z-score 18.26)")
+}
@@ -0,0 +1,58 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// registerAuthenticatedAPI registers the API endpoints modifying the queue or the executions,
+// or sending notifications, on the given group which is expected to require the API token.
+func (s *Server) registerAuthenticatedAPI(api *gin.RouterGroup) {
+	api.POST("/notify/compare", s.notifyCompareHandler)
+	api.POST("/recompare", s.recompareHandler)
+	api.POST("/run/sync", s.syncRunHandler)
+	api.POST("/queue", s.enqueueHandler)
+	api.POST("/execution/cancel-by-ref", s.cancelByRefHandler)
+	api.POST("/backfill", s.backfillHandler)
+	api.POST("/requeue", s.requeueHandler)
+	api.POST("/suite", s.suiteHandler)
+	api.POST("/execution/:uuid/cleanup", s.executionCleanUpAPIHandler)
+}
+
+// requireAPIToken is a middleware refusing the requests that do not give the API token as
+// the bearer token of their Authorization header. The token is not required if no API token
+// is configured.
+func (s *Server) requireAPIToken(c *gin.Context) {
+	if s.apiToken == "" {
+		c.Next()
+		return
+	}
+	header := c.GetHeader("Authorization")
+	token := strings.TrimPrefix(header, "Bearer ")
+	if token == header || subtle.ConstantTimeCompare([]byte(token), []byte(s.apiToken)) != 1 {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing or invalid API token"})
+		return
+	}
+	c.Next()
+}
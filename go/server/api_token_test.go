@@ -0,0 +1,77 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gin-gonic/gin"
+)
+
+func TestServer_requireAPIToken(t *testing.T) {
+	testcases := []struct {
+		name          string
+		token         string
+		authorization string
+		wantStatus    int
+	}{
+		{name: "No token configured", token: "", authorization: "", wantStatus: http.StatusNoContent},
+		{name: "Missing token", token: "token", authorization: "", wantStatus: http.StatusUnauthorized},
+		{name: "Token without bearer scheme", token: "token", authorization: "token", wantStatus: http.StatusUnauthorized},
+		{name: "Invalid token", token: "token", authorization: "Bearer other", wantStatus: http.StatusUnauthorized},
+		{name: "Valid token", token: "token", authorization: "Bearer token", wantStatus: http.StatusNoContent},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := qt.New(t)
+			s := &Server{apiToken: tc.token}
+			router := gin.New()
+			router.POST("/api/queue", s.requireAPIToken, func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+			req := httptest.NewRequest(http.MethodPost, "/api/queue", nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			c.Assert(rec.Code, qt.Equals, tc.wantStatus)
+		})
+	}
+}
+
+func TestServer_registerAuthenticatedAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := &Server{apiToken: "token"}
+	router := gin.New()
+	s.registerAuthenticatedAPI(router.Group("/api", s.requireAPIToken))
+
+	for _, path := range []string{"/api/notify/compare", "/api/recompare", "/api/run/sync", "/api/queue", "/api/execution/cancel-by-ref",
+		"/api/backfill", "/api/requeue", "/api/suite", "/api/execution/0a1b2c3d/cleanup"} {
+		t.Run(path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, path, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			qt.Assert(t, rec.Code, qt.Equals, http.StatusUnauthorized)
+		})
+	}
+}
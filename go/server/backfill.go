@@ -0,0 +1,123 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vitessio/arewefastyet/go/exec"
+	"github.com/vitessio/arewefastyet/go/tools/git"
+)
+
+var errBackfillRangeTooLarge = errors.New("backfill range too large")
+
+// backfillRequest is the body expected by backfillHandler.
+type backfillRequest struct {
+	// From and To delimit the range of commits to backfill, From is excluded.
+	From           string `json:"from" binding:"required"`
+	To             string `json:"to" binding:"required"`
+	Type           string `json:"type" binding:"required"`
+	PlannerVersion string `json:"planner_version"`
+
+	// Force allows to backfill ranges larger than the maximum number of commits.
+	Force bool `json:"force"`
+}
+
+// backfillHandler enqueues the executions of all the commits between two git refs,
+// in order to fill the history of a benchmark type. The commits are enqueued
+// one by one, waiting backfillEnqueueInterval between two commits.
+func (s *Server) backfillHandler(c *gin.Context) {
+	var req backfillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	configFile, ok := s.getConfigFiles()[req.Type]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s %s", errUnknownBenchmarkType, req.Type)})
+		return
+	}
+	planner := string(syncRunPlannerVersion(req.Type, req.PlannerVersion))
+
+	s.vitessPathMu.Lock()
+	commits, err := listBackfillCommits(s.getVitessPath(), req.From, req.To)
+	s.vitessPathMu.Unlock()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("could not list the commits between %s and %s: %v", req.From, req.To, err)})
+		return
+	}
+	if err := checkBackfillRange(len(commits), s.backfillMaxCommits, req.Force); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	elements := make([]*executionQueueElement, 0, len(commits))
+	for _, ref := range commits {
+		element := s.createSimpleExecutionQueueElement(exec.SourceBackfill, configFile, ref, req.Type, planner, false, 0)
+		element.baselineOnly = true
+		element.lowPriority = true
+		elements = append(elements, element)
+	}
+	go s.enqueueBackfill(elements, s.backfillEnqueueInterval)
+	c.JSON(http.StatusAccepted, gin.H{"status": "queued", "commits": len(commits)})
+}
+
+// listBackfillCommits returns the commits of the git repository repoDir between the git refs from
+// and to, from excluded, the oldest first. Both git refs must name a commit, they are verified
+// before being given to git rev-list so that they cannot be interpreted as options.
+func listBackfillCommits(repoDir, from, to string) ([]string, error) {
+	var shas []string
+	for _, ref := range []string{from, to} {
+		out, err := git.ExecCmd(repoDir, "git", "rev-parse", "--verify", "--quiet", "--end-of-options", ref+"^{commit}")
+		if err != nil {
+			return nil, fmt.Errorf("%s is not a commit", ref)
+		}
+		shas = append(shas, strings.TrimSpace(string(out)))
+	}
+	out, err := git.ExecCmd(repoDir, "git", "rev-list", "--reverse", "--end-of-options", shas[0]+".."+shas[1])
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// checkBackfillRange returns an error if a backfill of nbCommits commits exceeds
+// maxCommits, unless the backfill is forced. A maxCommits of zero disables the limit.
+func checkBackfillRange(nbCommits, maxCommits int, force bool) error {
+	if force || maxCommits <= 0 || nbCommits <= maxCommits {
+		return nil
+	}
+	return fmt.Errorf("%w: %d commits, the maximum is %d, use force to backfill it anyway", errBackfillRangeTooLarge, nbCommits, maxCommits)
+}
+
+// enqueueBackfill adds the elements to the queue, waiting interval between two elements
+// to avoid saturating the infrastructure with a large backfill.
+func (s *Server) enqueueBackfill(elements []*executionQueueElement, interval time.Duration) {
+	for i, element := range elements {
+		if i > 0 && interval > 0 {
+			time.Sleep(interval)
+		}
+		s.addToQueue(element)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/vitessio/arewefastyet/go/tools/git"
+)
+
+func TestCheckBackfillRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		nbCommits  int
+		maxCommits int
+		force      bool
+		wantErr    bool
+	}{
+		{name: "within the limit", nbCommits: 10, maxCommits: 50},
+		{name: "at the limit", nbCommits: 50, maxCommits: 50},
+		{name: "above the limit", nbCommits: 51, maxCommits: 50, wantErr: true},
+		{name: "above the limit but forced", nbCommits: 1000, maxCommits: 50, force: true},
+		{name: "no limit", nbCommits: 1000, maxCommits: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkBackfillRange(tt.nbCommits, tt.maxCommits, tt.force)
+			if tt.wantErr {
+				qt.Assert(t, errors.Is(err, errBackfillRangeTooLarge), qt.IsTrue)
+				return
+			}
+			qt.Assert(t, err, qt.IsNil)
+		})
+	}
+}
+
+func TestListBackfillCommits(t *testing.T) {
+	c := qt.New(t)
+	repo := c.TempDir()
+	run := func(args ...string) string {
+		out, err := git.ExecCmd(repo, "git", args...)
+		c.Assert(err, qt.IsNil, qt.Commentf("git %v: %s", args, out))
+		return strings.TrimSpace(string(out))
+	}
+	run("init", "-b", "main")
+	var commits []string
+	for i := 0; i < 4; i++ {
+		run("-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "commit")
+		commits = append(commits, run("rev-parse", "HEAD"))
+	}
+
+	got, err := listBackfillCommits(repo, commits[0], "main")
+	c.Assert(err, qt.IsNil)
+	c.Assert(got, qt.DeepEquals, commits[1:])
+
+	for _, ref := range []string{"unknown", "--output=/tmp/backfill", "main^{tree}"} {
+		_, err = listBackfillCommits(repo, ref, "main")
+		c.Assert(err, qt.ErrorMatches, ".* is not a commit")
+		_, err = listBackfillCommits(repo, commits[0], ref)
+		c.Assert(err, qt.ErrorMatches, ".* is not a commit")
+	}
+}
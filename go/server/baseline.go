@@ -0,0 +1,259 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vitessio/arewefastyet/go/exec"
+	"github.com/vitessio/arewefastyet/go/slack"
+	"github.com/vitessio/arewefastyet/go/tools/git"
+)
+
+// getPreviousFromSameSource returns the git ref of the previous finished execution of the given source,
+// benchmark type and planner version, which is used as the baseline of ref. Executions older than
+// maxBaselineAge are ignored: if the previous execution is too old, no git ref is returned and Slack
+// is notified that ref has no recent baseline, see notifyNoRecentBaseline. If there is no previous execution at all, Slack is
+// notified that the benchmark type is being baselined, see notifyNoBaseline.
+func (s *Server) getPreviousFromSameSource(source, configType, plannerVersion, ref string) (string, error) {
+	getPrevious := func(maxAge time.Duration) (previousGitRef string, err error) {
+		if configType == "micro" {
+			_, previousGitRef, err = exec.GetPreviousFromSourceMicrobenchmark(s.readDB(), source, ref, maxAge)
+		} else {
+			_, previousGitRef, err = exec.GetPreviousFromSourceMacrobenchmark(s.readDB(), source, configType, plannerVersion, ref, maxAge)
+		}
+		return previousGitRef, err
+	}
+
+	previousGitRef, err := getPrevious(s.maxBaselineAge)
+	if err != nil || previousGitRef != "" {
+		return previousGitRef, err
+	}
+
+	identifier := executionIdentifier{GitRef: ref, Source: source, BenchmarkType: configType, PlannerVersion: plannerVersion}
+	var staleGitRef string
+	if s.maxBaselineAge > 0 {
+		staleGitRef, err = getPrevious(0)
+		if err != nil {
+			return "", err
+		}
+	}
+	if staleGitRef == "" {
+		s.notifyNoBaseline(identifier)
+		return "", nil
+	}
+	s.notifyNoRecentBaseline(identifier, staleGitRef)
+	return "", nil
+}
+
+const (
+	// baselinePreviousSameSource compares an execution against the previous execution
+	// of its source, see getPreviousFromSameSource. This is the default strategy.
+	baselinePreviousSameSource = "previous-same-source"
+
+	// baselineLatestCron compares an execution against the latest finished cron execution.
+	baselineLatestCron = "latest-cron"
+
+	// baselineTag compares an execution against the execution of a release tag, given
+	// after the prefix, e.g. "tag:v14.0.0".
+	baselineTag = "tag:"
+
+	// baselineGolden compares an execution against a fixed git ref, given after the prefix.
+	// The golden git ref is benchmarked once with the golden source and then reused.
+	baselineGolden = "golden:"
+
+	// sourceBaselineWildcard ends the sources of sourceBaselines matching all the sources
+	// they prefix, e.g. "cron_release-*".
+	sourceBaselineWildcard = "*"
+)
+
+// baselineStrategy decides the execution a new execution of a source is compared against.
+// The argument is the tag of baselineTag and the git ref of baselineGolden.
+type baselineStrategy struct {
+	name     string
+	argument string
+}
+
+// parseBaselineStrategy parses a strategy of the sourceBaselines flag.
+func parseBaselineStrategy(value string) (baselineStrategy, error) {
+	switch {
+	case value == baselinePreviousSameSource || value == baselineLatestCron:
+		return baselineStrategy{name: value}, nil
+	case strings.HasPrefix(value, baselineTag) || strings.HasPrefix(value, baselineGolden):
+		idx := strings.Index(value, ":") + 1
+		if value[idx:] == "" {
+			missing := "tag"
+			if value[:idx] == baselineGolden {
+				missing = "git ref"
+			}
+			return baselineStrategy{}, fmt.Errorf("invalid baseline strategy %q: missing the %s", value, missing)
+		}
+		return baselineStrategy{name: value[:idx], argument: value[idx:]}, nil
+	}
+	return baselineStrategy{}, fmt.Errorf("invalid baseline strategy %q: expected %s, %s, %s<tag> or %s<git ref>",
+		value, baselinePreviousSameSource, baselineLatestCron, baselineTag, baselineGolden)
+}
+
+// getSourceBaselines parses the baseline strategy of each source of sourceBaselines.
+func (s *Server) getSourceBaselines() (map[string]baselineStrategy, error) {
+	strategies := make(map[string]baselineStrategy, len(s.sourceBaselines))
+	for source, value := range s.sourceBaselines {
+		strategy, err := parseBaselineStrategy(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid baseline of source %s: %w", source, err)
+		}
+		strategies[source] = strategy
+	}
+	return strategies, nil
+}
+
+// baselineStrategyFor returns the baseline strategy of source: the one of source itself, or
+// else the one of the longest wildcard source prefixing it, or else baselinePreviousSameSource.
+func (s *Server) baselineStrategyFor(source string) baselineStrategy {
+	strategy := baselineStrategy{name: baselinePreviousSameSource}
+	strategies, err := s.getSourceBaselines()
+	if err != nil {
+		slog.Error(err)
+		return strategy
+	}
+	if exact, ok := strategies[source]; ok {
+		return exact
+	}
+	longest := -1
+	for key, candidate := range strategies {
+		prefix := strings.TrimSuffix(key, sourceBaselineWildcard)
+		if prefix != key && strings.HasPrefix(source, prefix) && len(prefix) > longest {
+			strategy, longest = candidate, len(prefix)
+		}
+	}
+	return strategy
+}
+
+// getSourceBaseline returns the git ref and source of the execution the execution of ref from the
+// given source is compared against, according to the baseline strategy of the source. No git ref
+// is returned if there is no baseline to compare against.
+func (s *Server) getSourceBaseline(source, configType, plannerVersion, ref string) (baselineRef, baselineSource string, err error) {
+	strategy := s.baselineStrategyFor(source)
+	switch strategy.name {
+	case baselineLatestCron:
+		baselineSource = exec.SourceCron
+	case baselineTag:
+		baselineSource = exec.SourceTag + strategy.argument
+	case baselineGolden:
+		return strategy.argument, exec.SourceGolden, nil
+	default:
+		baselineRef, err = s.getPreviousFromSameSource(source, configType, plannerVersion, ref)
+		return baselineRef, source, err
+	}
+
+	_, baselineRef, err = exec.GetLatestFinishedExecutionFromSource(s.readDB(), baselineSource, configType, plannerVersion)
+	if err != nil {
+		return "", "", err
+	}
+	if baselineRef == "" {
+		s.notifyNoBaseline(executionIdentifier{GitRef: ref, Source: source, BenchmarkType: configType, PlannerVersion: plannerVersion})
+	}
+	if baselineRef == ref && baselineSource == source {
+		// the execution cannot be its own baseline
+		return "", "", nil
+	}
+	return baselineRef, baselineSource, nil
+}
+
+// baselineNotifications records the benchmarks for which Slack was notified that
+// they have no baseline, or no recent baseline, so that they are only notified once.
+type baselineNotifications struct {
+	mu       sync.Mutex
+	notified map[string]bool
+}
+
+// first returns true the first time it is called with key.
+func (bn *baselineNotifications) first(key string) bool {
+	bn.mu.Lock()
+	defer bn.mu.Unlock()
+	if bn.notified == nil {
+		bn.notified = map[string]bool{}
+	}
+	if bn.notified[key] {
+		return false
+	}
+	bn.notified[key] = true
+	return true
+}
+
+// notifyNoBaseline notifies Slack, once per source, benchmark type and planner version,
+// that the execution of the given identifier has no previous execution to be compared
+// against. Unlike the absence of a regression, this means its results are accumulating
+// to form a baseline but are not compared yet.
+func (s *Server) notifyNoBaseline(identifier executionIdentifier) {
+	if !s.notifyNoBaselines {
+		return
+	}
+	if !s.baselineNotifications.first(identifier.Source + "/" + identifier.BenchmarkType + "/" + identifier.PlannerVersion) {
+		return
+	}
+	msg := slack.TextMessage{Content: formatNoBaseline(identifier)}
+	if err := msg.Send(s.slackConfig); err != nil {
+		slog.Error(err)
+	}
+}
+
+// notifyNoRecentBaseline notifies Slack that the execution of the given identifier is not compared
+// against staleGitRef as it is older than maxBaselineAge. The baseline of a source is looked up on
+// every cron tick, Slack is thus only notified once per source, benchmark type, planner version
+// and stale baseline. It returns true if Slack was notified.
+func (s *Server) notifyNoRecentBaseline(identifier executionIdentifier, staleGitRef string) bool {
+	key := "stale/" + identifier.Source + "/" + identifier.BenchmarkType + "/" + identifier.PlannerVersion + "/" + staleGitRef
+	if !s.baselineNotifications.first(key) {
+		return false
+	}
+	msg := slack.TextMessage{Content: formatNoRecentBaseline(identifier, staleGitRef, s.maxBaselineAge)}
+	if err := msg.Send(s.slackConfig); err != nil {
+		slog.Error(err)
+	}
+	return true
+}
+
+// formatNoBaseline formats the Slack message notifying that the benchmark of
+// the given identifier has no baseline yet.
+func formatNoBaseline(identifier executionIdentifier) string {
+	content := fmt.Sprintf("*No baseline yet.*\nThe %s benchmark from source %s has no previous execution, <https://github.com/vitessio/vitess/commit/%s|%s> "+
+		"is not compared against anything: its results are used as the baseline of the next executions. This is not a regression check.\n",
+		identifier.BenchmarkType, identifier.Source, identifier.GitRef, git.ShortenSHAN(identifier.GitRef, notificationShortSHALength))
+	if identifier.PlannerVersion != "" {
+		content += fmt.Sprintf("Query planner: %s\n", identifier.PlannerVersion)
+	}
+	return content
+}
+
+// formatNoRecentBaseline formats the Slack message notifying that ref is not compared
+// against staleGitRef, the previous execution of its source, as it is older than maxAge.
+func formatNoRecentBaseline(identifier executionIdentifier, staleGitRef string, maxAge time.Duration) string {
+	content := fmt.Sprintf("*No recent baseline.*\nThe %s benchmark of <https://github.com/vitessio/vitess/commit/%s|%s> from source %s is not compared against "+
+		"the previous execution of the same source (<https://github.com/vitessio/vitess/commit/%s|%s>), as it is older than %s.\n",
+		identifier.BenchmarkType, identifier.GitRef, git.ShortenSHAN(identifier.GitRef, notificationShortSHALength), identifier.Source,
+		staleGitRef, git.ShortenSHAN(staleGitRef, notificationShortSHALength), maxAge)
+	if identifier.PlannerVersion != "" {
+		content += fmt.Sprintf("Query planner: %s\n", identifier.PlannerVersion)
+	}
+	return content
+}
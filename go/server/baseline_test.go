@@ -0,0 +1,158 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/vitessio/arewefastyet/go/exec"
+	"go.uber.org/zap"
+)
+
+func TestFormatNoRecentBaseline(t *testing.T) {
+	testcases := []struct {
+		name       string
+		identifier executionIdentifier
+		out        string
+	}{
+		{
+			name:       "Microbenchmark",
+			identifier: executionIdentifier{GitRef: "4a70d3d226113282554b393a97f893d133486b94", Source: "cron", BenchmarkType: "micro"},
+			out: "*No recent baseline.*\nThe micro benchmark of <https://github.com/vitessio/vitess/commit/4a70d3d226113282554b393a97f893d133486b94|4a70d3d226> from source cron is not compared against " +
+				"the previous execution of the same source (<https://github.com/vitessio/vitess/commit/1111111111aaaaaaaaaa|1111111111>), as it is older than 168h0m0s.\n",
+		},
+		{
+			name:       "Macrobenchmark",
+			identifier: executionIdentifier{GitRef: "4a70d3d226113282554b393a97f893d133486b94", Source: "cron", BenchmarkType: "oltp", PlannerVersion: "V3"},
+			out: "*No recent baseline.*\nThe oltp benchmark of <https://github.com/vitessio/vitess/commit/4a70d3d226113282554b393a97f893d133486b94|4a70d3d226> from source cron is not compared against " +
+				"the previous execution of the same source (<https://github.com/vitessio/vitess/commit/1111111111aaaaaaaaaa|1111111111>), as it is older than 168h0m0s.\n" +
+				"Query planner: V3\n",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			qt.Assert(t, formatNoRecentBaseline(tc.identifier, "1111111111aaaaaaaaaa", 7*24*time.Hour), qt.Equals, tc.out)
+		})
+	}
+}
+
+func TestFormatNoBaseline(t *testing.T) {
+	identifier := executionIdentifier{GitRef: "4a70d3d226113282554b393a97f893d133486b94", Source: "cron", BenchmarkType: "oltp", PlannerVersion: "V3"}
+	qt.Assert(t, formatNoBaseline(identifier), qt.Equals, "*No baseline yet.*\nThe oltp benchmark from source cron has no previous execution, "+
+		"<https://github.com/vitessio/vitess/commit/4a70d3d226113282554b393a97f893d133486b94|4a70d3d226> is not compared against anything: "+
+		"its results are used as the baseline of the next executions. This is not a regression check.\nQuery planner: V3\n")
+}
+
+func TestBaselineNotificationsFirst(t *testing.T) {
+	var bn baselineNotifications
+	qt.Assert(t, bn.first("cron/oltp/V3"), qt.IsTrue)
+	qt.Assert(t, bn.first("cron/oltp/V3"), qt.IsFalse)
+	qt.Assert(t, bn.first("cron/oltp/Gen4"), qt.IsTrue)
+}
+
+func TestServer_notifyNoRecentBaseline(t *testing.T) {
+	c := qt.New(t)
+	previous := slog
+	SetSLogger(zap.NewNop().Sugar())
+	c.Cleanup(func() { SetSLogger(previous) })
+
+	s := &Server{maxBaselineAge: 7 * 24 * time.Hour}
+	identifier := executionIdentifier{GitRef: "a", Source: "cron", BenchmarkType: "oltp", PlannerVersion: "V3"}
+	c.Assert(s.notifyNoRecentBaseline(identifier, "stale"), qt.IsTrue)
+
+	// the next ticks find the same stale baseline, even for another git ref
+	c.Assert(s.notifyNoRecentBaseline(identifier, "stale"), qt.IsFalse)
+	identifier.GitRef = "b"
+	c.Assert(s.notifyNoRecentBaseline(identifier, "stale"), qt.IsFalse)
+
+	// another stale baseline, or another benchmark, is notified
+	c.Assert(s.notifyNoRecentBaseline(identifier, "newer"), qt.IsTrue)
+	identifier.BenchmarkType = "tpcc"
+	c.Assert(s.notifyNoRecentBaseline(identifier, "stale"), qt.IsTrue)
+}
+
+func TestParseBaselineStrategy(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    baselineStrategy
+		wantErr string
+	}{
+		{value: "previous-same-source", want: baselineStrategy{name: baselinePreviousSameSource}},
+		{value: "latest-cron", want: baselineStrategy{name: baselineLatestCron}},
+		{value: "tag:v14.0.0", want: baselineStrategy{name: baselineTag, argument: "v14.0.0"}},
+		{value: "golden:4a70d3d226113282554b393a97f893d133486b94", want: baselineStrategy{name: baselineGolden, argument: "4a70d3d226113282554b393a97f893d133486b94"}},
+		{value: "tag:", wantErr: `invalid baseline strategy "tag:": missing the tag`},
+		{value: "golden:", wantErr: `invalid baseline strategy "golden:": missing the git ref`},
+		{value: "latest", wantErr: `invalid baseline strategy "latest": expected previous-same-source, latest-cron, tag:<tag> or golden:<git ref>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			c := qt.New(t)
+			got, err := parseBaselineStrategy(tt.value)
+			if tt.wantErr != "" {
+				c.Assert(err, qt.ErrorMatches, tt.wantErr)
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			c.Assert(got, qt.Equals, tt.want)
+		})
+	}
+}
+
+func TestServer_baselineStrategyFor(t *testing.T) {
+	s := &Server{sourceBaselines: map[string]string{
+		"cron":              "latest-cron",
+		"cron_*":            "tag:v14.0.0",
+		"cron_release-15.0": "golden:abc",
+		"cron_release-1*":   "previous-same-source",
+	}}
+	tests := []struct {
+		source string
+		want   baselineStrategy
+	}{
+		{source: "cron", want: baselineStrategy{name: baselineLatestCron}},
+		{source: "cron_release-15.0", want: baselineStrategy{name: baselineGolden, argument: "abc"}},
+		{source: "cron_release-14.0", want: baselineStrategy{name: baselinePreviousSameSource}},
+		{source: "cron_release-9.0", want: baselineStrategy{name: baselineTag, argument: "v14.0.0"}},
+		{source: "merge", want: baselineStrategy{name: baselinePreviousSameSource}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			qt.Assert(t, s.baselineStrategyFor(tt.source), qt.Equals, tt.want)
+		})
+	}
+}
+
+func TestServer_getSourceBaselineGolden(t *testing.T) {
+	c := qt.New(t)
+	s := &Server{sourceBaselines: map[string]string{"cron": "golden:abc"}}
+
+	gitRef, source, err := s.getSourceBaseline("cron", "oltp", "V3", "def")
+	c.Assert(err, qt.IsNil)
+	c.Assert(gitRef, qt.Equals, "abc")
+	c.Assert(source, qt.Equals, exec.SourceGolden)
+}
+
+func TestServer_getSourceBaselinesInvalid(t *testing.T) {
+	s := &Server{sourceBaselines: map[string]string{"cron": "latest"}}
+	_, err := s.getSourceBaselines()
+	qt.Assert(t, err, qt.ErrorMatches, `invalid baseline of source cron: invalid baseline strategy "latest": .*`)
+}
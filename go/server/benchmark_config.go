@@ -0,0 +1,68 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+
+	"github.com/spf13/viper"
+	"github.com/vitessio/arewefastyet/go/exec"
+)
+
+// benchmarkConfigTypeKey is the configuration key giving the type of a benchmark definition.
+const benchmarkConfigTypeKey = "exec-type"
+
+// loadBenchmarkConfigDir reads the benchmark definitions of dir and returns the path of
+// each definition keyed by the type of benchmark it executes. A definition is either a
+// configuration file or a sub-directory of configuration files merged together, its type
+// is given by its exec-type key. Two definitions cannot execute the same type of benchmark.
+func loadBenchmarkConfigDir(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || exec.IsConfigFile(entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	configs := map[string]string{}
+	for _, name := range names {
+		definition := path.Join(dir, name)
+		v := viper.New()
+		if err := exec.ReadConfig(v, definition); err != nil {
+			return nil, fmt.Errorf("invalid benchmark definition %s: %w", definition, err)
+		}
+		benchmarkType := v.GetString(benchmarkConfigTypeKey)
+		if benchmarkType == "" {
+			return nil, fmt.Errorf("benchmark definition %s has no %s", definition, benchmarkConfigTypeKey)
+		}
+		if previous, ok := configs[benchmarkType]; ok {
+			return nil, fmt.Errorf("benchmark definitions %s and %s both execute %s", previous, definition, benchmarkType)
+		}
+		configs[benchmarkType] = definition
+	}
+	return configs, nil
+}
@@ -0,0 +1,72 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestLoadBenchmarkConfigDir(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "one definition per file", files: map[string]string{"micro.yaml": "exec-type: micro\n", "oltp.yaml": "exec-type: oltp\n", "notes.txt": ""}, want: map[string]string{"micro": "micro.yaml", "oltp": "oltp.yaml"}},
+		{name: "definition in a sub-directory", files: map[string]string{"tpcc/base.yaml": "exec-type: tpcc\n", "tpcc/mysql.yaml": "exec-mysql-config: {}\n"}, want: map[string]string{"tpcc": "tpcc"}},
+		{name: "missing type", files: map[string]string{"micro.yaml": "exec-go-version: \"1.17\"\n"}, wantErr: true},
+		{name: "duplicated type", files: map[string]string{"a.yaml": "exec-type: oltp\n", "b.yaml": "exec-type: oltp\n"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				c.Assert(os.MkdirAll(path.Dir(path.Join(dir, name)), 0755), qt.IsNil)
+				c.Assert(os.WriteFile(path.Join(dir, name), []byte(content), 0644), qt.IsNil)
+			}
+
+			got, err := loadBenchmarkConfigDir(dir)
+			if tt.wantErr {
+				c.Assert(err, qt.IsNotNil)
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			want := map[string]string{}
+			for benchmarkType, name := range tt.want {
+				want[benchmarkType] = path.Join(dir, name)
+			}
+			c.Assert(got, qt.DeepEquals, want)
+		})
+	}
+}
+
+func TestServer_getConfigFiles(t *testing.T) {
+	s := &Server{
+		microbenchConfigPath:     "micro.yaml",
+		macrobenchConfigPathOLTP: "oltp.yaml",
+		benchmarkConfigs:         map[string]string{"oltp": "defs/oltp.yaml", "tpcc": "defs/tpcc.yaml"},
+	}
+	qt.Assert(t, s.getConfigFiles(), qt.DeepEquals, map[string]string{"micro": "micro.yaml", "oltp": "defs/oltp.yaml", "tpcc": "defs/tpcc.yaml"})
+}
@@ -0,0 +1,167 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vitessio/arewefastyet/go/exec"
+	"github.com/vitessio/arewefastyet/go/slack"
+	"github.com/vitessio/arewefastyet/go/tools/git"
+)
+
+// bisection is a binary search of the commit that introduced a regression between
+// a good and a bad git ref. commits are the first-parent commits after the good git ref, from the
+// oldest to the newest, the last one being the bad git ref.
+type bisection struct {
+	good    string
+	commits []string
+
+	// low is the index of the newest commit known to be good, -1 being the good git ref.
+	// high is the index of the oldest commit known to be bad.
+	low, high int
+}
+
+func newBisection(good string, commits []string) *bisection {
+	return &bisection{
+		good:    good,
+		commits: commits,
+		low:     -1,
+		high:    len(commits) - 1,
+	}
+}
+
+// next returns the next commit to benchmark. It returns false once the culprit is found.
+func (b *bisection) next() (string, bool) {
+	if b.high-b.low <= 1 {
+		return "", false
+	}
+	return b.commits[(b.low+b.high)/2], true
+}
+
+// record records whether the commit returned by next regressed compared to lastGood.
+func (b *bisection) record(regressed bool) {
+	mid := (b.low + b.high) / 2
+	if regressed {
+		b.high = mid
+	} else {
+		b.low = mid
+	}
+}
+
+// lastGood returns the newest git ref known to be good.
+func (b *bisection) lastGood() string {
+	if b.low < 0 {
+		return b.good
+	}
+	return b.commits[b.low]
+}
+
+// culprit returns the oldest commit known to be bad, which is the
+// commit that introduced the regression once next returns false.
+func (b *bisection) culprit() string {
+	return b.commits[b.high]
+}
+
+// bisectRegression benchmarks the commits between the good and bad git refs to find
+// the commit that introduced a regression, and notifies Slack of the culprit, or of the abort
+// of the bisection. Only the first-parent commits are benchmarked, which are the merges on
+// the bad git ref's branch. The executions are enqueued with a low priority and are only used
+// as baselines.
+func (s *Server) bisectRegression(config string, identifier executionIdentifier, good, bad string) {
+	s.vitessPathMu.Lock()
+	out, err := git.ExecCmd(s.getVitessPath(), "git", "rev-list", "--first-parent", "--reverse", good+".."+bad)
+	s.vitessPathMu.Unlock()
+	if err != nil {
+		slog.Errorf("Could not bisect the regression of %+v: %v", identifier, err)
+		return
+	}
+	b := newBisection(good, strings.Fields(string(out)))
+	if len(b.commits) == 0 {
+		return
+	}
+
+	slog.Infof("Bisecting the regression of %+v over %d commits", identifier, len(b.commits))
+	for {
+		ref, ok := b.next()
+		if !ok {
+			break
+		}
+		element := s.createSimpleExecutionQueueElement(exec.SourceBisect, config, ref, identifier.BenchmarkType, identifier.PlannerVersion, false, 0)
+		element.baselineOnly = true
+		element.lowPriority = true
+		s.addToQueue(element)
+		err = s.waitForExecution(context.Background(), element.identifier)
+		if err != nil {
+			s.abortBisection(identifier, good, bad, fmt.Errorf("the execution of %s failed: %w", ref, err))
+			return
+		}
+
+		report, err := s.getComparisonReport(ref, b.lastGood(), identifier.PlannerVersion, identifier.PlannerVersion, identifier.BenchmarkType)
+		if err != nil {
+			s.abortBisection(identifier, good, bad, fmt.Errorf("could not compare %s: %w", ref, err))
+			return
+		}
+		b.record(report.Regression != "")
+	}
+
+	culprit := b.culprit()
+	slog.Infof("Bisected the regression of %+v to %s", identifier, culprit)
+	msg := slack.TextMessage{Content: formatBisectionResult(identifier, good, bad, culprit)}
+	if err := msg.Send(s.slackConfig); err != nil {
+		slog.Error(err)
+	}
+}
+
+// abortBisection logs and notifies Slack that the bisection of the regression between
+// the good and bad git refs was aborted because of err.
+func (s *Server) abortBisection(identifier executionIdentifier, good, bad string, err error) {
+	slog.Errorf("Could not bisect the regression of %+v: %v", identifier, err)
+	msg := slack.TextMessage{Content: formatBisectionAborted(identifier, good, bad, err)}
+	if err := msg.Send(s.slackConfig); err != nil {
+		slog.Error(err)
+	}
+}
+
+// formatBisectionResult formats the Slack message reporting the culprit of a regression.
+func formatBisectionResult(identifier executionIdentifier, good, bad, culprit string) string {
+	content := fmt.Sprintf("*Bisected a regression.*\nThe %s regression between %s and %s was introduced by %s.\n",
+		identifier.BenchmarkType, bisectionCommitLink(good), bisectionCommitLink(bad), bisectionCommitLink(culprit))
+	if identifier.PlannerVersion != "" {
+		content += fmt.Sprintf("Query planner: %s\n", identifier.PlannerVersion)
+	}
+	return content
+}
+
+// formatBisectionAborted formats the Slack message reporting that the bisection of a regression was aborted.
+func formatBisectionAborted(identifier executionIdentifier, good, bad string, err error) string {
+	content := fmt.Sprintf("*Aborted the bisection of a regression.*\nThe %s regression between %s and %s could not be bisected: %v\n",
+		identifier.BenchmarkType, bisectionCommitLink(good), bisectionCommitLink(bad), err)
+	if identifier.PlannerVersion != "" {
+		content += fmt.Sprintf("Query planner: %s\n", identifier.PlannerVersion)
+	}
+	return content
+}
+
+// bisectionCommitLink formats a Slack link to the given commit.
+func bisectionCommitLink(ref string) string {
+	return fmt.Sprintf("<https://github.com/vitessio/vitess/commit/%s|%s>", ref, git.ShortenSHAN(ref, notificationShortSHALength))
+}
@@ -0,0 +1,88 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestBisection(t *testing.T) {
+	commits := []string{"c1", "c2", "c3", "c4", "c5", "c6"}
+	tests := []struct {
+		name    string
+		culprit string
+	}{
+		{name: "First commit", culprit: "c1"},
+		{name: "Middle commit", culprit: "c4"},
+		{name: "Last commit", culprit: "c6"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			culpritIndex := -1
+			for i, commit := range commits {
+				if commit == tt.culprit {
+					culpritIndex = i
+				}
+			}
+
+			b := newBisection("good", commits)
+			steps := 0
+			for {
+				ref, ok := b.next()
+				if !ok {
+					break
+				}
+				steps++
+				var refIndex int
+				for i, commit := range commits {
+					if commit == ref {
+						refIndex = i
+					}
+				}
+				b.record(refIndex >= culpritIndex)
+			}
+			c.Assert(b.culprit(), qt.Equals, tt.culprit)
+			c.Assert(steps <= 3, qt.IsTrue)
+		})
+	}
+}
+
+func TestBisection_SingleCommit(t *testing.T) {
+	c := qt.New(t)
+	b := newBisection("good", []string{"bad"})
+	_, ok := b.next()
+	c.Assert(ok, qt.IsFalse)
+	c.Assert(b.culprit(), qt.Equals, "bad")
+	c.Assert(b.lastGood(), qt.Equals, "good")
+}
+
+func TestFormatBisectionAborted(t *testing.T) {
+	c := qt.New(t)
+	identifier := executionIdentifier{BenchmarkType: "oltp", PlannerVersion: "Gen4"}
+	content := formatBisectionAborted(identifier, "aaaaaaaaaaaa", "bbbbbbbbbbbb", errors.New("the execution of cccccccc failed"))
+	c.Assert(strings.HasPrefix(content, "*Aborted the bisection of a regression.*\n"), qt.IsTrue)
+	c.Assert(strings.Contains(content, "the execution of cccccccc failed"), qt.IsTrue)
+	c.Assert(strings.Contains(content, "https://github.com/vitessio/vitess/commit/aaaaaaaaaaaa|"), qt.IsTrue)
+	c.Assert(strings.Contains(content, "Query planner: Gen4"), qt.IsTrue)
+}
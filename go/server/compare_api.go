@@ -0,0 +1,327 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vitessio/arewefastyet/go/exec"
+	"github.com/vitessio/arewefastyet/go/tools/genericbench"
+	"github.com/vitessio/arewefastyet/go/tools/macrobench"
+	"github.com/vitessio/arewefastyet/go/tools/microbench"
+)
+
+// compareAPIHandler compares the git refs given in the "r" (reference) and "c" (compare)
+// query parameters for the benchmark "type" and returns the comparison as JSON, or as
+// Markdown if the "format" query parameter is set to "markdown". The "planner" query
+// parameter is used by macrobenchmarks and defaults to the V3 planner. The optional "component"
+// query parameter restricts the comparison to the executions that focused on a vitess component.
+// Generic benchmarks also compare the partial results of running executions if the "partial"
+// query parameter is set to "true".
+func (s *Server) compareAPIHandler(c *gin.Context) {
+	reference := c.Query("r")
+	compare := c.Query("c")
+	benchmarkType := c.Query("type")
+	component := c.Query("component")
+	planner := macrobench.PlannerVersion(c.DefaultQuery("planner", string(macrobench.V3Planner)))
+	if reference == "" || compare == "" || benchmarkType == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "the r, c and type query parameters are required"})
+		return
+	}
+
+	var result comparisonResult
+	var err error
+	if benchmarkType == "generic" && c.Query("partial") == "true" {
+		result, err = genericbench.CompareWithPartial(s.readDB(), reference, compare, true)
+	} else {
+		result, err = s.compareGitRefs(reference, compare, benchmarkType, planner, component)
+	}
+	if err != nil {
+		c.JSON(comparisonErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.Query("format") == "markdown" {
+		c.String(http.StatusOK, result.Markdown())
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// compareSourcesAPIHandler compares the latest finished executions of the sources given in
+// the "left" and "right" query parameters for the benchmark "type". Along with the comparison,
+// the git refs of both executions are returned. The "planner" and "format" query parameters
+// work like in compareAPIHandler.
+func (s *Server) compareSourcesAPIHandler(c *gin.Context) {
+	leftSource := c.Query("left")
+	rightSource := c.Query("right")
+	benchmarkType := c.Query("type")
+	planner := macrobench.PlannerVersion(c.DefaultQuery("planner", string(macrobench.V3Planner)))
+	if leftSource == "" || rightSource == "" || benchmarkType == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "the left, right and type query parameters are required"})
+		return
+	}
+
+	result, leftRef, rightRef, err := s.compareLatestOfSources(leftSource, rightSource, benchmarkType, planner)
+	if err != nil {
+		c.JSON(comparisonErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.Query("format") == "markdown" {
+		c.String(http.StatusOK, result.Markdown())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"left_git_ref":  leftRef,
+		"right_git_ref": rightRef,
+		"comparison":    result,
+	})
+}
+
+// comparisonResult is the result of the comparison of two git refs, either a
+// macrobench.ComparisonArray, a microbench.ComparisonArray or a genericbench.ComparisonArray.
+type comparisonResult interface {
+	Markdown() string
+}
+
+var (
+	errUnknownBenchmarkType = errors.New("unknown benchmark type")
+	errNoFinishedExecution  = errors.New("no finished execution")
+)
+
+// comparisonErrorStatus returns the HTTP status code matching an error returned
+// by compareGitRefs or compareLatestOfSources.
+func comparisonErrorStatus(err error) int {
+	if errors.Is(err, errUnknownBenchmarkType) || errors.Is(err, errNoFinishedExecution) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// compareGitRefs compares the git refs reference and compare for the given benchmark type.
+// The planner is only used by macrobenchmarks. All results are used if component is empty.
+func (s *Server) compareGitRefs(reference, compare, benchmarkType string, planner macrobench.PlannerVersion, component string) (comparisonResult, error) {
+	if benchmarkType == "micro" {
+		return microbench.CompareForComponent(s.readDB(), reference, compare, component)
+	}
+	if benchmarkType == "generic" {
+		return genericbench.Compare(s.readDB(), reference, compare)
+	}
+	macros, err := macrobench.CompareMacroBenchmarksForComponent(s.readDB(), reference, compare, planner, component)
+	if err != nil {
+		return nil, err
+	}
+	macro, ok := macros[macrobench.Type(benchmarkType)]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", errUnknownBenchmarkType, benchmarkType)
+	}
+	return macro.(macrobench.ComparisonArray), nil
+}
+
+// compareLatestOfSources resolves the latest finished execution of leftSource and rightSource
+// for the given benchmark type and compares them. The comparison is returned along with
+// the git refs of both executions.
+func (s *Server) compareLatestOfSources(leftSource, rightSource, benchmarkType string, planner macrobench.PlannerVersion) (result comparisonResult, leftRef, rightRef string, err error) {
+	plannerVersion := string(planner)
+	if benchmarkType == "micro" || benchmarkType == "generic" {
+		plannerVersion = ""
+	}
+	_, leftRef, err = exec.GetLatestFinishedExecutionFromSource(s.readDB(), leftSource, benchmarkType, plannerVersion)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if leftRef == "" {
+		return nil, "", "", fmt.Errorf("%w for source %s", errNoFinishedExecution, leftSource)
+	}
+	_, rightRef, err = exec.GetLatestFinishedExecutionFromSource(s.readDB(), rightSource, benchmarkType, plannerVersion)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if rightRef == "" {
+		return nil, "", "", fmt.Errorf("%w for source %s", errNoFinishedExecution, rightSource)
+	}
+	result, err = s.compareGitRefs(leftRef, rightRef, benchmarkType, planner, "")
+	if err != nil {
+		return nil, "", "", err
+	}
+	return result, leftRef, rightRef, nil
+}
+
+// compareProvidersAPIHandler compares the latest finished executions of the git ref "ref" for the
+// benchmark "type" that ran on the providers given in the "left" and "right" query parameters.
+// The provider is the only dimension that differs between both sides of the comparison. The UUIDs
+// of both executions are returned along with the comparison. The "format" query parameter works
+// like in compareAPIHandler.
+func (s *Server) compareProvidersAPIHandler(c *gin.Context) {
+	gitRef := c.Query("ref")
+	leftProvider := c.Query("left")
+	rightProvider := c.Query("right")
+	benchmarkType := c.Query("type")
+	if gitRef == "" || leftProvider == "" || rightProvider == "" || benchmarkType == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "the ref, left, right and type query parameters are required"})
+		return
+	}
+
+	result, leftUUID, rightUUID, err := s.compareProviders(gitRef, leftProvider, rightProvider, benchmarkType)
+	if err != nil {
+		c.JSON(comparisonErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.Query("format") == "markdown" {
+		c.String(http.StatusOK, result.Markdown())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"dimension":       "provider",
+		"git_ref":         gitRef,
+		"left_exec_uuid":  leftUUID,
+		"right_exec_uuid": rightUUID,
+		"comparison":      result,
+	})
+}
+
+// compareProviders resolves the latest finished execution of gitRef on leftProvider and
+// rightProvider for the given benchmark type and compares them. The comparison is returned
+// along with the UUIDs of both executions.
+func (s *Server) compareProviders(gitRef, leftProvider, rightProvider, benchmarkType string) (result comparisonResult, leftUUID, rightUUID string, err error) {
+	leftUUID, err = exec.GetLatestFinishedExecutionForProvider(s.readDB(), gitRef, benchmarkType, leftProvider)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if leftUUID == "" {
+		return nil, "", "", fmt.Errorf("%w for provider %s", errNoFinishedExecution, leftProvider)
+	}
+	rightUUID, err = exec.GetLatestFinishedExecutionForProvider(s.readDB(), gitRef, benchmarkType, rightProvider)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if rightUUID == "" {
+		return nil, "", "", fmt.Errorf("%w for provider %s", errNoFinishedExecution, rightProvider)
+	}
+	result, err = s.compareExecutions(leftUUID, rightUUID, benchmarkType)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return result, leftUUID, rightUUID, nil
+}
+
+// compareExecutions compares the results of the executions reference and compare
+// for the given benchmark type.
+func (s *Server) compareExecutions(reference, compare, benchmarkType string) (comparisonResult, error) {
+	if benchmarkType == "micro" {
+		return microbench.CompareExecutions(s.readDB(), reference, compare)
+	}
+	if benchmarkType == "generic" {
+		return genericbench.CompareExecutions(s.readDB(), reference, compare)
+	}
+	for _, mtype := range macrobench.Types {
+		if mtype == macrobench.Type(benchmarkType) {
+			return macrobench.CompareExecutions(s.readDB(), mtype, reference, compare)
+		}
+	}
+	return nil, fmt.Errorf("%w %s", errUnknownBenchmarkType, benchmarkType)
+}
+
+// compareAllAPIHandler compares the git refs given in the "ref_a" (reference) and "ref_b"
+// (compare) query parameters for the microbenchmarks and all the macrobenchmark types, and
+// returns the comparisons as JSON keyed by benchmark type. Benchmark types without results
+// for either git ref are omitted. The "planner" query parameter works like in compareAPIHandler.
+func (s *Server) compareAllAPIHandler(c *gin.Context) {
+	reference := c.Query("ref_a")
+	compare := c.Query("ref_b")
+	planner := macrobench.PlannerVersion(c.DefaultQuery("planner", string(macrobench.V3Planner)))
+	if reference == "" || compare == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "the ref_a and ref_b query parameters are required"})
+		return
+	}
+
+	micro, err := microbench.Compare(s.readDB(), reference, compare)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	macros, err := macrobench.CompareMacroBenchmarks(s.readDB(), reference, compare, planner)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	results := combineComparisons(micro, macros)
+	if len(results) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no results for the given git refs"})
+		return
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// combineComparisons merges the microbenchmark and macrobenchmark comparisons
+// in a single map keyed by benchmark type, leaving out empty comparisons.
+func combineComparisons(micro microbench.ComparisonArray, macros map[macrobench.Type]interface{}) map[string]comparisonResult {
+	results := map[string]comparisonResult{}
+	if len(micro) > 0 {
+		results["micro"] = micro
+	}
+	for mtype, macro := range macros {
+		comparisons, ok := macro.(macrobench.ComparisonArray)
+		if ok && len(comparisons) > 0 {
+			results[mtype.String()] = comparisons
+		}
+	}
+	return results
+}
+
+// histogramAPIHandler returns the latency histogram of the macro benchmarks of type "type"
+// for the git ref "r". If the git ref "c" is also given, its histogram is returned as well,
+// along with the Wasserstein distance between both distributions.
+func (s *Server) histogramAPIHandler(c *gin.Context) {
+	reference := c.Query("r")
+	compare := c.Query("c")
+	macroType := macrobench.Type(c.Query("type"))
+	planner := macrobench.PlannerVersion(c.DefaultQuery("planner", string(macrobench.V3Planner)))
+	if reference == "" || macroType == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "the r and type query parameters are required"})
+		return
+	}
+
+	referenceHistogram, err := macrobench.GetHistogramForGitRefAndPlanner(macroType, reference, planner, s.readDB())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if compare == "" {
+		c.JSON(http.StatusOK, gin.H{"reference": referenceHistogram})
+		return
+	}
+
+	compareHistogram, err := macrobench.GetHistogramForGitRefAndPlanner(macroType, compare, planner, s.readDB())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"reference":            referenceHistogram,
+		"compare":              compareHistogram,
+		"wasserstein_distance": referenceHistogram.WassersteinDistance(compareHistogram),
+	})
+}
@@ -0,0 +1,93 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/vitessio/arewefastyet/go/tools/macrobench"
+	"github.com/vitessio/arewefastyet/go/tools/microbench"
+)
+
+func TestComparisonErrorStatus(t *testing.T) {
+	testcases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{name: "Unknown benchmark type", err: fmt.Errorf("%w %s", errUnknownBenchmarkType, "foo"), status: http.StatusBadRequest},
+		{name: "No finished execution", err: fmt.Errorf("%w for source %s", errNoFinishedExecution, "cron"), status: http.StatusBadRequest},
+		{name: "Other error", err: errors.New("connection refused"), status: http.StatusInternalServerError},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			qt.Assert(t, comparisonErrorStatus(tc.err), qt.Equals, tc.status)
+		})
+	}
+}
+
+func TestCombineComparisons(t *testing.T) {
+	micro := microbench.ComparisonArray{{}}
+	oltp := macrobench.ComparisonArray{{}}
+	testcases := []struct {
+		name   string
+		micro  microbench.ComparisonArray
+		macros map[macrobench.Type]interface{}
+		want   map[string]comparisonResult
+	}{
+		{
+			name:   "All types have results",
+			micro:  micro,
+			macros: map[macrobench.Type]interface{}{"oltp": oltp, "tpcc": oltp},
+			want:   map[string]comparisonResult{"micro": micro, "oltp": oltp, "tpcc": oltp},
+		},
+		{
+			name:   "Only some macrobenchmark types have results",
+			micro:  micro,
+			macros: map[macrobench.Type]interface{}{"oltp": oltp, "tpcc": macrobench.ComparisonArray(nil)},
+			want:   map[string]comparisonResult{"micro": micro, "oltp": oltp},
+		},
+		{
+			name:   "No microbenchmark results",
+			macros: map[macrobench.Type]interface{}{"oltp": oltp},
+			want:   map[string]comparisonResult{"oltp": oltp},
+		},
+		{
+			name:   "No results",
+			macros: map[macrobench.Type]interface{}{"oltp": macrobench.ComparisonArray(nil)},
+			want:   map[string]comparisonResult{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			qt.Assert(t, combineComparisons(tc.micro, tc.macros), qt.DeepEquals, tc.want)
+		})
+	}
+}
+
+func TestCompareExecutionsUnknownBenchmarkType(t *testing.T) {
+	s := &Server{}
+	_, err := s.compareExecutions("reference-uuid", "compare-uuid", "foo")
+	qt.Assert(t, errors.Is(err, errUnknownBenchmarkType), qt.IsTrue)
+	qt.Assert(t, comparisonErrorStatus(err), qt.Equals, http.StatusBadRequest)
+}
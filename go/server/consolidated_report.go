@@ -0,0 +1,169 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vitessio/arewefastyet/go/slack"
+	"github.com/vitessio/arewefastyet/go/tools/git"
+)
+
+// baselineReport is the result of the comparison of an element against one of its baselines.
+type baselineReport struct {
+	baseline executionIdentifier
+	report   ComparisonReport
+	warnings []string
+
+	// skipped is the reason why the element was not compared against the baseline, if any.
+	skipped string
+}
+
+// status summarizes the baselineReport in a few words.
+func (br baselineReport) status() string {
+	switch {
+	case br.skipped != "":
+		return "skipped"
+	case br.report.Regression != "":
+		return "regression"
+	case br.report.Improvement != "":
+		return "improvement"
+	default:
+		return "no change"
+	}
+}
+
+// sendConsolidatedReport notifies Slack of the comparisons of the element against all its
+// baselines in a single message. The message is sent if any baseline observed a regression,
+// or an improvement if their notification is enabled, or regardless of the results if the
+// element must always be notified.
+func (s *Server) sendConsolidatedReport(element *executionQueueElement, labels map[string]string, reports []baselineReport) error {
+	notify := element.notifyAlways
+	if element.identifier.PullNb == 0 && !element.notifyAlways {
+		now := time.Now()
+		for i, br := range reports {
+			reports[i].report.Regression = s.regressionHoldDown.filter(regressionHoldDownKey(element.identifier, br.baseline.GitRef), br.report.Regression, now)
+		}
+	}
+	for _, br := range reports {
+		if br.report.Regression != "" || (s.notifyImprovements && br.report.Improvement != "") {
+			notify = true
+		}
+	}
+	if !notify {
+		return nil
+	}
+	msg := slack.TextMessage{Content: formatConsolidatedReport(element.identifier, labels, reports)}
+	return msg.Send(s.slackConfig)
+}
+
+// formatConsolidatedReport formats the comparisons of an element against all its baselines, with a
+// table summarizing the result of each baseline in its own column, followed by the details of the changes.
+func formatConsolidatedReport(identifier executionIdentifier, labels map[string]string, reports []baselineReport) string {
+	var b strings.Builder
+	for _, br := range reports {
+		if br.report.Regression != "" {
+			b.WriteString("*Observed a regression.*\n")
+			break
+		}
+	}
+	fmt.Fprintf(&b, "Comparing %s <https://github.com/vitessio/vitess/commit/%s|%s> with %d baselines, with the %s benchmark",
+		identifier.Source, identifier.GitRef, git.ShortenSHAN(identifier.GitRef, notificationShortSHALength), len(reports), identifier.BenchmarkType)
+	if identifier.PlannerVersion != "" {
+		fmt.Fprintf(&b, " using the %s query planner", identifier.PlannerVersion)
+	}
+	b.WriteString("\n")
+	if identifier.PullNb > 0 {
+		fmt.Fprintf(&b, "Benchmarked PR #<https://github.com/vitessio/vitess/pull/%d>.\n", identifier.PullNb)
+	}
+	if len(labels) > 0 {
+		b.WriteString("Labels: " + formatLabels(labels) + "\n")
+	}
+
+	rows := [][]string{{""}, {"Git ref"}, {"Planner"}, {"Result"}}
+	for _, br := range reports {
+		rows[0] = append(rows[0], br.baseline.Source)
+		rows[1] = append(rows[1], git.ShortenSHAN(br.baseline.GitRef, notificationShortSHALength))
+		rows[2] = append(rows[2], br.baseline.PlannerVersion)
+		rows[3] = append(rows[3], br.status())
+	}
+	for _, br := range reports {
+		if br.report.Confidence != "" {
+			rows = append(rows, confidenceRow(reports))
+			break
+		}
+	}
+	b.WriteString("```\n" + formatTable(rows) + "```\n")
+
+	for _, br := range reports {
+		details := br.report.Regression + br.report.Improvement
+		if br.skipped != "" {
+			details = br.skipped + "\n"
+		}
+		if details == "" && len(br.warnings) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n*Against %s* (<%s|comparison>)\n", br.baseline.Source, getComparisonLink(identifier.GitRef, br.baseline.GitRef))
+		for _, warning := range br.warnings {
+			b.WriteString("Warning: " + warning + "\n")
+		}
+		b.WriteString(details)
+	}
+	return b.String()
+}
+
+// confidenceRow returns the row of the consolidated report table containing the
+// confidence of the comparison against each baseline.
+func confidenceRow(reports []baselineReport) []string {
+	row := []string{"Confidence"}
+	for _, br := range reports {
+		confidence := br.report.Confidence
+		if confidence == "" || br.skipped != "" {
+			confidence = "-"
+		}
+		row = append(row, confidence)
+	}
+	return row
+}
+
+// formatTable aligns the cells of the given rows in columns separated by a pipe.
+func formatTable(rows [][]string) string {
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+	var b strings.Builder
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = fmt.Sprintf("%-*s", widths[i], cell)
+		}
+		b.WriteString(strings.TrimRight(strings.Join(cells, " | "), " ") + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,78 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestFormatTable(t *testing.T) {
+	rows := [][]string{
+		{"", "cron", "cron_tags_v12.0.0"},
+		{"Result", "regression", "no change"},
+	}
+	want := "       | cron       | cron_tags_v12.0.0\n" +
+		"Result | regression | no change\n"
+	qt.Assert(t, formatTable(rows), qt.Equals, want)
+}
+
+func TestFormatConsolidatedReport(t *testing.T) {
+	identifier := executionIdentifier{GitRef: "4a70d3d226113282554b393a97f893d133486b94", Source: "cron", BenchmarkType: "oltp", PlannerVersion: "V3"}
+	reports := []baselineReport{
+		{
+			baseline: executionIdentifier{GitRef: "1111111111aaaaaaaaaa", Source: "cron", BenchmarkType: "oltp", PlannerVersion: "V3"},
+			report:   ComparisonReport{Regression: "- TPS decreased by 12%\n"},
+		},
+		{
+			baseline: executionIdentifier{GitRef: "2222222222bbbbbbbbbb", Source: "cron_tags_v12.0.0", BenchmarkType: "oltp", PlannerVersion: "V3"},
+			warnings: []string{"the executions used different MySQL configurations (default against small)"},
+		},
+		{
+			baseline: executionIdentifier{GitRef: "3333333333cccccccccc", Source: "cron_pr_base", BenchmarkType: "oltp", PlannerVersion: "V3"},
+			skipped:  "the executions ran with a different duration (300s against 600s)",
+		},
+	}
+	want := "*Observed a regression.*\n" +
+		"Comparing cron <https://github.com/vitessio/vitess/commit/4a70d3d226113282554b393a97f893d133486b94|4a70d3d226> with 3 baselines, with the oltp benchmark using the V3 query planner\n" +
+		"Labels: team=query\n" +
+		"```\n" +
+		"        | cron       | cron_tags_v12.0.0 | cron_pr_base\n" +
+		"Git ref | 1111111111 | 2222222222        | 3333333333\n" +
+		"Planner | V3         | V3                | V3\n" +
+		"Result  | regression | no change         | skipped\n" +
+		"```\n" +
+		"\n*Against cron* (<https://benchmark.vitess.io/compare?r=4a70d3d226113282554b393a97f893d133486b94&c=1111111111aaaaaaaaaa|comparison>)\n" +
+		"- TPS decreased by 12%\n" +
+		"\n*Against cron_tags_v12.0.0* (<https://benchmark.vitess.io/compare?r=4a70d3d226113282554b393a97f893d133486b94&c=2222222222bbbbbbbbbb|comparison>)\n" +
+		"Warning: the executions used different MySQL configurations (default against small)\n" +
+		"\n*Against cron_pr_base* (<https://benchmark.vitess.io/compare?r=4a70d3d226113282554b393a97f893d133486b94&c=3333333333cccccccccc|comparison>)\n" +
+		"the executions ran with a different duration (300s against 600s)\n"
+	qt.Assert(t, formatConsolidatedReport(identifier, map[string]string{"team": "query"}, reports), qt.Equals, want)
+}
+
+func TestConfidenceRow(t *testing.T) {
+	reports := []baselineReport{
+		{report: ComparisonReport{Confidence: "high"}},
+		{report: ComparisonReport{}},
+		{report: ComparisonReport{Confidence: "low"}, skipped: "the executions ran with a different duration (300s against 600s)"},
+	}
+	qt.Assert(t, confidenceRow(reports), qt.DeepEquals, []string{"Confidence", "high", "-", "-"})
+}
@@ -19,8 +19,12 @@
 package server
 
 import (
-	"sync"
+	"context"
+	"fmt"
 	"time"
+	// embedding the time zone database allows to use any IANA time zone,
+	// even on hosts that do not have it installed
+	_ "time/tzdata"
 
 	"github.com/robfig/cron/v3"
 )
@@ -29,9 +33,39 @@ type (
 	executionQueueElement struct {
 		config                  string
 		retry                   int
+		attempt                 int
 		identifier              executionIdentifier
 		compareWith             []executionIdentifier
 		notifyAlways, executing bool
+
+		// infraRequeues is the number of times the element was requeued because
+		// of the infrastructure, see Server.requeuesInfraFailure.
+		infraRequeues int
+
+		// baselineOnly elements are never compared against their compareWith
+		// elements, they only serve as a baseline for other elements.
+		baselineOnly bool
+
+		// lowPriority elements are only executed when no other element is waiting.
+		lowPriority bool
+
+		// noop elements benchmark a commit that only changed files that are not expected
+		// to impact performance, their execution is labeled with noopLabel.
+		noop bool
+
+		// batchID is the ID of the suiteBatch the element belongs to, if any. The elements
+		// of a batch are not notified individually but along with the rest of their batch.
+		batchID string
+
+		// seq is the order in which the element was added to the Queue, and startedAt
+		// the time at which it last started executing.
+		seq       uint64
+		startedAt time.Time
+
+		// cancel cancels the running execution of the element, if any, and canceled
+		// records that the element was canceled by Queue.CancelIf.
+		cancel   context.CancelFunc
+		canceled bool
 	}
 
 	executionIdentifier struct {
@@ -47,18 +81,33 @@ const (
 	maxConcurJob = 1
 )
 
-var (
-	currentCountExec int
-	mtx              sync.RWMutex
-	queue            executionQueue
-)
+// loadLocation returns the location of the configured IANA time zone, in which
+// the CRON schedules are interpreted and the dates are displayed.
+func (s *Server) loadLocation() (*time.Location, error) {
+	location, err := time.LoadLocation(s.timeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %w", s.timeZone, err)
+	}
+	return location, nil
+}
 
-func createIndividualCron(schedule string, jobs []func()) error {
+// formatDate formats the given timestamp in location, or in UTC if location is nil.
+func formatDate(t *time.Time, location *time.Location) string {
+	if t == nil {
+		return ""
+	}
+	if location == nil {
+		location = time.UTC
+	}
+	return t.In(location).Format(time.RFC822)
+}
+
+func createIndividualCron(schedule string, jobs []func(), location *time.Location) error {
 	if schedule == "" {
 		return nil
 	}
 
-	c := cron.New()
+	c := cron.New(cron.WithLocation(location))
 	for _, job := range jobs {
 		_, err := c.AddFunc(schedule, job)
 		if err != nil {
@@ -73,19 +122,23 @@ func (s *Server) createCrons() error {
 	if s.cronSchedule == "" {
 		return nil
 	}
-	queue = make(executionQueue)
-
 	err := createIndividualCron(s.cronSchedule, []func(){
 		s.branchCronHandler,
 		s.tagsCronHandler,
-	})
+	}, s.location)
 	if err != nil {
 		return err
 	}
-	err = createIndividualCron(s.cronSchedulePullRequests, []func(){s.pullRequestsCronHandler})
+	err = createIndividualCron(s.cronSchedulePullRequests, []func(){s.pullRequestsCronHandler}, s.location)
 	if err != nil {
 		return err
 	}
+	if s.stuckExecutionMaxDuration > 0 {
+		err = createIndividualCron(stuckExecutionsWatchdogSchedule, []func(){s.stuckExecutionsWatchdog}, s.location)
+		if err != nil {
+			return err
+		}
+	}
 
 	go s.cronExecutionQueueWatcher()
 	return nil
@@ -97,18 +150,14 @@ func (s *Server) getConfigFiles() map[string]string {
 		"oltp":  s.macrobenchConfigPathOLTP,
 		"tpcc":  s.macrobenchConfigPathTPCC,
 	}
+	for benchmarkType, configFile := range s.benchmarkConfigs {
+		configs[benchmarkType] = configFile
+	}
 	return configs
 }
 
 func (s *Server) addToQueue(element *executionQueueElement) {
-	mtx.Lock()
-	defer func() {
-		mtx.Unlock()
-	}()
-
-	_, found := queue[element.identifier]
-
-	if found {
+	if s.queue.Contains(element.identifier) {
 		return
 	}
 	exists, err := s.checkIfExecutionExists(element.identifier)
@@ -116,9 +165,17 @@ func (s *Server) addToQueue(element *executionQueueElement) {
 		slog.Error(err.Error())
 		return
 	}
-	if !exists {
-		queue[element.identifier] = element
+	if exists {
+		return
+	}
+	if s.labelNoopCommits {
+		s.detectNoopCommit(element)
+	}
+	if s.queue.Add(element) {
 		slog.Infof("%+v is added to the queue", element.identifier)
+		if err := persistQueueElement(s.dbClient, element); err != nil {
+			slog.Error(err)
+		}
 
 		// we sleep here to avoid adding too many similar elements to the queue at the same time.
 		time.Sleep(2 * time.Second)
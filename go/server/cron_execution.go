@@ -19,17 +19,21 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/vitessio/arewefastyet/go/exec"
 	"time"
 )
 
-func (s *Server) executeSingle(config string, identifier executionIdentifier) (err error) {
+// executeSingle runs an execution of the given configuration file and identifier. Once the
+// execution is prepared, prepared is called with its UUID if it is not nil.
+func (s *Server) executeSingle(ctx context.Context, config string, identifier executionIdentifier, labels map[string]string, attempt, retriesLeft int, prepared func(execUUID string)) (execUUID string, err error) {
 	var e *exec.Exec
 	defer func() {
 		if e != nil {
 			if err != nil {
-				err = fmt.Errorf("%v", err)
+				err = fmt.Errorf("%w", err)
 			}
 			if errSuccess := e.Success(); errSuccess != nil {
 				err = errSuccess
@@ -42,95 +46,385 @@ func (s *Server) executeSingle(config string, identifier executionIdentifier) (e
 	e, err = exec.NewExecWithConfig(config)
 	if err != nil {
 		slog.Error(err.Error())
-		return err
+		return "", err
 	}
+	execUUID = e.UUID.String()
 	e.Source = identifier.Source
 	e.GitRef = identifier.GitRef
 	e.VtgatePlannerVersion = identifier.PlannerVersion
 	e.PullNB = identifier.PullNb
+	e.Attempt = attempt
+	e.RetriesLeft = retriesLeft
+	// the infrastructure is torn down by executeElement once the execution is compared
+	e.SkipPostCleanup = s.defersCleanup(identifier.BenchmarkType)
+	// the labels of the element take precedence over the ones derived from git
+	for key, value := range s.deriveGitLabels(identifier.GitRef, identifier.Source) {
+		if e.Labels == nil {
+			e.Labels = map[string]string{}
+		}
+		e.Labels[key] = value
+	}
+	for key, value := range labels {
+		if e.Labels == nil {
+			e.Labels = map[string]string{}
+		}
+		e.Labels[key] = value
+	}
 
-	slog.Info("Starting execution: UUID: [", e.UUID.String(), "], Git Ref: [", identifier.GitRef, "], Type: [", identifier.BenchmarkType, "]")
+	slog.Info("Starting execution: UUID: [", e.UUID.String(), "], Git Ref: [", identifier.GitRef, "], Type: [", identifier.BenchmarkType, "], Attempt: [", attempt, "], Retries left: [", retriesLeft, "]")
 	err = e.Prepare()
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("prepare step error: %v", err))
+		return execUUID, fmt.Errorf("prepare step error: %w", err)
+	}
+	if prepared != nil {
+		prepared(execUUID)
 	}
 
 	err = e.SetOutputToDefaultPath()
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("prepare outputs step error: %v", err))
+		return execUUID, fmt.Errorf("prepare outputs step error: %w", err)
 	}
 
-	err = e.ExecuteWithTimeout(time.Hour * 2)
+	err = e.ExecuteWithContext(ctx, time.Hour*2)
 	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("execution step error: %v", err))
+		return execUUID, fmt.Errorf("execution step error: %w", err)
 	}
-	return nil
+	return execUUID, nil
 }
 
 func (s *Server) executeElement(element *executionQueueElement) {
 	if element.retry < 0 {
-		if _, found := queue[element.identifier]; found {
-			// removing the element from the queue since we are done with it
-			mtx.Lock()
-			delete(queue, element.identifier)
-			mtx.Unlock()
-		}
-		decrementNumberOfOnGoingExecution()
+		// removing the element from the queue since we are done with it
+		s.removeFromQueue(element.identifier)
+		s.queue.Done()
+		return
+	}
+
+	ctx, done := s.queue.ExecutionContext(element)
+	if ctx.Err() != nil {
+		// the element was canceled before it started
+		s.cancelElement(element)
+		done()
 		return
 	}
 
 	// execute with the given configuration file and exec identifier
-	err := s.executeSingle(element.config, element.identifier)
+	element.attempt++
+	s.persistQueueElementState(element)
+	// the settings of the element are recorded as soon as its execution exists, so that it can
+	// be requeued even if the server stops during the execution, see requeueHandler
+	execUUID, err := s.executeSingle(ctx, element.config, element.identifier, element.labels(), element.attempt, element.retry, func(execUUID string) {
+		s.recordRequeueMetadata(element, execUUID)
+	})
+	done()
 	if err != nil {
-		slog.Error(err.Error())
+		slog.Errorf("Attempt %d of %+v failed (%d retries left): %v", element.attempt, element.identifier, element.retry, err)
+
+		// the execution was canceled, it is not retried
+		if errors.Is(err, exec.ErrExecutionCanceled) {
+			s.cancelElement(element)
+			return
+		}
+
+		// the execution produced implausible results, it is not retried as
+		// running it again with the same configuration would not help
+		if errors.Is(err, exec.ErrInvalidResults) {
+			s.notifyInvalidExecution(element.identifier, execUUID, err)
+			// the execution completed but is not compared, nothing is left to investigate
+			if s.defersCleanup(element.identifier.BenchmarkType) {
+				s.holdInfrastructure(execUUID, element.identifier.BenchmarkType)
+				go s.releaseInfrastructure(execUUID, element.identifier.BenchmarkType)
+			}
+			if element.batchID != "" {
+				s.completeSuiteMember(element, exec.StatusInvalid, nil)
+			}
+			s.removeFromQueue(element.identifier)
+			s.queue.Done()
+			return
+		}
+
+		// the failed execution can be requeued later with the latest baselines of
+		// the element, see requeueHandler
+		s.recordRequeueMetadata(element, execUUID)
+
+		// the execution failed because of the infrastructure, we requeue it
+		// later without consuming the element's retries
+		if s.requeuesInfraFailure(element, err) {
+			element.infraRequeues++
+			slog.Infof("%+v failed because of the infrastructure, it will be requeued in %s (requeue %d of %d)", element.identifier, s.infraFailureRequeueDelay.String(), element.infraRequeues, s.infraFailureMaxRequeues)
+			go s.requeueAfter(element, s.infraFailureRequeueDelay)
+			s.queue.Done()
+			return
+		}
 
 		// execution failed, we retry
 		element.retry -= 1
+		if element.retry >= 0 {
+			slog.Infof("Retrying %+v, attempt %d (%d retries left)", element.identifier, element.attempt+1, element.retry)
+		} else {
+			s.notifyExecutionFailure(element.identifier, execUUID, element.attempt, err)
+			if element.batchID != "" {
+				s.completeSuiteMember(element, exec.StatusFailed, nil)
+			}
+		}
 		s.executeElement(element)
 		return
 	}
 
+	s.completions.add(time.Now())
+
+	// the infrastructure is held before the running slot is released,
+	// so that no other execution starts on the same hosts
+	if s.defersCleanup(element.identifier.BenchmarkType) {
+		s.holdInfrastructure(execUUID, element.identifier.BenchmarkType)
+	}
+
 	go func() {
-		s.compareElement(element)
+		regression := s.compareElement(element)
+		if s.defersCleanup(element.identifier.BenchmarkType) {
+			s.cleanUpAfterComparison(execUUID, element.identifier.BenchmarkType, regression)
+		}
 
 		// removing the element from the queue since we are done with it
-		mtx.Lock()
-		delete(queue, element.identifier)
-		mtx.Unlock()
+		s.removeFromQueue(element.identifier)
 	}()
 
-	decrementNumberOfOnGoingExecution()
+	s.queue.Done()
+}
+
+// requeuesInfraFailure returns true if the element that failed with err is requeued without
+// consuming its retries: the failure must be caused by the infrastructure, and the element
+// must not have been requeued infraFailureMaxRequeues times already.
+func (s *Server) requeuesInfraFailure(element *executionQueueElement, err error) bool {
+	return exec.IsInfraError(err) && element.infraRequeues < s.infraFailureMaxRequeues
 }
 
-func (s *Server) compareElement(element *executionQueueElement) {
-	done := 0
-	for done != len(element.compareWith) {
-		time.Sleep(1 * time.Second)
-		for _, comparer := range element.compareWith {
-			comparerUUID, err := exec.GetFinishedExecution(s.dbClient, comparer.GitRef, comparer.Source, comparer.BenchmarkType, comparer.PlannerVersion, comparer.PullNb)
+// requeueAfter marks the given element as not executing after the given delay,
+// allowing cronExecutionQueueWatcher to pick it up again. The element stays
+// in the queue in the meantime, so it cannot be added twice.
+func (s *Server) requeueAfter(element *executionQueueElement, delay time.Duration) {
+	time.Sleep(delay)
+	if !s.queue.Requeue(element) {
+		// the element was canceled while waiting
+		s.removeFromQueue(element.identifier)
+		return
+	}
+	s.persistQueueElementState(element)
+}
+
+// cancelElement removes the canceled element from the queue and releases its running slot.
+func (s *Server) cancelElement(element *executionQueueElement) {
+	slog.Infof("%+v was canceled", element.identifier)
+	if element.batchID != "" {
+		s.completeSuiteMember(element, exec.StatusCanceled, nil)
+	}
+	s.removeFromQueue(element.identifier)
+	s.queue.Done()
+}
+
+// compareElement compares the finished execution of element against its baselines and notifies
+// the results. It returns true if a regression was found against one of the baselines.
+func (s *Server) compareElement(element *executionQueueElement) (regression bool) {
+	if element.baselineOnly {
+		return
+	}
+
+	// the elements of a batch are notified along with the rest of their batch once compared
+	var batchReports []baselineReport
+	if element.batchID != "" {
+		defer func() {
+			s.completeSuiteMember(element, exec.StatusFinished, batchReports)
+		}()
+	}
+
+	// the execution and its baselines may have just finished, they are read from the
+	// primary since the read replica could lag behind
+	elementUUID, err := exec.GetFinishedExecution(s.dbClient, element.identifier.GitRef, element.identifier.Source, element.identifier.BenchmarkType, element.identifier.PlannerVersion, element.identifier.PullNb)
+	if err != nil {
+		slog.Error(err)
+		return
+	}
+
+	endTrace := exec.TraceComparison(s.tracingConfig, elementUUID)
+	defer func() {
+		endTrace(err)
+	}()
+
+	labels, err := exec.GetLabels(s.dbClient, elementUUID)
+	if err != nil {
+		slog.Error(err)
+		return
+	}
+
+	s.notifyAnomalies(element.identifier, elementUUID)
+
+	// the comparisons against all the baselines are notified in a single message if needed
+	consolidate := element.batchID != "" || (s.consolidateReports && len(element.compareWith) > 1)
+	reports := map[executionIdentifier]baselineReport{}
+
+	finishedExecution := func(comparer executionIdentifier) (string, error) {
+		return exec.GetFinishedExecution(s.dbClient, comparer.GitRef, comparer.Source, comparer.BenchmarkType, comparer.PlannerVersion, comparer.PullNb)
+	}
+	skipped, err := s.forEachFinishedBaseline(element.compareWith, finishedExecution, func(comparer executionIdentifier, comparerUUID string) error {
+		br, err := s.compareWithBaseline(element.identifier, elementUUID, comparer, comparerUUID, labels, consolidate, element.notifyAlways)
+		if err != nil {
+			return err
+		}
+		reports[comparer] = br
+		if br.report.Regression != "" {
+			regression = true
+		}
+		// regressions are tracked per planner version, comparisons between two
+		// planner versions are only notified
+		if br.report.Regression != "" && comparer.PlannerVersion == element.identifier.PlannerVersion {
+			s.trackRegression(element.identifier, elementUUID, comparer.GitRef, comparerUUID, br.report.Regression)
+			if s.autoBisect && element.identifier.Source == exec.SourceCron && comparer.Source == exec.SourceCron {
+				go s.bisectRegression(element.config, element.identifier, comparer.GitRef, element.identifier.GitRef)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error(err)
+		return
+	}
+	for comparer, reason := range skipped {
+		slog.Warnf("Skipping the comparison of %+v with %+v: %s", element.identifier, comparer, reason)
+		reports[comparer] = baselineReport{baseline: comparer, skipped: reason}
+	}
+	var ordered []baselineReport
+	for _, comparer := range element.compareWith {
+		if report, ok := reports[comparer]; ok {
+			ordered = append(ordered, report)
+		}
+	}
+	s.reportCommitStatus(element, ordered)
+	if consolidate {
+		if element.batchID != "" {
+			batchReports = ordered
+		} else if err := s.sendConsolidatedReport(element, labels, ordered); err != nil {
+			slog.Error(err)
+		}
+	}
+	s.resolveRegressions(element.identifier, elementUUID)
+	return regression
+}
+
+// baselinePollInterval is the interval at which forEachFinishedBaseline checks whether the
+// executions of the baselines finished, and baselineWaitTimeout the duration after which it
+// stops waiting for the ones that are still queued.
+var (
+	baselinePollInterval = time.Second
+	baselineWaitTimeout  = 24 * time.Hour
+)
+
+// forEachFinishedBaseline calls compare once for each of the given baselines, as soon as
+// finishedExecution returns the UUID of its finished execution. It gives up on the baselines
+// that are neither finished nor queued anymore, as they failed or were canceled, and on the
+// ones that did not finish within baselineWaitTimeout. The reasons why these baselines were
+// given up are returned. It stops at the first error returned by finishedExecution or compare.
+func (s *Server) forEachFinishedBaseline(baselines []executionIdentifier, finishedExecution func(executionIdentifier) (string, error), compare func(baseline executionIdentifier, baselineUUID string) error) (skipped map[executionIdentifier]string, err error) {
+	skipped = map[executionIdentifier]string{}
+	compared := map[executionIdentifier]bool{}
+	deadline := time.Now().Add(baselineWaitTimeout)
+	for {
+		time.Sleep(baselinePollInterval)
+		pending := false
+		for _, baseline := range baselines {
+			if compared[baseline] || skipped[baseline] != "" {
+				continue
+			}
+			baselineUUID, err := finishedExecution(baseline)
 			if err != nil {
-				slog.Error(err)
-				return
+				return nil, err
 			}
-			if comparerUUID != "" {
-				err := s.sendNotificationForRegression(
-					element.identifier.Source,
-					comparer.Source,
-					element.identifier.GitRef,
-					comparer.GitRef,
-					element.identifier.PlannerVersion,
-					element.identifier.BenchmarkType,
-					element.identifier.PullNb,
-					element.notifyAlways,
-				)
+			if baselineUUID == "" && !s.queue.Contains(baseline) {
+				// the baseline may have finished and left the queue since we checked
+				baselineUUID, err = finishedExecution(baseline)
 				if err != nil {
-					slog.Error(err)
-					return
+					return nil, err
+				}
+				if baselineUUID == "" {
+					skipped[baseline] = "the baseline did not finish, it failed or was canceled"
+					continue
+				}
+			}
+			if baselineUUID == "" {
+				if time.Now().After(deadline) {
+					skipped[baseline] = fmt.Sprintf("the baseline did not finish within %s", baselineWaitTimeout.String())
+					continue
 				}
-				done++
+				pending = true
+				continue
+			}
+			if err := compare(baseline, baselineUUID); err != nil {
+				return nil, err
 			}
+			compared[baseline] = true
+		}
+		if !pending {
+			return skipped, nil
+		}
+	}
+}
+
+// compareWithBaseline compares the execution elementUUID of the given identifier against the execution
+// baselineUUID of baseline, and notifies the result unless consolidate is set, in which case the report
+// is meant to be notified along with the ones of the other baselines. The comparison is skipped if the
+// executions cannot be compared.
+func (s *Server) compareWithBaseline(identifier executionIdentifier, elementUUID string, baseline executionIdentifier, baselineUUID string, labels map[string]string, consolidate, notifyAlways bool) (baselineReport, error) {
+	elementMetadata, baselineMetadata, err := s.getExecutionsMetadata(elementUUID, baselineUUID)
+	if err != nil {
+		return baselineReport{}, err
+	}
+	for _, mismatch := range []func(left, right map[string]string) string{durationsMismatch, clientsMismatch, workloadMixMismatch} {
+		if reason := mismatch(elementMetadata, baselineMetadata); reason != "" {
+			slog.Warnf("Skipping the comparison of %+v with %+v: %s", identifier, baseline, reason)
+			return baselineReport{baseline: baseline, skipped: reason}, nil
 		}
 	}
+	var warnings []string
+	if reason := networkImpairmentMismatch(elementMetadata, baselineMetadata); reason != "" {
+		if labels[allowImpairedComparisonLabel] != "true" {
+			slog.Warnf("Skipping the comparison of %+v with %+v: %s", identifier, baseline, reason)
+			return baselineReport{baseline: baseline, skipped: reason}, nil
+		}
+		warnings = append(warnings, reason)
+	}
+	if labels[noopLabel] == "true" {
+		warnings = append(warnings, noopWarning)
+	}
+	warnings = append(warnings, metadataWarnings(elementMetadata, baselineMetadata)...)
+	if identifier.BenchmarkType != "micro" && identifier.BenchmarkType != "generic" {
+		changes, err := s.diffQueryPlans(baselineUUID, elementUUID)
+		if err != nil {
+			slog.Warnf("Could not compare the query plans of %+v with %+v: %v", identifier, baseline, err)
+		} else if warning := queryPlansWarning(changes); warning != "" {
+			warnings = append(warnings, warning)
+		}
+	}
+	var report ComparisonReport
+	if consolidate {
+		report, err = s.getComparisonReport(identifier.GitRef, baseline.GitRef, identifier.PlannerVersion, baseline.PlannerVersion, identifier.BenchmarkType)
+	} else {
+		report, err = s.sendNotificationForRegression(
+			identifier.Source,
+			baseline.Source,
+			identifier.GitRef,
+			baseline.GitRef,
+			identifier.PlannerVersion,
+			baseline.PlannerVersion,
+			identifier.BenchmarkType,
+			identifier.PullNb,
+			labels,
+			warnings,
+			notifyAlways,
+		)
+	}
+	if err != nil {
+		return baselineReport{}, err
+	}
+	return baselineReport{baseline: baseline, report: report, warnings: warnings}, nil
 }
 
 func (s *Server) checkIfExecutionExists(identifier executionIdentifier) (bool, error) {
@@ -162,27 +456,8 @@ func (s *Server) checkIfExecutionExists(identifier executionIdentifier) (bool, e
 func (s *Server) cronExecutionQueueWatcher() {
 	for {
 		time.Sleep(time.Second * 1)
-		mtx.Lock()
-		if currentCountExec >= maxConcurJob {
-			mtx.Unlock()
-			continue
-		}
-		for _, element := range queue {
-			if !element.executing {
-				currentCountExec++
-
-				// setting this element to `executing = true`, so we do not execute it twice in the future
-				element.executing = true
-				go s.executeElement(element)
-				break
-			}
+		if element := s.queue.Next(); element != nil {
+			go s.executeElement(element)
 		}
-		mtx.Unlock()
 	}
 }
-
-func decrementNumberOfOnGoingExecution() {
-	mtx.Lock()
-	currentCountExec--
-	mtx.Unlock()
-}
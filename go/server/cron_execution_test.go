@@ -0,0 +1,135 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/vitessio/arewefastyet/go/infra/ansible"
+)
+
+func TestServer_compareElementBaselineOnly(t *testing.T) {
+	c := qt.New(t)
+	// a baseline-only element must return before querying the database,
+	// which is not configured on this Server, and before completing its batch
+	s := &Server{}
+	element := &executionQueueElement{
+		identifier:   executionIdentifier{GitRef: "a", Source: "api", BenchmarkType: "micro"},
+		compareWith:  []executionIdentifier{{GitRef: "b", Source: "api", BenchmarkType: "micro"}},
+		baselineOnly: true,
+		batchID:      "batch",
+	}
+	s.suiteBatches.add(&suiteBatch{id: "batch", members: []*suiteMember{{identifier: element.identifier, status: suiteMemberPending}}}, time.Now())
+
+	c.Assert(s.compareElement(element), qt.IsFalse)
+	status, ok := s.suiteBatches.status("batch", nil)
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(status.Status, qt.Equals, suiteMemberPending)
+	c.Assert(status.Members[0].Status, qt.Equals, "queued")
+}
+
+// withBaselinePolling shortens the polling of forEachFinishedBaseline for the duration of the test.
+func withBaselinePolling(c *qt.C, interval, timeout time.Duration) {
+	previousInterval, previousTimeout := baselinePollInterval, baselineWaitTimeout
+	baselinePollInterval, baselineWaitTimeout = interval, timeout
+	c.Cleanup(func() {
+		baselinePollInterval, baselineWaitTimeout = previousInterval, previousTimeout
+	})
+}
+
+func TestServer_forEachFinishedBaseline(t *testing.T) {
+	c := qt.New(t)
+	withBaselinePolling(c, time.Millisecond, time.Hour)
+
+	finished := executionIdentifier{GitRef: "finished", Source: "cron", BenchmarkType: "micro"}
+	later := executionIdentifier{GitRef: "later", Source: "cron", BenchmarkType: "micro"}
+	failed := executionIdentifier{GitRef: "failed", Source: "cron", BenchmarkType: "micro"}
+
+	s := &Server{queue: NewQueue(1)}
+	s.queue.Add(&executionQueueElement{identifier: later})
+
+	// later finishes after a few polls, failed is neither finished nor queued
+	polls := 0
+	finishedExecution := func(baseline executionIdentifier) (string, error) {
+		switch baseline {
+		case finished:
+			return "finished-uuid", nil
+		case later:
+			polls++
+			if polls > 3 {
+				return "later-uuid", nil
+			}
+		}
+		return "", nil
+	}
+	compared := map[executionIdentifier]int{}
+	skipped, err := s.forEachFinishedBaseline([]executionIdentifier{finished, later, failed}, finishedExecution, func(baseline executionIdentifier, baselineUUID string) error {
+		compared[baseline]++
+		return nil
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(compared, qt.DeepEquals, map[executionIdentifier]int{finished: 1, later: 1})
+	c.Assert(skipped, qt.HasLen, 1)
+	c.Assert(skipped[failed], qt.Contains, "failed or was canceled")
+}
+
+func TestServer_forEachFinishedBaselineTimeout(t *testing.T) {
+	c := qt.New(t)
+	withBaselinePolling(c, time.Millisecond, 20*time.Millisecond)
+
+	queued := executionIdentifier{GitRef: "queued", Source: "cron", BenchmarkType: "micro"}
+	s := &Server{queue: NewQueue(1)}
+	s.queue.Add(&executionQueueElement{identifier: queued})
+
+	skipped, err := s.forEachFinishedBaseline([]executionIdentifier{queued}, func(executionIdentifier) (string, error) {
+		return "", nil
+	}, func(executionIdentifier, string) error {
+		c.Fatal("the baseline never finished")
+		return nil
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(strings.HasPrefix(skipped[queued], "the baseline did not finish within"), qt.IsTrue)
+}
+
+func TestServer_requeuesInfraFailure(t *testing.T) {
+	s := &Server{infraFailureMaxRequeues: 2}
+	infraErr := fmt.Errorf("execution step error: %w", ansible.ErrHostUnreachable)
+	tests := []struct {
+		name          string
+		err           error
+		infraRequeues int
+		want          bool
+	}{
+		{name: "infrastructure failure", err: infraErr, want: true},
+		{name: "below the cap", err: infraErr, infraRequeues: 1, want: true},
+		{name: "cap reached", err: infraErr, infraRequeues: 2},
+		{name: "code failure", err: errors.New("benchmark failed")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			element := &executionQueueElement{infraRequeues: tt.infraRequeues}
+			qt.Assert(t, s.requeuesInfraFailure(element, tt.err), qt.Equals, tt.want)
+		})
+	}
+}
@@ -19,6 +19,8 @@
 package server
 
 import (
+	"strings"
+
 	"github.com/vitessio/arewefastyet/go/exec"
 	"github.com/vitessio/arewefastyet/go/tools/git"
 	"github.com/vitessio/arewefastyet/go/tools/macrobench"
@@ -28,7 +30,7 @@ func (s *Server) branchCronHandler() {
 	// update the local clone of vitess from remote
 	s.vitessPathMu.Lock()
 	defer s.vitessPathMu.Unlock()
-	err := s.pullLocalVitess()
+	err := s.pullLocalVitess(exec.SourceCron)
 	if err != nil {
 		slog.Error(err.Error())
 		return
@@ -57,8 +59,8 @@ func (s *Server) mainBranchCronHandler() ([]*executionQueueElement, error) {
 	configs := s.getConfigFiles()
 	vitessPath := s.getVitessPath()
 
-	// getting the latest commit hash from local fork of Vitess
-	ref, err := git.GetCommitHash(vitessPath)
+	// getting the latest commit hash of the branch configured for SourceCron
+	ref, err := s.checkoutSourceBranch(exec.SourceCron, defaultSourceBranch)
 	if err != nil {
 		return nil, err
 	}
@@ -72,26 +74,30 @@ func (s *Server) mainBranchCronHandler() ([]*executionQueueElement, error) {
 	// We compare main with the previous hash of main and with the latest release
 	for configType, configFile := range configs {
 		if configType == "micro" {
-			_, previousGitRef, err := exec.GetPreviousFromSourceMicrobenchmark(s.dbClient, exec.SourceCron, ref)
+			previousGitRef, previousSource, err := s.getSourceBaseline(exec.SourceCron, configType, "", ref)
 			if err != nil {
 				slog.Warn(err.Error())
 				continue
 			}
-			elements = append(elements, s.createBranchElementWithComparisonOnPreviousAndRelease(configFile, ref, configType, previousGitRef, "", exec.SourceCron, lastRelease)...)
+			elements = append(elements, s.createBranchElementWithComparisonOnPreviousAndRelease(configFile, ref, configType, previousGitRef, previousSource, "", "", exec.SourceCron, lastRelease)...)
 		} else {
 			for _, version := range macrobench.PlannerVersions {
-				_, previousGitRef, err := exec.GetPreviousFromSourceMacrobenchmark(s.dbClient, exec.SourceCron, configType, string(version), ref)
+				previousGitRef, previousSource, previousVersion, err := s.getMacrobenchmarkBaseline(exec.SourceCron, configType, ref, version, macrobench.PlannerVersions)
 				if err != nil {
 					slog.Warn(err.Error())
 					continue
 				}
-				elements = append(elements, s.createBranchElementWithComparisonOnPreviousAndRelease(configFile, ref, configType, previousGitRef, string(version), exec.SourceCron, lastRelease)...)
+				elements = append(elements, s.createBranchElementWithComparisonOnPreviousAndRelease(configFile, ref, configType, previousGitRef, previousSource, string(version), string(previousVersion), exec.SourceCron, lastRelease)...)
 			}
 		}
 	}
 	return elements, nil
 }
 
+// releaseBranchNameSuffix is the suffix of the names of the release branches given by
+// git.GetLatestVitessReleaseBranchCommitHash, appended to their git branch.
+const releaseBranchNameSuffix = "-branch"
+
 func (s *Server) releaseBranchesCronHandler() ([]*executionQueueElement, error) {
 	var elements []*executionQueueElement
 	configs := s.getConfigFiles()
@@ -105,8 +111,14 @@ func (s *Server) releaseBranchesCronHandler() ([]*executionQueueElement, error)
 
 	// We compare release-branches with the previous hash of that branch and with the latest patch release of that version
 	for _, release := range releases {
-		ref := release.CommitHash
 		source := exec.SourceReleaseBranch + release.Name
+
+		// the source of a release branch benchmarks that branch unless another one is configured for it
+		ref, err := s.checkoutSourceBranch(source, strings.TrimSuffix(release.Name, releaseBranchNameSuffix))
+		if err != nil {
+			slog.Warn(err.Error())
+			continue
+		}
 		lastPatchRelease, err := git.GetLastPatchReleaseAndCommitHash(vitesLocalPath, release.Number)
 		if err != nil {
 			slog.Warn(err.Error())
@@ -115,24 +127,24 @@ func (s *Server) releaseBranchesCronHandler() ([]*executionQueueElement, error)
 
 		for configType, configFile := range configs {
 			if configType == "micro" {
-				_, previousGitRef, err := exec.GetPreviousFromSourceMicrobenchmark(s.dbClient, source, ref)
+				previousGitRef, previousSource, err := s.getSourceBaseline(source, configType, "", ref)
 				if err != nil {
 					slog.Warn(err.Error())
 					continue
 				}
 
-				elements = append(elements, s.createBranchElementWithComparisonOnPreviousAndRelease(configFile, ref, configType, previousGitRef, "", source, lastPatchRelease)...)
+				elements = append(elements, s.createBranchElementWithComparisonOnPreviousAndRelease(configFile, ref, configType, previousGitRef, previousSource, "", "", source, lastPatchRelease)...)
 			} else {
 				versions := git.GetPlannerVersionsForRelease(release)
 
 				for _, version := range versions {
-					_, previousGitRef, err := exec.GetPreviousFromSourceMacrobenchmark(s.dbClient, source, configType, string(version), ref)
+					previousGitRef, previousSource, previousVersion, err := s.getMacrobenchmarkBaseline(source, configType, ref, version, versions)
 					if err != nil {
 						slog.Warn(err.Error())
 						continue
 					}
 
-					elements = append(elements, s.createBranchElementWithComparisonOnPreviousAndRelease(configFile, ref, configType, previousGitRef, string(version), source, lastPatchRelease)...)
+					elements = append(elements, s.createBranchElementWithComparisonOnPreviousAndRelease(configFile, ref, configType, previousGitRef, previousSource, string(version), string(previousVersion), source, lastPatchRelease)...)
 				}
 			}
 		}
@@ -140,7 +152,28 @@ func (s *Server) releaseBranchesCronHandler() ([]*executionQueueElement, error)
 	return elements, nil
 }
 
-func (s *Server) createBranchElementWithComparisonOnPreviousAndRelease(configFile, ref, configType, previousGitRef, plannerVersion, source string, lastRelease *git.Release) []*executionQueueElement {
+// getMacrobenchmarkBaseline returns the git ref, source and planner version of the macrobenchmark against
+// which ref should be compared. By default, this is the baseline given by the baseline strategy of the source,
+// see getSourceBaseline, using the same planner version. If compareWithPreviousPlanner is set, the baseline is
+// ref itself using the planner version that precedes version, as long as it is part of the available versions.
+func (s *Server) getMacrobenchmarkBaseline(source, configType, ref string, version macrobench.PlannerVersion, versions []macrobench.PlannerVersion) (string, string, macrobench.PlannerVersion, error) {
+	if s.compareWithPreviousPlanner {
+		if previousVersion, ok := macrobench.PreviousPlannerVersion(version); ok {
+			for _, v := range versions {
+				if v == previousVersion {
+					return ref, source, previousVersion, nil
+				}
+			}
+		}
+	}
+	previousGitRef, previousSource, err := s.getSourceBaseline(source, configType, string(version), ref)
+	if err != nil {
+		return "", "", "", err
+	}
+	return previousGitRef, previousSource, version, nil
+}
+
+func (s *Server) createBranchElementWithComparisonOnPreviousAndRelease(configFile, ref, configType, previousGitRef, previousSource, plannerVersion, previousPlannerVersion, source string, lastRelease *git.Release) []*executionQueueElement {
 	var elements []*executionQueueElement
 
 	// creating a benchmark for the latest commit on the branch with SourceCron as a source
@@ -151,7 +184,7 @@ func (s *Server) createBranchElementWithComparisonOnPreviousAndRelease(configFil
 	if previousGitRef != "" {
 		// creating an execution queue element for the latest benchmark with SourceCron as source
 		// this will not be executed since the benchmark already exist, we still create the element in order to compare
-		previousElement := s.createSimpleExecutionQueueElement(source, configFile, previousGitRef, configType, plannerVersion, false, 0)
+		previousElement := s.createSimpleExecutionQueueElement(previousSource, configFile, previousGitRef, configType, previousPlannerVersion, false, 0)
 		previousElement.compareWith = append(previousElement.compareWith, newExecutionElement.identifier)
 		newExecutionElement.compareWith = append(newExecutionElement.compareWith, previousElement.identifier)
 		elements = append(elements, previousElement)
@@ -245,7 +278,7 @@ func (s *Server) tagsCronHandler() {
 	// update the local clone of vitess from remote
 	s.vitessPathMu.Lock()
 	defer s.vitessPathMu.Unlock()
-	err := s.pullLocalVitess()
+	err := s.pullLocalVitess(exec.SourceTag)
 	if err != nil {
 		slog.Error(err.Error())
 		return
@@ -0,0 +1,48 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/vitessio/arewefastyet/go/tools/macrobench"
+)
+
+func TestServer_getMacrobenchmarkBaselineWithPreviousPlanner(t *testing.T) {
+	c := qt.New(t)
+	s := &Server{compareWithPreviousPlanner: true}
+
+	gitRef, source, planner, err := s.getMacrobenchmarkBaseline("cron", "oltp", "abc", macrobench.Gen4FallbackPlanner, macrobench.PlannerVersions)
+	c.Assert(err, qt.IsNil)
+	c.Assert(gitRef, qt.Equals, "abc")
+	c.Assert(source, qt.Equals, "cron")
+	c.Assert(planner, qt.Equals, macrobench.V3Planner)
+}
+
+func TestServer_createBranchElementWithPreviousPlanner(t *testing.T) {
+	c := qt.New(t)
+	s := &Server{}
+
+	elements := s.createBranchElementWithComparisonOnPreviousAndRelease("config.yaml", "abc", "oltp", "abc", "cron", string(macrobench.Gen4FallbackPlanner), string(macrobench.V3Planner), "cron", nil)
+	c.Assert(elements, qt.HasLen, 2)
+	c.Assert(elements[0].identifier.PlannerVersion, qt.Equals, string(macrobench.Gen4FallbackPlanner))
+	c.Assert(elements[1].identifier.PlannerVersion, qt.Equals, string(macrobench.V3Planner))
+	c.Assert(elements[0].compareWith, qt.DeepEquals, []executionIdentifier{elements[1].identifier})
+}
@@ -0,0 +1,62 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestServer_loadLocation(t *testing.T) {
+	testcases := []struct {
+		name     string
+		timeZone string
+		wantErr  string
+	}{
+		{name: "UTC", timeZone: "UTC"},
+		{name: "IANA time zone", timeZone: "America/New_York"},
+		{name: "Unknown time zone", timeZone: "Mars/Olympus_Mons", wantErr: `invalid time zone "Mars/Olympus_Mons": .*`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := qt.New(t)
+			s := &Server{timeZone: tc.timeZone}
+			location, err := s.loadLocation()
+			if tc.wantErr != "" {
+				c.Assert(err, qt.ErrorMatches, tc.wantErr)
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			c.Assert(location.String(), qt.Equals, tc.timeZone)
+		})
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	c := qt.New(t)
+	newYork, err := time.LoadLocation("America/New_York")
+	c.Assert(err, qt.IsNil)
+	date := time.Date(2021, 6, 1, 2, 0, 0, 0, time.UTC)
+
+	c.Assert(formatDate(nil, newYork), qt.Equals, "")
+	c.Assert(formatDate(&date, nil), qt.Equals, "01 Jun 21 02:00 UTC")
+	c.Assert(formatDate(&date, newYork), qt.Equals, "31 May 21 22:00 EDT")
+}
@@ -0,0 +1,193 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/vitessio/arewefastyet/go/exec"
+	"github.com/vitessio/arewefastyet/go/infra/ansible"
+	"github.com/vitessio/arewefastyet/go/storage"
+)
+
+// provisionsInfrastructure returns true if the executions of the given benchmark type provision
+// infrastructure on the hosts of their inventory. Only macrobenchmarks do.
+func provisionsInfrastructure(benchmarkType string) bool {
+	return exec.ProvisionsInfrastructure(benchmarkType)
+}
+
+// defersCleanup returns true if the infrastructure of the executions of the given benchmark
+// type is torn down once they are compared rather than at the end of their execution.
+// Only macrobenchmarks provision infrastructure that is worth holding.
+func (s *Server) defersCleanup(benchmarkType string) bool {
+	return s.deferredCleanup && provisionsInfrastructure(benchmarkType)
+}
+
+// cleanUpExecution tears down the infrastructure of the execution execUUID. The SSH configuration
+// of the given benchmark type is used to connect to its hosts.
+func (s *Server) cleanUpExecution(ctx context.Context, execUUID, benchmarkType string) (*exec.CleanUpReport, error) {
+	var ansibleCfg ansible.Config
+	if configFile, ok := s.getConfigFiles()[benchmarkType]; ok {
+		e, err := exec.NewExecWithConfig(configFile)
+		if err != nil {
+			return nil, err
+		}
+		ansibleCfg = e.AnsibleConfig
+		ansibleCfg.UseFilesOfType(benchmarkType)
+	}
+	return exec.CleanUp(ctx, s.readDB(), execUUID, benchmarkType, ansibleCfg)
+}
+
+// holdInfrastructure holds the infrastructure of the execution execUUID, whose cleanup is deferred,
+// until releaseInfrastructure tears it down. The executions provisioning infrastructure are not
+// started while it is held, as they would run on the same hosts, see Queue.Next. The hold is
+// persisted so that it survives a restart of the server, see restoreInfrastructureHolds.
+func (s *Server) holdInfrastructure(execUUID, benchmarkType string) {
+	s.queue.HoldInfrastructure(execUUID)
+	if err := persistInfrastructureHold(s.dbClient, execUUID, benchmarkType); err != nil {
+		slog.Error(err)
+	}
+}
+
+// releaseInfrastructure tears down the held infrastructure of the execution execUUID and releases
+// its hold. The infrastructure stays held if it could not be torn down, it must then be cleaned
+// up through the API. Nothing is done if the infrastructure was already released.
+func (s *Server) releaseInfrastructure(execUUID, benchmarkType string) {
+	if !s.queue.HoldsInfrastructure(execUUID) {
+		return
+	}
+	report, err := s.cleanUpExecution(context.Background(), execUUID, benchmarkType)
+	if err != nil {
+		slog.Errorf("Could not clean up the infrastructure of execution %s, it is held until it is cleaned up through the API: %v", execUUID, err)
+		return
+	}
+	slog.Infof("Cleaned up the infrastructure of execution %s on hosts %v", execUUID, report.Hosts)
+	s.infrastructureReleased(execUUID)
+}
+
+// infrastructureReleased releases the hold of the execution execUUID once its infrastructure was torn down.
+func (s *Server) infrastructureReleased(execUUID string) {
+	s.queue.ReleaseInfrastructure(execUUID)
+	if err := deleteInfrastructureHold(s.dbClient, execUUID); err != nil {
+		slog.Error(err)
+	}
+}
+
+// cleanUpAfterComparison tears down the held infrastructure of the execution execUUID once it was
+// compared. It is torn down right away unless a regression was found, in which case it is held
+// for regressionCleanupDelay so that it can be investigated, or until it is cleaned up through
+// the API if the delay is zero.
+func (s *Server) cleanUpAfterComparison(execUUID, benchmarkType string, regression bool) {
+	if !regression {
+		s.releaseInfrastructure(execUUID, benchmarkType)
+		return
+	}
+	if s.regressionCleanupDelay == 0 {
+		slog.Warnf("Holding the infrastructure of execution %s, which regressed, until it is cleaned up through the API: no macrobenchmark is started until then", execUUID)
+		return
+	}
+	slog.Warnf("Holding the infrastructure of execution %s, which regressed, for %s: no macrobenchmark is started until then", execUUID, s.regressionCleanupDelay.String())
+	releaseAt := time.Now().Add(s.regressionCleanupDelay)
+	if err := scheduleInfrastructureRelease(s.dbClient, execUUID, releaseAt); err != nil {
+		slog.Error(err)
+	}
+	s.releaseInfrastructureAt(execUUID, benchmarkType, releaseAt)
+}
+
+// releaseInfrastructureAt calls releaseInfrastructure at the given time.
+func (s *Server) releaseInfrastructureAt(execUUID, benchmarkType string, releaseAt time.Time) {
+	s.queue.ScheduleInfrastructureRelease(execUUID, releaseAt)
+	time.AfterFunc(time.Until(releaseAt), func() {
+		s.releaseInfrastructure(execUUID, benchmarkType)
+	})
+}
+
+// restoreInfrastructureHolds holds again the infrastructure that was held when the server stopped.
+// The holds that had a release time are released at that time, or right away if it passed. The
+// other ones, held until cleaned up through the API or whose comparison was interrupted by the
+// restart, stay held until they are cleaned up through the API.
+func (s *Server) restoreInfrastructureHolds() error {
+	holds, err := getInfrastructureHolds(s.dbClient)
+	if err != nil {
+		return err
+	}
+	for _, hold := range holds {
+		s.queue.HoldInfrastructure(hold.execUUID)
+		if hold.releaseAt == nil {
+			slog.Warnf("The infrastructure of execution %s is held until it is cleaned up through the API: no macrobenchmark is started until then", hold.execUUID)
+			continue
+		}
+		slog.Warnf("The infrastructure of execution %s is held until %s: no macrobenchmark is started until then", hold.execUUID, hold.releaseAt.String())
+		s.releaseInfrastructureAt(hold.execUUID, hold.benchmarkType, *hold.releaseAt)
+	}
+	return nil
+}
+
+// infrastructureHold is a row of the infrastructure_hold table.
+type infrastructureHold struct {
+	execUUID, benchmarkType string
+
+	// releaseAt is the time at which the infrastructure is torn down,
+	// nil if it is held until it is cleaned up through the API.
+	releaseAt *time.Time
+}
+
+// persistInfrastructureHold saves the hold of the infrastructure of the execution execUUID in
+// the infrastructure_hold table, without release time.
+func persistInfrastructureHold(client storage.SQLClient, execUUID, benchmarkType string) error {
+	_, err := client.Insert("INSERT INTO infrastructure_hold(exec_uuid, type) VALUES(?, ?) ON DUPLICATE KEY UPDATE type = VALUES(type), release_at = NULL", execUUID, benchmarkType)
+	return err
+}
+
+// scheduleInfrastructureRelease saves the time at which the held infrastructure of the execution execUUID is torn down.
+func scheduleInfrastructureRelease(client storage.SQLClient, execUUID string, releaseAt time.Time) error {
+	_, err := client.Insert("UPDATE infrastructure_hold SET release_at = ? WHERE exec_uuid = ?", releaseAt.UTC(), execUUID)
+	return err
+}
+
+// deleteInfrastructureHold removes the hold of the execution execUUID from the infrastructure_hold table.
+func deleteInfrastructureHold(client storage.SQLClient, execUUID string) error {
+	_, err := client.Insert("DELETE FROM infrastructure_hold WHERE exec_uuid = ?", execUUID)
+	return err
+}
+
+// getInfrastructureHolds returns all the holds of the infrastructure_hold table, the oldest first.
+func getInfrastructureHolds(client storage.SQLClient) ([]infrastructureHold, error) {
+	rows, err := client.Select("SELECT exec_uuid, type, release_at FROM infrastructure_hold ORDER BY created_at")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holds []infrastructureHold
+	for rows.Next() {
+		var hold infrastructureHold
+		var releaseAt sql.NullTime
+		if err = rows.Scan(&hold.execUUID, &hold.benchmarkType, &releaseAt); err != nil {
+			return nil, err
+		}
+		if releaseAt.Valid {
+			hold.releaseAt = &releaseAt.Time
+		}
+		holds = append(holds, hold)
+	}
+	return holds, nil
+}
@@ -0,0 +1,121 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/vitessio/arewefastyet/go/storage/mysql/mysqltest"
+	"github.com/vitessio/arewefastyet/go/storage/psdb"
+	"go.uber.org/zap"
+)
+
+func TestServer_defersCleanup(t *testing.T) {
+	tests := []struct {
+		name          string
+		enabled       bool
+		benchmarkType string
+		want          bool
+	}{
+		{name: "disabled", enabled: false, benchmarkType: "oltp", want: false},
+		{name: "macrobenchmark", enabled: true, benchmarkType: "oltp", want: true},
+		{name: "microbenchmark", enabled: true, benchmarkType: "micro", want: false},
+		{name: "generic", enabled: true, benchmarkType: "generic", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Server{deferredCleanup: tt.enabled}
+			qt.Assert(t, s.defersCleanup(tt.benchmarkType), qt.Equals, tt.want)
+		})
+	}
+}
+
+func TestServer_cleanUpAfterComparisonHoldsQueue(t *testing.T) {
+	c := qt.New(t)
+	previous := slog
+	SetSLogger(zap.NewNop().Sugar())
+	c.Cleanup(func() { SetSLogger(previous) })
+
+	// the database is unreachable, the holds are only kept in memory
+	s := &Server{deferredCleanup: true, queue: NewQueue(1), dbClient: &psdb.Client{}}
+	next := newTestQueueElement("next")
+	next.identifier.BenchmarkType = "oltp"
+	s.queue.Add(next)
+
+	// the infrastructure is held from the end of the execution until it is compared
+	s.holdInfrastructure("regressed", "oltp")
+	c.Assert(s.queue.Next(), qt.IsNil)
+
+	// a regression holds the infrastructure until it is cleaned up through the API
+	s.cleanUpAfterComparison("regressed", "oltp", true)
+	c.Assert(s.queue.HoldsInfrastructure("regressed"), qt.IsTrue)
+	c.Assert(s.queue.Next(), qt.IsNil)
+
+	s.infrastructureReleased("regressed")
+	c.Assert(s.queue.Next(), qt.Equals, next)
+}
+
+func TestInfrastructureHoldStorage(t *testing.T) {
+	c := qt.New(t)
+	client := mysqltest.New(t)
+
+	c.Assert(persistInfrastructureHold(client, "held", "oltp"), qt.IsNil)
+	c.Assert(persistInfrastructureHold(client, "scheduled", "tpcc"), qt.IsNil)
+	releaseAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	c.Assert(scheduleInfrastructureRelease(client, "scheduled", releaseAt), qt.IsNil)
+
+	holds, err := getInfrastructureHolds(client)
+	c.Assert(err, qt.IsNil)
+	c.Assert(holds, qt.HasLen, 2)
+	byUUID := map[string]infrastructureHold{}
+	for _, hold := range holds {
+		byUUID[hold.execUUID] = hold
+	}
+	c.Assert(byUUID["held"].benchmarkType, qt.Equals, "oltp")
+	c.Assert(byUUID["held"].releaseAt, qt.IsNil)
+	c.Assert(byUUID["scheduled"].benchmarkType, qt.Equals, "tpcc")
+	c.Assert(byUUID["scheduled"].releaseAt, qt.Not(qt.IsNil))
+	c.Assert(byUUID["scheduled"].releaseAt.Equal(releaseAt), qt.IsTrue)
+
+	c.Assert(deleteInfrastructureHold(client, "held"), qt.IsNil)
+	holds, err = getInfrastructureHolds(client)
+	c.Assert(err, qt.IsNil)
+	c.Assert(holds, qt.HasLen, 1)
+	c.Assert(holds[0].execUUID, qt.Equals, "scheduled")
+}
+
+func TestServer_checkInfrastructureUnused(t *testing.T) {
+	c := qt.New(t)
+	s := &Server{queue: NewQueue(1)}
+	c.Assert(s.checkInfrastructureUnused("leaked"), qt.IsNil)
+
+	// the infrastructure held for the execution itself can be cleaned up
+	s.queue.HoldInfrastructure("leaked")
+	c.Assert(s.checkInfrastructureUnused("leaked"), qt.IsNil)
+	c.Assert(s.checkInfrastructureUnused("other"), qt.Not(qt.IsNil))
+	s.queue.ReleaseInfrastructure("leaked")
+
+	macro := newTestQueueElement("macro")
+	macro.identifier.BenchmarkType = "oltp"
+	s.queue.Add(macro)
+	c.Assert(s.queue.Next(), qt.Equals, macro)
+	c.Assert(s.checkInfrastructureUnused("leaked"), qt.Not(qt.IsNil))
+}
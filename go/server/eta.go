@@ -0,0 +1,160 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"sort"
+	"time"
+
+	"github.com/vitessio/arewefastyet/go/exec"
+)
+
+// etaHistory is the period of finished executions from which the average
+// duration of each benchmark type is computed.
+const etaHistory = 30 * 24 * time.Hour
+
+// estimateCompletions estimates when each element of the queue will have finished
+// executing, given the number of elements that can execute concurrently and the average
+// duration of each benchmark type. The elements that already have a finished execution,
+// such as the executing elements waiting for their comparisons, are skipped. The other
+// executing elements are expected to take their average duration since they started, and
+// the waiting elements to start as soon as a slot is free, in the order Queue.Next returns
+// them: the elements provisioning infrastructure come last while infrastructureHeld is set.
+// The elements of a benchmark type without a known duration are estimated with the average
+// of all types, or not at all if no duration is known.
+func estimateCompletions(elements map[executionIdentifier]executionQueueElement, finished map[executionIdentifier]bool, infrastructureHeld bool, concurrency int, durations map[string]time.Duration, now time.Time) map[executionIdentifier]*time.Time {
+	etas := make(map[executionIdentifier]*time.Time, len(elements))
+	fallback := averageDuration(durations)
+	if fallback == 0 {
+		return etas
+	}
+	durationOf := func(benchmarkType string) time.Duration {
+		if d, ok := durations[benchmarkType]; ok && d > 0 {
+			return d
+		}
+		return fallback
+	}
+
+	// slots holds the time at which each execution slot is free, the executing elements
+	// that should already have finished are expected to finish now.
+	var slots []time.Time
+	var waiting []*executionQueueElement
+	for identifier := range elements {
+		if finished[identifier] {
+			continue
+		}
+		element := elements[identifier]
+		if !element.executing {
+			waiting = append(waiting, &element)
+			continue
+		}
+		end := element.startedAt.Add(durationOf(identifier.BenchmarkType))
+		if element.startedAt.IsZero() || end.Before(now) {
+			end = now
+		}
+		etas[identifier] = &end
+		slots = append(slots, end)
+	}
+
+	// only the latest executing elements hold a slot, the others are waiting for their comparisons
+	sort.Slice(slots, func(i, j int) bool { return slots[i].After(slots[j]) })
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if len(slots) > concurrency {
+		slots = slots[:concurrency]
+	}
+	for len(slots) < concurrency {
+		slots = append(slots, now)
+	}
+
+	sort.Slice(waiting, func(i, j int) bool {
+		if infrastructureHeld {
+			iProvisions, jProvisions := provisionsInfrastructure(waiting[i].identifier.BenchmarkType), provisionsInfrastructure(waiting[j].identifier.BenchmarkType)
+			if iProvisions != jProvisions {
+				return jProvisions
+			}
+		}
+		return waitsBefore(waiting[i], waiting[j])
+	})
+	for _, element := range waiting {
+		earliest := 0
+		for i := range slots {
+			if slots[i].Before(slots[earliest]) {
+				earliest = i
+			}
+		}
+		end := slots[earliest].Add(durationOf(element.identifier.BenchmarkType))
+		slots[earliest] = end
+		etas[element.identifier] = &end
+	}
+	return etas
+}
+
+// averageDuration returns the average of the given durations, or zero if there are none.
+func averageDuration(durations map[string]time.Duration) time.Duration {
+	var total time.Duration
+	var count int
+	for _, d := range durations {
+		if d > 0 {
+			total += d
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return total / time.Duration(count)
+}
+
+// getQueueETAs returns the estimated completion time of each element of the queue snapshot,
+// based on the average duration of the executions of the last etaHistory.
+func (s *Server) getQueueETAs(queue map[executionIdentifier]executionQueueElement) (map[executionIdentifier]*time.Time, error) {
+	durations, err := exec.GetAverageDurations(s.readDB(), time.Now().Add(-etaHistory))
+	if err != nil {
+		return nil, err
+	}
+	var gitRefs []string
+	seen := map[string]bool{}
+	for identifier := range queue {
+		if !seen[identifier.GitRef] {
+			seen[identifier.GitRef] = true
+			gitRefs = append(gitRefs, identifier.GitRef)
+		}
+	}
+	executions, err := exec.FindFinishedOfGitRefs(s.readDB(), gitRefs)
+	if err != nil {
+		return nil, err
+	}
+	return estimateCompletions(queue, finishedIdentifiers(executions), s.queue.InfrastructureHeld(), s.queue.MaxRunning(), durations, time.Now()), nil
+}
+
+// finishedIdentifiers returns the identifiers of the given finished executions. Like with
+// exec.GetFinishedExecution, an identifier without planner version matches the executions
+// of any planner version.
+func finishedIdentifiers(executions []*exec.Exec) map[executionIdentifier]bool {
+	finished := map[executionIdentifier]bool{}
+	for _, e := range executions {
+		identifier := executionIdentifier{GitRef: e.GitRef, Source: e.Source, BenchmarkType: e.TypeOf, PullNb: e.PullNB}
+		finished[identifier] = true
+		identifier.PlannerVersion = e.VtgatePlannerVersion
+		finished[identifier] = true
+	}
+	return finished
+}
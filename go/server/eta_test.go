@@ -0,0 +1,107 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/vitessio/arewefastyet/go/exec"
+)
+
+func TestEstimateCompletions(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	durations := map[string]time.Duration{"micro": time.Hour, "oltp": 3 * time.Hour}
+	identifier := func(gitRef, benchmarkType string) executionIdentifier {
+		return executionIdentifier{GitRef: gitRef, Source: "cron", BenchmarkType: benchmarkType}
+	}
+	elements := map[executionIdentifier]executionQueueElement{
+		identifier("running", "oltp"):    {identifier: identifier("running", "oltp"), executing: true, startedAt: now.Add(-time.Hour), seq: 1},
+		identifier("comparing", "micro"): {identifier: identifier("comparing", "micro"), executing: true, startedAt: now.Add(-2 * time.Hour), seq: 2},
+		identifier("low", "micro"):       {identifier: identifier("low", "micro"), lowPriority: true, seq: 3},
+		identifier("first", "micro"):     {identifier: identifier("first", "micro"), seq: 4},
+		identifier("second", "tpcc"):     {identifier: identifier("second", "tpcc"), seq: 5},
+	}
+
+	// the comparing element already has a finished execution
+	finished := map[executionIdentifier]bool{identifier("comparing", "micro"): true}
+
+	tests := []struct {
+		name               string
+		concurrency        int
+		finished           map[executionIdentifier]bool
+		infrastructureHeld bool
+		want               map[string]time.Duration
+	}{
+		{
+			name:        "one slot",
+			concurrency: 1,
+			want:        map[string]time.Duration{"running": 2 * time.Hour, "comparing": 0, "first": 3 * time.Hour, "second": 5 * time.Hour, "low": 6 * time.Hour},
+		},
+		{
+			name:        "two slots",
+			concurrency: 2,
+			want:        map[string]time.Duration{"running": 2 * time.Hour, "comparing": 0, "first": time.Hour, "second": 3 * time.Hour, "low": 3 * time.Hour},
+		},
+		{
+			name:        "finished element skipped",
+			concurrency: 1,
+			finished:    finished,
+			want:        map[string]time.Duration{"running": 2 * time.Hour, "first": 3 * time.Hour, "second": 5 * time.Hour, "low": 6 * time.Hour},
+		},
+		{
+			name:               "infrastructure held",
+			concurrency:        1,
+			finished:           finished,
+			infrastructureHeld: true,
+			want:               map[string]time.Duration{"running": 2 * time.Hour, "first": 3 * time.Hour, "low": 4 * time.Hour, "second": 6 * time.Hour},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			etas := estimateCompletions(elements, tt.finished, tt.infrastructureHeld, tt.concurrency, durations, now)
+			got := map[string]time.Duration{}
+			for id, eta := range etas {
+				got[id.GitRef] = eta.Sub(now)
+			}
+			c.Assert(got, qt.DeepEquals, tt.want)
+		})
+	}
+}
+
+func TestEstimateCompletionsWithoutHistory(t *testing.T) {
+	elements := map[executionIdentifier]executionQueueElement{
+		{GitRef: "a", BenchmarkType: "micro"}: {identifier: executionIdentifier{GitRef: "a", BenchmarkType: "micro"}},
+	}
+	qt.Assert(t, estimateCompletions(elements, nil, false, 1, nil, time.Now()), qt.HasLen, 0)
+}
+
+func TestFinishedIdentifiers(t *testing.T) {
+	finished := finishedIdentifiers([]*exec.Exec{
+		{GitRef: "a", Source: "cron", TypeOf: "micro"},
+		{GitRef: "a", Source: "cron", TypeOf: "oltp", VtgatePlannerVersion: "Gen4"},
+	})
+	qt.Assert(t, finished, qt.DeepEquals, map[executionIdentifier]bool{
+		{GitRef: "a", Source: "cron", BenchmarkType: "micro"}:                        true,
+		{GitRef: "a", Source: "cron", BenchmarkType: "oltp"}:                         true,
+		{GitRef: "a", Source: "cron", BenchmarkType: "oltp", PlannerVersion: "Gen4"}: true,
+	})
+}
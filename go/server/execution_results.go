@@ -0,0 +1,180 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/vitessio/arewefastyet/go/exec"
+	"github.com/vitessio/arewefastyet/go/tools/genericbench"
+	"github.com/vitessio/arewefastyet/go/tools/macrobench"
+	"github.com/vitessio/arewefastyet/go/tools/microbench"
+)
+
+// executionResult is a single result of an execution, in a shape that is
+// uniform across benchmark types: the name of the benchmark and its metrics.
+type executionResult struct {
+	Name    string             `json:"name"`
+	Metrics map[string]float64 `json:"metrics"`
+}
+
+// executionResultsAPIHandler returns the raw results stored for the execution given by the
+// "uuid" path parameter. The results list is empty if the execution has no data yet.
+func (s *Server) executionResultsAPIHandler(c *gin.Context) {
+	execUUID, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	e, err := exec.GetExecution(s.readDB(), execUUID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if e == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+		return
+	}
+
+	results, err := s.getExecutionResults(e.TypeOf, execUUID.String())
+	if err != nil {
+		c.JSON(comparisonErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"uuid":    execUUID.String(),
+		"type":    e.TypeOf,
+		"status":  e.Status,
+		"git_ref": e.GitRef,
+		"results": results,
+	})
+}
+
+// getExecutionResults returns the results of the execution execUUID of the given benchmark type.
+func (s *Server) getExecutionResults(benchmarkType, execUUID string) ([]executionResult, error) {
+	switch benchmarkType {
+	case "micro":
+		micros, err := microbench.GetResultsForExecution(execUUID, s.readDB())
+		if err != nil {
+			return nil, err
+		}
+		return microbenchExecutionResults(micros), nil
+	case "generic":
+		generics, err := genericbench.GetResultsForExecution(execUUID, s.readDB())
+		if err != nil {
+			return nil, err
+		}
+		return genericbenchExecutionResults(generics), nil
+	default:
+		macros, err := macrobench.GetResultsForExecution(macrobench.Type(benchmarkType), execUUID, s.readDB())
+		if err != nil {
+			return nil, err
+		}
+		workloads, err := macrobench.GetWorkloadResultsForExecution(execUUID, s.readDB())
+		if err != nil {
+			return nil, err
+		}
+		results := macrobenchExecutionResults(macrobench.Type(benchmarkType), macros)
+		return append(results, workloadExecutionResults(macrobench.Type(benchmarkType), workloads)...), nil
+	}
+}
+
+func microbenchExecutionResults(micros microbench.DetailsArray) []executionResult {
+	results := []executionResult{}
+	for _, micro := range micros {
+		name := micro.PkgName + "." + micro.Name
+		if micro.SubBenchmarkName != "" {
+			name += "/" + micro.SubBenchmarkName
+		}
+		results = append(results, executionResult{
+			Name: name,
+			Metrics: map[string]float64{
+				"ops":           micro.Result.Ops,
+				"ns_per_op":     micro.Result.NSPerOp,
+				"mb_per_sec":    micro.Result.MBPerSec,
+				"bytes_per_op":  micro.Result.BytesPerOp,
+				"allocs_per_op": micro.Result.AllocsPerOp,
+			},
+		})
+	}
+	return results
+}
+
+func macrobenchExecutionResults(macroType macrobench.Type, macros macrobench.DetailsArray) []executionResult {
+	results := []executionResult{}
+	for _, macro := range macros {
+		metrics := map[string]float64{
+			"tps":                                    macro.Result.TPS,
+			"latency":                                macro.Result.Latency,
+			"errors":                                 macro.Result.Errors,
+			"reconnects":                             macro.Result.Reconnects,
+			"time":                                   float64(macro.Result.Time),
+			"threads":                                macro.Result.Threads,
+			"qps_total":                              macro.Result.QPS.Total,
+			"qps_reads":                              macro.Result.QPS.Reads,
+			"qps_writes":                             macro.Result.QPS.Writes,
+			"qps_other":                              macro.Result.QPS.Other,
+			"total_components_cpu_time":              macro.Metrics.TotalComponentsCPUTime,
+			"total_components_mem_stats_alloc_bytes": macro.Metrics.TotalComponentsMemStatsAllocBytes,
+		}
+		for component, value := range macro.Metrics.ComponentsCPUTime {
+			metrics["components_cpu_time."+component] = value
+		}
+		for component, value := range macro.Metrics.ComponentsMemStatsAllocBytes {
+			metrics["components_mem_stats_alloc_bytes."+component] = value
+		}
+		results = append(results, executionResult{Name: macroType.String(), Metrics: metrics})
+	}
+	return results
+}
+
+// workloadExecutionResults returns the results of the workloads of a mix, named after the
+// macrobenchmark type and their workload.
+func workloadExecutionResults(macroType macrobench.Type, workloads []macrobench.WorkloadResult) []executionResult {
+	results := []executionResult{}
+	for _, workload := range workloads {
+		results = append(results, executionResult{
+			Name: macroType.String() + "/" + workload.Path,
+			Metrics: map[string]float64{
+				"ratio":      workload.Ratio,
+				"tps":        workload.Result.TPS,
+				"latency":    workload.Result.Latency,
+				"errors":     workload.Result.Errors,
+				"reconnects": workload.Result.Reconnects,
+				"time":       float64(workload.Result.Time),
+				"threads":    workload.Result.Threads,
+				"qps_total":  workload.Result.QPS.Total,
+				"qps_reads":  workload.Result.QPS.Reads,
+				"qps_writes": workload.Result.QPS.Writes,
+				"qps_other":  workload.Result.QPS.Other,
+			},
+		})
+	}
+	return results
+}
+
+func genericbenchExecutionResults(generics []genericbench.Result) []executionResult {
+	results := []executionResult{}
+	for _, generic := range generics {
+		results = append(results, executionResult{Name: generic.Name, Metrics: map[string]float64{"value": generic.Value}})
+	}
+	return results
+}
@@ -0,0 +1,89 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/vitessio/arewefastyet/go/exec/metrics"
+	"github.com/vitessio/arewefastyet/go/tools/genericbench"
+	"github.com/vitessio/arewefastyet/go/tools/macrobench"
+	"github.com/vitessio/arewefastyet/go/tools/microbench"
+)
+
+func TestMicrobenchExecutionResults(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(microbenchExecutionResults(nil), qt.DeepEquals, []executionResult{})
+
+	micros := microbench.DetailsArray{
+		{BenchmarkId: microbench.BenchmarkId{PkgName: "sqlparser", Name: "BenchmarkParse", SubBenchmarkName: "simple"}, Result: microbench.Result{Ops: 100, NSPerOp: 12.5}},
+		{BenchmarkId: microbench.BenchmarkId{PkgName: "vtgate", Name: "BenchmarkExecute"}, Result: microbench.Result{AllocsPerOp: 3}},
+	}
+	c.Assert(microbenchExecutionResults(micros), qt.DeepEquals, []executionResult{
+		{Name: "sqlparser.BenchmarkParse/simple", Metrics: map[string]float64{"ops": 100, "ns_per_op": 12.5, "mb_per_sec": 0, "bytes_per_op": 0, "allocs_per_op": 0}},
+		{Name: "vtgate.BenchmarkExecute", Metrics: map[string]float64{"ops": 0, "ns_per_op": 0, "mb_per_sec": 0, "bytes_per_op": 0, "allocs_per_op": 3}},
+	})
+}
+
+func TestMacrobenchExecutionResults(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(macrobenchExecutionResults(macrobench.OLTP, nil), qt.DeepEquals, []executionResult{})
+
+	macros := macrobench.DetailsArray{{
+		Result: macrobench.Result{TPS: 50, Latency: 4.5, Time: 300, QPS: macrobench.QPS{Total: 1000}},
+		Metrics: metrics.ExecutionMetrics{
+			TotalComponentsCPUTime: 20,
+			ComponentsCPUTime:      map[string]float64{"vtgate": 12, "vttablet": 8},
+		},
+	}}
+	results := macrobenchExecutionResults(macrobench.OLTP, macros)
+	c.Assert(results, qt.HasLen, 1)
+	c.Assert(results[0].Name, qt.Equals, "oltp")
+	c.Assert(results[0].Metrics["tps"], qt.Equals, 50.0)
+	c.Assert(results[0].Metrics["time"], qt.Equals, 300.0)
+	c.Assert(results[0].Metrics["qps_total"], qt.Equals, 1000.0)
+	c.Assert(results[0].Metrics["total_components_cpu_time"], qt.Equals, 20.0)
+	c.Assert(results[0].Metrics["components_cpu_time.vtgate"], qt.Equals, 12.0)
+}
+
+func TestWorkloadExecutionResults(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(workloadExecutionResults(macrobench.OLTP, nil), qt.DeepEquals, []executionResult{})
+
+	workloads := []macrobench.WorkloadResult{{
+		Workload: macrobench.Workload{Path: "oltp_read_only", Ratio: 0.7},
+		Result:   macrobench.Result{TPS: 50, Time: 300, QPS: macrobench.QPS{Reads: 700}},
+	}}
+	results := workloadExecutionResults(macrobench.OLTP, workloads)
+	c.Assert(results, qt.HasLen, 1)
+	c.Assert(results[0].Name, qt.Equals, "oltp/oltp_read_only")
+	c.Assert(results[0].Metrics["ratio"], qt.Equals, 0.7)
+	c.Assert(results[0].Metrics["tps"], qt.Equals, 50.0)
+	c.Assert(results[0].Metrics["time"], qt.Equals, 300.0)
+	c.Assert(results[0].Metrics["qps_reads"], qt.Equals, 700.0)
+}
+
+func TestGenericbenchExecutionResults(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(genericbenchExecutionResults(nil), qt.DeepEquals, []executionResult{})
+	c.Assert(genericbenchExecutionResults([]genericbench.Result{{Name: "qps", Value: 1500}}), qt.DeepEquals, []executionResult{
+		{Name: "qps", Metrics: map[string]float64{"value": 1500}},
+	})
+}
@@ -0,0 +1,223 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/vitessio/arewefastyet/go/exec"
+)
+
+// executionResponse is an execution as returned by the executions API. It only exposes
+// the fields describing the execution, under stable names.
+type executionResponse struct {
+	UUID       string     `json:"uuid"`
+	Status     string     `json:"status"`
+	GitRef     string     `json:"git_ref"`
+	Source     string     `json:"source"`
+	Type       string     `json:"type"`
+	PullNb     int        `json:"pull_nb"`
+	GoVersion  string     `json:"go_version"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// newExecutionResponses returns the executionResponse of each of the given executions.
+func newExecutionResponses(executions []*exec.Exec) []executionResponse {
+	responses := make([]executionResponse, 0, len(executions))
+	for _, e := range executions {
+		responses = append(responses, executionResponse{
+			UUID:       e.UUID.String(),
+			Status:     e.Status,
+			GitRef:     e.GitRef,
+			Source:     e.Source,
+			Type:       e.TypeOf,
+			PullNb:     e.PullNB,
+			GoVersion:  e.GolangVersion,
+			StartedAt:  e.StartedAt,
+			FinishedAt: e.FinishedAt,
+			Error:      e.Error,
+		})
+	}
+	return responses
+}
+
+// executionsAPIHandler returns the executions that have the label given by the label_key
+// query parameter. The label_value query parameter optionally restricts the executions
+// to the ones whose label has the given value.
+func (s *Server) executionsAPIHandler(c *gin.Context) {
+	key := c.Query("label_key")
+	if key == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "label_key is required"})
+		return
+	}
+	executions, err := exec.FindByLabel(s.readDB(), key, c.Query("label_value"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, newExecutionResponses(executions))
+}
+
+// failedExecutionsAPIHandler returns the most recent executions that failed, timed out,
+// or failed before starting (exec.StatusPrepareFailed) along with their error.
+// The source query parameter optionally restricts the executions to a single source.
+func (s *Server) failedExecutionsAPIHandler(c *gin.Context) {
+	executions, err := exec.FindFailed(s.readDB(), c.Query("source"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, newExecutionResponses(executions))
+}
+
+// executionSourcesAPIHandler returns the distinct sources of the executions, sorted
+// alphabetically, so that the filters of the UI do not hardcode the known sources.
+func (s *Server) executionSourcesAPIHandler(c *gin.Context) {
+	sources, err := exec.DistinctSources(s.readDB())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sources)
+}
+
+// executionTypesAPIHandler returns the distinct benchmark types of the executions, sorted
+// alphabetically, so that the filters of the UI do not hardcode the known types.
+func (s *Server) executionTypesAPIHandler(c *gin.Context) {
+	types, err := exec.DistinctTypes(s.readDB())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, types)
+}
+
+// executionProfileAPIHandler downloads the CPU profile of vtgate collected during the execution
+// given by the "uuid" path parameter, in the pprof format. Profiles are only collected for the
+// executions that opted in with the exec-profile flag.
+func (s *Server) executionProfileAPIHandler(c *gin.Context) {
+	execUUID, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	profile, err := exec.GetProfile(s.readDB(), execUUID.String(), exec.ProfileVTGate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if profile == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "execution has no profile"})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s-%s.pprof", execUUID, exec.ProfileVTGate))
+	c.Data(http.StatusOK, "application/octet-stream", profile)
+}
+
+// executionInventoryAPIHandler downloads the effective Ansible inventory used by the execution
+// given by the "uuid" path parameter. Passwords, tokens and secrets are redacted before the
+// inventory is returned.
+func (s *Server) executionInventoryAPIHandler(c *gin.Context) {
+	execUUID, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	inventory, err := exec.GetInventory(s.readDB(), execUUID.String())
+	if errors.Is(err, exec.ErrNoInventory) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", inventory)
+}
+
+// executionCleanUpAPIHandler tears down the infrastructure of the execution given by the "uuid"
+// path parameter, for instance when it leaked because the server was killed during the execution.
+// The SSH configuration of the benchmark type of the execution is used to connect to its hosts.
+// Started executions are only cleaned up if the "force" query parameter is true, as they could
+// still be running. Nothing is cleaned up while the hosts are used by an executing element or
+// hold the infrastructure of another execution, even with force, since the clean playbook would
+// tear them down. The infrastructure held by the server, see holdInfrastructure, is released.
+func (s *Server) executionCleanUpAPIHandler(c *gin.Context) {
+	execUUID, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	execution, err := exec.GetExecution(s.readDB(), execUUID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if execution == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+		return
+	}
+	if execution.Status == exec.StatusStarted && c.Query("force") != "true" {
+		c.JSON(http.StatusConflict, gin.H{"error": "the execution is still started, use force=true to clean it up anyway"})
+		return
+	}
+	if !exec.ProvisionsInfrastructure(execution.TypeOf) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": exec.ErrNothingToCleanUp.Error()})
+		return
+	}
+	if err := s.checkInfrastructureUnused(execUUID.String()); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := s.cleanUpExecution(c.Request.Context(), execUUID.String(), execution.TypeOf)
+	if errors.Is(err, exec.ErrNoInventory) || errors.Is(err, exec.ErrNoExecutionDirectory) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	slog.Infof("Cleaned up the infrastructure of execution %s on hosts %v", execUUID, report.Hosts)
+	if s.queue.HoldsInfrastructure(execUUID.String()) {
+		s.infrastructureReleased(execUUID.String())
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// checkInfrastructureUnused returns an error if the hosts are in use, either by an executing
+// element provisioning infrastructure or by the infrastructure held for another execution than
+// execUUID, see holdInfrastructure.
+func (s *Server) checkInfrastructureUnused(execUUID string) error {
+	if s.queue.ExecutesInfrastructure() {
+		return errors.New("an execution provisioning infrastructure is running, retry once it is done")
+	}
+	if s.queue.InfrastructureHeld() && !s.queue.HoldsInfrastructure(execUUID) {
+		return errors.New("the infrastructure of another execution is held, release it first")
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/google/uuid"
+	"github.com/vitessio/arewefastyet/go/exec"
+)
+
+func TestNewExecutionResponses(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(newExecutionResponses(nil), qt.DeepEquals, []executionResponse{})
+
+	startedAt := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	execUUID := uuid.MustParse("0f8fad5b-d9cb-469f-a165-70867728950e")
+	responses := newExecutionResponses([]*exec.Exec{{
+		UUID:          execUUID,
+		Status:        exec.StatusFailed,
+		GitRef:        "abcdef",
+		Source:        exec.SourceCron,
+		TypeOf:        "oltp",
+		PullNB:        42,
+		GolangVersion: "1.18",
+		StartedAt:     &startedAt,
+		Error:         "ansible failed",
+	}})
+	content, err := json.Marshal(responses)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(content), qt.Equals, `[{"uuid":"0f8fad5b-d9cb-469f-a165-70867728950e","status":"failed","git_ref":"abcdef","source":"cron",`+
+		`"type":"oltp","pull_nb":42,"go_version":"1.18","started_at":"2022-03-01T10:00:00Z","finished_at":null,"error":"ansible failed"}]`)
+}
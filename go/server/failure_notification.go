@@ -0,0 +1,113 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vitessio/arewefastyet/go/slack"
+	"github.com/vitessio/arewefastyet/go/tools/git"
+)
+
+// failureRateLimiter limits the number of failure notifications sent for a same key,
+// a single notification is allowed per interval.
+type failureRateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	last     map[string]time.Time
+}
+
+// allow returns true if a notification for key can be sent at the given time,
+// in which case the notification is recorded.
+func (rl *failureRateLimiter) allow(key string, now time.Time) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.last == nil {
+		rl.last = map[string]time.Time{}
+	}
+	if last, ok := rl.last[key]; ok && now.Sub(last) < rl.interval {
+		return false
+	}
+	rl.last[key] = now
+	return true
+}
+
+// notifyExecutionFailure notifies Slack that the execution of the given identifier failed
+// after exhausting its retries. Notifications are rate-limited per source and benchmark type.
+func (s *Server) notifyExecutionFailure(identifier executionIdentifier, execUUID string, attempts int, execErr error) {
+	if !s.notifyFailures {
+		return
+	}
+	if !s.failureRateLimiter.allow(identifier.Source+"/"+identifier.BenchmarkType, time.Now()) {
+		slog.Infof("Failure notification of %+v was rate-limited", identifier)
+		return
+	}
+
+	msg := slack.TextMessage{Content: s.formatExecutionFailure(identifier, execUUID, attempts, execErr)}
+	if err := msg.Send(s.slackConfig); err != nil {
+		slog.Error(err)
+	}
+}
+
+// notifyInvalidExecution notifies Slack that the execution of the given identifier produced
+// implausible results (out of their sanity bounds or failing their verification query), so that
+// someone investigates its configuration.
+func (s *Server) notifyInvalidExecution(identifier executionIdentifier, execUUID string, execErr error) {
+	msg := slack.TextMessage{Content: s.formatInvalidExecution(identifier, execUUID, execErr)}
+	if err := msg.Send(s.slackConfig); err != nil {
+		slog.Error(err)
+	}
+}
+
+func (s *Server) formatInvalidExecution(identifier executionIdentifier, execUUID string, execErr error) string {
+	content := fmt.Sprintf("*Execution invalid.*\nThe %s benchmark of <https://github.com/vitessio/vitess/commit/%s|%s> from source %s produced implausible results, it is excluded from comparisons.\n",
+		identifier.BenchmarkType, identifier.GitRef, git.ShortenSHAN(identifier.GitRef, notificationShortSHALength), identifier.Source)
+	content += s.formatExecutionDetails(identifier, execUUID)
+	content += fmt.Sprintf("```%v```", execErr)
+	return content
+}
+
+func (s *Server) formatExecutionFailure(identifier executionIdentifier, execUUID string, attempts int, execErr error) string {
+	content := fmt.Sprintf("*Execution failed.*\nThe %s benchmark of <https://github.com/vitessio/vitess/commit/%s|%s> from source %s failed after %d attempt(s).\n",
+		identifier.BenchmarkType, identifier.GitRef, git.ShortenSHAN(identifier.GitRef, notificationShortSHALength), identifier.Source, attempts)
+	content += s.formatExecutionDetails(identifier, execUUID)
+	content += fmt.Sprintf("```%v```", execErr)
+	return content
+}
+
+// formatExecutionDetails formats the planner version, the pull request and the logs of an execution.
+func (s *Server) formatExecutionDetails(identifier executionIdentifier, execUUID string) string {
+	var content string
+	if identifier.PlannerVersion != "" {
+		content += fmt.Sprintf("Query planner: %s\n", identifier.PlannerVersion)
+	}
+	if identifier.PullNb > 0 {
+		content += fmt.Sprintf("Pull request: <https://github.com/vitessio/vitess/pull/%d|#%d>\n", identifier.PullNb, identifier.PullNb)
+	}
+	if execUUID != "" {
+		if s.executionLogsURL != "" {
+			content += fmt.Sprintf("Logs: %s/%s\n", s.executionLogsURL, execUUID)
+		} else {
+			content += fmt.Sprintf("Execution: %s\n", execUUID)
+		}
+	}
+	return content
+}
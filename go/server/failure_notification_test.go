@@ -0,0 +1,71 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestFailureRateLimiter_allow(t *testing.T) {
+	c := qt.New(t)
+	rl := failureRateLimiter{interval: time.Hour}
+	now := time.Now()
+
+	c.Assert(rl.allow("cron/oltp", now), qt.IsTrue)
+	c.Assert(rl.allow("cron/oltp", now.Add(30*time.Minute)), qt.IsFalse)
+	c.Assert(rl.allow("cron/micro", now.Add(30*time.Minute)), qt.IsTrue)
+	c.Assert(rl.allow("cron/oltp", now.Add(time.Hour)), qt.IsTrue)
+}
+
+func TestServer_formatExecutionFailure(t *testing.T) {
+	identifier := executionIdentifier{GitRef: "4a70d3d226113282554b393a97f893d133486b94", Source: "cron", BenchmarkType: "oltp", PlannerVersion: "V3"}
+	testcases := []struct {
+		name    string
+		logsURL string
+		out     string
+	}{
+		{
+			name: "Without logs URL",
+			out:  "*Execution failed.*\nThe oltp benchmark of <https://github.com/vitessio/vitess/commit/4a70d3d226113282554b393a97f893d133486b94|4a70d3d226> from source cron failed after 2 attempt(s).\nQuery planner: V3\nExecution: uuid\n```execution step error: timeout```",
+		},
+		{
+			name:    "With logs URL",
+			logsURL: "https://logs.example.com",
+			out:     "*Execution failed.*\nThe oltp benchmark of <https://github.com/vitessio/vitess/commit/4a70d3d226113282554b393a97f893d133486b94|4a70d3d226> from source cron failed after 2 attempt(s).\nQuery planner: V3\nLogs: https://logs.example.com/uuid\n```execution step error: timeout```",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Server{executionLogsURL: tc.logsURL}
+			qt.Assert(t, s.formatExecutionFailure(identifier, "uuid", 2, errors.New("execution step error: timeout")), qt.Equals, tc.out)
+		})
+	}
+}
+
+func TestServer_formatInvalidExecution(t *testing.T) {
+	identifier := executionIdentifier{GitRef: "4a70d3d226113282554b393a97f893d133486b94", Source: "cron", BenchmarkType: "oltp", PullNb: 42}
+	s := &Server{}
+	out := s.formatInvalidExecution(identifier, "uuid", errors.New("implausible results: qps.total is 0, below 1"))
+	qt.Assert(t, out, qt.Equals, "*Execution invalid.*\nThe oltp benchmark of <https://github.com/vitessio/vitess/commit/4a70d3d226113282554b393a97f893d133486b94|4a70d3d226> from source cron produced implausible results, it is excluded from comparisons.\n"+
+		"Pull request: <https://github.com/vitessio/vitess/pull/42|#42>\nExecution: uuid\n```implausible results: qps.total is 0, below 1```")
+}
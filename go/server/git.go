@@ -21,6 +21,7 @@ package server
 import (
 	"io/ioutil"
 	"path"
+	"strings"
 
 	"github.com/vitessio/arewefastyet/go/tools/git"
 )
@@ -47,12 +48,57 @@ func (s *Server) getVitessPath() string {
 	return path.Join(s.localVitessPath, "vitess")
 }
 
-// pullLocalVitess is used to execute
-func (s *Server) pullLocalVitess() error {
+// defaultSourceBranch is the git branch used by the sources that are not in Server.sourceBranches.
+const defaultSourceBranch = "main"
+
+// branchForSource returns the git branch configured for the given source of executions.
+func (s *Server) branchForSource(source string) string {
+	return s.branchForSourceOr(source, defaultSourceBranch)
+}
+
+// branchForSourceOr returns the git branch configured for the given source of executions,
+// or fallback if none is configured.
+func (s *Server) branchForSourceOr(source, fallback string) string {
+	if branch, ok := s.sourceBranches[source]; ok && branch != "" {
+		return branch
+	}
+	return fallback
+}
+
+// checkoutSourceBranch resets the local clone of vitess, which must have been fetched, to the
+// git branch configured for the given source, or to fallback if none is configured, and returns
+// the SHA of its head.
+func (s *Server) checkoutSourceBranch(source, fallback string) (string, error) {
+	_, err := git.ExecCmd(s.getVitessPath(), "git", "reset", "--hard", "origin/"+s.branchForSourceOr(source, fallback))
+	if err != nil {
+		return "", err
+	}
+	return git.GetCommitHash(s.getVitessPath())
+}
+
+// pullLocalVitess fetches the remote and resets the local clone of vitess
+// to the git branch configured for the given source.
+func (s *Server) pullLocalVitess(source string) error {
 	_, err := git.ExecCmd(s.getVitessPath(), "git", "fetch", "origin", "--tags")
 	if err != nil {
 		return err
 	}
-	_, err = git.ExecCmd(s.getVitessPath(), "git", "reset", "--hard", "origin/main")
+	_, err = git.ExecCmd(s.getVitessPath(), "git", "reset", "--hard", "origin/"+s.branchForSource(source))
 	return err
 }
+
+// resolveGitRef returns gitRef, or if it is empty, the SHA of the head of the remote git
+// branch configured for the given source, allowing to benchmark the latest commit of a
+// branch. The branch is fetched without resetting the local clone of vitess.
+func (s *Server) resolveGitRef(gitRef, source string) (string, error) {
+	if gitRef != "" {
+		return gitRef, nil
+	}
+	branch := s.branchForSource(source)
+	_, err := git.ExecCmd(s.getVitessPath(), "git", "fetch", "origin", branch)
+	if err != nil {
+		return "", err
+	}
+	out, err := git.ExecCmd(s.getVitessPath(), "git", "rev-parse", "origin/"+branch)
+	return strings.TrimSpace(string(out)), err
+}
@@ -0,0 +1,106 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vitessio/arewefastyet/go/exec"
+	"github.com/vitessio/arewefastyet/go/tools/git"
+)
+
+const (
+	// gitLabelDescribe labels the executions with the output of git describe for their git ref.
+	gitLabelDescribe = "describe"
+
+	// gitLabelRelease labels the executions of the release branches and release tags with
+	// the release branch of their release line, see releaseLineOfSource.
+	gitLabelRelease = "release"
+)
+
+// gitLabelKeys maps the labels that can be derived from git to the key of the label set on executions.
+var gitLabelKeys = map[string]string{
+	gitLabelDescribe: "git_describe",
+	gitLabelRelease:  "release_line",
+}
+
+// validateGitLabels returns an error if one of the gitLabels cannot be derived from git.
+func (s *Server) validateGitLabels() error {
+	for _, label := range s.gitLabels {
+		if _, ok := gitLabelKeys[label]; !ok {
+			return fmt.Errorf("unknown git label %s, must be one of: %s, %s", label, gitLabelDescribe, gitLabelRelease)
+		}
+	}
+	return nil
+}
+
+// deriveGitLabels returns the labels of gitLabels derived from gitRef, in the local clone of vitess,
+// and from the source of its execution. The labels that cannot be derived, for instance when no tag
+// is reachable from gitRef or when the source is not a release source, are omitted.
+func (s *Server) deriveGitLabels(gitRef, source string) map[string]string {
+	if len(s.gitLabels) == 0 {
+		return nil
+	}
+	derived := map[string]string{}
+	for _, label := range s.gitLabels {
+		switch label {
+		case gitLabelDescribe:
+			described, err := git.Describe(s.getVitessPath(), gitRef)
+			if err != nil {
+				slog.Warnf("Could not describe %s: %v", gitRef, err)
+				continue
+			}
+			derived[gitLabelKeys[label]] = described
+		case gitLabelRelease:
+			if branch, ok := releaseLineOfSource(source); ok {
+				derived[gitLabelKeys[label]] = branch
+			}
+		}
+	}
+	return derived
+}
+
+// releaseLineOfSource returns the release branch of the release line benchmarked by the given
+// source of executions: the branch of a release branch source, or the release branch of the tag
+// of a tag source. False is returned for the other sources, as their git refs are not part of
+// a release line even when a release tag is reachable from them, such as the commits of main.
+func releaseLineOfSource(source string) (string, bool) {
+	if strings.HasPrefix(source, exec.SourceTag) {
+		return git.ReleaseBranchOfTag("v" + strings.TrimPrefix(strings.TrimPrefix(source, exec.SourceTag), "v"))
+	}
+	branch := strings.TrimPrefix(source, exec.SourceReleaseBranch)
+	if branch == source || !strings.HasSuffix(branch, releaseBranchNameSuffix) {
+		return "", false
+	}
+	branch = strings.TrimSuffix(branch, releaseBranchNameSuffix)
+	if !strings.HasPrefix(branch, "release-") {
+		return "", false
+	}
+	return branch, true
+}
+
+// gitLabelsUsage lists the labels that can be derived from git, for the help of the flag.
+func gitLabelsUsage() string {
+	var usage []string
+	for _, label := range []string{gitLabelDescribe, gitLabelRelease} {
+		usage = append(usage, fmt.Sprintf("%s (%s)", label, gitLabelKeys[label]))
+	}
+	return strings.Join(usage, ", ")
+}
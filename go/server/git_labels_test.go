@@ -0,0 +1,68 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/vitessio/arewefastyet/go/exec"
+)
+
+func TestServer_validateGitLabels(t *testing.T) {
+	c := qt.New(t)
+	c.Assert((&Server{}).validateGitLabels(), qt.IsNil)
+	c.Assert((&Server{gitLabels: []string{gitLabelDescribe, gitLabelRelease}}).validateGitLabels(), qt.IsNil)
+	c.Assert((&Server{gitLabels: []string{"milestone"}}).validateGitLabels(), qt.ErrorMatches, "unknown git label milestone, must be one of: describe, release")
+}
+
+func TestReleaseLineOfSource(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{source: exec.SourceReleaseBranch + "release-15.0-branch", want: "release-15.0"},
+		{source: exec.SourceTag + "15.0.2", want: "release-15.0"},
+		{source: exec.SourceTag + "16.0.0-rc1", want: "release-16.0"},
+		{source: exec.SourceCron},
+		{source: exec.SourcePullRequest},
+		{source: exec.SourceBisect},
+		{source: exec.SourceMerge},
+		{source: exec.SourceTag + "foo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			branch, ok := releaseLineOfSource(tt.source)
+			qt.Assert(t, ok, qt.Equals, tt.want != "")
+			qt.Assert(t, branch, qt.Equals, tt.want)
+		})
+	}
+}
+
+func TestServer_deriveGitLabelsDisabled(t *testing.T) {
+	qt.Assert(t, (&Server{}).deriveGitLabels("HEAD", exec.SourceCron), qt.IsNil)
+}
+
+func TestServer_deriveGitLabelsRelease(t *testing.T) {
+	c := qt.New(t)
+	// the release line does not need the local clone of vitess
+	s := &Server{gitLabels: []string{gitLabelRelease}}
+	c.Assert(s.deriveGitLabels("HEAD", exec.SourceCron), qt.DeepEquals, map[string]string{})
+	c.Assert(s.deriveGitLabels("HEAD", exec.SourceReleaseBranch+"release-15.0-branch"), qt.DeepEquals, map[string]string{"release_line": "release-15.0"})
+}
@@ -21,9 +21,12 @@ package server
 import (
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 
 	qt "github.com/frankban/quicktest"
+	"github.com/vitessio/arewefastyet/go/exec"
+	"github.com/vitessio/arewefastyet/go/tools/git"
 )
 
 func TestSetupLocalVitess(t *testing.T) {
@@ -99,6 +102,100 @@ func TestPullLocalVitess(t *testing.T) {
 	}
 	err = s.setupLocalVitess()
 	qt.Assert(t, err, qt.IsNil)
-	err = s.pullLocalVitess()
+	err = s.pullLocalVitess(exec.SourceCron)
 	qt.Assert(t, err, qt.IsNil)
 }
+
+func TestBranchForSource(t *testing.T) {
+	s := Server{
+		sourceBranches: map[string]string{
+			exec.SourceCron:                   "main",
+			exec.SourceReleaseBranch + "14.0": "release-14.0",
+			exec.SourceMerge:                  "",
+		},
+	}
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{source: exec.SourceCron, want: "main"},
+		{source: exec.SourceReleaseBranch + "14.0", want: "release-14.0"},
+		{source: exec.SourceMerge, want: defaultSourceBranch},
+		{source: exec.SourceTag, want: defaultSourceBranch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			qt.Assert(t, s.branchForSource(tt.source), qt.Equals, tt.want)
+		})
+	}
+}
+
+func TestServer_resolveGitRef(t *testing.T) {
+	c := qt.New(t)
+	run := func(dir string, args ...string) string {
+		out, err := git.ExecCmd(dir, "git", args...)
+		c.Assert(err, qt.IsNil, qt.Commentf("git %v: %s", args, out))
+		return strings.TrimSpace(string(out))
+	}
+	commit := func(dir string) string {
+		run(dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "commit")
+		return run(dir, "rev-parse", "HEAD")
+	}
+
+	remote := c.TempDir()
+	run(remote, "init", "-b", "main")
+	commit(remote)
+	s := Server{localVitessPath: c.TempDir()}
+	run(s.localVitessPath, "clone", remote, "vitess")
+	head := commit(remote)
+
+	gitRef, err := s.resolveGitRef("", exec.SourceCron)
+	c.Assert(err, qt.IsNil)
+	c.Assert(gitRef, qt.Equals, head)
+
+	gitRef, err = s.resolveGitRef("abcdef", exec.SourceCron)
+	c.Assert(err, qt.IsNil)
+	c.Assert(gitRef, qt.Equals, "abcdef")
+
+	s.sourceBranches = map[string]string{exec.SourceCron: "unknown"}
+	_, err = s.resolveGitRef("", exec.SourceCron)
+	c.Assert(err, qt.IsNotNil)
+}
+
+func TestServer_checkoutSourceBranch(t *testing.T) {
+	c := qt.New(t)
+	run := func(dir string, args ...string) string {
+		out, err := git.ExecCmd(dir, "git", args...)
+		c.Assert(err, qt.IsNil, qt.Commentf("git %v: %s", args, out))
+		return strings.TrimSpace(string(out))
+	}
+	commit := func(dir string) string {
+		run(dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "commit")
+		return run(dir, "rev-parse", "HEAD")
+	}
+
+	remote := c.TempDir()
+	run(remote, "init", "-b", "main")
+	main := commit(remote)
+	run(remote, "checkout", "-b", "release-14.0")
+	release := commit(remote)
+	run(remote, "checkout", "-b", "release-14.0-fixes")
+	fixes := commit(remote)
+	s := Server{localVitessPath: c.TempDir()}
+	run(s.localVitessPath, "clone", remote, "vitess")
+	source := exec.SourceReleaseBranch + "release-14.0" + releaseBranchNameSuffix
+
+	ref, err := s.checkoutSourceBranch(source, "release-14.0")
+	c.Assert(err, qt.IsNil)
+	c.Assert(ref, qt.Equals, release)
+
+	s.sourceBranches = map[string]string{source: "release-14.0-fixes"}
+	ref, err = s.checkoutSourceBranch(source, "release-14.0")
+	c.Assert(err, qt.IsNil)
+	c.Assert(ref, qt.Equals, fixes)
+	c.Assert(run(s.getVitessPath(), "rev-parse", "HEAD"), qt.Equals, fixes)
+
+	ref, err = s.checkoutSourceBranch(exec.SourceCron, defaultSourceBranch)
+	c.Assert(err, qt.IsNil)
+	c.Assert(ref, qt.Equals, main)
+}
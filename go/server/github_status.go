@@ -0,0 +1,81 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"fmt"
+
+	"github.com/vitessio/arewefastyet/go/tools/git"
+)
+
+// commitStatusContext is the prefix of the context of the commit statuses reported on GitHub.
+const commitStatusContext = "arewefastyet"
+
+// reportCommitStatus reports the comparisons of a pull request's element against its baselines
+// as a status of its commit on GitHub. The status is a failure if any baseline observed a
+// regression, and links to the comparison against that baseline. Nothing is reported if no
+// GitHub token is configured, if the element is not a pull request, or if no comparison was made.
+func (s *Server) reportCommitStatus(element *executionQueueElement, reports []baselineReport) {
+	if s.githubToken == "" || element.identifier.PullNb == 0 {
+		return
+	}
+	status, ok := commitStatus(element.identifier, reports)
+	if !ok {
+		return
+	}
+	if err := git.SetCommitStatus(s.githubToken, s.githubStatusRepo, element.identifier.GitRef, status); err != nil {
+		slog.Error(err)
+	}
+}
+
+// commitStatus returns the commit status summarizing the given reports of the element with the
+// given identifier. It returns false if every comparison was skipped.
+func commitStatus(identifier executionIdentifier, reports []baselineReport) (git.CommitStatus, bool) {
+	context := commitStatusContext + "/" + identifier.BenchmarkType
+	if identifier.PlannerVersion != "" {
+		context += "/" + identifier.PlannerVersion
+	}
+
+	var compared *baselineReport
+	for i, br := range reports {
+		if br.skipped != "" {
+			continue
+		}
+		if compared == nil {
+			compared = &reports[i]
+		}
+		if br.report.Regression != "" {
+			return git.CommitStatus{
+				State:       git.StatusStateFailure,
+				TargetURL:   getComparisonLink(identifier.GitRef, br.baseline.GitRef),
+				Description: fmt.Sprintf("Regression against %s", git.ShortenSHAN(br.baseline.GitRef, notificationShortSHALength)),
+				Context:     context,
+			}, true
+		}
+	}
+	if compared == nil {
+		return git.CommitStatus{}, false
+	}
+	return git.CommitStatus{
+		State:       git.StatusStateSuccess,
+		TargetURL:   getComparisonLink(identifier.GitRef, compared.baseline.GitRef),
+		Description: fmt.Sprintf("No regression against %s", git.ShortenSHAN(compared.baseline.GitRef, notificationShortSHALength)),
+		Context:     context,
+	}, true
+}
@@ -0,0 +1,74 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/vitessio/arewefastyet/go/exec"
+	"github.com/vitessio/arewefastyet/go/tools/git"
+)
+
+func TestCommitStatus(t *testing.T) {
+	identifier := executionIdentifier{GitRef: "head", Source: exec.SourcePullRequest, BenchmarkType: "oltp", PlannerVersion: "Gen4", PullNb: 42}
+	base := executionIdentifier{GitRef: "base", Source: exec.SourcePullRequestBase, BenchmarkType: "oltp", PlannerVersion: "Gen4", PullNb: 42}
+	tests := []struct {
+		name    string
+		reports []baselineReport
+		want    git.CommitStatus
+		wantOK  bool
+	}{
+		{name: "no comparison"},
+		{name: "skipped comparison", reports: []baselineReport{{baseline: base, skipped: "different durations"}}},
+		{
+			name:    "no regression",
+			reports: []baselineReport{{baseline: base, report: ComparisonReport{Improvement: "- TPS increased by 10.00%\n"}}},
+			want: git.CommitStatus{
+				State:       git.StatusStateSuccess,
+				TargetURL:   getComparisonLink("head", "base"),
+				Description: "No regression against base",
+				Context:     "arewefastyet/oltp/Gen4",
+			},
+			wantOK: true,
+		},
+		{
+			name: "regression",
+			reports: []baselineReport{
+				{baseline: executionIdentifier{GitRef: "other"}},
+				{baseline: base, report: ComparisonReport{Regression: "- TPS decreased by 10.00%\n"}},
+			},
+			want: git.CommitStatus{
+				State:       git.StatusStateFailure,
+				TargetURL:   getComparisonLink("head", "base"),
+				Description: "Regression against base",
+				Context:     "arewefastyet/oltp/Gen4",
+			},
+			wantOK: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			got, ok := commitStatus(identifier, tt.reports)
+			c.Assert(ok, qt.Equals, tt.wantOK)
+			c.Assert(got, qt.Equals, tt.want)
+		})
+	}
+}
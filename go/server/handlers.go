@@ -44,12 +44,12 @@ func handleRenderErrors(c *gin.Context, err error) {
 func (s *Server) cronHandler(c *gin.Context) {
 	planner := getPlannerVersion(c)
 
-	oltpData, err := macrobench.GetResultsForLastDays(macrobench.OLTP, "cron", planner, 31, s.dbClient)
+	oltpData, err := macrobench.GetResultsForLastDays(macrobench.OLTP, "cron", planner, 31, s.readDB())
 	if err != nil {
 		slog.Warn(err.Error())
 	}
 
-	tpccData, err := macrobench.GetResultsForLastDays(macrobench.TPCC, "cron", planner, 31, s.dbClient)
+	tpccData, err := macrobench.GetResultsForLastDays(macrobench.TPCC, "cron", planner, 31, s.readDB())
 	if err != nil {
 		slog.Warn(err.Error())
 	}
@@ -81,15 +81,24 @@ func (s *Server) homeHandler(c *gin.Context) {
 }
 
 func (s *Server) statusHandler(c *gin.Context) {
-	recentExecutions, err := exec.GetRecentExecutions(s.dbClient)
+	recentExecutions, err := exec.GetRecentExecutions(s.readDB())
 	if err != nil {
 		handleRenderErrors(c, err)
 		return
 	}
+	queue := s.queue.Snapshot()
+	// the estimations are only informative, the page is rendered without them if they fail
+	etas, err := s.getQueueETAs(queue)
+	if err != nil {
+		slog.Warnf("Could not estimate the completion of the queue: %v", err)
+	}
 	c.HTML(http.StatusOK, "status.tmpl", gin.H{
 		"title":      "Vitess benchmark - Status",
 		"queue":      queue,
+		"etas":       etas,
 		"executions": recentExecutions,
+		// the macrobenchmarks are not started while infrastructure is held, see Queue.Next
+		"heldInfrastructure": s.queue.HeldInfrastructure(),
 	})
 }
 
@@ -116,14 +125,14 @@ func (s *Server) compareHandler(c *gin.Context) {
 	}
 
 	// Compare Macrobenchmarks for the two given SHAs.
-	macrosMatrices, err := macrobench.CompareMacroBenchmarks(s.dbClient, reference, compare, planner)
+	macrosMatrices, err := macrobench.CompareMacroBenchmarks(s.readDB(), reference, compare, planner)
 	if err != nil {
 		handleRenderErrors(c, err)
 		return
 	}
 
 	// Compare Microbenchmarks for the two given SHAs.
-	microsMatrix, err := microbench.Compare(s.dbClient, reference, compare)
+	microsMatrix, err := microbench.Compare(s.readDB(), reference, compare)
 	if err != nil {
 		handleRenderErrors(c, err)
 		return
@@ -148,13 +157,16 @@ func (s *Server) searchHandler(c *gin.Context) {
 		return
 	}
 
-	macros, err := macrobench.GetDetailsArraysFromAllTypes(search, planner, s.dbClient)
+	macros, err := macrobench.GetDetailsArraysFromAllTypes(search, planner, s.readDB())
 	if err != nil {
 		handleRenderErrors(c, err)
 		return
 	}
+	for mtype := range macros {
+		macros[mtype] = macros[mtype].ReduceSimpleMedian()
+	}
 
-	micro, err := microbench.GetResultsForGitRef(search, s.dbClient)
+	micro, err := microbench.GetResultsForGitRef(search, s.readDB())
 	if err != nil {
 		handleRenderErrors(c, err)
 		return
@@ -185,7 +197,7 @@ func (s *Server) microbenchmarkResultsHandler(c *gin.Context) {
 		handleRenderErrors(c, err)
 		return
 	}
-	lastrunCronSHA, err := exec.GetLatestCronJobForMicrobenchmarks(s.dbClient)
+	lastrunCronSHA, err := exec.GetLatestCronJobForMicrobenchmarks(s.readDB())
 	if err != nil {
 		handleRenderErrors(c, err)
 		return
@@ -239,13 +251,13 @@ func (s *Server) microbenchmarkResultsHandler(c *gin.Context) {
 	}
 
 	// Get the results from the SHAs
-	leftMbd, err := microbench.GetResultsForGitRef(leftSHA, s.dbClient)
+	leftMbd, err := microbench.GetResultsForGitRef(leftSHA, s.readDB())
 	if err != nil {
 		handleRenderErrors(c, err)
 		return
 	}
 	leftMbd = leftMbd.ReduceSimpleMedianByName()
-	rightMbd, err := microbench.GetResultsForGitRef(rightSHA, s.dbClient)
+	rightMbd, err := microbench.GetResultsForGitRef(rightSHA, s.readDB())
 	if err != nil {
 		handleRenderErrors(c, err)
 		return
@@ -277,7 +289,7 @@ func (s *Server) microbenchmarkSingleResultsHandler(c *gin.Context) {
 	name := c.Param("name")
 	subBenchmarkName := c.Query("subBenchmarkName")
 
-	results, err := microbench.GetLatestResultsFor(name, subBenchmarkName, 10, s.dbClient)
+	results, err := microbench.GetLatestResultsFor(name, subBenchmarkName, 10, s.readDB())
 	if err != nil {
 		handleRenderErrors(c, err)
 		return
@@ -301,7 +313,7 @@ func (s *Server) macrobenchmarkResultsHandler(c *gin.Context) {
 		handleRenderErrors(c, err)
 		return
 	}
-	lastrunCronSHA, err := exec.GetLatestCronJobForMacrobenchmarks(s.dbClient)
+	lastrunCronSHA, err := exec.GetLatestCronJobForMacrobenchmarks(s.readDB())
 	if err != nil {
 		handleRenderErrors(c, err)
 		return
@@ -355,7 +367,7 @@ func (s *Server) macrobenchmarkResultsHandler(c *gin.Context) {
 	}
 
 	// Compare Macrobenchmarks for the two given SHAs.
-	macrosMatrices, err := macrobench.CompareMacroBenchmarks(s.dbClient, rightSHA, leftSHA, planner)
+	macrosMatrices, err := macrobench.CompareMacroBenchmarks(s.readDB(), rightSHA, leftSHA, planner)
 	if err != nil {
 		handleRenderErrors(c, err)
 		return
@@ -383,7 +395,7 @@ func (s *Server) macrobenchmarkQueriesDetails(c *gin.Context) {
 		})
 		return
 	}
-	plans, err := macrobench.GetVTGateSelectQueryPlansWithFilter(gitRef, macroType, planner, s.dbClient)
+	plans, err := macrobench.GetVTGateSelectQueryPlansWithFilter(gitRef, macroType, planner, s.readDB())
 	if err != nil {
 		handleRenderErrors(c, err)
 		return
@@ -424,12 +436,12 @@ func (s *Server) macrobenchmarkCompareQueriesDetails(c *gin.Context) {
 		rightPlanner = planner
 	}
 
-	plansLeft, err := macrobench.GetVTGateSelectQueryPlansWithFilter(leftGitRef, macroType, leftPlanner, s.dbClient)
+	plansLeft, err := macrobench.GetVTGateSelectQueryPlansWithFilter(leftGitRef, macroType, leftPlanner, s.readDB())
 	if err != nil {
 		handleRenderErrors(c, err)
 		return
 	}
-	plansRight, err := macrobench.GetVTGateSelectQueryPlansWithFilter(rightGitRef, macroType, rightPlanner, s.dbClient)
+	plansRight, err := macrobench.GetVTGateSelectQueryPlansWithFilter(rightGitRef, macroType, rightPlanner, s.readDB())
 	if err != nil {
 		handleRenderErrors(c, err)
 		return
@@ -461,7 +473,7 @@ func (s *Server) v3VsGen4Handler(c *gin.Context) {
 		handleRenderErrors(c, err)
 		return
 	}
-	lastrunCronSHA, err := exec.GetLatestCronJobForMacrobenchmarks(s.dbClient)
+	lastrunCronSHA, err := exec.GetLatestCronJobForMacrobenchmarks(s.readDB())
 	if err != nil {
 		handleRenderErrors(c, err)
 		return
@@ -495,7 +507,7 @@ func (s *Server) v3VsGen4Handler(c *gin.Context) {
 	}
 
 	// Compare Macrobenchmarks for the two planners for the given SHA.
-	macrosMatrices, err := macrobench.ComparePlanners(s.dbClient, sha)
+	macrosMatrices, err := macrobench.ComparePlanners(s.readDB(), sha)
 	if err != nil {
 		handleRenderErrors(c, err)
 		return
@@ -0,0 +1,133 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vitessio/arewefastyet/go/exec"
+	"github.com/vitessio/arewefastyet/go/tools/git"
+	"github.com/vitessio/arewefastyet/go/tools/macrobench"
+)
+
+// commitSHARegexp matches the full SHA of a git commit.
+var commitSHARegexp = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// mergeWebhookPayload contains the fields we use from GitHub's pull_request event.
+type mergeWebhookPayload struct {
+	Action      string `json:"action"`
+	PullRequest struct {
+		Number         int    `json:"number"`
+		Merged         bool   `json:"merged"`
+		MergeCommitSHA string `json:"merge_commit_sha"`
+	} `json:"pull_request"`
+}
+
+// mergeWebhookHandler handles GitHub's pull_request events. When a pull request
+// is merged, its merge commit is benchmarked and compared against its first parent,
+// which isolates the impact of the merged pull request.
+func (s *Server) mergeWebhookHandler(c *gin.Context) {
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !s.isValidWebhookSignature(body, c.GetHeader("X-Hub-Signature-256")) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid signature"})
+		return
+	}
+
+	var payload mergeWebhookPayload
+	err = json.Unmarshal(body, &payload)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if payload.Action != "closed" || !payload.PullRequest.Merged || payload.PullRequest.MergeCommitSHA == "" {
+		c.JSON(http.StatusOK, gin.H{"status": "ignored"})
+		return
+	}
+	if !commitSHARegexp.MatchString(payload.PullRequest.MergeCommitSHA) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid merge commit SHA"})
+		return
+	}
+
+	go s.mergeHandler(payload.PullRequest.MergeCommitSHA)
+	c.JSON(http.StatusAccepted, gin.H{"status": "queued", "git_ref": payload.PullRequest.MergeCommitSHA})
+}
+
+// isValidWebhookSignature verifies the HMAC signature GitHub computes with the
+// webhook's secret. All requests are rejected if no secret is configured.
+func (s *Server) isValidWebhookSignature(body []byte, signature string) bool {
+	if s.mergeWebhookSecret == "" {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(s.mergeWebhookSecret))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(strings.TrimPrefix(signature, "sha256=")), []byte(expected))
+}
+
+// mergeHandler adds the given merge commit to the queue, it will be compared
+// against the first parent of the merge commit.
+func (s *Server) mergeHandler(ref string) {
+	s.vitessPathMu.Lock()
+	err := s.pullLocalVitess(exec.SourceMerge)
+	if err != nil {
+		s.vitessPathMu.Unlock()
+		slog.Error(err.Error())
+		return
+	}
+	parentRef, err := git.GetParentCommitHash(s.getVitessPath(), ref)
+	s.vitessPathMu.Unlock()
+	if err != nil {
+		slog.Error(err.Error())
+		return
+	}
+
+	var elements []*executionQueueElement
+	for configType, configFile := range s.getConfigFiles() {
+		if configType == "micro" {
+			elements = append(elements, s.createMergeElementWithParentComparison(configFile, ref, configType, parentRef, "")...)
+		} else {
+			for _, version := range macrobench.PlannerVersions {
+				elements = append(elements, s.createMergeElementWithParentComparison(configFile, ref, configType, parentRef, version)...)
+			}
+		}
+	}
+	for _, element := range elements {
+		s.addToQueue(element)
+	}
+}
+
+func (s *Server) createMergeElementWithParentComparison(configFile, ref, configType, parentRef string, version macrobench.PlannerVersion) []*executionQueueElement {
+	newExecutionElement := s.createSimpleExecutionQueueElement(exec.SourceMerge, configFile, ref, configType, string(version), false, 0)
+	parentElement := s.createSimpleExecutionQueueElement(exec.SourceMergeParent, configFile, parentRef, configType, string(version), false, 0)
+	parentElement.compareWith = append(parentElement.compareWith, newExecutionElement.identifier)
+	newExecutionElement.compareWith = append(newExecutionElement.compareWith, parentElement.identifier)
+	return []*executionQueueElement{newExecutionElement, parentElement}
+}
@@ -0,0 +1,88 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gin-gonic/gin"
+)
+
+func TestServer_isValidWebhookSignature(t *testing.T) {
+	body := []byte(`{"action":"closed"}`)
+	testcases := []struct {
+		name      string
+		secret    string
+		signature string
+		valid     bool
+	}{
+		{name: "No secret", secret: "", signature: "", valid: false},
+		{name: "Valid signature", secret: "secret", signature: "sha256=336cf634bffeed63498de4350ea7c1c1ad9ecb668d04a357794118841e02c3db", valid: true},
+		{name: "Invalid signature", secret: "another secret", signature: "sha256=336cf634bffeed63498de4350ea7c1c1ad9ecb668d04a357794118841e02c3db", valid: false},
+		{name: "Missing signature", secret: "secret", signature: "", valid: false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := qt.New(t)
+			s := &Server{mergeWebhookSecret: tc.secret}
+			c.Assert(s.isValidWebhookSignature(body, tc.signature), qt.Equals, tc.valid)
+		})
+	}
+}
+
+func TestServer_mergeWebhookHandler(t *testing.T) {
+	sign := func(secret, body string) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(body))
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+	testcases := []struct {
+		name       string
+		secret     string
+		body       string
+		wantStatus int
+	}{
+		{name: "No secret", secret: "", body: `{"action":"closed","pull_request":{"merged":true,"merge_commit_sha":"0123456789abcdef0123456789abcdef01234567"}}`, wantStatus: http.StatusUnauthorized},
+		{name: "Not merged", secret: "secret", body: `{"action":"closed","pull_request":{"merged":false}}`, wantStatus: http.StatusOK},
+		{name: "Invalid SHA", secret: "secret", body: `{"action":"closed","pull_request":{"merged":true,"merge_commit_sha":"--upload-pack=touch"}}`, wantStatus: http.StatusBadRequest},
+		{name: "Short SHA", secret: "secret", body: `{"action":"closed","pull_request":{"merged":true,"merge_commit_sha":"0123456"}}`, wantStatus: http.StatusBadRequest},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := qt.New(t)
+			s := &Server{mergeWebhookSecret: tc.secret}
+			router := gin.New()
+			router.POST("/webhook/merge", s.mergeWebhookHandler)
+
+			req := httptest.NewRequest(http.MethodPost, "/webhook/merge", strings.NewReader(tc.body))
+			req.Header.Set("X-Hub-Signature-256", sign("secret", tc.body))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			c.Assert(rec.Code, qt.Equals, tc.wantStatus)
+		})
+	}
+}
@@ -0,0 +1,165 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vitessio/arewefastyet/go/tools/macrobench"
+	"github.com/vitessio/arewefastyet/go/tools/microbench"
+)
+
+// metricSet is the set of metrics of a benchmark type considered by the regression detection.
+// Primary is the headline metric of the type, its changes are reported first. The changes of
+// the metrics that are not in the set are only reported as secondary detail.
+type metricSet struct {
+	Primary string
+	Metrics []string
+}
+
+// comparisonMetricsOf returns the metrics whose changes can be reported for the given benchmark type.
+func comparisonMetricsOf(benchmarkType string) []string {
+	if benchmarkType == "micro" {
+		return microbench.ComparisonMetrics
+	}
+	return macrobench.ComparisonMetrics
+}
+
+// parseMetricSet parses the metric set of the given benchmark type, given as a list of metrics
+// separated by a "+", the first one being the primary metric (e.g. "qps.total+latency").
+func parseMetricSet(benchmarkType, value string) (metricSet, error) {
+	known := map[string]bool{}
+	for _, metric := range comparisonMetricsOf(benchmarkType) {
+		known[metric] = true
+	}
+	var set metricSet
+	seen := map[string]bool{}
+	for _, metric := range strings.Split(value, "+") {
+		metric = strings.TrimSpace(metric)
+		if !known[metric] {
+			return metricSet{}, fmt.Errorf("unknown metric %q in the metric set of %s, must be one of %s", metric, benchmarkType, strings.Join(comparisonMetricsOf(benchmarkType), ", "))
+		}
+		if seen[metric] {
+			continue
+		}
+		seen[metric] = true
+		set.Metrics = append(set.Metrics, metric)
+	}
+	set.Primary = set.Metrics[0]
+	return set, nil
+}
+
+// getMetricSets parses the configured metric sets of the benchmark types.
+func (s *Server) getMetricSets() (map[string]metricSet, error) {
+	sets := make(map[string]metricSet, len(s.metricSets))
+	for benchmarkType, value := range s.metricSets {
+		set, err := parseMetricSet(benchmarkType, value)
+		if err != nil {
+			return nil, err
+		}
+		sets[benchmarkType] = set
+	}
+	return sets, nil
+}
+
+// getMetricSet returns the metric set of the given benchmark type. Its Primary is empty if
+// the type has no metric set, in which case all the metrics are considered equally.
+func (s *Server) getMetricSet(benchmarkType string) (metricSet, error) {
+	sets, err := s.getMetricSets()
+	if err != nil {
+		return metricSet{}, err
+	}
+	return sets[benchmarkType], nil
+}
+
+// secondary returns the metrics of the given benchmark type that are not in the metricSet.
+func (set metricSet) secondary(benchmarkType string) []string {
+	listed := map[string]bool{}
+	for _, metric := range set.Metrics {
+		listed[metric] = true
+	}
+	var metrics []string
+	for _, metric := range comparisonMetricsOf(benchmarkType) {
+		if !listed[metric] {
+			metrics = append(metrics, metric)
+		}
+	}
+	return metrics
+}
+
+// report builds the ComparisonReport of a comparison using the metricSet. changes returns the reason
+// of the regressions, or of the improvements if improvement is set, of the given metrics. The changes
+// of the primary metric come first, the regressions of the other metrics of the type are only reported
+// in the Secondary field of the report.
+func (set metricSet) report(benchmarkType string, changes func(metrics []string, improvement bool) string) ComparisonReport {
+	if set.Primary == "" {
+		return ComparisonReport{
+			Regression:  changes(nil, false),
+			Improvement: changes(nil, true),
+		}
+	}
+	report := ComparisonReport{PrimaryMetric: set.Primary}
+	for _, metrics := range [][]string{{set.Primary}, set.Metrics[1:]} {
+		if len(metrics) == 0 {
+			continue
+		}
+		report.Regression += changes(metrics, false)
+		report.Improvement += changes(metrics, true)
+	}
+	if secondary := set.secondary(benchmarkType); len(secondary) > 0 {
+		report.Secondary = changes(secondary, false)
+	}
+	return report
+}
+
+// macroComparisonReport returns the ComparisonReport of the macrobenchmark Comparison of the given
+// benchmark type, using the type's metric set.
+func (s *Server) macroComparisonReport(benchmarkType string, comparison macrobench.Comparison) (ComparisonReport, error) {
+	set, err := s.getMetricSet(benchmarkType)
+	if err != nil {
+		return ComparisonReport{}, err
+	}
+	report := set.report(benchmarkType, func(metrics []string, improvement bool) string {
+		if improvement {
+			return comparison.ImprovementOfMetrics(metrics)
+		}
+		return comparison.RegressionOfMetrics(metrics)
+	})
+	report.Confidence = string(comparison.Confidence)
+	return report, nil
+}
+
+// microComparisonReport returns the ComparisonReport of the given microbenchmark comparisons,
+// using the metric set of microbenchmarks and the configured thresholds.
+func (s *Server) microComparisonReport(comparisons microbench.ComparisonArray) (ComparisonReport, error) {
+	thresholds, err := s.getMicrobenchThresholds()
+	if err != nil {
+		return ComparisonReport{}, err
+	}
+	set, err := s.getMetricSet("micro")
+	if err != nil {
+		return ComparisonReport{}, err
+	}
+	return set.report("micro", func(metrics []string, improvement bool) string {
+		if improvement {
+			return comparisons.ImprovementOfMetrics(thresholds, metrics)
+		}
+		return comparisons.RegressionOfMetrics(thresholds, metrics)
+	}), nil
+}
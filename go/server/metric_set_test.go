@@ -0,0 +1,114 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+package server
+
+import (
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestParseMetricSet(t *testing.T) {
+	tests := []struct {
+		name          string
+		benchmarkType string
+		value         string
+		want          metricSet
+		wantErr       string
+	}{
+		{name: "Microbenchmarks", benchmarkType: "micro", value: "ns/op", want: metricSet{Primary: "ns/op", Metrics: []string{"ns/op"}}},
+		{name: "Primary metric first", benchmarkType: "oltp", value: "qps.total+latency", want: metricSet{Primary: "qps.total", Metrics: []string{"qps.total", "latency"}}},
+		{name: "Duplicated metric", benchmarkType: "tpcc", value: "tps+tps", want: metricSet{Primary: "tps", Metrics: []string{"tps"}}},
+		{name: "Unknown metric", benchmarkType: "oltp", value: "ns/op", wantErr: `unknown metric "ns/op" in the metric set of oltp, must be one of cpu_time, tps, qps.total, latency`},
+		{name: "Empty", benchmarkType: "oltp", value: "", wantErr: `unknown metric "" in the metric set of oltp, must be one of cpu_time, tps, qps.total, latency`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			got, err := parseMetricSet(tt.benchmarkType, tt.value)
+			if tt.wantErr != "" {
+				c.Assert(err, qt.ErrorMatches, tt.wantErr)
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			c.Assert(got, qt.DeepEquals, tt.want)
+		})
+	}
+}
+
+func TestMetricSet_report(t *testing.T) {
+	// changes reports a change of every metric it is given, or of all the metrics if none is given
+	changes := func(metrics []string, improvement bool) string {
+		if len(metrics) == 0 {
+			metrics = comparisonMetricsOf("oltp")
+		}
+		verb := "regressed"
+		if improvement {
+			verb = "improved"
+		}
+		return "- " + strings.Join(metrics, ", ") + " " + verb + "\n"
+	}
+	tests := []struct {
+		name string
+		set  metricSet
+		want ComparisonReport
+	}{
+		{
+			name: "No metric set",
+			want: ComparisonReport{
+				Regression:  "- cpu_time, tps, qps.total, latency regressed\n",
+				Improvement: "- cpu_time, tps, qps.total, latency improved\n",
+			},
+		},
+		{
+			name: "Primary metric only",
+			set:  metricSet{Primary: "tps", Metrics: []string{"tps"}},
+			want: ComparisonReport{
+				PrimaryMetric: "tps",
+				Regression:    "- tps regressed\n",
+				Improvement:   "- tps improved\n",
+				Secondary:     "- cpu_time, qps.total, latency regressed\n",
+			},
+		},
+		{
+			name: "Primary metric first",
+			set:  metricSet{Primary: "qps.total", Metrics: []string{"qps.total", "latency"}},
+			want: ComparisonReport{
+				PrimaryMetric: "qps.total",
+				Regression:    "- qps.total regressed\n- latency regressed\n",
+				Improvement:   "- qps.total improved\n- latency improved\n",
+				Secondary:     "- cpu_time, tps regressed\n",
+			},
+		},
+		{
+			name: "All metrics listed",
+			set:  metricSet{Primary: "latency", Metrics: []string{"latency", "cpu_time", "tps", "qps.total"}},
+			want: ComparisonReport{
+				PrimaryMetric: "latency",
+				Regression:    "- latency regressed\n- cpu_time, tps, qps.total regressed\n",
+				Improvement:   "- latency improved\n- cpu_time, tps, qps.total improved\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qt.Assert(t, tt.set.report("oltp", changes), qt.DeepEquals, tt.want)
+		})
+	}
+}
@@ -0,0 +1,133 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"path"
+	"strings"
+
+	"github.com/vitessio/arewefastyet/go/tools/git"
+)
+
+const (
+	// noopLabel is the label set on the executions of no-op commits.
+	noopLabel = "noop"
+
+	noopWarning = "the commit only changed files that are not expected to impact performance, a flat result is expected"
+)
+
+// relevantFiles are the files, other than Go files, that can impact performance.
+var relevantFiles = map[string]bool{
+	"go.mod": true,
+	"go.sum": true,
+}
+
+// relevantDirectories are the directories of vitess whose files can impact performance.
+var relevantDirectories = []string{
+	"config/",
+}
+
+// testDirectories are the directories of vitess whose files, Go files included, are only
+// used by the tests or the examples and are not part of the benchmarked binaries.
+var testDirectories = []string{
+	"examples/",
+	"go/test/",
+	"test/",
+}
+
+// isNoopChange returns true if none of the given changed files can impact performance.
+func isNoopChange(files []string) bool {
+	if len(files) == 0 {
+		return false
+	}
+	for _, file := range files {
+		if !isNoopFile(file) {
+			return false
+		}
+	}
+	return true
+}
+
+// isNoopFile returns true if the given file of vitess cannot impact performance: the tests and
+// their data, the files of testDirectories, and the files that are neither Go files, nor
+// relevantFiles, nor in relevantDirectories, such as the documentation, CI or web UI files.
+func isNoopFile(file string) bool {
+	if strings.HasSuffix(file, "_test.go") || strings.Contains("/"+file, "/testdata/") {
+		return true
+	}
+	for _, dir := range testDirectories {
+		if strings.HasPrefix(file, dir) {
+			return true
+		}
+	}
+	if path.Ext(file) == ".go" || relevantFiles[path.Base(file)] {
+		return false
+	}
+	for _, dir := range relevantDirectories {
+		if strings.HasPrefix(file, dir) {
+			return false
+		}
+	}
+	return true
+}
+
+// detectNoopCommit marks the element as noop if its git ref only changed files that are not
+// expected to impact performance since its merge base with each of the git refs it is compared
+// against, see noopBaseRefs. The commits are read from the local clone of vitess without locking it,
+// as they are not modified by its updates.
+func (s *Server) detectNoopCommit(element *executionQueueElement) {
+	for _, base := range noopBaseRefs(element) {
+		files, err := git.GetChangedFiles(s.getVitessPath(), base, element.identifier.GitRef)
+		if err != nil {
+			slog.Warnf("Could not get the files changed by %s since %s: %v", element.identifier.GitRef, base, err)
+			return
+		}
+		if !isNoopChange(files) {
+			return
+		}
+	}
+	element.noop = true
+	slog.Infof("%+v is a no-op commit", element.identifier)
+}
+
+// noopBaseRefs returns the git refs the changes of the element's git ref are computed from:
+// the git refs of the baselines it is compared against, such as the base of a pull request,
+// or else the first parent of its git ref.
+func noopBaseRefs(element *executionQueueElement) []string {
+	var bases []string
+	seen := map[string]bool{}
+	for _, baseline := range element.compareWith {
+		if !seen[baseline.GitRef] {
+			seen[baseline.GitRef] = true
+			bases = append(bases, baseline.GitRef)
+		}
+	}
+	if len(bases) == 0 {
+		bases = append(bases, element.identifier.GitRef+"^1")
+	}
+	return bases
+}
+
+// labels returns the labels set on the execution of the element.
+func (element *executionQueueElement) labels() map[string]string {
+	if element.noop {
+		return map[string]string{noopLabel: "true"}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"go.uber.org/zap"
+)
+
+func TestIsNoopChange(t *testing.T) {
+	testcases := []struct {
+		name  string
+		files []string
+		want  bool
+	}{
+		{name: "Documentation only", files: []string{"README.md", "doc/releasenotes/15_0_0_summary.md"}, want: true},
+		{name: "CI and web files", files: []string{".github/workflows/unit_test.yml", "web/vtadmin/src/App.tsx"}, want: true},
+		{name: "Tests and test data", files: []string{"go/vt/vtgate/executor_test.go", "go/vt/vtgate/testdata/select_cases.json"}, want: true},
+		{name: "End-to-end tests and examples", files: []string{"go/test/endtoend/cluster/cluster_process.go", "examples/local/101_initial_cluster.sh", "test/config.json"}, want: true},
+		{name: "Go file", files: []string{"README.md", "go/vt/vtgate/executor.go"}, want: false},
+		{name: "Dependencies", files: []string{"go.mod", "go.sum"}, want: false},
+		{name: "MySQL configuration", files: []string{"config/mycnf/default.cnf"}, want: false},
+		{name: "No changed files", files: nil, want: false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			qt.Assert(t, isNoopChange(tc.files), qt.Equals, tc.want)
+		})
+	}
+}
+
+func TestExecutionQueueElement_labels(t *testing.T) {
+	c := qt.New(t)
+	element := newTestQueueElement("a")
+	c.Assert(element.labels(), qt.IsNil)
+
+	element.noop = true
+	c.Assert(element.labels(), qt.DeepEquals, map[string]string{noopLabel: "true"})
+}
+
+func TestNoopBaseRefs(t *testing.T) {
+	c := qt.New(t)
+	element := newTestQueueElement("a")
+	c.Assert(noopBaseRefs(element), qt.DeepEquals, []string{"a^1"})
+
+	element.compareWith = []executionIdentifier{
+		{GitRef: "base", Source: "cron", BenchmarkType: "micro"},
+		{GitRef: "base", Source: "cron", BenchmarkType: "micro", PlannerVersion: "Gen4"},
+		{GitRef: "previous", Source: "cron", BenchmarkType: "micro"},
+	}
+	c.Assert(noopBaseRefs(element), qt.DeepEquals, []string{"base", "previous"})
+}
+
+func TestServer_detectNoopCommit(t *testing.T) {
+	c := qt.New(t)
+	previous := slog
+	SetSLogger(zap.NewNop().Sugar())
+	c.Cleanup(func() { SetSLogger(previous) })
+
+	// the pull request changes a Go file first, and then only its documentation
+	dir := c.TempDir()
+	repo := filepath.Join(dir, "vitess")
+	c.Assert(os.Mkdir(repo, 0o755), qt.IsNil)
+	run := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@test", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@test")
+		out, err := cmd.CombinedOutput()
+		c.Assert(err, qt.IsNil, qt.Commentf("%s", out))
+		return strings.TrimSpace(string(out))
+	}
+	commit := func(file string) string {
+		c.Assert(os.WriteFile(filepath.Join(repo, file), []byte(file), 0o644), qt.IsNil)
+		run("add", file)
+		run("commit", "-m", file)
+		return run("rev-parse", "HEAD")
+	}
+	run("init", "-q")
+	base := commit("README.md")
+	commit("main.go")
+	head := commit("doc.md")
+
+	s := &Server{localVitessPath: dir}
+	element := newTestQueueElement(head)
+	s.detectNoopCommit(element)
+	c.Assert(element.noop, qt.IsTrue)
+
+	// compared against the base of the pull request, the Go change is part of the diff
+	element = newTestQueueElement(head)
+	element.compareWith = []executionIdentifier{{GitRef: base, Source: "cron", BenchmarkType: "micro"}}
+	s.detectNoopCommit(element)
+	c.Assert(element.noop, qt.IsFalse)
+
+	// a Go change made on the base branch after the pull request branched off is not part of its changes
+	run("checkout", "-q", "-b", "pull", base)
+	pull := commit("pull.md")
+	run("checkout", "-q", "-")
+	element = newTestQueueElement(pull)
+	element.compareWith = []executionIdentifier{{GitRef: head, Source: "cron", BenchmarkType: "micro"}}
+	s.detectNoopCommit(element)
+	c.Assert(element.noop, qt.IsTrue)
+}
@@ -20,15 +20,29 @@ package server
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/vitessio/arewefastyet/go/exec"
 	"github.com/vitessio/arewefastyet/go/slack"
-
 	"github.com/vitessio/arewefastyet/go/tools/git"
 	"github.com/vitessio/arewefastyet/go/tools/macrobench"
-	"github.com/vitessio/arewefastyet/go/tools/microbench"
 )
 
-func (s *Server) sendNotificationForRegression(leftSource, rightSource, leftRef, rightRef, plannerVersion, benchmarkType string, pullNb int, notifyAlways bool) (err error) {
+// notificationShortSHALength is the length of the SHAs displayed in notifications.
+// It is longer than the default to avoid ambiguous SHAs in the vitess repository.
+const notificationShortSHALength = 10
+
+// sendNotificationForRegression compares leftRef against rightRef and notifies Slack if
+// a regression is found, or regardless of the result if notifyAlways is set. If the
+// notification of improvements is enabled, improvements are notified separately.
+// The report of the comparison is returned, its fields are empty if there is no change.
+// The macrobenchmark results of leftRef and rightRef are read using leftPlannerVersion
+// and rightPlannerVersion respectively. The given labels of the left execution are
+// displayed in the notification.
+func (s *Server) sendNotificationForRegression(leftSource, rightSource, leftRef, rightRef, leftPlannerVersion, rightPlannerVersion, benchmarkType string, pullNb int, labels map[string]string, warnings []string, notifyAlways bool) (report ComparisonReport, err error) {
 	// regression header, appender to header in the event of a regression
 	regressionHeader := `*Observed a regression.*
 `
@@ -36,59 +50,282 @@ func (s *Server) sendNotificationForRegression(leftSource, rightSource, leftRef,
 	// header of the message, before the regression explanation
 	header := fmt.Sprintf("Comparing %s with %s, with the %s benchmark", leftSource, rightSource, benchmarkType)
 	if benchmarkType != "micro" {
-		header += fmt.Sprintf(" using the %s query planner", plannerVersion)
+		if leftPlannerVersion == rightPlannerVersion {
+			header += fmt.Sprintf(" using the %s query planner", leftPlannerVersion)
+		} else {
+			header += fmt.Sprintf(" using the %s query planner against the %s query planner", leftPlannerVersion, rightPlannerVersion)
+		}
 	}
 	header += "\n\n"
 	if pullNb > 0 {
 		header += fmt.Sprintf(`Benchmarked PR #<https://github.com/vitessio/vitess/pull/%d>. `, pullNb)
 	} else {
-		header += `Comparing: recent commit <https://github.com/vitessio/vitess/commit/` + leftRef + `|` + git.ShortenSHA(leftRef) + `> with old commit <https://github.com/vitessio/vitess/commit/` + rightRef + `|` + git.ShortenSHA(rightRef) + `>. `
+		header += `Comparing: recent commit <https://github.com/vitessio/vitess/commit/` + leftRef + `|` + git.ShortenSHAN(leftRef, notificationShortSHALength) + `> with old commit <https://github.com/vitessio/vitess/commit/` + rightRef + `|` + git.ShortenSHAN(rightRef, notificationShortSHALength) + `>. `
 	}
 	header += `Comparison can be seen at : ` + getComparisonLink(leftRef, rightRef) + `
-
 `
+	if len(labels) > 0 {
+		header += `Labels: ` + formatLabels(labels) + `
+`
+	}
+	for _, warning := range warnings {
+		header += `Warning: ` + warning + `
+`
+	}
 
-	if benchmarkType == "micro" {
-		microBenchmarks, err := microbench.Compare(s.dbClient, leftRef, rightRef)
-		if err != nil {
-			return err
-		}
-		regression := microBenchmarks.Regression()
-		err = s.sendMessageIfRegression(notifyAlways, regression, header, regressionHeader)
+	report, err = s.getComparisonReport(leftRef, rightRef, leftPlannerVersion, rightPlannerVersion, benchmarkType)
+	if err != nil {
+		return ComparisonReport{}, err
+	}
+	if report.Confidence != "" {
+		header += `Confidence: ` + report.Confidence + `
+`
+	}
+	if report.PrimaryMetric != "" {
+		header += `Primary metric: ` + report.PrimaryMetric + `
+`
+	}
+	header += `
+`
+	// regressions already notified against the same baseline are held down, unless the
+	// notification was explicitly requested
+	regression := report.Regression
+	if pullNb == 0 && !notifyAlways {
+		identifier := executionIdentifier{Source: leftSource, BenchmarkType: benchmarkType, PlannerVersion: leftPlannerVersion}
+		regression = s.regressionHoldDown.filter(regressionHoldDownKey(identifier, rightRef), regression, time.Now())
+	}
+	err = s.sendMessageIfRegression(notifyAlways, regression, secondaryMetricsDetail(report.Secondary), header, regressionHeader)
+	if err != nil {
+		return ComparisonReport{}, err
+	}
+	if s.notifyImprovements && report.Improvement != "" {
+		err = s.sendImprovementMessage(report.Improvement, header)
 		if err != nil {
-			return err
+			return ComparisonReport{}, err
 		}
-	} else if benchmarkType == "oltp" || benchmarkType == "tpcc" {
-		macrosMatrices, err := macrobench.CompareMacroBenchmarks(s.dbClient, leftRef, rightRef, macrobench.PlannerVersion(plannerVersion))
+	}
+	return report, nil
+}
+
+// getRegression compares leftRef against rightRef for the given benchmark type and
+// returns the reason of the regression, or an empty string if there is none.
+func (s *Server) getRegression(leftRef, rightRef, plannerVersion, benchmarkType string) (string, error) {
+	report, err := s.getComparisonReport(leftRef, rightRef, plannerVersion, plannerVersion, benchmarkType)
+	if err != nil {
+		return "", err
+	}
+	return report.Regression, nil
+}
+
+// ComparisonReport separates the regressions and the improvements observed
+// when comparing two git refs. Each field contains the reasons of the changes,
+// it is empty if there is no such change.
+type ComparisonReport struct {
+	Regression  string `json:"regression"`
+	Improvement string `json:"improvement"`
+
+	// Confidence is the macrobench.Confidence of the comparison, it is empty if unknown.
+	Confidence string `json:"confidence,omitempty"`
+
+	// PrimaryMetric is the headline metric of the benchmark type, see metricSet. Secondary
+	// contains the reasons of the regressions of the metrics that are not in the metric set
+	// of the benchmark type, they are not considered as a regression.
+	PrimaryMetric string `json:"primary_metric,omitempty"`
+	Secondary     string `json:"secondary,omitempty"`
+}
+
+// getComparisonReport compares leftRef against rightRef for the given benchmark type and returns
+// the regressions and improvements found by the configured RegressionDetector. The macrobenchmark
+// results of leftRef and rightRef are read using leftPlannerVersion and rightPlannerVersion respectively.
+func (s *Server) getComparisonReport(leftRef, rightRef, leftPlannerVersion, rightPlannerVersion, benchmarkType string) (ComparisonReport, error) {
+	detector, err := s.getRegressionDetector()
+	if err != nil {
+		return ComparisonReport{}, err
+	}
+	return detector.Detect(DetectionContext{s: s}, DetectionRequest{
+		LeftRef:             leftRef,
+		RightRef:            rightRef,
+		LeftPlannerVersion:  leftPlannerVersion,
+		RightPlannerVersion: rightPlannerVersion,
+		BenchmarkType:       benchmarkType,
+	})
+}
+
+// getMicrobenchThresholds parses the configured thresholds of microbenchmarks.
+func (s *Server) getMicrobenchThresholds() (map[string]float64, error) {
+	thresholds := make(map[string]float64, len(s.microbenchThresholds))
+	for name, value := range s.microbenchThresholds {
+		threshold, err := strconv.ParseFloat(value, 64)
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("invalid threshold for microbenchmark %s: %w", name, err)
 		}
+		thresholds[name] = threshold
+	}
+	return thresholds, nil
+}
 
-		macroResults := macrosMatrices[macrobench.Type(benchmarkType)].(macrobench.ComparisonArray)
-		if len(macroResults) == 0 {
-			return fmt.Errorf("no macrobenchmark result")
-		}
+// formatLabels returns the given labels as a list of key=value pairs sorted by key.
+func formatLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+labels[key])
+	}
+	return strings.Join(pairs, ", ")
+}
 
-		regression := macroResults[0].Regression()
-		err = s.sendMessageIfRegression(notifyAlways, regression, header, regressionHeader)
-		if err != nil {
-			return err
+// getMetadataWarnings returns the warnings of metadataWarnings for the executions
+// leftUUID and rightUUID.
+func (s *Server) getMetadataWarnings(leftUUID, rightUUID string) ([]string, error) {
+	leftMetadata, rightMetadata, err := s.getExecutionsMetadata(leftUUID, rightUUID)
+	if err != nil {
+		return nil, err
+	}
+	return metadataWarnings(leftMetadata, rightMetadata), nil
+}
+
+// metadataWarnings returns a warning for each setting, such as the MySQL configuration
+// or the benchmark environment variables, that differs between two executions, making
+// their comparison less meaningful.
+func metadataWarnings(leftMetadata, rightMetadata map[string]string) []string {
+	var warnings []string
+	if warning := mySQLConfigWarning(leftMetadata[exec.MetadataMySQLConfig], rightMetadata[exec.MetadataMySQLConfig]); warning != "" {
+		warnings = append(warnings, warning)
+	}
+	if warning := benchmarkEnvWarning(leftMetadata[exec.MetadataBenchmarkEnv], rightMetadata[exec.MetadataBenchmarkEnv]); warning != "" {
+		warnings = append(warnings, warning)
+	}
+	return warnings
+}
+
+// getExecutionsMetadata returns the metadata of the executions leftUUID and rightUUID.
+func (s *Server) getExecutionsMetadata(leftUUID, rightUUID string) (leftMetadata, rightMetadata map[string]string, err error) {
+	leftMetadata, err = exec.GetMetadata(s.readDB(), leftUUID)
+	if err != nil {
+		return nil, nil, err
+	}
+	rightMetadata, err = exec.GetMetadata(s.readDB(), rightUUID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return leftMetadata, rightMetadata, nil
+}
+
+// durationsMismatch returns the reason why two executions cannot be compared if their
+// macrobenchmarks ran for different durations. An empty string is returned if the durations
+// match, or if they are unknown for one of the executions.
+func durationsMismatch(leftMetadata, rightMetadata map[string]string) string {
+	for _, key := range []string{exec.MetadataDuration, exec.MetadataWarmupDuration} {
+		left, right := leftMetadata[key], rightMetadata[key]
+		if left != "" && right != "" && left != right {
+			return fmt.Sprintf("the executions ran with a different %s (%ss against %ss)", key, left, right)
 		}
 	}
-	return nil
+	return ""
+}
+
+// clientsMismatch returns the reason why two executions cannot be compared if their
+// macrobenchmarks ran with a different number of clients, which throughput heavily
+// depends on. An empty string is returned if the numbers of clients match, or if they
+// are unknown for one of the executions.
+func clientsMismatch(leftMetadata, rightMetadata map[string]string) string {
+	left, right := leftMetadata[exec.MetadataClients], rightMetadata[exec.MetadataClients]
+	if left != "" && right != "" && left != right {
+		return fmt.Sprintf("the executions ran with a different number of clients (%s against %s)", left, right)
+	}
+	return ""
+}
+
+// workloadMixMismatch returns the reason why two executions cannot be compared if their
+// macrobenchmarks ran different workload mixes, such as a mix against a single workload.
+// An empty string is returned if the workload mixes match.
+func workloadMixMismatch(leftMetadata, rightMetadata map[string]string) string {
+	left, right := leftMetadata[macrobench.MetadataWorkloadMix], rightMetadata[macrobench.MetadataWorkloadMix]
+	if left == right {
+		return ""
+	}
+	if left == "" {
+		left = "none"
+	}
+	if right == "" {
+		right = "none"
+	}
+	return fmt.Sprintf("the executions ran different workload mixes (%s against %s)", left, right)
+}
+
+// allowImpairedComparisonLabel is the execution label explicitly allowing an execution to be
+// compared against a baseline that ran with a different network impairment.
+const allowImpairedComparisonLabel = "allow_impaired_comparison"
+
+// networkImpairmentMismatch returns the reason why two executions cannot be compared if
+// they ran with different network impairments, such as an impaired execution against a
+// clean one. An empty string is returned if the network impairments match.
+func networkImpairmentMismatch(leftMetadata, rightMetadata map[string]string) string {
+	left, right := leftMetadata[exec.MetadataNetworkImpairment], rightMetadata[exec.MetadataNetworkImpairment]
+	if left == right {
+		return ""
+	}
+	if left == "" {
+		left = "none"
+	}
+	if right == "" {
+		right = "none"
+	}
+	return fmt.Sprintf("the executions ran with different network impairments (%s against %s)", left, right)
+}
+
+func mySQLConfigWarning(leftConfig, rightConfig string) string {
+	if leftConfig == rightConfig {
+		return ""
+	}
+	if leftConfig == "" {
+		leftConfig = "default"
+	}
+	if rightConfig == "" {
+		rightConfig = "default"
+	}
+	return fmt.Sprintf("the executions used different MySQL configurations (%s against %s)", leftConfig, rightConfig)
+}
+
+func benchmarkEnvWarning(leftEnv, rightEnv string) string {
+	if leftEnv == rightEnv {
+		return ""
+	}
+	if leftEnv == "" {
+		leftEnv = "none"
+	}
+	if rightEnv == "" {
+		rightEnv = "none"
+	}
+	return fmt.Sprintf("the executions used different benchmark environment variables (%s against %s)", leftEnv, rightEnv)
+}
+
+// secondaryMetricsDetail formats the regressions of the secondary metrics of a ComparisonReport
+// for notifications. An empty string is returned if there is none.
+func secondaryMetricsDetail(secondary string) string {
+	if secondary == "" {
+		return ""
+	}
+	return "\nSecondary metrics, not considered as a regression:\n" + secondary
 }
 
 func getComparisonLink(leftSHA, rightSHA string) string {
 	return "https://benchmark.vitess.io/compare?r=" + leftSHA + "&c=" + rightSHA
 }
 
-func (s *Server) sendMessageIfRegression(ignoreNonRegression bool, regression, header, regressionHeader string) error {
+// sendMessageIfRegression notifies Slack of the regression, or regardless of it if ignoreNonRegression
+// is set. The detail is appended to the message, it is not considered as a regression.
+func (s *Server) sendMessageIfRegression(ignoreNonRegression bool, regression, detail, header, regressionHeader string) error {
 	if regression != "" || ignoreNonRegression {
 		hd := header
 		if regression != "" {
 			hd = regressionHeader + header
 		}
-		err := s.sendSlackMessage(regression, hd)
+		err := s.sendSlackMessage(regression+detail, hd)
 		if err != nil {
 			return err
 		}
@@ -96,6 +333,17 @@ func (s *Server) sendMessageIfRegression(ignoreNonRegression bool, regression, h
 	return nil
 }
 
+// sendImprovementMessage notifies Slack of the given improvement. The message is sent to
+// the improvements channel, or to the default channel if the former is not configured.
+func (s *Server) sendImprovementMessage(improvement, header string) error {
+	config := s.slackConfig
+	if s.improvementsSlackChannel != "" {
+		config.Channel = s.improvementsSlackChannel
+	}
+	msg := slack.TextMessage{Content: "*Observed an improvement.*\n" + header + improvement}
+	return msg.Send(config)
+}
+
 func (s *Server) sendSlackMessage(regression, header string) error {
 	content := header + regression
 	msg := slack.TextMessage{Content: content}
@@ -48,3 +48,137 @@ func TestGetComparisonLink(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatLabels(t *testing.T) {
+	testcases := []struct {
+		name   string
+		labels map[string]string
+		out    string
+	}{
+		{name: "No labels", labels: map[string]string{}, out: ""},
+		{name: "Single label", labels: map[string]string{"experiment": "foo"}, out: "experiment=foo"},
+		{name: "Sorted labels", labels: map[string]string{"ticket": "123", "experiment": "foo"}, out: "experiment=foo, ticket=123"},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			qt.Assert(t, formatLabels(testcase.labels), qt.Equals, testcase.out)
+		})
+	}
+}
+
+func TestMySQLConfigWarning(t *testing.T) {
+	testcases := []struct {
+		name        string
+		left, right string
+		out         string
+	}{
+		{name: "Default configurations", left: "", right: "", out: ""},
+		{name: "Same configurations", left: "innodb_doublewrite=1", right: "innodb_doublewrite=1", out: ""},
+		{name: "Different configurations", left: "innodb_doublewrite=1", right: "innodb_doublewrite=0", out: "the executions used different MySQL configurations (innodb_doublewrite=1 against innodb_doublewrite=0)"},
+		{name: "Default configuration", left: "innodb_doublewrite=1", right: "", out: "the executions used different MySQL configurations (innodb_doublewrite=1 against default)"},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			qt.Assert(t, mySQLConfigWarning(testcase.left, testcase.right), qt.Equals, testcase.out)
+		})
+	}
+}
+
+func TestMetadataWarnings(t *testing.T) {
+	testcases := []struct {
+		name        string
+		left, right map[string]string
+		out         []string
+	}{
+		{name: "No settings", left: map[string]string{}, right: map[string]string{}, out: nil},
+		{name: "Same settings", left: map[string]string{"mysql_config": "innodb_doublewrite=1", "benchmark_env": "GOGC=200"}, right: map[string]string{"mysql_config": "innodb_doublewrite=1", "benchmark_env": "GOGC=200"}, out: nil},
+		{name: "Different benchmark environments", left: map[string]string{"benchmark_env": "GOGC=200"}, right: map[string]string{}, out: []string{"the executions used different benchmark environment variables (GOGC=200 against none)"}},
+		{name: "Different settings", left: map[string]string{"mysql_config": "innodb_doublewrite=1", "benchmark_env": "GOGC=200"}, right: map[string]string{"benchmark_env": "GOGC=100"}, out: []string{
+			"the executions used different MySQL configurations (innodb_doublewrite=1 against default)",
+			"the executions used different benchmark environment variables (GOGC=200 against GOGC=100)",
+		}},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			qt.Assert(t, metadataWarnings(testcase.left, testcase.right), qt.DeepEquals, testcase.out)
+		})
+	}
+}
+
+func TestDurationsMismatch(t *testing.T) {
+	testcases := []struct {
+		name        string
+		left, right map[string]string
+		out         string
+	}{
+		{name: "Unknown durations", left: map[string]string{}, right: map[string]string{"duration": "900"}, out: ""},
+		{name: "Same durations", left: map[string]string{"duration": "900", "warmup_duration": "10"}, right: map[string]string{"duration": "900", "warmup_duration": "10"}, out: ""},
+		{name: "Different durations", left: map[string]string{"duration": "60"}, right: map[string]string{"duration": "600"}, out: "the executions ran with a different duration (60s against 600s)"},
+		{name: "Different warm up durations", left: map[string]string{"duration": "900", "warmup_duration": "10"}, right: map[string]string{"duration": "900", "warmup_duration": "90"}, out: "the executions ran with a different warmup_duration (10s against 90s)"},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			qt.Assert(t, durationsMismatch(testcase.left, testcase.right), qt.Equals, testcase.out)
+		})
+	}
+}
+
+func TestClientsMismatch(t *testing.T) {
+	testcases := []struct {
+		name        string
+		left, right map[string]string
+		out         string
+	}{
+		{name: "Unknown clients", left: map[string]string{}, right: map[string]string{"clients": "100"}, out: ""},
+		{name: "Same clients", left: map[string]string{"clients": "100"}, right: map[string]string{"clients": "100"}, out: ""},
+		{name: "Different clients", left: map[string]string{"clients": "8"}, right: map[string]string{"clients": "100"}, out: "the executions ran with a different number of clients (8 against 100)"},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			qt.Assert(t, clientsMismatch(testcase.left, testcase.right), qt.Equals, testcase.out)
+		})
+	}
+}
+
+func TestWorkloadMixMismatch(t *testing.T) {
+	testcases := []struct {
+		name        string
+		left, right map[string]string
+		out         string
+	}{
+		{name: "Single workloads", left: map[string]string{}, right: map[string]string{}, out: ""},
+		{name: "Same mixes", left: map[string]string{"workload_mix": "oltp_read_only=0.7,oltp_write_only=0.3"}, right: map[string]string{"workload_mix": "oltp_read_only=0.7,oltp_write_only=0.3"}, out: ""},
+		{name: "Mix against single workload", left: map[string]string{"workload_mix": "oltp_read_only=0.7,oltp_write_only=0.3"}, right: map[string]string{}, out: "the executions ran different workload mixes (oltp_read_only=0.7,oltp_write_only=0.3 against none)"},
+		{name: "Different ratios", left: map[string]string{"workload_mix": "oltp_read_only=0.5,oltp_write_only=0.5"}, right: map[string]string{"workload_mix": "oltp_read_only=0.7,oltp_write_only=0.3"}, out: "the executions ran different workload mixes (oltp_read_only=0.5,oltp_write_only=0.5 against oltp_read_only=0.7,oltp_write_only=0.3)"},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			qt.Assert(t, workloadMixMismatch(testcase.left, testcase.right), qt.Equals, testcase.out)
+		})
+	}
+}
+
+func TestNetworkImpairmentMismatch(t *testing.T) {
+	testcases := []struct {
+		name        string
+		left, right map[string]string
+		out         string
+	}{
+		{name: "Clean executions", left: map[string]string{}, right: map[string]string{}, out: ""},
+		{name: "Same impairments", left: map[string]string{"network_impairment": "delay=20ms,jitter=0s,loss=0%"}, right: map[string]string{"network_impairment": "delay=20ms,jitter=0s,loss=0%"}, out: ""},
+		{name: "Impaired against clean", left: map[string]string{"network_impairment": "delay=20ms,jitter=0s,loss=0%"}, right: map[string]string{}, out: "the executions ran with different network impairments (delay=20ms,jitter=0s,loss=0% against none)"},
+		{name: "Different impairments", left: map[string]string{"network_impairment": "delay=0s,jitter=0s,loss=1%"}, right: map[string]string{"network_impairment": "delay=0s,jitter=0s,loss=5%"}, out: "the executions ran with different network impairments (delay=0s,jitter=0s,loss=1% against delay=0s,jitter=0s,loss=5%)"},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			qt.Assert(t, networkImpairmentMismatch(testcase.left, testcase.right), qt.Equals, testcase.out)
+		})
+	}
+}
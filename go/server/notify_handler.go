@@ -0,0 +1,109 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/vitessio/arewefastyet/go/exec"
+)
+
+// notifyCompareRequest is the body expected by notifyCompareHandler.
+// Left and Right can either be the UUID of an execution or a git ref.
+type notifyCompareRequest struct {
+	Left           string `json:"left" binding:"required"`
+	Right          string `json:"right" binding:"required"`
+	Type           string `json:"type" binding:"required"`
+	PlannerVersion string `json:"planner_version"`
+}
+
+// notifyCompareHandler compares two existing runs and sends the result of the
+// comparison to Slack, whether there is a regression or not. It allows to
+// resend notifications that could not be sent in the first place.
+func (s *Server) notifyCompareHandler(c *gin.Context) {
+	var req notifyCompareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Type != "micro" && req.PlannerVersion == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "planner_version is required for macrobenchmarks"})
+		return
+	}
+
+	leftRef, leftSource, err := s.resolveNotifyRef(req.Left)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rightRef, rightSource, err := s.resolveNotifyRef(req.Right)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// labels are only known when the left run is an execution UUID
+	var labels map[string]string
+	_, errParseLeft := uuid.Parse(req.Left)
+	if errParseLeft == nil {
+		labels, err = exec.GetLabels(s.readDB(), req.Left)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// the execution settings can only be compared when both runs are execution UUIDs
+	var warnings []string
+	if _, errParseRight := uuid.Parse(req.Right); errParseLeft == nil && errParseRight == nil {
+		warnings, err = s.getMetadataWarnings(req.Left, req.Right)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	report, err := s.sendNotificationForRegression(leftSource, rightSource, leftRef, rightRef, req.PlannerVersion, req.PlannerVersion, req.Type, 0, labels, warnings, true)
+	if err != nil {
+		slog.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// resolveNotifyRef returns the git ref and the source of the given execution UUID.
+// If ref is not a UUID, it is considered as a git ref and returned as is.
+func (s *Server) resolveNotifyRef(ref string) (gitRef, source string, err error) {
+	execUUID, err := uuid.Parse(ref)
+	if err != nil {
+		return ref, ref, nil
+	}
+	e, err := exec.GetExecution(s.readDB(), execUUID)
+	if err != nil {
+		return "", "", err
+	}
+	if e == nil {
+		return "", "", fmt.Errorf("execution %s not found", ref)
+	}
+	return e.GitRef, e.Source, nil
+}
@@ -0,0 +1,77 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vitessio/arewefastyet/go/tools/macrobench"
+)
+
+// queryPlansAPIHandler compares the query plans captured by the executions given in the
+// "left" and "right" query parameters, and returns the queries whose plan changed along
+// with the diff of their plans.
+func (s *Server) queryPlansAPIHandler(c *gin.Context) {
+	leftUUID := c.Query("left")
+	rightUUID := c.Query("right")
+	if leftUUID == "" || rightUUID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "the left and right query parameters are required"})
+		return
+	}
+	changes, err := s.diffQueryPlans(leftUUID, rightUUID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"left": leftUUID, "right": rightUUID, "changes": changes})
+}
+
+// diffQueryPlans returns the changes between the query plans of the executions leftUUID
+// and rightUUID. No change is returned if either execution did not capture its plans.
+func (s *Server) diffQueryPlans(leftUUID, rightUUID string) ([]macrobench.QueryPlanChange, error) {
+	left, err := macrobench.GetQueryPlansForExecution(leftUUID, s.readDB())
+	if err != nil {
+		return nil, err
+	}
+	right, err := macrobench.GetQueryPlansForExecution(rightUUID, s.readDB())
+	if err != nil {
+		return nil, err
+	}
+	if len(left) == 0 || len(right) == 0 {
+		return []macrobench.QueryPlanChange{}, nil
+	}
+	return macrobench.DiffQueryPlans(left, right), nil
+}
+
+// queryPlansWarning returns a warning if the query plans changed between two executions, as
+// a plan change often explains a difference of performance. An empty string is returned if
+// no plan changed.
+func queryPlansWarning(changes []macrobench.QueryPlanChange) string {
+	if len(changes) == 0 {
+		return ""
+	}
+	counts := map[string]int{}
+	for _, change := range changes {
+		counts[change.Status]++
+	}
+	return fmt.Sprintf("the query plans differ between the executions (%d changed, %d added, %d removed)",
+		counts[macrobench.QueryPlanChanged], counts[macrobench.QueryPlanAdded], counts[macrobench.QueryPlanRemoved])
+}
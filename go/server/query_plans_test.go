@@ -0,0 +1,36 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/vitessio/arewefastyet/go/tools/macrobench"
+)
+
+func TestQueryPlansWarning(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(queryPlansWarning(nil), qt.Equals, "")
+	c.Assert(queryPlansWarning([]macrobench.QueryPlanChange{
+		{Key: "select a from t", Status: macrobench.QueryPlanChanged},
+		{Key: "select b from t", Status: macrobench.QueryPlanChanged},
+		{Key: "select c from t", Status: macrobench.QueryPlanAdded},
+	}), qt.Equals, "the query plans differ between the executions (2 changed, 1 added, 0 removed)")
+}
@@ -0,0 +1,315 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Queue holds the executions waiting to be executed, being executed, or waiting
+// for their comparisons. It owns its mutex and is safe for concurrent use.
+type Queue struct {
+	mu       sync.Mutex
+	elements executionQueue
+
+	// running is the number of elements currently executing,
+	// it cannot exceed maxRunning.
+	running    int
+	maxRunning int
+
+	// lastSeq is the sequence number given to the last element added to the Queue.
+	lastSeq uint64
+
+	// heldInfrastructure maps the UUIDs of the executions whose infrastructure is held, see
+	// HoldInfrastructure, to the time at which it is released, nil if it is not scheduled.
+	heldInfrastructure map[string]*time.Time
+}
+
+// NewQueue creates an empty Queue that allows maxRunning
+// elements to execute at the same time.
+func NewQueue(maxRunning int) *Queue {
+	return &Queue{
+		elements:           make(executionQueue),
+		maxRunning:         maxRunning,
+		heldInfrastructure: map[string]*time.Time{},
+	}
+}
+
+// Add adds the element to the Queue. It returns false if an element
+// with the same identifier is already in the Queue.
+func (q *Queue) Add(element *executionQueueElement) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, found := q.elements[element.identifier]; found {
+		return false
+	}
+	q.lastSeq++
+	element.seq = q.lastSeq
+	q.elements[element.identifier] = element
+	return true
+}
+
+// Contains returns true if an element with the given identifier is in the Queue.
+func (q *Queue) Contains(identifier executionIdentifier) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	_, found := q.elements[identifier]
+	return found
+}
+
+// Remove removes the element with the given identifier from the Queue.
+func (q *Queue) Remove(identifier executionIdentifier) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.elements, identifier)
+}
+
+// RemoveIf removes all the elements whose identifier matches the given condition.
+// The identifiers of the removed elements are returned.
+func (q *Queue) RemoveIf(condition func(identifier executionIdentifier) bool) []executionIdentifier {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var removed []executionIdentifier
+	for identifier := range q.elements {
+		if condition(identifier) {
+			delete(q.elements, identifier)
+			removed = append(removed, identifier)
+		}
+	}
+	return removed
+}
+
+// CancelIf cancels all the elements whose identifier matches the given condition: the
+// elements that are not executing are removed from the Queue, and the running execution
+// of the executing ones is canceled. The removed elements and the identifiers of the
+// canceled executions are returned.
+func (q *Queue) CancelIf(condition func(identifier executionIdentifier) bool) (removed []*executionQueueElement, canceled []executionIdentifier) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for identifier, element := range q.elements {
+		if !condition(identifier) {
+			continue
+		}
+		if !element.executing {
+			delete(q.elements, identifier)
+			removed = append(removed, element)
+			continue
+		}
+		// elements that are about to execute, or waiting to be requeued,
+		// are canceled once they start, see ExecutionContext
+		element.canceled = true
+		if element.cancel != nil {
+			element.cancel()
+			canceled = append(canceled, identifier)
+		}
+	}
+	return removed, canceled
+}
+
+// RemoveBaselinesIf removes the baselines whose identifier matches the given condition from
+// the elements that are not executing, which will no longer wait for nor be compared against
+// them. The elements whose baselines changed are returned. The executing elements give up on
+// the baselines that are neither queued nor finished once they are compared.
+func (q *Queue) RemoveBaselinesIf(condition func(identifier executionIdentifier) bool) (updated []*executionQueueElement) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, element := range q.elements {
+		if element.executing {
+			continue
+		}
+		var compareWith []executionIdentifier
+		for _, baseline := range element.compareWith {
+			if !condition(baseline) {
+				compareWith = append(compareWith, baseline)
+			}
+		}
+		if len(compareWith) != len(element.compareWith) {
+			element.compareWith = compareWith
+			updated = append(updated, element)
+		}
+	}
+	return updated
+}
+
+// ExecutionContext returns the context of an execution of the element, which is canceled
+// by CancelIf, and the function to call once the execution ended. The context is already
+// canceled if the element was canceled before its execution started.
+func (q *Queue) ExecutionContext(element *executionQueueElement) (context.Context, func()) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	ctx, cancel := context.WithCancel(context.Background())
+	if element.canceled {
+		cancel()
+	}
+	element.cancel = cancel
+	return ctx, func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		element.cancel = nil
+		cancel()
+	}
+}
+
+// Next returns the oldest element that is not executing yet and marks it as executing.
+// Low priority elements are only returned if no other element is waiting, and the elements
+// provisioning infrastructure are not returned while the infrastructure of an execution is held.
+// It returns nil if all the elements are executing, or if the maximum number
+// of running elements is reached. Done must be called once the element is
+// no longer executing.
+func (q *Queue) Next() *executionQueueElement {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.running >= q.maxRunning {
+		return nil
+	}
+	var next *executionQueueElement
+	for _, element := range q.elements {
+		if element.executing {
+			continue
+		}
+		if len(q.heldInfrastructure) > 0 && provisionsInfrastructure(element.identifier.BenchmarkType) {
+			continue
+		}
+		if next == nil || waitsBefore(element, next) {
+			next = element
+		}
+	}
+	if next != nil {
+		q.running++
+
+		// setting this element to `executing = true`, so we do not execute it twice in the future
+		next.executing = true
+		next.startedAt = time.Now()
+	}
+	return next
+}
+
+// Done releases the running slot taken by an element returned by Next.
+func (q *Queue) Done() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.running--
+}
+
+// Requeue marks the element as not executing, so it can be returned by Next again.
+// It returns false, leaving the element untouched, if the element was canceled.
+func (q *Queue) Requeue(element *executionQueueElement) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if element.canceled {
+		return false
+	}
+	element.executing = false
+	element.startedAt = time.Time{}
+	return true
+}
+
+// HoldInfrastructure records that the infrastructure of the execution execUUID is held on the
+// hosts of its inventory. The elements provisioning infrastructure are not returned by Next until
+// ReleaseInfrastructure is called, as they would run on the same hosts.
+func (q *Queue) HoldInfrastructure(execUUID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.heldInfrastructure[execUUID] = nil
+}
+
+// ScheduleInfrastructureRelease records the time at which the held infrastructure of the execution
+// execUUID is released. Nothing is done if it is not held.
+func (q *Queue) ScheduleInfrastructureRelease(execUUID string, releaseAt time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, ok := q.heldInfrastructure[execUUID]; ok {
+		q.heldInfrastructure[execUUID] = &releaseAt
+	}
+}
+
+// ReleaseInfrastructure records that the infrastructure of the execution execUUID was torn down.
+func (q *Queue) ReleaseInfrastructure(execUUID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.heldInfrastructure, execUUID)
+}
+
+// InfrastructureHeld returns true if the infrastructure of any execution is held,
+// in which case the elements provisioning infrastructure are not returned by Next.
+func (q *Queue) InfrastructureHeld() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.heldInfrastructure) > 0
+}
+
+// HoldsInfrastructure returns true if the infrastructure of the execution execUUID is held.
+func (q *Queue) HoldsInfrastructure(execUUID string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	_, ok := q.heldInfrastructure[execUUID]
+	return ok
+}
+
+// HeldInfrastructure returns a copy of the UUIDs of the executions whose infrastructure
+// is held, mapped to the time at which it is released, nil if it is not scheduled.
+func (q *Queue) HeldInfrastructure() map[string]*time.Time {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	held := make(map[string]*time.Time, len(q.heldInfrastructure))
+	for execUUID, releaseAt := range q.heldInfrastructure {
+		held[execUUID] = releaseAt
+	}
+	return held
+}
+
+// ExecutesInfrastructure returns true if an element provisioning infrastructure is executing,
+// in which case the shared hosts are in use and must not be cleaned up.
+func (q *Queue) ExecutesInfrastructure() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, element := range q.elements {
+		if element.executing && provisionsInfrastructure(element.identifier.BenchmarkType) {
+			return true
+		}
+	}
+	return false
+}
+
+// Snapshot returns a copy of the elements currently in the Queue.
+func (q *Queue) Snapshot() map[executionIdentifier]executionQueueElement {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	snapshot := make(map[executionIdentifier]executionQueueElement, len(q.elements))
+	for identifier, element := range q.elements {
+		snapshot[identifier] = *element
+	}
+	return snapshot
+}
+
+// MaxRunning returns the maximum number of elements executing at the same time.
+func (q *Queue) MaxRunning() int {
+	return q.maxRunning
+}
+
+// waitsBefore reports whether the waiting element a is executed before the waiting element b:
+// low priority elements are executed last, and elements are executed in the order they were added.
+func waitsBefore(a, b *executionQueueElement) bool {
+	if a.lowPriority != b.lowPriority {
+		return !a.lowPriority
+	}
+	return a.seq < b.seq
+}
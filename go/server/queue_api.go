@@ -0,0 +1,135 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vitessio/arewefastyet/go/exec"
+)
+
+// enqueueRequest is the body expected by enqueueHandler.
+type enqueueRequest struct {
+	// GitRef is the git ref to benchmark, if empty the latest commit of the
+	// branch configured for Source is benchmarked.
+	GitRef         string `json:"git_ref"`
+	Type           string `json:"type" binding:"required"`
+	Source         string `json:"source" binding:"required"`
+	PlannerVersion string `json:"planner_version"`
+
+	// CompareWith lists the git refs the execution is compared against once finished.
+	// They are enqueued with the same source as baseline-only executions.
+	CompareWith []string `json:"compare_with"`
+
+	// BaselineOnly marks the execution as a baseline: it is never compared against
+	// CompareWith and does not trigger notifications, it only serves as a baseline.
+	BaselineOnly bool `json:"baseline_only"`
+	NotifyAlways bool `json:"notify_always"`
+}
+
+// enqueueHandler adds an execution to the queue, along with the executions of the
+// git refs it is compared against.
+func (s *Server) enqueueHandler(c *gin.Context) {
+	var req enqueueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	configFile, ok := s.getConfigFiles()[req.Type]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s %s", errUnknownBenchmarkType, req.Type)})
+		return
+	}
+	planner := string(syncRunPlannerVersion(req.Type, req.PlannerVersion))
+	gitRef, err := s.resolveGitRef(req.GitRef, req.Source)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	req.GitRef = gitRef
+
+	element := s.createSimpleExecutionQueueElement(req.Source, configFile, req.GitRef, req.Type, planner, req.NotifyAlways, 0)
+	element.baselineOnly = req.BaselineOnly
+	elements := []*executionQueueElement{element}
+	for _, ref := range req.CompareWith {
+		baselineElement := s.createSimpleExecutionQueueElement(req.Source, configFile, ref, req.Type, planner, false, 0)
+		baselineElement.baselineOnly = true
+		element.compareWith = append(element.compareWith, baselineElement.identifier)
+		elements = append(elements, baselineElement)
+	}
+
+	go func() {
+		for _, element := range elements {
+			s.addToQueue(element)
+		}
+	}()
+	c.JSON(http.StatusAccepted, gin.H{"status": "queued", "git_ref": req.GitRef})
+}
+
+// cancelByRefRequest is the body expected by cancelByRefHandler.
+type cancelByRefRequest struct {
+	GitRef string `json:"git_ref" binding:"required"`
+}
+
+// cancelByRefHandler cancels all the executions of a git ref, whatever their benchmark type:
+// the queued executions are removed from the queue and the running ones are canceled. The
+// queued executions compared against the git ref are no longer compared against it. The
+// number of removed and canceled executions, and of executions no longer compared against
+// the git ref, is returned.
+func (s *Server) cancelByRefHandler(c *gin.Context) {
+	var req cancelByRefRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	removed, canceled, detached := s.cancelGitRef(req.GitRef)
+	c.JSON(http.StatusOK, gin.H{"git_ref": req.GitRef, "removed": removed, "canceled": canceled, "detached": detached})
+}
+
+// cancelGitRef cancels the executions of gitRef in the queue, and removes gitRef from the
+// baselines of the queued executions. It returns the number of executions removed from the
+// queue, of running executions canceled, and of executions detached from gitRef.
+func (s *Server) cancelGitRef(gitRef string) (removed, canceled, detached int) {
+	condition := func(identifier executionIdentifier) bool {
+		return identifier.GitRef == gitRef
+	}
+	removedElements, canceledIdentifiers := s.queue.CancelIf(condition)
+	for _, element := range removedElements {
+		slog.Infof("%+v was removed from the queue", element.identifier)
+		if err := deletePersistedQueueElement(s.dbClient, element.identifier); err != nil {
+			slog.Error(err)
+		}
+		if element.batchID != "" {
+			s.completeSuiteMember(element, exec.StatusCanceled, nil)
+		}
+	}
+	for _, identifier := range canceledIdentifiers {
+		slog.Infof("Canceling the execution of %+v", identifier)
+	}
+	detachedElements := s.queue.RemoveBaselinesIf(condition)
+	for _, element := range detachedElements {
+		slog.Infof("%+v is no longer compared against %s", element.identifier, gitRef)
+		if err := persistQueueElement(s.dbClient, element); err != nil {
+			slog.Error(err)
+		}
+	}
+	return len(removedElements), len(canceledIdentifiers), len(detachedElements)
+}
@@ -0,0 +1,146 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/vitessio/arewefastyet/go/exec"
+	"github.com/vitessio/arewefastyet/go/storage"
+)
+
+const queueElementWhereClause = "git_ref = ? AND source = ? AND type = ? AND planner_version = ? AND pull_nb = ?"
+
+func queueElementWhereArgs(identifier executionIdentifier) []interface{} {
+	return []interface{}{identifier.GitRef, identifier.Source, identifier.BenchmarkType, identifier.PlannerVersion, identifier.PullNb}
+}
+
+// persistQueueElement saves the given element in the queue table,
+// allowing it to be restored if the server restarts.
+func persistQueueElement(client storage.SQLClient, element *executionQueueElement) error {
+	compareWith, err := json.Marshal(element.compareWith)
+	if err != nil {
+		return err
+	}
+	query := "INSERT INTO queue(git_ref, source, type, planner_version, pull_nb, config, retry, attempt, compare_with, notify_always, executing, baseline_only, noop, low_priority, batch_id) " +
+		"VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) " +
+		"ON DUPLICATE KEY UPDATE config = VALUES(config), retry = VALUES(retry), attempt = VALUES(attempt), " +
+		"compare_with = VALUES(compare_with), notify_always = VALUES(notify_always), executing = VALUES(executing), baseline_only = VALUES(baseline_only), noop = VALUES(noop), " +
+		"low_priority = VALUES(low_priority), batch_id = VALUES(batch_id)"
+	_, err = client.Insert(query, element.identifier.GitRef, element.identifier.Source, element.identifier.BenchmarkType,
+		element.identifier.PlannerVersion, element.identifier.PullNb, element.config, element.retry, element.attempt,
+		string(compareWith), element.notifyAlways, element.executing, element.baselineOnly, element.noop, element.lowPriority, element.batchID)
+	return err
+}
+
+// updatePersistedQueueElement saves the state of an element that was previously
+// persisted: its retries, its attempts and whether it is executing.
+func updatePersistedQueueElement(client storage.SQLClient, element *executionQueueElement) error {
+	query := "UPDATE queue SET retry = ?, attempt = ?, executing = ? WHERE " + queueElementWhereClause
+	args := append([]interface{}{element.retry, element.attempt, element.executing}, queueElementWhereArgs(element.identifier)...)
+	_, err := client.Insert(query, args...)
+	return err
+}
+
+// deletePersistedQueueElement removes the element with the given identifier from the queue table.
+func deletePersistedQueueElement(client storage.SQLClient, identifier executionIdentifier) error {
+	_, err := client.Insert("DELETE FROM queue WHERE "+queueElementWhereClause, queueElementWhereArgs(identifier)...)
+	return err
+}
+
+// getPersistedQueueElements returns all the elements of the queue table, the oldest first.
+func getPersistedQueueElements(client storage.SQLClient) ([]*executionQueueElement, error) {
+	query := "SELECT git_ref, source, type, planner_version, pull_nb, config, retry, attempt, compare_with, notify_always, executing, baseline_only, noop, low_priority, batch_id " +
+		"FROM queue ORDER BY created_at"
+	rows, err := client.Select(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []*executionQueueElement
+	for rows.Next() {
+		var element executionQueueElement
+		var compareWith string
+		err = rows.Scan(&element.identifier.GitRef, &element.identifier.Source, &element.identifier.BenchmarkType,
+			&element.identifier.PlannerVersion, &element.identifier.PullNb, &element.config, &element.retry,
+			&element.attempt, &compareWith, &element.notifyAlways, &element.executing, &element.baselineOnly, &element.noop, &element.lowPriority, &element.batchID)
+		if err != nil {
+			return nil, err
+		}
+		if compareWith != "" {
+			err = json.Unmarshal([]byte(compareWith), &element.compareWith)
+			if err != nil {
+				return nil, err
+			}
+		}
+		res = append(res, &element)
+	}
+	return res, nil
+}
+
+// restoreQueue adds the elements persisted in the queue table to the in-memory queue.
+// Elements that were executing when the server stopped are requeued, and their abandoned
+// execution is marked as timed out, so that the stuck executions watchdog does not remove
+// them from the queue once their execution has been started for too long. The batches of
+// the restored elements belonging to a suite are rebuilt.
+func (s *Server) restoreQueue() error {
+	elements, err := getPersistedQueueElements(s.dbClient)
+	if err != nil {
+		return err
+	}
+	for _, element := range elements {
+		if element.executing {
+			slog.Infof("%+v was executing when the server stopped, it is requeued", element.identifier)
+			err = exec.MarkAbandonedExecutionsAsTimedOut(s.dbClient, element.identifier.GitRef, element.identifier.Source, element.identifier.BenchmarkType, element.identifier.PullNb)
+			if err != nil {
+				slog.Error(err)
+			}
+			element.executing = false
+			s.persistQueueElementState(element)
+		}
+		if s.queue.Add(element) {
+			slog.Infof("%+v is restored in the queue", element.identifier)
+			if element.batchID != "" {
+				s.suiteBatches.restore(element, time.Now())
+			}
+		}
+	}
+	return nil
+}
+
+// removeFromQueue removes the element with the given identifier
+// from the in-memory queue and from the queue table.
+func (s *Server) removeFromQueue(identifier executionIdentifier) {
+	s.queue.Remove(identifier)
+	err := deletePersistedQueueElement(s.dbClient, identifier)
+	if err != nil {
+		slog.Error(err)
+	}
+}
+
+// persistQueueElementState saves the state of the given element in the queue table.
+// Failures are only logged, the in-memory queue stays the source of truth.
+func (s *Server) persistQueueElementState(element *executionQueueElement) {
+	err := updatePersistedQueueElement(s.dbClient, element)
+	if err != nil {
+		slog.Error(err)
+	}
+}
@@ -0,0 +1,47 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/vitessio/arewefastyet/go/storage/mysql/mysqltest"
+)
+
+func TestQueueElementStorage(t *testing.T) {
+	c := qt.New(t)
+	client := mysqltest.New(t)
+
+	element := &executionQueueElement{
+		identifier:  executionIdentifier{GitRef: "abc", Source: "cron", BenchmarkType: "oltp"},
+		compareWith: []executionIdentifier{{GitRef: "def", Source: "cron", BenchmarkType: "oltp"}},
+		lowPriority: true,
+		batchID:     "batch",
+	}
+	c.Assert(persistQueueElement(client, element), qt.IsNil)
+
+	elements, err := getPersistedQueueElements(client)
+	c.Assert(err, qt.IsNil)
+	c.Assert(elements, qt.HasLen, 1)
+	c.Assert(elements[0].identifier, qt.Equals, element.identifier)
+	c.Assert(elements[0].compareWith, qt.DeepEquals, element.compareWith)
+	c.Assert(elements[0].lowPriority, qt.IsTrue)
+	c.Assert(elements[0].batchID, qt.Equals, "batch")
+}
@@ -0,0 +1,271 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func newTestQueueElement(gitRef string) *executionQueueElement {
+	return &executionQueueElement{identifier: executionIdentifier{GitRef: gitRef, Source: "cron", BenchmarkType: "micro"}}
+}
+
+func TestQueue_Add(t *testing.T) {
+	c := qt.New(t)
+	q := NewQueue(1)
+
+	c.Assert(q.Add(newTestQueueElement("a")), qt.IsTrue)
+	c.Assert(q.Add(newTestQueueElement("a")), qt.IsFalse)
+	c.Assert(q.Add(newTestQueueElement("b")), qt.IsTrue)
+	c.Assert(q.Contains(newTestQueueElement("a").identifier), qt.IsTrue)
+	c.Assert(q.Snapshot(), qt.HasLen, 2)
+
+	q.Remove(newTestQueueElement("a").identifier)
+	c.Assert(q.Contains(newTestQueueElement("a").identifier), qt.IsFalse)
+	c.Assert(q.Snapshot(), qt.HasLen, 1)
+}
+
+func TestQueue_Next(t *testing.T) {
+	c := qt.New(t)
+	q := NewQueue(1)
+	c.Assert(q.Next(), qt.IsNil)
+
+	q.Add(newTestQueueElement("a"))
+	q.Add(newTestQueueElement("b"))
+
+	first := q.Next()
+	c.Assert(first, qt.Not(qt.IsNil))
+	c.Assert(first.executing, qt.IsTrue)
+
+	// the maximum number of running elements is reached
+	c.Assert(q.Next(), qt.IsNil)
+
+	q.Done()
+	second := q.Next()
+	c.Assert(second, qt.Not(qt.IsNil))
+	c.Assert(second.identifier, qt.Not(qt.Equals), first.identifier)
+
+	// all the elements are executing
+	q.Done()
+	c.Assert(q.Next(), qt.IsNil)
+
+	q.Requeue(first)
+	c.Assert(q.Next(), qt.Equals, first)
+}
+
+func TestQueue_NextLowPriority(t *testing.T) {
+	c := qt.New(t)
+	q := NewQueue(1)
+
+	low := newTestQueueElement("low")
+	low.lowPriority = true
+	q.Add(low)
+	q.Add(newTestQueueElement("a"))
+
+	c.Assert(q.Next().identifier.GitRef, qt.Equals, "a")
+	q.Done()
+	c.Assert(q.Next(), qt.Equals, low)
+}
+
+func TestQueue_NextOrder(t *testing.T) {
+	c := qt.New(t)
+	q := NewQueue(1)
+	for _, gitRef := range []string{"c", "a", "b"} {
+		q.Add(newTestQueueElement(gitRef))
+	}
+
+	for _, want := range []string{"c", "a", "b"} {
+		next := q.Next()
+		c.Assert(next.identifier.GitRef, qt.Equals, want)
+		c.Assert(next.startedAt.IsZero(), qt.IsFalse)
+		q.Done()
+	}
+}
+
+func TestQueue_RemoveIf(t *testing.T) {
+	c := qt.New(t)
+	q := NewQueue(1)
+	q.Add(newTestQueueElement("a"))
+	q.Add(newTestQueueElement("b"))
+
+	removed := q.RemoveIf(func(identifier executionIdentifier) bool {
+		return identifier.GitRef == "a"
+	})
+	c.Assert(removed, qt.DeepEquals, []executionIdentifier{newTestQueueElement("a").identifier})
+	c.Assert(q.Contains(newTestQueueElement("a").identifier), qt.IsFalse)
+	c.Assert(q.Contains(newTestQueueElement("b").identifier), qt.IsTrue)
+}
+
+func TestQueue_Concurrency(t *testing.T) {
+	c := qt.New(t)
+	q := NewQueue(4)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			element := newTestQueueElement(fmt.Sprintf("%d", i%10))
+			q.Add(element)
+			_ = q.Snapshot()
+			if next := q.Next(); next != nil {
+				q.Requeue(next)
+				q.Done()
+			}
+			q.Remove(element.identifier)
+		}(i)
+	}
+	wg.Wait()
+
+	c.Assert(q.Snapshot(), qt.HasLen, 0)
+	c.Assert(q.running, qt.Equals, 0)
+}
+
+func TestQueue_CancelIf(t *testing.T) {
+	c := qt.New(t)
+	q := NewQueue(2)
+
+	running := newTestQueueElement("bad")
+	running.identifier.BenchmarkType = "oltp"
+	starting := newTestQueueElement("bad")
+	starting.identifier.BenchmarkType = "tpcc"
+	waiting := newTestQueueElement("bad")
+	other := newTestQueueElement("good")
+	for _, element := range []*executionQueueElement{running, starting, waiting, other} {
+		q.Add(element)
+	}
+	c.Assert(q.Next(), qt.Equals, running)
+	c.Assert(q.Next(), qt.Equals, starting)
+	ctx, done := q.ExecutionContext(running)
+	defer done()
+
+	removed, canceled := q.CancelIf(func(identifier executionIdentifier) bool {
+		return identifier.GitRef == "bad"
+	})
+	c.Assert(removed, qt.HasLen, 1)
+	c.Assert(removed[0], qt.Equals, waiting)
+	c.Assert(canceled, qt.DeepEquals, []executionIdentifier{running.identifier})
+	c.Assert(ctx.Err(), qt.Not(qt.IsNil))
+	c.Assert(q.Contains(waiting.identifier), qt.IsFalse)
+	c.Assert(q.Contains(other.identifier), qt.IsTrue)
+
+	// the element that did not start yet is canceled once it starts
+	startingCtx, startingDone := q.ExecutionContext(starting)
+	defer startingDone()
+	c.Assert(startingCtx.Err(), qt.Not(qt.IsNil))
+
+	// canceled elements cannot be requeued
+	c.Assert(q.Requeue(running), qt.IsFalse)
+	c.Assert(running.executing, qt.IsTrue)
+}
+
+func TestQueue_RemoveBaselinesIf(t *testing.T) {
+	c := qt.New(t)
+	q := NewQueue(1)
+
+	bad := newTestQueueElement("bad")
+	executing := newTestQueueElement("executing")
+	executing.compareWith = []executionIdentifier{bad.identifier}
+	dependent := newTestQueueElement("dependent")
+	dependent.compareWith = []executionIdentifier{bad.identifier, executing.identifier}
+	other := newTestQueueElement("other")
+	other.compareWith = []executionIdentifier{executing.identifier}
+	for _, element := range []*executionQueueElement{executing, bad, dependent, other} {
+		q.Add(element)
+	}
+	c.Assert(q.Next(), qt.Equals, executing)
+
+	updated := q.RemoveBaselinesIf(func(identifier executionIdentifier) bool {
+		return identifier.GitRef == "bad"
+	})
+	c.Assert(updated, qt.HasLen, 1)
+	c.Assert(updated[0], qt.Equals, dependent)
+	c.Assert(dependent.compareWith, qt.DeepEquals, []executionIdentifier{executing.identifier})
+	c.Assert(other.compareWith, qt.DeepEquals, []executionIdentifier{executing.identifier})
+
+	// the executing elements give up on the baseline once compared
+	c.Assert(executing.compareWith, qt.DeepEquals, []executionIdentifier{bad.identifier})
+}
+
+func TestQueue_NextHeldInfrastructure(t *testing.T) {
+	c := qt.New(t)
+	q := NewQueue(2)
+	macro := newTestQueueElement("macro")
+	macro.identifier.BenchmarkType = "oltp"
+	micro := newTestQueueElement("micro")
+	q.Add(macro)
+	q.Add(micro)
+
+	q.HoldInfrastructure("exec")
+	c.Assert(q.HoldsInfrastructure("exec"), qt.IsTrue)
+
+	// the macrobenchmark would run on the held hosts
+	c.Assert(q.Next(), qt.Equals, micro)
+	c.Assert(q.Next(), qt.IsNil)
+
+	q.ReleaseInfrastructure("exec")
+	c.Assert(q.HoldsInfrastructure("exec"), qt.IsFalse)
+	c.Assert(q.Next(), qt.Equals, macro)
+}
+
+func TestQueue_HeldInfrastructure(t *testing.T) {
+	c := qt.New(t)
+	q := NewQueue(1)
+
+	// the release of infrastructure that is not held is not recorded
+	releaseAt := time.Now().Add(time.Hour)
+	q.ScheduleInfrastructureRelease("released", releaseAt)
+	c.Assert(q.HeldInfrastructure(), qt.HasLen, 0)
+
+	q.HoldInfrastructure("held")
+	q.HoldInfrastructure("scheduled")
+	q.ScheduleInfrastructureRelease("scheduled", releaseAt)
+	held := q.HeldInfrastructure()
+	c.Assert(held, qt.HasLen, 2)
+	c.Assert(held["held"], qt.IsNil)
+	c.Assert(*held["scheduled"], qt.Equals, releaseAt)
+
+	// holding again resets the release time
+	q.HoldInfrastructure("scheduled")
+	c.Assert(q.HeldInfrastructure()["scheduled"], qt.IsNil)
+}
+
+func TestQueue_ExecutesInfrastructure(t *testing.T) {
+	c := qt.New(t)
+	q := NewQueue(2)
+	macro := newTestQueueElement("macro")
+	macro.identifier.BenchmarkType = "oltp"
+	micro := newTestQueueElement("micro")
+	q.Add(macro)
+	q.Add(micro)
+
+	// waiting elements do not use the hosts
+	c.Assert(q.ExecutesInfrastructure(), qt.IsFalse)
+
+	macro.lowPriority = true
+	c.Assert(q.Next(), qt.Equals, micro)
+	c.Assert(q.ExecutesInfrastructure(), qt.IsFalse)
+	c.Assert(q.Next(), qt.Equals, macro)
+	c.Assert(q.ExecutesInfrastructure(), qt.IsTrue)
+}
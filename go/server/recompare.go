@@ -0,0 +1,127 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/vitessio/arewefastyet/go/exec"
+)
+
+// recompareRequest is the body expected by recompareHandler.
+type recompareRequest struct {
+	Left           string `json:"left" binding:"required"`
+	Right          string `json:"right" binding:"required"`
+	PlannerVersion string `json:"planner_version"`
+	NotifyAlways   bool   `json:"notify_always"`
+}
+
+// recompareHandler re-evaluates the comparison of two finished executions, given by their UUID,
+// using the same logic as compareElement, and notifies its result. No benchmark is run, allowing
+// to fix the notifications of comparisons that were wrong because of a bug in the comparison logic.
+func (s *Server) recompareHandler(c *gin.Context) {
+	var req recompareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	leftUUID, err := uuid.Parse(req.Left)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rightUUID, err := uuid.Parse(req.Right)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	left, right, err := s.getRecomparedExecutions(leftUUID, rightUUID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if left.TypeOf != "micro" && req.PlannerVersion == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "planner_version is required for macrobenchmarks"})
+		return
+	}
+
+	br, err := s.recompare(left, right, req.PlannerVersion, req.NotifyAlways)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"regression":  br.report.Regression,
+		"improvement": br.report.Improvement,
+		"warnings":    br.warnings,
+		"skipped":     br.skipped,
+	})
+}
+
+// getRecomparedExecutions returns the executions leftUUID and rightUUID, which must
+// both be finished and of the same benchmark type to be recompared.
+func (s *Server) getRecomparedExecutions(leftUUID, rightUUID uuid.UUID) (left, right *exec.Exec, err error) {
+	left, err = exec.GetExecution(s.readDB(), leftUUID)
+	if err != nil {
+		return nil, nil, err
+	}
+	right, err = exec.GetExecution(s.readDB(), rightUUID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return left, right, checkRecomparedExecutions(leftUUID, rightUUID, left, right)
+}
+
+func checkRecomparedExecutions(leftUUID, rightUUID uuid.UUID, left, right *exec.Exec) error {
+	for _, e := range []struct {
+		uuid uuid.UUID
+		exec *exec.Exec
+	}{{uuid: leftUUID, exec: left}, {uuid: rightUUID, exec: right}} {
+		if e.exec == nil {
+			return fmt.Errorf("execution %s not found", e.uuid)
+		}
+		if e.exec.Status != exec.StatusFinished {
+			return fmt.Errorf("execution %s is %s, only finished executions can be recompared", e.uuid, e.exec.Status)
+		}
+	}
+	if left.TypeOf != right.TypeOf {
+		return fmt.Errorf("executions of different benchmark types cannot be recompared (%s against %s)", left.TypeOf, right.TypeOf)
+	}
+	return nil
+}
+
+// recompare runs the comparison and notification logic of compareElement for the executions
+// left and right. Unlike compareElement, the regressions are not tracked again, as they were
+// already tracked when the executions were first compared.
+func (s *Server) recompare(left, right *exec.Exec, plannerVersion string, notifyAlways bool) (baselineReport, error) {
+	if left.TypeOf == "micro" {
+		plannerVersion = ""
+	}
+	identifier := executionIdentifier{GitRef: left.GitRef, Source: left.Source, BenchmarkType: left.TypeOf, PlannerVersion: plannerVersion, PullNb: left.PullNB}
+	baseline := executionIdentifier{GitRef: right.GitRef, Source: right.Source, BenchmarkType: right.TypeOf, PlannerVersion: plannerVersion, PullNb: right.PullNB}
+	labels, err := exec.GetLabels(s.readDB(), left.UUID.String())
+	if err != nil {
+		return baselineReport{}, err
+	}
+	return s.compareWithBaseline(identifier, left.UUID.String(), baseline, right.UUID.String(), labels, false, notifyAlways)
+}
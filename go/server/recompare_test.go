@@ -0,0 +1,54 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/google/uuid"
+	"github.com/vitessio/arewefastyet/go/exec"
+)
+
+func TestCheckRecomparedExecutions(t *testing.T) {
+	leftUUID, rightUUID := uuid.New(), uuid.New()
+	finished := func(typeOf string) *exec.Exec {
+		return &exec.Exec{Status: exec.StatusFinished, TypeOf: typeOf}
+	}
+	testcases := []struct {
+		name        string
+		left, right *exec.Exec
+		wantErr     string
+	}{
+		{name: "Finished executions", left: finished("oltp"), right: finished("oltp")},
+		{name: "Unknown execution", left: finished("oltp"), right: nil, wantErr: "execution " + rightUUID.String() + " not found"},
+		{name: "Unfinished execution", left: &exec.Exec{Status: exec.StatusFailed, TypeOf: "oltp"}, right: finished("oltp"), wantErr: "execution " + leftUUID.String() + " is failed, only finished executions can be recompared"},
+		{name: "Different types", left: finished("oltp"), right: finished("tpcc"), wantErr: `executions of different benchmark types cannot be recompared \(oltp against tpcc\)`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkRecomparedExecutions(leftUUID, rightUUID, tc.left, tc.right)
+			if tc.wantErr == "" {
+				qt.Assert(t, err, qt.IsNil)
+				return
+			}
+			qt.Assert(t, err, qt.ErrorMatches, tc.wantErr)
+		})
+	}
+}
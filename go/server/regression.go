@@ -0,0 +1,91 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vitessio/arewefastyet/go/exec"
+)
+
+// trackRegression persists the regression observed between the execution execUUID
+// and its baseline. Regressions of pull requests are not tracked since a later
+// execution of the same source may concern a different pull request.
+func (s *Server) trackRegression(identifier executionIdentifier, execUUID, baselineGitRef, baselineExecUUID, regression string) {
+	if identifier.PullNb > 0 {
+		return
+	}
+	err := exec.InsertRegression(s.dbClient, exec.Regression{
+		Source:           identifier.Source,
+		BenchmarkType:    identifier.BenchmarkType,
+		PlannerVersion:   identifier.PlannerVersion,
+		ExecUUID:         execUUID,
+		GitRef:           identifier.GitRef,
+		BaselineExecUUID: baselineExecUUID,
+		BaselineGitRef:   baselineGitRef,
+		Metrics:          regression,
+	})
+	if err != nil {
+		slog.Error(err)
+	}
+}
+
+// resolveRegressions compares the execution execUUID against the baseline of every
+// open regression of the same source, type and planner version. The regressions for
+// which the execution returned to the baseline are marked as resolved.
+func (s *Server) resolveRegressions(identifier executionIdentifier, execUUID string) {
+	if identifier.PullNb > 0 {
+		return
+	}
+	regressions, err := exec.GetOpenRegressionsFor(s.dbClient, identifier.Source, identifier.BenchmarkType, identifier.PlannerVersion)
+	if err != nil {
+		slog.Error(err)
+		return
+	}
+	for _, regression := range regressions {
+		if regression.ExecUUID == execUUID {
+			continue
+		}
+		reason, err := s.getRegression(identifier.GitRef, regression.BaselineGitRef, identifier.PlannerVersion, identifier.BenchmarkType)
+		if err != nil {
+			slog.Error(err)
+			continue
+		}
+		if reason != "" {
+			continue
+		}
+		err = exec.ResolveRegression(s.dbClient, regression.ID, execUUID)
+		if err != nil {
+			slog.Error(err)
+			continue
+		}
+		s.regressionHoldDown.release(regressionHoldDownKey(identifier, regression.BaselineGitRef))
+		slog.Infof("Regression %d of %s (%s) was resolved by %s", regression.ID, regression.GitRef, regression.BenchmarkType, identifier.GitRef)
+	}
+}
+
+func (s *Server) openRegressionsHandler(c *gin.Context) {
+	regressions, err := exec.GetOpenRegressions(s.dbClient)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, regressions)
+}
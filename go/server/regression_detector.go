@@ -0,0 +1,194 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/vitessio/arewefastyet/go/storage"
+	"github.com/vitessio/arewefastyet/go/tools/macrobench"
+	"github.com/vitessio/arewefastyet/go/tools/microbench"
+)
+
+// defaultRegressionDetector is the name of the RegressionDetector used by default.
+const defaultRegressionDetector = "pairwise"
+
+// DetectionRequest describes the comparison a RegressionDetector is asked to evaluate:
+// the results of LeftRef are compared against the ones of RightRef. The macrobenchmark
+// results are read using LeftPlannerVersion and RightPlannerVersion respectively.
+type DetectionRequest struct {
+	LeftRef, RightRef                       string
+	LeftPlannerVersion, RightPlannerVersion string
+	BenchmarkType                           string
+}
+
+// RegressionDetector detects the regressions and improvements of a git ref against another.
+// Implementations are registered with RegisterRegressionDetector and selected with the
+// web-regression-detector flag.
+type RegressionDetector interface {
+	Detect(dc DetectionContext, req DetectionRequest) (ComparisonReport, error)
+}
+
+// DetectionContext gives a RegressionDetector access to the results stored by the Server
+// and to the metric sets and thresholds it is configured with.
+type DetectionContext struct {
+	s *Server
+}
+
+// DB returns the client used to read the results of the executions.
+func (dc DetectionContext) DB() storage.SQLClient {
+	return dc.s.readDB()
+}
+
+// MacroReport returns the report of the given macrobenchmark comparison according to
+// the metric set of benchmarkType.
+func (dc DetectionContext) MacroReport(benchmarkType string, comparison macrobench.Comparison) (ComparisonReport, error) {
+	return dc.s.macroComparisonReport(benchmarkType, comparison)
+}
+
+// MicroReport returns the report of the given microbenchmark comparisons according to
+// the microbenchmark thresholds.
+func (dc DetectionContext) MicroReport(comparisons microbench.ComparisonArray) (ComparisonReport, error) {
+	return dc.s.microComparisonReport(comparisons)
+}
+
+var (
+	regressionDetectorsMu sync.RWMutex
+
+	// regressionDetectors maps the name of each available RegressionDetector to its implementation.
+	regressionDetectors = map[string]RegressionDetector{
+		defaultRegressionDetector: pairwiseDetector{},
+		"percentile":              percentileDetector{},
+		"external":                externalDetector{},
+	}
+)
+
+// RegisterRegressionDetector makes detector available under the given name, which can then
+// be selected with the web-regression-detector flag. It returns an error if the name is empty
+// or already used.
+func RegisterRegressionDetector(name string, detector RegressionDetector) error {
+	if name == "" || detector == nil {
+		return errors.New("a regression detector requires a name and an implementation")
+	}
+	regressionDetectorsMu.Lock()
+	defer regressionDetectorsMu.Unlock()
+	if _, ok := regressionDetectors[name]; ok {
+		return fmt.Errorf("regression detector %s is already registered", name)
+	}
+	regressionDetectors[name] = detector
+	return nil
+}
+
+// getRegressionDetector returns the RegressionDetector configured on the Server.
+func (s *Server) getRegressionDetector() (RegressionDetector, error) {
+	name := s.regressionDetector
+	if name == "" {
+		name = defaultRegressionDetector
+	}
+	regressionDetectorsMu.RLock()
+	defer regressionDetectorsMu.RUnlock()
+	detector, ok := regressionDetectors[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown regression detector %s", name)
+	}
+	return detector, nil
+}
+
+// pairwiseDetector compares the results of both git refs against each other and
+// reports the metrics whose change exceeds their threshold.
+type pairwiseDetector struct{}
+
+func (pairwiseDetector) Detect(dc DetectionContext, req DetectionRequest) (ComparisonReport, error) {
+	if req.BenchmarkType == "micro" {
+		microBenchmarks, err := microbench.Compare(dc.DB(), req.LeftRef, req.RightRef)
+		if err != nil {
+			return ComparisonReport{}, err
+		}
+		return dc.MicroReport(microBenchmarks)
+	} else if req.BenchmarkType == "oltp" || req.BenchmarkType == "tpcc" {
+		macrosMatrices, err := macrobench.CompareMacroBenchmarksForPlanners(dc.DB(), req.LeftRef, req.RightRef, macrobench.PlannerVersion(req.LeftPlannerVersion), macrobench.PlannerVersion(req.RightPlannerVersion), "")
+		if err != nil {
+			return ComparisonReport{}, err
+		}
+
+		macroResults := macrosMatrices[macrobench.Type(req.BenchmarkType)].(macrobench.ComparisonArray)
+		if len(macroResults) == 0 {
+			return ComparisonReport{}, fmt.Errorf("no macrobenchmark result")
+		}
+		return dc.MacroReport(req.BenchmarkType, macroResults[0])
+	}
+	return ComparisonReport{}, nil
+}
+
+// percentileDetector compares the macrobenchmark results of the left git ref against a percentile
+// of the last executions of the right git ref's source, configured with the web-baseline-percentile
+// and web-baseline-window flags. Microbenchmarks are compared like pairwiseDetector does.
+type percentileDetector struct{}
+
+func (percentileDetector) Detect(dc DetectionContext, req DetectionRequest) (ComparisonReport, error) {
+	if req.BenchmarkType != "oltp" && req.BenchmarkType != "tpcc" {
+		return pairwiseDetector{}.Detect(dc, req)
+	}
+	s := dc.s
+	if s.baselinePercentile < 0 || s.baselinePercentile > 100 {
+		return ComparisonReport{}, fmt.Errorf("invalid baseline percentile %g, must be between 0 and 100", s.baselinePercentile)
+	}
+	if s.baselineWindow < 1 {
+		return ComparisonReport{}, fmt.Errorf("invalid baseline window %d, must be at least 1", s.baselineWindow)
+	}
+	macroResults, err := macrobench.ComparePercentileBaseline(dc.DB(), macrobench.Type(req.BenchmarkType), req.LeftRef, req.RightRef,
+		macrobench.PlannerVersion(req.LeftPlannerVersion), macrobench.PlannerVersion(req.RightPlannerVersion), s.baselineWindow, s.baselinePercentile)
+	if err != nil {
+		return ComparisonReport{}, err
+	}
+	if len(macroResults) == 0 {
+		return ComparisonReport{}, fmt.Errorf("no macrobenchmark result")
+	}
+	return dc.MacroReport(req.BenchmarkType, macroResults[0])
+}
+
+// externalDetector compares the macrobenchmark results of the left git ref against the reference
+// metrics loaded from the web-external-baseline flag, which do not come from arewefastyet. The right
+// git ref is ignored for macrobenchmarks. Microbenchmarks are compared like pairwiseDetector does.
+type externalDetector struct{}
+
+func (externalDetector) Detect(dc DetectionContext, req DetectionRequest) (ComparisonReport, error) {
+	if req.BenchmarkType != "oltp" && req.BenchmarkType != "tpcc" {
+		return pairwiseDetector{}.Detect(dc, req)
+	}
+	s := dc.s
+	if s.externalBaseline == "" {
+		return ComparisonReport{}, fmt.Errorf("no external baseline configured, the %s flag is required", flagExternalBaseline)
+	}
+	baseline, err := macrobench.LoadExternalBaseline(s.externalBaseline)
+	if err != nil {
+		return ComparisonReport{}, err
+	}
+	macroResults, err := macrobench.CompareExternalBaseline(dc.DB(), macrobench.Type(req.BenchmarkType), req.LeftRef,
+		macrobench.PlannerVersion(req.LeftPlannerVersion), baseline)
+	if err != nil {
+		return ComparisonReport{}, err
+	}
+	if len(macroResults) == 0 {
+		return ComparisonReport{}, fmt.Errorf("no macrobenchmark result")
+	}
+	return dc.MacroReport(req.BenchmarkType, macroResults[0])
+}
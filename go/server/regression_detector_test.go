@@ -0,0 +1,104 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestServer_getRegressionDetector(t *testing.T) {
+	testcases := []struct {
+		name     string
+		detector string
+		want     RegressionDetector
+		wantErr  bool
+	}{
+		{name: "Default detector", detector: "", want: pairwiseDetector{}},
+		{name: "Pairwise detector", detector: "pairwise", want: pairwiseDetector{}},
+		{name: "Percentile detector", detector: "percentile", want: percentileDetector{}},
+		{name: "External detector", detector: "external", want: externalDetector{}},
+		{name: "Unknown detector", detector: "changepoint", wantErr: true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := qt.New(t)
+			s := &Server{regressionDetector: tc.detector}
+			detector, err := s.getRegressionDetector()
+			if tc.wantErr {
+				c.Assert(err, qt.ErrorMatches, "unknown regression detector "+tc.detector)
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			c.Assert(detector, qt.Equals, tc.want)
+		})
+	}
+}
+
+// constantDetector is a RegressionDetector reporting the same regression for every comparison.
+type constantDetector struct{}
+
+func (constantDetector) Detect(_ DetectionContext, req DetectionRequest) (ComparisonReport, error) {
+	return ComparisonReport{Regression: req.LeftRef + " is slower than " + req.RightRef}, nil
+}
+
+func TestRegisterRegressionDetector(t *testing.T) {
+	c := qt.New(t)
+	c.Cleanup(func() {
+		regressionDetectorsMu.Lock()
+		delete(regressionDetectors, "constant")
+		regressionDetectorsMu.Unlock()
+	})
+
+	c.Assert(RegisterRegressionDetector("constant", constantDetector{}), qt.IsNil)
+	c.Assert(RegisterRegressionDetector("constant", constantDetector{}), qt.ErrorMatches, "regression detector constant is already registered")
+	c.Assert(RegisterRegressionDetector("pairwise", constantDetector{}), qt.ErrorMatches, "regression detector pairwise is already registered")
+	c.Assert(RegisterRegressionDetector("", constantDetector{}), qt.ErrorMatches, "a regression detector requires a name and an implementation")
+
+	s := &Server{regressionDetector: "constant"}
+	report, err := s.getComparisonReport("a", "b", "", "", "oltp")
+	c.Assert(err, qt.IsNil)
+	c.Assert(report.Regression, qt.Equals, "a is slower than b")
+}
+
+func TestPercentileDetector_DetectInvalidConfiguration(t *testing.T) {
+	testcases := []struct {
+		name       string
+		percentile float64
+		window     int
+		wantErr    string
+	}{
+		{name: "Negative percentile", percentile: -1, window: 10, wantErr: "invalid baseline percentile -1, must be between 0 and 100"},
+		{name: "Percentile above 100", percentile: 101, window: 10, wantErr: "invalid baseline percentile 101, must be between 0 and 100"},
+		{name: "Empty window", percentile: 50, window: 0, wantErr: "invalid baseline window 0, must be at least 1"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Server{baselinePercentile: tc.percentile, baselineWindow: tc.window}
+			_, err := percentileDetector{}.Detect(DetectionContext{s: s}, DetectionRequest{LeftRef: "a", RightRef: "b", BenchmarkType: "oltp"})
+			qt.Assert(t, err, qt.ErrorMatches, tc.wantErr)
+		})
+	}
+}
+
+func TestExternalDetector_DetectWithoutBaseline(t *testing.T) {
+	_, err := externalDetector{}.Detect(DetectionContext{s: &Server{}}, DetectionRequest{LeftRef: "a", RightRef: "b", BenchmarkType: "oltp"})
+	qt.Assert(t, err, qt.ErrorMatches, "no external baseline configured, the web-external-baseline flag is required")
+}
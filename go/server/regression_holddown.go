@@ -0,0 +1,88 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// regressionHoldDown deduplicates the notifications of a same regression: once a metric
+// regressed against a baseline, it is not notified again for that baseline during window,
+// unless the regression is resolved in the meantime.
+type regressionHoldDown struct {
+	mu       sync.Mutex
+	window   time.Duration
+	notified map[string]time.Time
+}
+
+// filter returns the lines of regression that can be notified at the given time, each line
+// being the regression of one metric against the baseline identified by key. The returned
+// lines are recorded as notified.
+func (hd *regressionHoldDown) filter(key, regression string, now time.Time) string {
+	if hd.window <= 0 || regression == "" {
+		return regression
+	}
+	hd.mu.Lock()
+	defer hd.mu.Unlock()
+	if hd.notified == nil {
+		hd.notified = map[string]time.Time{}
+	}
+	var kept []string
+	for _, line := range strings.SplitAfter(regression, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		metricKey := key + "/" + regressionMetric(line)
+		if last, ok := hd.notified[metricKey]; ok && now.Sub(last) < hd.window {
+			continue
+		}
+		hd.notified[metricKey] = now
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "")
+}
+
+// release forgets the regressions notified against the baseline identified by key,
+// allowing them to be notified again.
+func (hd *regressionHoldDown) release(key string) {
+	hd.mu.Lock()
+	defer hd.mu.Unlock()
+	for metricKey := range hd.notified {
+		if strings.HasPrefix(metricKey, key+"/") {
+			delete(hd.notified, metricKey)
+		}
+	}
+}
+
+// regressionMetric returns the metric of a line of a regression, such as "- TPS decreased"
+// for "- TPS decreased by 12.00%", so that the same regression matches regardless of its amount.
+func regressionMetric(line string) string {
+	line = strings.TrimSpace(line)
+	if i := strings.LastIndex(line, " by "); i >= 0 {
+		line = line[:i]
+	}
+	return line
+}
+
+// regressionHoldDownKey identifies the baseline a regression of the given identifier was observed against.
+func regressionHoldDownKey(identifier executionIdentifier, baselineGitRef string) string {
+	return identifier.Source + "/" + identifier.BenchmarkType + "/" + identifier.PlannerVersion + "/" + baselineGitRef
+}
@@ -0,0 +1,70 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestRegressionHoldDown(t *testing.T) {
+	c := qt.New(t)
+	now := time.Now()
+	hd := regressionHoldDown{window: time.Hour}
+
+	c.Assert(hd.filter("cron/oltp/V3/abc", "- TPS decreased by 12.00% \n- QPS decreased by 11.00% \n", now), qt.Equals, "- TPS decreased by 12.00% \n- QPS decreased by 11.00% \n")
+
+	// the same metrics against the same baseline are held down, regardless of their amount
+	c.Assert(hd.filter("cron/oltp/V3/abc", "- TPS decreased by 15.00% \n- Latency increased by 10.00% \n", now.Add(time.Minute)), qt.Equals, "- Latency increased by 10.00% \n")
+
+	// another baseline is not held down
+	c.Assert(hd.filter("cron/oltp/V3/def", "- TPS decreased by 12.00% \n", now.Add(time.Minute)), qt.Equals, "- TPS decreased by 12.00% \n")
+
+	// the regression is notified again once the window passed
+	c.Assert(hd.filter("cron/oltp/V3/abc", "- TPS decreased by 12.00% \n", now.Add(2*time.Hour)), qt.Equals, "- TPS decreased by 12.00% \n")
+
+	// or once it was resolved
+	hd.release("cron/oltp/V3/abc")
+	c.Assert(hd.filter("cron/oltp/V3/abc", "- QPS decreased by 11.00% \n", now.Add(2*time.Hour)), qt.Equals, "- QPS decreased by 11.00% \n")
+}
+
+func TestRegressionHoldDownDisabled(t *testing.T) {
+	var hd regressionHoldDown
+	now := time.Now()
+	qt.Assert(t, hd.filter("cron/oltp/V3/abc", "- TPS decreased by 12.00% \n", now), qt.Equals, "- TPS decreased by 12.00% \n")
+	qt.Assert(t, hd.filter("cron/oltp/V3/abc", "- TPS decreased by 12.00% \n", now), qt.Equals, "- TPS decreased by 12.00% \n")
+}
+
+func TestRegressionMetric(t *testing.T) {
+	testcases := []struct {
+		line string
+		want string
+	}{
+		{line: "- TPS decreased by 12.00% \n", want: "- TPS decreased"},
+		{line: "- vtgate CPU time increased by 6.10% \n", want: "- vtgate CPU time increased"},
+		{line: "- vitess.io/vitess/go/vt/sqlparser/BenchmarkParse1: metric: total operation, decreased by 5.00%\n", want: "- vitess.io/vitess/go/vt/sqlparser/BenchmarkParse1: metric: total operation, decreased"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.want, func(t *testing.T) {
+			qt.Assert(t, regressionMetric(tc.line), qt.Equals, tc.want)
+		})
+	}
+}
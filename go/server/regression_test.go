@@ -0,0 +1,58 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gin-gonic/gin"
+	"github.com/vitessio/arewefastyet/go/exec"
+	"github.com/vitessio/arewefastyet/go/storage/mysql/mysqltest"
+	"go.uber.org/zap"
+)
+
+func TestServer_trackRegression(t *testing.T) {
+	c := qt.New(t)
+	previous := slog
+	SetSLogger(zap.NewNop().Sugar())
+	c.Cleanup(func() { SetSLogger(previous) })
+	gin.SetMode(gin.TestMode)
+
+	s := &Server{dbClient: mysqltest.New(t)}
+	s.trackRegression(executionIdentifier{GitRef: "a", Source: exec.SourceCron, BenchmarkType: "oltp", PlannerVersion: "V3"}, "a-uuid", "base", "base-uuid", "- TPS decreased by 10.00%")
+	// the regressions of pull requests are not tracked
+	s.trackRegression(executionIdentifier{GitRef: "b", Source: exec.SourcePullRequest, BenchmarkType: "oltp", PlannerVersion: "V3", PullNb: 42}, "b-uuid", "base", "base-uuid", "- TPS decreased by 10.00%")
+
+	router := gin.New()
+	router.GET("/api/regressions/open", s.openRegressionsHandler)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/regressions/open", nil))
+	c.Assert(rec.Code, qt.Equals, http.StatusOK)
+
+	var regressions []exec.Regression
+	c.Assert(json.Unmarshal(rec.Body.Bytes(), &regressions), qt.IsNil)
+	c.Assert(regressions, qt.HasLen, 1)
+	c.Assert(regressions[0].ExecUUID, qt.Equals, "a-uuid")
+	c.Assert(regressions[0].BaselineGitRef, qt.Equals, "base")
+	c.Assert(regressions[0].Metrics, qt.Equals, "- TPS decreased by 10.00%")
+}
@@ -0,0 +1,212 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vitessio/arewefastyet/go/exec"
+)
+
+var errRequeueTooLarge = errors.New("requeue too large")
+
+const (
+	// metadataCompareWith, metadataNotifyAlways, metadataPlannerVersion, metadataLowPriority,
+	// metadataBaselineOnly and metadataLabels are the metadata keys storing the settings of
+	// the queue element of an execution, so that it is executed, compared and notified the
+	// same way once requeued.
+	metadataCompareWith    = "compare_with"
+	metadataNotifyAlways   = "notify_always"
+	metadataPlannerVersion = "planner_version"
+	metadataLowPriority    = "low_priority"
+	metadataBaselineOnly   = "baseline_only"
+	metadataLabels         = "labels"
+)
+
+// requeueRequest is the body expected by requeueHandler.
+type requeueRequest struct {
+	// From and To delimit the time window in which the executions failed.
+	From time.Time `json:"from" binding:"required"`
+	To   time.Time `json:"to" binding:"required"`
+
+	// Source and Type optionally restrict the executions to requeue.
+	Source string `json:"source"`
+	Type   string `json:"type"`
+
+	// PlannerVersion is the planner version the macrobenchmarks are requeued with
+	// when the planner version of the execution that failed was not recorded.
+	PlannerVersion string `json:"planner_version"`
+
+	// Force allows to requeue more executions than the maximum number of executions.
+	Force bool `json:"force"`
+}
+
+// requeueHandler enqueues again the executions that failed in a time window, for instance
+// after an outage of the infrastructure. The executions that are already queued, or that
+// finished successfully since they failed, are not requeued.
+func (s *Server) requeueHandler(c *gin.Context) {
+	var req requeueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !req.To.After(req.From) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "the end of the time window must be after its start"})
+		return
+	}
+	if _, ok := s.getConfigFiles()[req.Type]; req.Type != "" && !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s %s", errUnknownBenchmarkType, req.Type)})
+		return
+	}
+
+	executions, err := exec.FindFailedBetween(s.readDB(), req.From, req.To, req.Source, req.Type)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	metadata := make(map[string]map[string]string, len(executions))
+	for _, e := range executions {
+		metadata[e.UUID.String()], err = exec.GetMetadata(s.readDB(), e.UUID.String())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	var elements []*executionQueueElement
+	for _, element := range s.createRequeueElements(executions, metadata, req.PlannerVersion) {
+		if s.queue.Contains(element.identifier) {
+			continue
+		}
+		exists, err := s.checkIfExecutionExists(element.identifier)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !exists {
+			elements = append(elements, element)
+		}
+	}
+	if err := checkRequeueCount(len(elements), s.requeueMaxExecutions, req.Force); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	go func() {
+		for _, element := range elements {
+			s.addToQueue(element)
+		}
+	}()
+	c.JSON(http.StatusAccepted, gin.H{"status": "queued", "failed": len(executions), "requeued": len(elements)})
+}
+
+// createRequeueElements returns the queue elements running the given failed executions again.
+// The elements get the settings of the original ones, such as their planner version and their
+// baselines, which are read from the metadata of the executions indexed by their UUID, see
+// recordRequeueMetadata. The given plannerVersion is used if none was recorded. An execution
+// that failed several times in the time window is only requeued once, and the executions of an
+// unknown type are ignored.
+func (s *Server) createRequeueElements(executions []*exec.Exec, metadata map[string]map[string]string, plannerVersion string) []*executionQueueElement {
+	configs := s.getConfigFiles()
+	seen := map[executionIdentifier]bool{}
+	var elements []*executionQueueElement
+	for _, e := range executions {
+		configFile, ok := configs[e.TypeOf]
+		if !ok {
+			continue
+		}
+		executionMetadata := metadata[e.UUID.String()]
+		planner, ok := executionMetadata[metadataPlannerVersion]
+		if !ok {
+			planner = string(syncRunPlannerVersion(e.TypeOf, plannerVersion))
+		}
+		element := s.createSimpleExecutionQueueElement(e.Source, configFile, e.GitRef, e.TypeOf, planner, false, e.PullNB)
+		if seen[element.identifier] {
+			continue
+		}
+		seen[element.identifier] = true
+		applyRequeueMetadata(element, executionMetadata)
+		elements = append(elements, element)
+	}
+	return elements
+}
+
+// recordRequeueMetadata stores the settings of element in the metadata of its execution
+// execUUID, so that the execution can be requeued with them if it fails, see createRequeueElements.
+func (s *Server) recordRequeueMetadata(element *executionQueueElement, execUUID string) {
+	if execUUID == "" {
+		return
+	}
+	compareWith, err := json.Marshal(element.compareWith)
+	if err != nil {
+		slog.Error(err)
+		return
+	}
+	labels, err := json.Marshal(element.labels())
+	if err != nil {
+		slog.Error(err)
+		return
+	}
+	metadata := map[string]string{
+		metadataCompareWith:    string(compareWith),
+		metadataNotifyAlways:   fmt.Sprint(element.notifyAlways),
+		metadataPlannerVersion: element.identifier.PlannerVersion,
+		metadataLowPriority:    fmt.Sprint(element.lowPriority),
+		metadataBaselineOnly:   fmt.Sprint(element.baselineOnly),
+		metadataLabels:         string(labels),
+	}
+	for key, value := range metadata {
+		if err := exec.SetMetadata(s.dbClient, execUUID, key, value); err != nil {
+			slog.Error(err)
+		}
+	}
+}
+
+// applyRequeueMetadata sets the settings recorded in the metadata of a failed execution,
+// other than its planner version, on the element requeuing it.
+func applyRequeueMetadata(element *executionQueueElement, metadata map[string]string) {
+	if value := metadata[metadataCompareWith]; value != "" {
+		if err := json.Unmarshal([]byte(value), &element.compareWith); err != nil {
+			slog.Warnf("Could not read the baselines of %+v: %v", element.identifier, err)
+		}
+	}
+	element.notifyAlways = metadata[metadataNotifyAlways] == "true"
+	element.lowPriority = metadata[metadataLowPriority] == "true"
+	element.baselineOnly = metadata[metadataBaselineOnly] == "true"
+	if value := metadata[metadataLabels]; value != "" {
+		var labels map[string]string
+		if err := json.Unmarshal([]byte(value), &labels); err != nil {
+			slog.Warnf("Could not read the labels of %+v: %v", element.identifier, err)
+		}
+		element.noop = labels[noopLabel] == "true"
+	}
+}
+
+// checkRequeueCount returns an error if a requeue of nbExecutions executions exceeds
+// maxExecutions, unless the requeue is forced. A maxExecutions of zero disables the limit.
+func checkRequeueCount(nbExecutions, maxExecutions int, force bool) error {
+	if force || maxExecutions <= 0 || nbExecutions <= maxExecutions {
+		return nil
+	}
+	return fmt.Errorf("%w: %d executions, the maximum is %d, use force to requeue them anyway", errRequeueTooLarge, nbExecutions, maxExecutions)
+}
@@ -0,0 +1,100 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"errors"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/google/uuid"
+	"github.com/vitessio/arewefastyet/go/exec"
+)
+
+func TestCheckRequeueCount(t *testing.T) {
+	tests := []struct {
+		name          string
+		nbExecutions  int
+		maxExecutions int
+		force         bool
+		wantErr       bool
+	}{
+		{name: "within the limit", nbExecutions: 10, maxExecutions: 50},
+		{name: "above the limit", nbExecutions: 51, maxExecutions: 50, wantErr: true},
+		{name: "above the limit but forced", nbExecutions: 100, maxExecutions: 50, force: true},
+		{name: "no limit", nbExecutions: 100, maxExecutions: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkRequeueCount(tt.nbExecutions, tt.maxExecutions, tt.force)
+			if tt.wantErr {
+				qt.Assert(t, errors.Is(err, errRequeueTooLarge), qt.IsTrue)
+				return
+			}
+			qt.Assert(t, err, qt.IsNil)
+		})
+	}
+}
+
+func TestServer_createRequeueElements(t *testing.T) {
+	c := qt.New(t)
+	s := &Server{microbenchConfigPath: "micro.yaml", macrobenchConfigPathOLTP: "oltp.yaml", macrobenchConfigPathTPCC: "tpcc.yaml", cronNbRetry: 1}
+	failedUUID, backfillUUID := uuid.New(), uuid.New()
+	executions := []*exec.Exec{
+		{UUID: failedUUID, GitRef: "a", Source: exec.SourceCron, TypeOf: "oltp"},
+		{GitRef: "a", Source: exec.SourceCron, TypeOf: "oltp"},
+		{GitRef: "a", Source: exec.SourceCron, TypeOf: "micro"},
+		{GitRef: "b", Source: exec.SourcePullRequest, TypeOf: "tpcc", PullNB: 42},
+		{UUID: backfillUUID, GitRef: "d", Source: exec.SourceBackfill, TypeOf: "oltp"},
+		{GitRef: "c", Source: exec.SourceCron, TypeOf: "generic"},
+	}
+
+	baseline := executionIdentifier{GitRef: "z", Source: exec.SourceCron, BenchmarkType: "oltp", PlannerVersion: "Gen4"}
+	metadata := map[string]map[string]string{
+		failedUUID.String(): {metadataCompareWith: `[{"GitRef":"z","Source":"cron","BenchmarkType":"oltp","PlannerVersion":"Gen4","PullNb":0}]`, metadataNotifyAlways: "true"},
+		backfillUUID.String(): {
+			metadataPlannerVersion: "V3",
+			metadataLowPriority:    "true",
+			metadataBaselineOnly:   "true",
+			metadataLabels:         `{"noop":"true"}`,
+		},
+	}
+
+	elements := s.createRequeueElements(executions, metadata, "Gen4")
+	var identifiers []executionIdentifier
+	for _, element := range elements {
+		c.Assert(element.retry, qt.Equals, 1)
+		identifiers = append(identifiers, element.identifier)
+	}
+	c.Assert(identifiers, qt.DeepEquals, []executionIdentifier{
+		{GitRef: "a", Source: exec.SourceCron, BenchmarkType: "oltp", PlannerVersion: "Gen4"},
+		{GitRef: "a", Source: exec.SourceCron, BenchmarkType: "micro"},
+		{GitRef: "b", Source: exec.SourcePullRequest, BenchmarkType: "tpcc", PlannerVersion: "Gen4", PullNb: 42},
+		{GitRef: "d", Source: exec.SourceBackfill, BenchmarkType: "oltp", PlannerVersion: "V3"},
+	})
+	c.Assert(elements[0].config, qt.Equals, "oltp.yaml")
+	c.Assert(elements[0].compareWith, qt.DeepEquals, []executionIdentifier{baseline})
+	c.Assert(elements[0].notifyAlways, qt.IsTrue)
+	c.Assert(elements[1].compareWith, qt.HasLen, 0)
+	c.Assert(elements[1].notifyAlways, qt.IsFalse)
+	c.Assert(elements[0].lowPriority || elements[0].baselineOnly || elements[0].noop, qt.IsFalse)
+	c.Assert(elements[3].lowPriority, qt.IsTrue)
+	c.Assert(elements[3].baselineOnly, qt.IsTrue)
+	c.Assert(elements[3].noop, qt.IsTrue)
+}
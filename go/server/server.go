@@ -20,8 +20,11 @@ package server
 
 import (
 	"errors"
+	"fmt"
 	"github.com/google/uuid"
+	"github.com/vitessio/arewefastyet/go/exec"
 	"github.com/vitessio/arewefastyet/go/slack"
+	"github.com/vitessio/arewefastyet/go/storage"
 	"github.com/vitessio/arewefastyet/go/storage/psdb"
 	"html/template"
 	"sync"
@@ -49,6 +52,48 @@ const (
 	flagPullRequestLabelTrigger              = "web-pr-label-trigger"
 	flagPullRequestLabelTriggerWithPlannerV3 = "web-pr-label-trigger-planner-v3"
 	flagCronNbRetry                          = "web-cron-nb-retry"
+	flagStuckExecutionMaxDuration            = "web-stuck-execution-max-duration"
+	flagInfraFailureRequeueDelay             = "web-infra-failure-requeue-delay"
+	flagInfraFailureMaxRequeues              = "web-infra-failure-max-requeues"
+	flagMergeWebhookSecret                   = "web-merge-webhook-secret"
+	flagAPIToken                             = "web-api-token"
+	flagThroughputWindow                     = "web-throughput-window"
+	flagMicroBenchThresholds                 = "web-microbench-thresholds"
+	flagCompareWithPreviousPlanner           = "web-compare-with-previous-planner"
+	flagNotifyImprovements                   = "web-notify-improvements"
+	flagImprovementsSlackChannel             = "web-improvements-slack-channel"
+	flagSyncRunTimeout                       = "web-sync-run-timeout"
+	flagNotifyFailures                       = "web-notify-failures"
+	flagFailureNotificationInterval          = "web-failure-notification-interval"
+	flagExecutionLogsURL                     = "web-execution-logs-url"
+	flagRegressionDetector                   = "web-regression-detector"
+	flagSourceBranches                       = "web-source-branches"
+	flagAutoBisect                           = "web-auto-bisect"
+	flagConsolidateReports                   = "web-consolidate-reports"
+	flagLabelNoopCommits                     = "web-label-noop-commits"
+	flagMaxBaselineAge                       = "web-max-baseline-age"
+	flagNotifyNoBaselines                    = "web-notify-no-baselines"
+	flagTracingEndpoint                      = "web-otlp-endpoint"
+	flagTracingInsecure                      = "web-otlp-insecure"
+	flagBackfillMaxCommits                   = "web-backfill-max-commits"
+	flagRequeueMaxExecutions                 = "web-requeue-max-executions"
+	flagSuites                               = "web-suites"
+	flagGitHubToken                          = "web-github-token"
+	flagGitHubStatusRepo                     = "web-github-status-repo"
+	flagBackfillEnqueueInterval              = "web-backfill-enqueue-interval"
+	flagAnomalyThreshold                     = "web-anomaly-threshold"
+	flagAnomalyHistoryDays                   = "web-anomaly-history-days"
+	flagBaselinePercentile                   = "web-baseline-percentile"
+	flagBaselineWindow                       = "web-baseline-window"
+	flagExternalBaseline                     = "web-external-baseline"
+	flagRegressionHoldDown                   = "web-regression-hold-down"
+	flagTimeZone                             = "web-time-zone"
+	flagSourceBaselines                      = "web-source-baselines"
+	flagMetricSets                           = "web-metric-sets"
+	flagBenchmarkConfigDir                   = "web-benchmark-config-dir"
+	flagGitLabels                            = "web-git-labels"
+	flagDeferredCleanup                      = "web-deferred-cleanup"
+	flagRegressionCleanupDelay               = "web-regression-cleanup-delay"
 )
 
 type Server struct {
@@ -60,22 +105,179 @@ type Server struct {
 	vitessPathMu    sync.Mutex
 	localVitessPath string
 
+	// sourceBranches maps the source of executions to the git branch the local
+	// clone of vitess is reset to before creating them. Sources that are not listed
+	// use defaultSourceBranch, or their release branch for the release branch sources.
+	sourceBranches map[string]string
+
 	dbCfg    *psdb.Config
-	dbClient *psdb.Client
+	dbClient storage.SQLClient
+
+	// dbReadClient is the client of the read replica, nil if none is configured, see readDB.
+	dbReadClient storage.SQLClient
 
 	// Configuration used to send message to Slack.
 	slackConfig slack.Config
 
+	// notifyImprovements enables the notification of significant improvements, which
+	// are sent to improvementsSlackChannel, or to the default Slack channel if empty.
+	notifyImprovements       bool
+	improvementsSlackChannel string
+
+	// notifyFailures enables the notification of the executions that failed after
+	// exhausting their retries, rate-limited by failureRateLimiter.
+	notifyFailures     bool
+	failureRateLimiter failureRateLimiter
+
+	// executionLogsURL is the base URL under which the logs of an execution
+	// are served, the execution's UUID is appended to it.
+	executionLogsURL string
+
 	cronSchedule             string
 	cronSchedulePullRequests string
 	cronNbRetry              int
+
+	// timeZone is the IANA time zone in which the CRON schedules are interpreted and
+	// the dates are displayed, location is its loaded location. Timestamps are stored in UTC.
+	timeZone string
+	location *time.Location
+
+	// stuckExecutionMaxDuration is the maximum duration an execution can stay started
+	// before it gets marked as timed out. A value of zero disables the watchdog.
+	stuckExecutionMaxDuration time.Duration
+
+	// infraFailureRequeueDelay is the delay after which an execution that failed
+	// because of the infrastructure is executed again.
+	infraFailureRequeueDelay time.Duration
+
+	// infraFailureMaxRequeues is the number of times an element is requeued because of
+	// infrastructure failures before they start consuming its retries.
+	infraFailureMaxRequeues int
+
 	microbenchConfigPath     string
 	macrobenchConfigPathOLTP string
 	macrobenchConfigPathTPCC string
 
+	// benchmarkConfigDir is the directory of benchmark definitions, see loadBenchmarkConfigDir.
+	// Its definitions are stored in benchmarkConfigs and take precedence over the
+	// configuration files given for each type of benchmark.
+	benchmarkConfigDir string
+	benchmarkConfigs   map[string]string
+
+	// microbenchThresholds maps the name of a microbenchmark to the threshold
+	// used to detect its regressions, overriding the default threshold.
+	microbenchThresholds map[string]string
+
+	// maxBaselineAge is the maximum age of the previous execution of a source for it
+	// to be used as a baseline. A value of zero disables the limit.
+	maxBaselineAge time.Duration
+
+	// labelNoopCommits enables the detection of the commits that only changed files that are
+	// not expected to impact performance, their executions are labeled with noopLabel.
+	labelNoopCommits bool
+
+	// gitLabels lists the labels derived from the git ref of the executions when they
+	// are prepared, see deriveGitLabels.
+	gitLabels []string
+
+	// deferredCleanup makes the infrastructure of macrobenchmarks torn down once their
+	// comparisons are done instead of at the end of their execution, it is held for
+	// regressionCleanupDelay when a regression was found, see cleanUpAfterComparison.
+	deferredCleanup        bool
+	regressionCleanupDelay time.Duration
+
+	// consolidateReports makes the comparisons of an element against several baselines
+	// notified in a single message instead of one message per baseline.
+	consolidateReports bool
+
+	// autoBisect enables the automatic bisection of the regressions detected by the
+	// cron between two consecutive git refs of the main branch.
+	autoBisect bool
+
+	// tracingConfig configures the export of the comparisons of the executions
+	// to the executions' OpenTelemetry traces.
+	tracingConfig exec.TracingConfig
+
+	// notifyNoBaselines enables the notification, once per benchmark, of the benchmarks
+	// that have no baseline yet. baselineNotifications records the notified benchmarks, along
+	// with the stale baselines notified by notifyNoRecentBaseline.
+	notifyNoBaselines     bool
+	baselineNotifications baselineNotifications
+
+	// anomalyThreshold is the z-score above which a metric of an execution is notified
+	// as anomalous compared to the executions of the last anomalyHistoryDays days.
+	anomalyThreshold   float64
+	anomalyHistoryDays int
+
+	// backfillMaxCommits is the maximum number of commits a backfill can enqueue
+	// unless it is forced, and backfillEnqueueInterval the delay between the
+	// enqueuing of two commits of a backfill.
+	backfillMaxCommits      int
+	backfillEnqueueInterval time.Duration
+
+	// requeueMaxExecutions is the maximum number of failed executions a requeue
+	// can enqueue unless it is forced.
+	requeueMaxExecutions int
+
+	// suites maps the name of a suite to the benchmark types it runs, separated by
+	// suiteTypesSeparator. suiteBatches tracks the batches the suites expanded into.
+	suites       map[string]string
+	suiteBatches suiteBatches
+
+	// regressionDetector is the name of the RegressionDetector used to compare executions.
+	regressionDetector string
+
+	// baselinePercentile and baselineWindow configure the percentile RegressionDetector: the
+	// baseline is the given percentile of the last baselineWindow executions of its source.
+	baselinePercentile float64
+	baselineWindow     int
+
+	// externalBaseline is the path or URL of the JSON baseline used by the external RegressionDetector.
+	externalBaseline string
+
+	// regressionHoldDown prevents the same regression against the same baseline
+	// from being notified again before its window passes or it is resolved.
+	regressionHoldDown regressionHoldDown
+
+	// sourceBaselines maps the source of the cron executions to the strategy deciding their
+	// baseline, see parseBaselineStrategy. Keys ending with sourceBaselineWildcard match by prefix.
+	sourceBaselines map[string]string
+
+	// metricSets maps a benchmark type to the metrics considered by its regression
+	// detection, the first one being its primary metric, see parseMetricSet.
+	metricSets map[string]string
+
+	// compareWithPreviousPlanner makes the cron compare macrobenchmarks against the same
+	// git ref using the previous planner version instead of the previous git ref.
+	compareWithPreviousPlanner bool
+
 	prLabelTrigger   string
 	prLabelTriggerV3 string
 
+	// githubToken is the token used to report the results of the pull requests' comparisons as
+	// commit statuses of githubStatusRepo. If empty, no status is reported.
+	githubToken      string
+	githubStatusRepo string
+
+	// mergeWebhookSecret is the secret used by GitHub to sign the merge webhook's requests.
+	mergeWebhookSecret string
+
+	// apiToken is the token required by the API endpoints modifying the queue or the
+	// executions, see requireAPIToken. If empty, these endpoints do not require a token.
+	apiToken string
+
+	// throughputWindow is the default window over which the queue's throughput is computed.
+	throughputWindow time.Duration
+
+	// syncRunTimeout is the maximum duration a synchronous run waits for its executions.
+	syncRunTimeout time.Duration
+
+	// queue contains the executions to run and to compare.
+	queue *Queue
+
+	// completions records the completion of the queue's executions.
+	completions completionTracker
+
 	// Mode used to run the server.
 	Mode
 }
@@ -88,17 +290,56 @@ func (s *Server) AddToCommand(cmd *cobra.Command) {
 	cmd.Flags().Var(&s.Mode, flagMode, "Specify the mode on which the server will run")
 
 	// execution configuration flags
-	cmd.Flags().StringVar(&s.microbenchConfigPath, flagMicroBenchConfigFile, "", "Path to the configuration file used to execute microbenchmark.")
-	cmd.Flags().StringVar(&s.macrobenchConfigPathOLTP, flagMacroBenchConfigFileOLTP, "", "Path to the configuration file used to execute OLTP macrobenchmark.")
-	cmd.Flags().StringVar(&s.macrobenchConfigPathTPCC, flagMacroBenchConfigFileTPCC, "", "Path to the configuration file used to execute TPCC macrobenchmark.")
+	cmd.Flags().StringVar(&s.microbenchConfigPath, flagMicroBenchConfigFile, "", "Path to the configuration file, or directory of configuration files, used to execute microbenchmark.")
+	cmd.Flags().StringVar(&s.macrobenchConfigPathOLTP, flagMacroBenchConfigFileOLTP, "", "Path to the configuration file, or directory of configuration files, used to execute OLTP macrobenchmark.")
+	cmd.Flags().StringVar(&s.macrobenchConfigPathTPCC, flagMacroBenchConfigFileTPCC, "", "Path to the configuration file, or directory of configuration files, used to execute TPCC macrobenchmark.")
+	cmd.Flags().StringVar(&s.benchmarkConfigDir, flagBenchmarkConfigDir, "", "Path to a directory of benchmark definitions, each file or sub-directory defines the benchmark type given by its exec-type key. Definitions take precedence over the configuration files given for each type.")
+	cmd.Flags().StringToStringVar(&s.sourceBranches, flagSourceBranches, map[string]string{}, "Git branch the local clone of vitess is reset to for each source of executions (e.g. cron=main). Sources that are not listed use the main branch, or their release branch for the release branch sources.")
+	cmd.Flags().StringToStringVar(&s.microbenchThresholds, flagMicroBenchThresholds, map[string]string{}, "Regression thresholds, as a decrease in percentage, of specific microbenchmarks (e.g. BenchmarkName=25). Microbenchmarks that are not listed use the default threshold.")
+	cmd.Flags().StringVar(&s.regressionDetector, flagRegressionDetector, defaultRegressionDetector, "Name of the algorithm used to detect regressions and improvements. Built-in algorithms: pairwise, percentile, external. Other algorithms can be registered with RegisterRegressionDetector.")
+	cmd.Flags().Float64Var(&s.baselinePercentile, flagBaselinePercentile, 50, "Percentile, in terms of performance, of the last executions used as the baseline by the percentile regression detector. Lower percentiles are more optimistic and trigger fewer regressions.")
+	cmd.Flags().DurationVar(&s.regressionHoldDown.window, flagRegressionHoldDown, 0, "Duration during which a regression of a metric is not notified again when compared against the same baseline, unless it is resolved in the meantime. Zero notifies every regression.")
+	cmd.Flags().IntVar(&s.baselineWindow, flagBaselineWindow, 10, "Number of executions aggregated into the baseline by the percentile regression detector.")
+	cmd.Flags().StringVar(&s.externalBaseline, flagExternalBaseline, "", "Path or URL of the JSON file containing the reference metrics compared against by the external regression detector.")
 	cmd.Flags().StringVar(&s.cronSchedule, flagCronSchedule, "@midnight", "Execution CRON schedule defaults to every day at midnight. An empty string will result in no CRON.")
+	cmd.Flags().StringVar(&s.timeZone, flagTimeZone, "UTC", "IANA time zone (e.g. Europe/Paris) in which the CRON schedules are interpreted and the dates are displayed. Timestamps are still stored in UTC.")
 	cmd.Flags().StringVar(&s.cronSchedulePullRequests, flagCronSchedulePullRequests, "*/5 * * * *", "Execution CRON schedule for pull requests benchmarks. An empty string will result in no CRON. Defaults to an execution every 5 minutes.")
 	cmd.Flags().IntVar(&s.cronNbRetry, flagCronNbRetry, 1, "Number of retries allowed for each cron job.")
+	cmd.Flags().DurationVar(&s.infraFailureRequeueDelay, flagInfraFailureRequeueDelay, 15*time.Minute, "Delay after which an execution that failed because of the infrastructure is executed again. Such failures do not consume the execution's retries.")
+	cmd.Flags().IntVar(&s.infraFailureMaxRequeues, flagInfraFailureMaxRequeues, 5, "Number of times an execution is requeued because of the infrastructure before such failures consume its retries.")
+	cmd.Flags().DurationVar(&s.throughputWindow, flagThroughputWindow, 24*time.Hour, "Default window over which the throughput of the execution queue is computed.")
+	cmd.Flags().DurationVar(&s.syncRunTimeout, flagSyncRunTimeout, 3*time.Hour, "Maximum duration a synchronous run waits for its executions to finish.")
+	cmd.Flags().DurationVar(&s.stuckExecutionMaxDuration, flagStuckExecutionMaxDuration, 4*time.Hour, "Maximum duration an execution can stay started before being marked as timed out. Zero disables the check.")
+	cmd.Flags().BoolVar(&s.compareWithPreviousPlanner, flagCompareWithPreviousPlanner, false, "Compare the macrobenchmarks of cron jobs against the same commit using the previous planner version, instead of the previous commit using the same planner version.")
+	cmd.Flags().BoolVar(&s.notifyImprovements, flagNotifyImprovements, false, "Notify Slack of significant improvements, using the same thresholds as regressions.")
+	cmd.Flags().StringVar(&s.improvementsSlackChannel, flagImprovementsSlackChannel, "", "Slack channel on which to post improvements. Defaults to the Slack channel used for regressions.")
+	cmd.Flags().DurationVar(&s.maxBaselineAge, flagMaxBaselineAge, 0, "Maximum age of the previous execution of a source for it to be used as a baseline, older executions are not compared against. Zero disables the limit.")
+	cmd.Flags().StringVar(&s.tracingConfig.Endpoint, flagTracingEndpoint, "", "Host and port of the OTLP/HTTP endpoint to which the comparisons of the executions are exported, as spans of the executions' traces. Tracing is disabled if empty.")
+	cmd.Flags().BoolVar(&s.tracingConfig.Insecure, flagTracingInsecure, false, "Export the spans to the OTLP endpoint without TLS.")
+	cmd.Flags().BoolVar(&s.notifyNoBaselines, flagNotifyNoBaselines, false, "Notify Slack, once per source and benchmark type, that a benchmark has no baseline yet and that its results are not compared until one is accumulated.")
+	cmd.Flags().Float64Var(&s.anomalyThreshold, flagAnomalyThreshold, 0, "Number of standard deviations from the mean of the previous executions of the same source above which the metrics of a macrobenchmark are notified as anomalous. Zero disables the detection.")
+	cmd.Flags().IntVar(&s.anomalyHistoryDays, flagAnomalyHistoryDays, 30, "Number of days of previous executions forming the series against which anomalies are detected.")
+	cmd.Flags().BoolVar(&s.labelNoopCommits, flagLabelNoopCommits, false, "Label the executions of the commits that only changed files not expected to impact performance, such as tests or documentation, with the noop label, a flat result is then expected from their comparisons.")
+	cmd.Flags().StringSliceVar(&s.gitLabels, flagGitLabels, []string{}, "Labels derived from the git ref of the executions and set on them. The describe label uses git describe in the local clone of vitess, the release label is only set on the executions of the release branches and release tags. Available labels, with their key: "+gitLabelsUsage()+".")
+	cmd.Flags().BoolVar(&s.deferredCleanup, flagDeferredCleanup, false, "Tear down the infrastructure of the macrobenchmarks once their comparisons are done instead of at the end of their execution, holding it when a regression was found.")
+	cmd.Flags().DurationVar(&s.regressionCleanupDelay, flagRegressionCleanupDelay, time.Hour, "Delay during which the infrastructure of a macrobenchmark that regressed is held for investigation before being torn down, when "+flagDeferredCleanup+" is set. Zero holds it until it is cleaned up through the API. No other macrobenchmark is started while infrastructure is held, keep it short.")
+	cmd.Flags().BoolVar(&s.consolidateReports, flagConsolidateReports, false, "Notify the comparisons of an execution against several baselines in a single message, with a column per baseline.")
+	cmd.Flags().BoolVar(&s.autoBisect, flagAutoBisect, false, "Bisect the commits between two cron executions when a regression is detected, and notify Slack of the culprit.")
+	cmd.Flags().IntVar(&s.backfillMaxCommits, flagBackfillMaxCommits, 50, "Maximum number of commits a backfill can enqueue, larger ranges are refused unless forced. Zero disables the limit.")
+	cmd.Flags().IntVar(&s.requeueMaxExecutions, flagRequeueMaxExecutions, 50, "Maximum number of failed executions a requeue can enqueue, larger requeues are refused unless forced. Zero disables the limit.")
+	cmd.Flags().StringToStringVar(&s.suites, flagSuites, map[string]string{}, "Suites of benchmarks that can be enqueued together and are notified in a single message once they all completed (e.g. full=micro+oltp+tpcc).")
+	cmd.Flags().StringToStringVar(&s.sourceBaselines, flagSourceBaselines, map[string]string{}, "Strategy deciding the baseline of the cron executions of each source, among previous-same-source (default), latest-cron, tag:<tag> and golden:<git ref> (e.g. cron=previous-same-source,cron_release-*=tag:v14.0.0). A source ending with * applies to all the sources it prefixes.")
+	cmd.Flags().StringToStringVar(&s.metricSets, flagMetricSets, map[string]string{}, "Metrics considered by the regression detection of each benchmark type, separated by a + and starting with the primary metric of the type (e.g. micro=ns/op,oltp=qps.total+latency,tpcc=tps). The regressions of the other metrics are only reported as secondary detail. All metrics are considered for the types that are not listed.")
+	cmd.Flags().DurationVar(&s.backfillEnqueueInterval, flagBackfillEnqueueInterval, time.Minute, "Delay between the enqueuing of two commits of a backfill.")
+	cmd.Flags().BoolVar(&s.notifyFailures, flagNotifyFailures, false, "Notify Slack of the executions that failed after exhausting their retries.")
+	cmd.Flags().DurationVar(&s.failureRateLimiter.interval, flagFailureNotificationInterval, time.Hour, "Minimum interval between two failure notifications of a same source and benchmark type.")
+	cmd.Flags().StringVar(&s.executionLogsURL, flagExecutionLogsURL, "", "Base URL under which the logs of the executions are served, the execution's UUID is appended to it. Used in failure notifications.")
 	cmd.Flags().StringVar(&s.prLabelTrigger, flagPullRequestLabelTrigger, "Benchmark me", "GitHub Pull Request label that will trigger the execution of new execution.")
 	cmd.Flags().StringVar(&s.prLabelTriggerV3, flagPullRequestLabelTriggerWithPlannerV3, "Benchmark me (V3)", "GitHub Pull Request label that will trigger the execution of new execution using the V3 planner.")
-	_ = cmd.MarkFlagRequired(flagMicroBenchConfigFile)
-	_ = cmd.MarkFlagRequired(flagMacroBenchConfigFileOLTP)
-	_ = cmd.MarkFlagRequired(flagMacroBenchConfigFileTPCC)
+	cmd.Flags().StringVar(&s.githubToken, flagGitHubToken, "", "GitHub token used to report the results of the pull requests' comparisons as commit statuses. If empty, no status is reported.")
+	cmd.Flags().StringVar(&s.githubStatusRepo, flagGitHubStatusRepo, "vitessio/vitess", "GitHub repository on which the commit statuses of the pull requests are reported.")
+	cmd.Flags().StringVar(&s.mergeWebhookSecret, flagMergeWebhookSecret, "", "Secret used to verify the signature of GitHub's pull request webhook. If empty, the webhook is disabled.")
+	cmd.Flags().StringVar(&s.apiToken, flagAPIToken, "", "Token required, as the bearer token of the Authorization header, by the API endpoints modifying the queue or the executions. If empty, these endpoints do not require a token.")
 
 	_ = viper.BindPFlag(flagPort, cmd.Flags().Lookup(flagPort))
 	_ = viper.BindPFlag(flagTemplatePath, cmd.Flags().Lookup(flagTemplatePath))
@@ -108,11 +349,53 @@ func (s *Server) AddToCommand(cmd *cobra.Command) {
 	_ = viper.BindPFlag(flagMicroBenchConfigFile, cmd.Flags().Lookup(flagMicroBenchConfigFile))
 	_ = viper.BindPFlag(flagMacroBenchConfigFileOLTP, cmd.Flags().Lookup(flagMacroBenchConfigFileOLTP))
 	_ = viper.BindPFlag(flagMacroBenchConfigFileTPCC, cmd.Flags().Lookup(flagMacroBenchConfigFileTPCC))
+	_ = viper.BindPFlag(flagBenchmarkConfigDir, cmd.Flags().Lookup(flagBenchmarkConfigDir))
+	_ = viper.BindPFlag(flagMaxBaselineAge, cmd.Flags().Lookup(flagMaxBaselineAge))
+	_ = viper.BindPFlag(flagLabelNoopCommits, cmd.Flags().Lookup(flagLabelNoopCommits))
+	_ = viper.BindPFlag(flagGitLabels, cmd.Flags().Lookup(flagGitLabels))
+	_ = viper.BindPFlag(flagDeferredCleanup, cmd.Flags().Lookup(flagDeferredCleanup))
+	_ = viper.BindPFlag(flagRegressionCleanupDelay, cmd.Flags().Lookup(flagRegressionCleanupDelay))
+	_ = viper.BindPFlag(flagNotifyNoBaselines, cmd.Flags().Lookup(flagNotifyNoBaselines))
+	_ = viper.BindPFlag(flagAnomalyThreshold, cmd.Flags().Lookup(flagAnomalyThreshold))
+	_ = viper.BindPFlag(flagAnomalyHistoryDays, cmd.Flags().Lookup(flagAnomalyHistoryDays))
+	_ = viper.BindPFlag(flagTracingEndpoint, cmd.Flags().Lookup(flagTracingEndpoint))
+	_ = viper.BindPFlag(flagTracingInsecure, cmd.Flags().Lookup(flagTracingInsecure))
+	_ = viper.BindPFlag(flagConsolidateReports, cmd.Flags().Lookup(flagConsolidateReports))
+	_ = viper.BindPFlag(flagAutoBisect, cmd.Flags().Lookup(flagAutoBisect))
+	_ = viper.BindPFlag(flagBackfillMaxCommits, cmd.Flags().Lookup(flagBackfillMaxCommits))
+	_ = viper.BindPFlag(flagRequeueMaxExecutions, cmd.Flags().Lookup(flagRequeueMaxExecutions))
+	_ = viper.BindPFlag(flagSuites, cmd.Flags().Lookup(flagSuites))
+	_ = viper.BindPFlag(flagSourceBaselines, cmd.Flags().Lookup(flagSourceBaselines))
+	_ = viper.BindPFlag(flagMetricSets, cmd.Flags().Lookup(flagMetricSets))
+	_ = viper.BindPFlag(flagGitHubToken, cmd.Flags().Lookup(flagGitHubToken))
+	_ = viper.BindPFlag(flagGitHubStatusRepo, cmd.Flags().Lookup(flagGitHubStatusRepo))
+	_ = viper.BindPFlag(flagBackfillEnqueueInterval, cmd.Flags().Lookup(flagBackfillEnqueueInterval))
+	_ = viper.BindPFlag(flagSourceBranches, cmd.Flags().Lookup(flagSourceBranches))
+	_ = viper.BindPFlag(flagMicroBenchThresholds, cmd.Flags().Lookup(flagMicroBenchThresholds))
+	_ = viper.BindPFlag(flagRegressionDetector, cmd.Flags().Lookup(flagRegressionDetector))
+	_ = viper.BindPFlag(flagBaselinePercentile, cmd.Flags().Lookup(flagBaselinePercentile))
+	_ = viper.BindPFlag(flagBaselineWindow, cmd.Flags().Lookup(flagBaselineWindow))
+	_ = viper.BindPFlag(flagExternalBaseline, cmd.Flags().Lookup(flagExternalBaseline))
+	_ = viper.BindPFlag(flagRegressionHoldDown, cmd.Flags().Lookup(flagRegressionHoldDown))
 	_ = viper.BindPFlag(flagCronSchedule, cmd.Flags().Lookup(flagCronSchedule))
+	_ = viper.BindPFlag(flagTimeZone, cmd.Flags().Lookup(flagTimeZone))
 	_ = viper.BindPFlag(flagCronSchedulePullRequests, cmd.Flags().Lookup(flagCronSchedulePullRequests))
 	_ = viper.BindPFlag(flagCronNbRetry, cmd.Flags().Lookup(flagCronNbRetry))
+	_ = viper.BindPFlag(flagStuckExecutionMaxDuration, cmd.Flags().Lookup(flagStuckExecutionMaxDuration))
+	_ = viper.BindPFlag(flagInfraFailureRequeueDelay, cmd.Flags().Lookup(flagInfraFailureRequeueDelay))
+	_ = viper.BindPFlag(flagInfraFailureMaxRequeues, cmd.Flags().Lookup(flagInfraFailureMaxRequeues))
+	_ = viper.BindPFlag(flagCompareWithPreviousPlanner, cmd.Flags().Lookup(flagCompareWithPreviousPlanner))
+	_ = viper.BindPFlag(flagNotifyImprovements, cmd.Flags().Lookup(flagNotifyImprovements))
+	_ = viper.BindPFlag(flagImprovementsSlackChannel, cmd.Flags().Lookup(flagImprovementsSlackChannel))
+	_ = viper.BindPFlag(flagNotifyFailures, cmd.Flags().Lookup(flagNotifyFailures))
+	_ = viper.BindPFlag(flagFailureNotificationInterval, cmd.Flags().Lookup(flagFailureNotificationInterval))
+	_ = viper.BindPFlag(flagExecutionLogsURL, cmd.Flags().Lookup(flagExecutionLogsURL))
 	_ = viper.BindPFlag(flagPullRequestLabelTrigger, cmd.Flags().Lookup(flagPullRequestLabelTrigger))
 	_ = viper.BindPFlag(flagPullRequestLabelTriggerWithPlannerV3, cmd.Flags().Lookup(flagPullRequestLabelTriggerWithPlannerV3))
+	_ = viper.BindPFlag(flagMergeWebhookSecret, cmd.Flags().Lookup(flagMergeWebhookSecret))
+	_ = viper.BindPFlag(flagAPIToken, cmd.Flags().Lookup(flagAPIToken))
+	_ = viper.BindPFlag(flagThroughputWindow, cmd.Flags().Lookup(flagThroughputWindow))
+	_ = viper.BindPFlag(flagSyncRunTimeout, cmd.Flags().Lookup(flagSyncRunTimeout))
 
 	s.slackConfig.AddToCommand(cmd)
 	if s.dbCfg == nil {
@@ -122,8 +405,9 @@ func (s *Server) AddToCommand(cmd *cobra.Command) {
 }
 
 func (s *Server) isReady() bool {
+	configs := s.getConfigFiles()
 	return s.port != "" && s.templatePath != "" && s.staticPath != "" &&
-		s.microbenchConfigPath != "" && s.macrobenchConfigPathOLTP != "" && s.macrobenchConfigPathTPCC != "" && s.localVitessPath != ""
+		configs["micro"] != "" && configs["oltp"] != "" && configs["tpcc"] != "" && s.localVitessPath != ""
 }
 
 func (s *Server) Run() error {
@@ -141,10 +425,52 @@ func (s *Server) Run() error {
 		defer cleanLogger()
 	}
 
+	if s.benchmarkConfigDir != "" {
+		configs, err := loadBenchmarkConfigDir(s.benchmarkConfigDir)
+		if err != nil {
+			return err
+		}
+		s.benchmarkConfigs = configs
+	}
+
 	if !s.isReady() {
 		return errors.New(ErrorIncorrectConfiguration)
 	}
 
+	if _, err := s.getMicrobenchThresholds(); err != nil {
+		return err
+	}
+
+	if _, err := s.getRegressionDetector(); err != nil {
+		return err
+	}
+
+	if _, err := s.getSuites(); err != nil {
+		return err
+	}
+
+	if err := s.validateGitLabels(); err != nil {
+		return err
+	}
+
+	if s.regressionCleanupDelay < 0 {
+		return fmt.Errorf("%s must not be negative", flagRegressionCleanupDelay)
+	}
+
+	if _, err := s.getSourceBaselines(); err != nil {
+		return err
+	}
+
+	if _, err := s.getMetricSets(); err != nil {
+		return err
+	}
+
+	location, err := s.loadLocation()
+	if err != nil {
+		return err
+	}
+	s.location = location
+
 	if err := s.setupLocalVitess(); err != nil {
 		return err
 	}
@@ -153,7 +479,15 @@ func (s *Server) Run() error {
 		return err
 	}
 
-	err := s.createCrons()
+	s.queue = NewQueue(maxConcurJob)
+	if err := s.restoreQueue(); err != nil {
+		return err
+	}
+	if err := s.restoreInfrastructureHolds(); err != nil {
+		return err
+	}
+
+	err = s.createCrons()
 	if err != nil {
 		return err
 	}
@@ -180,10 +514,7 @@ func (s *Server) Run() error {
 		},
 		"uuidToString": func(u uuid.UUID) string { return u.String() },
 		"timeToDateString": func(t *time.Time) string {
-			if t == nil {
-				return ""
-			}
-			return t.Format(time.RFC822)
+			return formatDate(t, s.location)
 		},
 	})
 
@@ -225,6 +556,33 @@ func (s *Server) Run() error {
 	// status page
 	s.router.GET("/status", s.statusHandler)
 
+	// API
+	api := s.router.Group("/api")
+	api.GET("/regressions/open", s.openRegressionsHandler)
+	api.GET("/compare", s.compareAPIHandler)
+	api.GET("/compare/sources", s.compareSourcesAPIHandler)
+	api.GET("/compare/all", s.compareAllAPIHandler)
+	api.GET("/compare/providers", s.compareProvidersAPIHandler)
+	api.GET("/compare/query_plans", s.queryPlansAPIHandler)
+	api.GET("/compare/workloads", s.workloadsAPIHandler)
+	api.GET("/macrobench/histogram", s.histogramAPIHandler)
+	if s.mergeWebhookSecret != "" {
+		api.POST("/webhook/merge", s.mergeWebhookHandler)
+	}
+	api.GET("/queue/throughput", s.queueThroughputHandler)
+	api.GET("/suite/:batch", s.suiteStatusHandler)
+	api.GET("/executions", s.executionsAPIHandler)
+	api.GET("/executions/failed", s.failedExecutionsAPIHandler)
+	api.GET("/executions/sources", s.executionSourcesAPIHandler)
+	api.GET("/executions/types", s.executionTypesAPIHandler)
+	api.GET("/execution/:uuid/results", s.executionResultsAPIHandler)
+	api.GET("/execution/:uuid/profile", s.executionProfileAPIHandler)
+	api.GET("/execution/:uuid/inventory", s.executionInventoryAPIHandler)
+	if s.apiToken == "" {
+		slog.Warnf("%s is not set, the API endpoints modifying the queue or the executions do not require a token", flagAPIToken)
+	}
+	s.registerAuthenticatedAPI(api.Group("", s.requireAPIToken))
+
 	return s.router.Run(":" + s.port)
 }
 
@@ -18,10 +18,30 @@
 
 package server
 
-func (s *Server) createStorages() (err error) {
-	s.dbClient, err = s.dbCfg.NewClient()
+import "github.com/vitessio/arewefastyet/go/storage"
+
+func (s *Server) createStorages() error {
+	client, err := s.dbCfg.NewClient()
 	if err != nil {
-		return
+		return err
+	}
+	s.dbClient = client
+	if readCfg, ok := s.dbCfg.ReadConfig(); ok {
+		readClient, err := readCfg.NewClient()
+		if err != nil {
+			return err
+		}
+		s.dbReadClient = readClient
+	}
+	return nil
+}
+
+// readDB returns the client used by the read paths, such as comparisons, that
+// can tolerate replication lag: the read replica if one is configured, and
+// the primary otherwise.
+func (s *Server) readDB() storage.SQLClient {
+	if s.dbReadClient != nil {
+		return s.dbReadClient
 	}
-	return
+	return s.dbClient
 }
@@ -0,0 +1,397 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/vitessio/arewefastyet/go/exec"
+	"github.com/vitessio/arewefastyet/go/slack"
+	"github.com/vitessio/arewefastyet/go/tools/git"
+)
+
+const (
+	// suiteTypesSeparator separates the benchmark types of a suite in its configuration,
+	// such as "micro+oltp+tpcc".
+	suiteTypesSeparator = "+"
+
+	// suiteMemberPending is the status of a member of a batch that did not finish nor fail yet.
+	suiteMemberPending = "pending"
+
+	// suiteBatchRetention is the duration during which a completed batch can still be queried.
+	suiteBatchRetention = 7 * 24 * time.Hour
+)
+
+var errUnknownSuite = errors.New("unknown suite")
+
+// suiteRequest is the body expected by suiteHandler.
+type suiteRequest struct {
+	Suite string `json:"suite" binding:"required"`
+
+	// GitRef is the git ref to benchmark, if empty the latest commit of the
+	// branch configured for Source is benchmarked.
+	GitRef         string `json:"git_ref"`
+	Source         string `json:"source" binding:"required"`
+	PlannerVersion string `json:"planner_version"`
+
+	// CompareWith lists the git refs each member of the suite is compared against once
+	// finished. They are enqueued with the same source as baseline-only executions.
+	CompareWith []string `json:"compare_with"`
+}
+
+// suiteMember is one of the executions of a suiteBatch.
+type suiteMember struct {
+	identifier executionIdentifier
+	status     string
+	reports    []baselineReport
+}
+
+// suiteBatch is the set of executions a suite expanded into for a git ref.
+type suiteBatch struct {
+	id, suite string
+	created   time.Time
+	members   []*suiteMember
+}
+
+// suiteBatchStatus is the combined status of a suiteBatch, as returned by suiteStatusHandler.
+type suiteBatchStatus struct {
+	BatchID string              `json:"batch_id"`
+	Suite   string              `json:"suite"`
+	Status  string              `json:"status"`
+	Members []suiteMemberStatus `json:"members"`
+}
+
+type suiteMemberStatus struct {
+	GitRef         string `json:"git_ref"`
+	Source         string `json:"source"`
+	Type           string `json:"type"`
+	PlannerVersion string `json:"planner_version"`
+	Status         string `json:"status"`
+}
+
+// suiteBatches tracks the batches of the suites until they complete. Batches are kept
+// in memory: after a restart, the batches are rebuilt from the members restored from
+// the queue table, without the members that completed before the restart.
+type suiteBatches struct {
+	mu      sync.Mutex
+	batches map[string]*suiteBatch
+}
+
+// getSuites returns the benchmark types of each configured suite.
+func (s *Server) getSuites() (map[string][]string, error) {
+	configs := s.getConfigFiles()
+	suites := make(map[string][]string, len(s.suites))
+	for name, value := range s.suites {
+		seen := map[string]bool{}
+		var types []string
+		for _, typ := range strings.Split(value, suiteTypesSeparator) {
+			typ = strings.TrimSpace(typ)
+			if _, ok := configs[typ]; !ok {
+				return nil, fmt.Errorf("invalid suite %s: %s %s", name, errUnknownBenchmarkType, typ)
+			}
+			if !seen[typ] {
+				seen[typ] = true
+				types = append(types, typ)
+			}
+		}
+		suites[name] = types
+	}
+	return suites, nil
+}
+
+// suiteHandler expands a suite into one execution per benchmark type of the suite, sharing a
+// batch ID. The members are compared silently and a single notification aggregating their
+// results is sent once all of them finished or failed.
+func (s *Server) suiteHandler(c *gin.Context) {
+	var req suiteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	suites, err := s.getSuites()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	types, ok := suites[req.Suite]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s %s", errUnknownSuite, req.Suite)})
+		return
+	}
+
+	req.GitRef, err = s.resolveGitRef(req.GitRef, req.Source)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	batch, elements := s.createSuiteElements(req, types, uuid.NewString())
+	for _, member := range batch.members {
+		exists := s.queue.Contains(member.identifier)
+		if !exists {
+			exists, err = s.checkIfExecutionExists(member.identifier)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		if exists {
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("the %s benchmark of %s is already queued or executed", member.identifier.BenchmarkType, member.identifier.GitRef)})
+			return
+		}
+	}
+	s.suiteBatches.add(batch, time.Now())
+
+	go func() {
+		for _, element := range elements {
+			s.addToQueue(element)
+		}
+	}()
+	c.JSON(http.StatusAccepted, gin.H{"status": "queued", "batch_id": batch.id, "git_ref": req.GitRef, "types": types})
+}
+
+// suiteStatusHandler returns the combined status of a batch and the status of each of its members.
+func (s *Server) suiteStatusHandler(c *gin.Context) {
+	status, ok := s.suiteBatches.status(c.Param("batch"), s.queue.Snapshot())
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "batch not found"})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// createSuiteElements returns the batch of the given suite request and the queue elements to add,
+// which are the members of the batch followed by the baseline-only elements they are compared against.
+func (s *Server) createSuiteElements(req suiteRequest, types []string, batchID string) (*suiteBatch, []*executionQueueElement) {
+	configs := s.getConfigFiles()
+	batch := &suiteBatch{id: batchID, suite: req.Suite}
+	var elements, baselines []*executionQueueElement
+	for _, typ := range types {
+		planner := string(syncRunPlannerVersion(typ, req.PlannerVersion))
+		element := s.createSimpleExecutionQueueElement(req.Source, configs[typ], req.GitRef, typ, planner, false, 0)
+		element.batchID = batchID
+		for _, ref := range req.CompareWith {
+			baselineElement := s.createSimpleExecutionQueueElement(req.Source, configs[typ], ref, typ, planner, false, 0)
+			baselineElement.baselineOnly = true
+			element.compareWith = append(element.compareWith, baselineElement.identifier)
+			baselines = append(baselines, baselineElement)
+		}
+		batch.members = append(batch.members, &suiteMember{identifier: element.identifier, status: suiteMemberPending})
+		elements = append(elements, element)
+	}
+	return batch, append(elements, baselines...)
+}
+
+// completeSuiteMember records the final status of an element belonging to a batch, along with
+// its comparisons, and notifies the results of the batch if it was its last pending member.
+func (s *Server) completeSuiteMember(element *executionQueueElement, status string, reports []baselineReport) {
+	batch := s.suiteBatches.complete(element.batchID, element.identifier, status, reports, time.Now())
+	if batch == nil {
+		return
+	}
+	msg := slack.TextMessage{Content: formatSuiteReport(batch)}
+	if err := msg.Send(s.slackConfig); err != nil {
+		slog.Error(err)
+	}
+}
+
+// add tracks the given batch, and forgets the batches that completed before the retention.
+func (sb *suiteBatches) add(batch *suiteBatch, now time.Time) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	if sb.batches == nil {
+		sb.batches = map[string]*suiteBatch{}
+	}
+	for id, b := range sb.batches {
+		if b.done() && now.Sub(b.created) > suiteBatchRetention {
+			delete(sb.batches, id)
+		}
+	}
+	batch.created = now
+	sb.batches[batch.id] = batch
+}
+
+// restore adds the given element restored from the queue table to its batch as a pending
+// member, the batch is created if it was not restored yet. The name of the suite of the
+// batches created this way is unknown.
+func (sb *suiteBatches) restore(element *executionQueueElement, now time.Time) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	if sb.batches == nil {
+		sb.batches = map[string]*suiteBatch{}
+	}
+	batch, ok := sb.batches[element.batchID]
+	if !ok {
+		batch = &suiteBatch{id: element.batchID, created: now}
+		sb.batches[batch.id] = batch
+	}
+	for _, member := range batch.members {
+		if member.identifier == element.identifier {
+			return
+		}
+	}
+	batch.members = append(batch.members, &suiteMember{identifier: element.identifier, status: suiteMemberPending})
+}
+
+// complete records the final status and the comparisons of the member of the batch with the
+// given identifier. It returns the batch if that member was its last pending one, nil otherwise.
+func (sb *suiteBatches) complete(batchID string, identifier executionIdentifier, status string, reports []baselineReport, now time.Time) *suiteBatch {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	batch, ok := sb.batches[batchID]
+	if !ok || batch.done() {
+		return nil
+	}
+	for _, member := range batch.members {
+		if member.identifier == identifier && member.status == suiteMemberPending {
+			member.status = status
+			member.reports = reports
+		}
+	}
+	if !batch.done() {
+		return nil
+	}
+	return batch
+}
+
+// status returns the combined status of the batch with the given ID. The pending members
+// are reported as executing or queued depending on their state in the given queue.
+func (sb *suiteBatches) status(batchID string, queue map[executionIdentifier]executionQueueElement) (suiteBatchStatus, bool) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	batch, ok := sb.batches[batchID]
+	if !ok {
+		return suiteBatchStatus{}, false
+	}
+	status := suiteBatchStatus{BatchID: batch.id, Suite: batch.suite, Status: batch.status()}
+	for _, member := range batch.members {
+		memberStatus := member.status
+		if memberStatus == suiteMemberPending {
+			memberStatus = "queued"
+			if element, ok := queue[member.identifier]; ok && element.executing {
+				memberStatus = "executing"
+			}
+		}
+		status.Members = append(status.Members, suiteMemberStatus{
+			GitRef:         member.identifier.GitRef,
+			Source:         member.identifier.Source,
+			Type:           member.identifier.BenchmarkType,
+			PlannerVersion: member.identifier.PlannerVersion,
+			Status:         memberStatus,
+		})
+	}
+	return status, true
+}
+
+// done reports whether all the members of the batch finished or failed.
+func (b *suiteBatch) done() bool {
+	for _, member := range b.members {
+		if member.status == suiteMemberPending {
+			return false
+		}
+	}
+	return true
+}
+
+// status combines the statuses of the members of the batch: the batch is pending until all its
+// members completed, and failed if any of them did not finish successfully.
+func (b *suiteBatch) status() string {
+	status := exec.StatusFinished
+	for _, member := range b.members {
+		switch member.status {
+		case suiteMemberPending:
+			return suiteMemberPending
+		case exec.StatusFinished:
+		default:
+			status = exec.StatusFailed
+		}
+	}
+	return status
+}
+
+// result summarizes the comparisons of the member against its baselines.
+func (m *suiteMember) result() string {
+	if m.status != exec.StatusFinished || len(m.reports) == 0 {
+		return "-"
+	}
+	results := make([]string, 0, len(m.reports))
+	for _, br := range m.reports {
+		results = append(results, br.status())
+	}
+	return strings.Join(results, ", ")
+}
+
+// formatSuiteReport formats the results of a completed batch, with a table summarizing the status
+// and the comparisons of each member, followed by the details of their changes.
+func formatSuiteReport(batch *suiteBatch) string {
+	var b strings.Builder
+	finished := 0
+	regression := false
+	for _, member := range batch.members {
+		if member.status == exec.StatusFinished {
+			finished++
+		}
+		for _, br := range member.reports {
+			regression = regression || br.report.Regression != ""
+		}
+	}
+	if regression {
+		b.WriteString("*Observed a regression.*\n")
+	}
+	suite := batch.suite
+	if suite == "" {
+		suite = batch.id
+	}
+	if len(batch.members) > 0 {
+		identifier := batch.members[0].identifier
+		fmt.Fprintf(&b, "Suite %s of %s <https://github.com/vitessio/vitess/commit/%s|%s> completed: %d/%d executions finished\n",
+			suite, identifier.Source, identifier.GitRef, git.ShortenSHAN(identifier.GitRef, notificationShortSHALength), finished, len(batch.members))
+	}
+
+	rows := [][]string{{"Type", "Planner", "Status", "Result"}}
+	for _, member := range batch.members {
+		rows = append(rows, []string{member.identifier.BenchmarkType, member.identifier.PlannerVersion, member.status, member.result()})
+	}
+	b.WriteString("```\n" + formatTable(rows) + "```\n")
+
+	for _, member := range batch.members {
+		for _, br := range member.reports {
+			details := br.report.Regression + br.report.Improvement
+			if br.skipped != "" {
+				details = br.skipped + "\n"
+			}
+			if details == "" && len(br.warnings) == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, "\n*%s against %s* (<%s|comparison>)\n", member.identifier.BenchmarkType, git.ShortenSHAN(br.baseline.GitRef, notificationShortSHALength), getComparisonLink(member.identifier.GitRef, br.baseline.GitRef))
+			for _, warning := range br.warnings {
+				b.WriteString("Warning: " + warning + "\n")
+			}
+			b.WriteString(details)
+		}
+	}
+	return b.String()
+}
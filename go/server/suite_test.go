@@ -0,0 +1,147 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/vitessio/arewefastyet/go/exec"
+)
+
+func TestServer_getSuites(t *testing.T) {
+	c := qt.New(t)
+	s := &Server{suites: map[string]string{"full": "micro+oltp+tpcc", "macro": "oltp + tpcc + oltp"}}
+	suites, err := s.getSuites()
+	c.Assert(err, qt.IsNil)
+	c.Assert(suites, qt.DeepEquals, map[string][]string{
+		"full":  {"micro", "oltp", "tpcc"},
+		"macro": {"oltp", "tpcc"},
+	})
+
+	s.suites = map[string]string{"broken": "oltp+generic"}
+	_, err = s.getSuites()
+	c.Assert(err, qt.ErrorMatches, `invalid suite broken: .* generic`)
+}
+
+func TestServer_createSuiteElements(t *testing.T) {
+	c := qt.New(t)
+	s := &Server{microbenchConfigPath: "micro.yaml", macrobenchConfigPathOLTP: "oltp.yaml", macrobenchConfigPathTPCC: "tpcc.yaml", cronNbRetry: 1}
+	req := suiteRequest{Suite: "full", GitRef: "a", Source: "manual", PlannerVersion: "Gen4", CompareWith: []string{"b"}}
+
+	batch, elements := s.createSuiteElements(req, []string{"micro", "oltp"}, "batch")
+	c.Assert(batch.id, qt.Equals, "batch")
+	c.Assert(batch.status(), qt.Equals, suiteMemberPending)
+	c.Assert(elements, qt.HasLen, 4)
+	for i, want := range []executionIdentifier{
+		{GitRef: "a", Source: "manual", BenchmarkType: "micro"},
+		{GitRef: "a", Source: "manual", BenchmarkType: "oltp", PlannerVersion: "Gen4"},
+	} {
+		c.Assert(batch.members[i].identifier, qt.Equals, want)
+		c.Assert(elements[i].identifier, qt.Equals, want)
+		c.Assert(elements[i].batchID, qt.Equals, "batch")
+		c.Assert(elements[i].compareWith, qt.HasLen, 1)
+	}
+	for _, element := range elements[2:] {
+		c.Assert(element.baselineOnly, qt.IsTrue)
+		c.Assert(element.batchID, qt.Equals, "")
+		c.Assert(element.identifier.GitRef, qt.Equals, "b")
+	}
+}
+
+func TestSuiteBatches_complete(t *testing.T) {
+	c := qt.New(t)
+	micro := executionIdentifier{GitRef: "a", Source: "manual", BenchmarkType: "micro"}
+	oltp := executionIdentifier{GitRef: "a", Source: "manual", BenchmarkType: "oltp", PlannerVersion: "Gen4"}
+	var sb suiteBatches
+	sb.add(&suiteBatch{id: "batch", suite: "full", members: []*suiteMember{
+		{identifier: micro, status: suiteMemberPending},
+		{identifier: oltp, status: suiteMemberPending},
+	}}, time.Now())
+
+	status, ok := sb.status("batch", map[executionIdentifier]executionQueueElement{oltp: {executing: true}})
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(status.Status, qt.Equals, suiteMemberPending)
+	c.Assert(status.Members[0].Status, qt.Equals, "queued")
+	c.Assert(status.Members[1].Status, qt.Equals, "executing")
+
+	c.Assert(sb.complete("batch", micro, exec.StatusFinished, nil, time.Now()), qt.IsNil)
+	batch := sb.complete("batch", oltp, exec.StatusFailed, nil, time.Now())
+	c.Assert(batch, qt.IsNotNil)
+	c.Assert(batch.status(), qt.Equals, exec.StatusFailed)
+
+	// a completed batch is notified only once
+	c.Assert(sb.complete("batch", oltp, exec.StatusFinished, nil, time.Now()), qt.IsNil)
+	c.Assert(sb.complete("unknown", oltp, exec.StatusFinished, nil, time.Now()), qt.IsNil)
+
+	_, ok = sb.status("unknown", nil)
+	c.Assert(ok, qt.IsFalse)
+
+	// completed batches are forgotten after the retention
+	sb.add(&suiteBatch{id: "other"}, time.Now().Add(suiteBatchRetention+time.Hour))
+	_, ok = sb.status("batch", nil)
+	c.Assert(ok, qt.IsFalse)
+}
+
+func TestSuiteBatches_restore(t *testing.T) {
+	c := qt.New(t)
+	micro := &executionQueueElement{identifier: executionIdentifier{GitRef: "a", Source: "manual", BenchmarkType: "micro"}, batchID: "batch"}
+	oltp := &executionQueueElement{identifier: executionIdentifier{GitRef: "a", Source: "manual", BenchmarkType: "oltp"}, batchID: "batch"}
+	var sb suiteBatches
+	sb.restore(micro, time.Now())
+	sb.restore(oltp, time.Now())
+	sb.restore(oltp, time.Now())
+
+	status, ok := sb.status("batch", nil)
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(status.Members, qt.HasLen, 2)
+
+	c.Assert(sb.complete("batch", micro.identifier, exec.StatusFinished, nil, time.Now()), qt.IsNil)
+	batch := sb.complete("batch", oltp.identifier, exec.StatusFinished, nil, time.Now())
+	c.Assert(batch, qt.IsNotNil)
+	c.Assert(formatSuiteReport(batch), qt.Contains, "Suite batch of manual")
+}
+
+func TestFormatSuiteReport(t *testing.T) {
+	c := qt.New(t)
+	batch := &suiteBatch{id: "batch", suite: "full", members: []*suiteMember{
+		{
+			identifier: executionIdentifier{GitRef: "abcdef0123456789", Source: "manual", BenchmarkType: "micro"},
+			status:     exec.StatusFinished,
+			reports: []baselineReport{{
+				baseline: executionIdentifier{GitRef: "0123456789abcdef", Source: "manual", BenchmarkType: "micro"},
+				report:   ComparisonReport{Regression: "- BenchmarkFoo decreased by 12.00%\n"},
+			}},
+		},
+		{
+			identifier: executionIdentifier{GitRef: "abcdef0123456789", Source: "manual", BenchmarkType: "oltp", PlannerVersion: "Gen4"},
+			status:     exec.StatusFailed,
+		},
+	}}
+
+	report := formatSuiteReport(batch)
+	c.Assert(strings.HasPrefix(report, "*Observed a regression.*\n"), qt.IsTrue)
+	c.Assert(report, qt.Contains, "Suite full of manual")
+	c.Assert(report, qt.Contains, "completed: 1/2 executions finished")
+	c.Assert(report, qt.Contains, "micro |         | finished | regression\n")
+	c.Assert(report, qt.Contains, "oltp  | Gen4    | failed   | -\n")
+	c.Assert(report, qt.Contains, "- BenchmarkFoo decreased by 12.00%")
+}
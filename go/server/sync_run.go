@@ -0,0 +1,158 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vitessio/arewefastyet/go/exec"
+	"github.com/vitessio/arewefastyet/go/tools/macrobench"
+)
+
+// syncRunPollInterval is the interval at which a synchronous run checks whether its executions finished.
+var syncRunPollInterval = 10 * time.Second
+
+var errExecutionNotFinished = errors.New("execution left the queue without finishing")
+
+// syncRunRequest is the body expected by syncRunHandler. If GitRef is empty, the latest
+// commit of the branch configured for the sync source is benchmarked. If Baseline is empty,
+// GitRef is compared against the latest finished execution of the cron.
+type syncRunRequest struct {
+	GitRef         string `json:"git_ref"`
+	Type           string `json:"type" binding:"required"`
+	PlannerVersion string `json:"planner_version"`
+	Baseline       string `json:"baseline"`
+}
+
+// syncRunHandler benchmarks a git ref and compares it against a baseline in one
+// blocking call. The executions go through the queue like any other execution,
+// the handler waits for them to finish and returns the comparison as JSON, or as
+// Markdown if the "format" query parameter is set to "markdown".
+func (s *Server) syncRunHandler(c *gin.Context) {
+	var req syncRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	configFile, ok := s.getConfigFiles()[req.Type]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s %s", errUnknownBenchmarkType, req.Type)})
+		return
+	}
+	planner := syncRunPlannerVersion(req.Type, req.PlannerVersion)
+	gitRef, err := s.resolveGitRef(req.GitRef, exec.SourceSync)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	req.GitRef = gitRef
+
+	baseline := req.Baseline
+	if baseline == "" {
+		_, baseline, err = exec.GetLatestFinishedExecutionFromSource(s.readDB(), exec.SourceCron, req.Type, string(planner))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if baseline == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s for source %s, a baseline is required", errNoFinishedExecution, exec.SourceCron)})
+			return
+		}
+	}
+
+	elements := []*executionQueueElement{s.createSimpleExecutionQueueElement(exec.SourceSync, configFile, req.GitRef, req.Type, string(planner), false, 0)}
+	if req.Baseline != "" {
+		baselineElement := s.createSimpleExecutionQueueElement(exec.SourceSyncBaseline, configFile, baseline, req.Type, string(planner), false, 0)
+		baselineElement.baselineOnly = true
+		elements = append(elements, baselineElement)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), s.syncRunTimeout)
+	defer cancel()
+	for _, element := range elements {
+		s.addToQueue(element)
+	}
+	for _, element := range elements {
+		err := s.waitForExecution(ctx, element.identifier)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("%s: %v", element.identifier.GitRef, err)})
+			return
+		}
+	}
+
+	result, err := s.compareGitRefs(req.GitRef, baseline, req.Type, planner, "")
+	if err != nil {
+		c.JSON(comparisonErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if c.Query("format") == "markdown" {
+		c.String(http.StatusOK, result.Markdown())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"git_ref":          req.GitRef,
+		"baseline_git_ref": baseline,
+		"comparison":       result,
+	})
+}
+
+// syncRunPlannerVersion returns the planner version used by a synchronous run of the given
+// benchmark type. Microbenchmarks do not use any planner, macrobenchmarks default to V3.
+func syncRunPlannerVersion(benchmarkType, plannerVersion string) macrobench.PlannerVersion {
+	if benchmarkType == "micro" {
+		return ""
+	}
+	if plannerVersion == "" {
+		return macrobench.V3Planner
+	}
+	return macrobench.PlannerVersion(plannerVersion)
+}
+
+// waitForExecution blocks until the execution with the given identifier finished.
+// It fails if the execution left the queue without finishing, or if ctx is done.
+func (s *Server) waitForExecution(ctx context.Context, identifier executionIdentifier) error {
+	ticker := time.NewTicker(syncRunPollInterval)
+	defer ticker.Stop()
+	for {
+		// the queue is checked first: an element only leaves it once its execution
+		// finished, which the following lookup on the primary then sees, unlike a
+		// lookup on the read replica that may lag behind
+		queued := s.queue.Contains(identifier)
+		execUUID, err := exec.GetFinishedExecution(s.dbClient, identifier.GitRef, identifier.Source, identifier.BenchmarkType, identifier.PlannerVersion, identifier.PullNb)
+		if err != nil {
+			return err
+		}
+		if execUUID != "" {
+			return nil
+		}
+		if !queued {
+			return errExecutionNotFinished
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,131 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/vitessio/arewefastyet/go/exec"
+	"github.com/vitessio/arewefastyet/go/storage/mysql/mysqltest"
+	"github.com/vitessio/arewefastyet/go/tools/macrobench"
+	"go.uber.org/zap"
+)
+
+func TestSyncRunPlannerVersion(t *testing.T) {
+	testcases := []struct {
+		name           string
+		benchmarkType  string
+		plannerVersion string
+		want           macrobench.PlannerVersion
+	}{
+		{name: "Microbenchmark", benchmarkType: "micro", plannerVersion: "Gen4Fallback", want: ""},
+		{name: "Macrobenchmark default", benchmarkType: "oltp", plannerVersion: "", want: macrobench.V3Planner},
+		{name: "Macrobenchmark", benchmarkType: "tpcc", plannerVersion: "Gen4Fallback", want: macrobench.Gen4FallbackPlanner},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			qt.Assert(t, syncRunPlannerVersion(tc.benchmarkType, tc.plannerVersion), qt.Equals, tc.want)
+		})
+	}
+}
+
+func TestServer_syncRunHandler(t *testing.T) {
+	c := qt.New(t)
+	previous := slog
+	SetSLogger(zap.NewNop().Sugar())
+	c.Cleanup(func() { SetSLogger(previous) })
+	previousInterval := syncRunPollInterval
+	syncRunPollInterval = 10 * time.Millisecond
+	c.Cleanup(func() { syncRunPollInterval = previousInterval })
+	gin.SetMode(gin.TestMode)
+
+	client := mysqltest.New(t)
+	s := &Server{dbClient: client, queue: NewQueue(1), microbenchConfigPath: "micro.yaml", syncRunTimeout: time.Minute}
+	router := gin.New()
+	router.POST("/api/run/sync", s.syncRunHandler)
+
+	// finish records a finished execution of identifier and removes it from the queue,
+	// like executeElement does once the execution is compared
+	finish := func(identifier executionIdentifier) {
+		_, err := client.Insert("INSERT INTO execution(uuid, status, source, git_ref, type, pull_nb, finished_at) VALUES(?, ?, ?, ?, ?, 0, NOW())",
+			uuid.New().String(), exec.StatusFinished, identifier.Source, identifier.GitRef, identifier.BenchmarkType)
+		c.Assert(err, qt.IsNil)
+		s.removeFromQueue(identifier)
+	}
+	identifier := func(gitRef string) executionIdentifier {
+		return executionIdentifier{GitRef: gitRef, Source: exec.SourceSync, BenchmarkType: "micro"}
+	}
+	finish(executionIdentifier{GitRef: "baseline", Source: exec.SourceSyncBaseline, BenchmarkType: "micro"})
+
+	tests := []struct {
+		name   string
+		gitRef string
+		setup  func(identifier executionIdentifier)
+	}{
+		{
+			name:   "already finished",
+			gitRef: "finished",
+			setup:  finish,
+		},
+		{
+			name:   "queued",
+			gitRef: "queued",
+			setup: func(identifier executionIdentifier) {
+				s.queue.Add(s.createSimpleExecutionQueueElement(identifier.Source, "micro.yaml", identifier.GitRef, identifier.BenchmarkType, "", false, 0))
+				go func() {
+					time.Sleep(50 * time.Millisecond)
+					finish(identifier)
+				}()
+			},
+		},
+		{
+			name:   "new",
+			gitRef: "new",
+			setup: func(identifier executionIdentifier) {
+				go func() {
+					for !s.queue.Contains(identifier) {
+						time.Sleep(10 * time.Millisecond)
+					}
+					finish(identifier)
+				}()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			tt.setup(identifier(tt.gitRef))
+
+			body := `{"git_ref": "` + tt.gitRef + `", "type": "micro", "baseline": "baseline"}`
+			req := httptest.NewRequest(http.MethodPost, "/api/run/sync", strings.NewReader(body))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			c.Assert(rec.Code, qt.Equals, http.StatusOK, qt.Commentf(rec.Body.String()))
+			c.Assert(rec.Body.String(), qt.Contains, `"git_ref":"`+tt.gitRef+`"`)
+			c.Assert(s.queue.Contains(identifier(tt.gitRef)), qt.IsFalse)
+		})
+	}
+}
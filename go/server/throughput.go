@@ -0,0 +1,100 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vitessio/arewefastyet/go/exec"
+)
+
+// completionRetention is how long the completions are kept by the completionTracker,
+// the completions of a larger window are only counted from the execution table.
+const completionRetention = 7 * 24 * time.Hour
+
+// completionTracker records when the executions of the queue complete,
+// it is used to compute the throughput of the queue.
+type completionTracker struct {
+	mu     sync.Mutex
+	events []time.Time
+}
+
+// add records a completion at the given time. The completions older
+// than completionRetention are discarded.
+func (ct *completionTracker) add(at time.Time) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.events = append(ct.events, at)
+	ct.prune(at.Add(-completionRetention))
+}
+
+// countSince returns the number of completions since the given time.
+func (ct *completionTracker) countSince(since time.Time) int {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.prune(time.Now().Add(-completionRetention))
+	count := 0
+	for _, event := range ct.events {
+		if !event.Before(since) {
+			count++
+		}
+	}
+	return count
+}
+
+// prune discards the completions that happened before the given time.
+func (ct *completionTracker) prune(before time.Time) {
+	i := 0
+	for i < len(ct.events) && ct.events[i].Before(before) {
+		i++
+	}
+	ct.events = ct.events[i:]
+}
+
+// queueThroughputHandler returns the number of executions completed per hour over the
+// configured window. The count is computed from the execution table, which includes
+// executions that completed before the server started, while the in-memory count
+// only includes the executions completed by this server's queue.
+func (s *Server) queueThroughputHandler(c *gin.Context) {
+	window := s.throughputWindow
+	if w := c.Query("window"); w != "" {
+		var err error
+		window, err = time.ParseDuration(w)
+		if err != nil || window <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid window " + w})
+			return
+		}
+	}
+	since := time.Now().Add(-window)
+
+	completed, err := exec.CountFinishedExecutionsSince(s.dbClient, since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"window":              window.String(),
+		"completed":           completed,
+		"completed_in_memory": s.completions.countSince(since),
+		"per_hour":            float64(completed) / window.Hours(),
+	})
+}
@@ -0,0 +1,47 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestCompletionTracker_countSince(t *testing.T) {
+	c := qt.New(t)
+	now := time.Now()
+
+	var ct completionTracker
+	ct.add(now.Add(-3 * time.Hour))
+	ct.add(now.Add(-2 * time.Hour))
+	ct.add(now.Add(-30 * time.Minute))
+	ct.add(now)
+
+	c.Assert(ct.countSince(now.Add(-4*time.Hour)), qt.Equals, 4)
+	c.Assert(ct.countSince(now.Add(-1*time.Hour)), qt.Equals, 2)
+
+	// a smaller window does not discard the completions of a larger one
+	c.Assert(ct.countSince(now.Add(-4*time.Hour)), qt.Equals, 4)
+
+	// completions older than the retention are discarded
+	ct.add(now.Add(completionRetention - time.Hour))
+	c.Assert(ct.events, qt.HasLen, 3)
+}
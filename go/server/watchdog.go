@@ -0,0 +1,52 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"github.com/vitessio/arewefastyet/go/exec"
+)
+
+// stuckExecutionsWatchdogSchedule is the CRON schedule of stuckExecutionsWatchdog.
+const stuckExecutionsWatchdogSchedule = "@every 15m"
+
+// stuckExecutionsWatchdog marks the executions that have been started for longer
+// than the configured maximum duration as timed out. This is a safety net for
+// executions whose own timeout did not fire, or that were abandoned when the
+// server stopped. Once marked, the stuck executions are removed from the queue,
+// allowing the next CRON to schedule them again.
+func (s *Server) stuckExecutionsWatchdog() {
+	execs, err := exec.MarkStuckExecutionsAsTimedOut(s.dbClient, s.stuckExecutionMaxDuration)
+	if err != nil {
+		slog.Error(err)
+		return
+	}
+
+	for _, e := range execs {
+		slog.Warnf("Execution %s (%s, %s) has been started for more than %s and was marked as %s", e.UUID.String(), e.GitRef, e.TypeOf, s.stuckExecutionMaxDuration.String(), exec.StatusTimedOut)
+
+		removed := s.queue.RemoveIf(func(identifier executionIdentifier) bool {
+			return identifier.GitRef == e.GitRef && identifier.Source == e.Source && identifier.BenchmarkType == e.TypeOf && identifier.PullNb == e.PullNB
+		})
+		for _, identifier := range removed {
+			if err := deletePersistedQueueElement(s.dbClient, identifier); err != nil {
+				slog.Error(err)
+			}
+		}
+	}
+}
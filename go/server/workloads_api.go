@@ -0,0 +1,56 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vitessio/arewefastyet/go/tools/macrobench"
+)
+
+// workloadsAPIHandler compares the per-workload results of the workload mixes run by the
+// executions given in the "left" and "right" query parameters. Whether both executions ran
+// the same mix is returned along with the comparison, as the aggregated results of two
+// different mixes cannot be compared meaningfully.
+func (s *Server) workloadsAPIHandler(c *gin.Context) {
+	leftUUID := c.Query("left")
+	rightUUID := c.Query("right")
+	if leftUUID == "" || rightUUID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "the left and right query parameters are required"})
+		return
+	}
+	left, err := macrobench.GetWorkloadResultsForExecution(leftUUID, s.readDB())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	right, err := macrobench.GetWorkloadResultsForExecution(rightUUID, s.readDB())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	comparisons := macrobench.CompareWorkloadResults(left, right)
+	c.JSON(http.StatusOK, gin.H{
+		"left":      leftUUID,
+		"right":     rightUUID,
+		"same_mix":  macrobench.SameMix(comparisons),
+		"workloads": comparisons,
+	})
+}
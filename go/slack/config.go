@@ -24,8 +24,9 @@ import (
 )
 
 const (
-	flagToken   = "slack-token"
-	flagChannel = "slack-channel"
+	flagToken      = "slack-token"
+	flagChannel    = "slack-channel"
+	flagMaxRetries = "slack-max-retries"
 
 	ErrorInvalidConfiguration = "invalid configuration"
 )
@@ -34,22 +35,31 @@ const (
 type Config struct {
 	Token   string
 	Channel string
+
+	// MaxRetries is the number of times a message rate limited by Slack is retried.
+	MaxRetries int
+
+	// apiURL overrides the URL of the Slack API, it is only used in tests.
+	apiURL string
 }
 
 func (c *Config) AddToViper(v *viper.Viper) {
 	_ = v.UnmarshalKey(flagToken, &c.Token)
 	_ = v.UnmarshalKey(flagChannel, &c.Channel)
+	_ = v.UnmarshalKey(flagMaxRetries, &c.MaxRetries)
 }
 
 // AddToCommand will add Config's CLI flags to the given *cobra.Command.
 func (c *Config) AddToCommand(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&c.Token, flagToken, "", "Token used to authenticate Slack")
 	cmd.Flags().StringVar(&c.Channel, flagChannel, "", "Slack channel on which to post messages")
+	cmd.Flags().IntVar(&c.MaxRetries, flagMaxRetries, 3, "Number of times a message rate limited by Slack is retried, respecting the delay requested by Slack")
 
 	_ = viper.BindPFlag(flagToken, cmd.Flags().Lookup(flagToken))
 	_ = viper.BindPFlag(flagChannel, cmd.Flags().Lookup(flagChannel))
+	_ = viper.BindPFlag(flagMaxRetries, cmd.Flags().Lookup(flagMaxRetries))
 }
 
 func (c Config) IsValid() bool {
 	return !(c.Token == "" || c.Channel == "")
-}
\ No newline at end of file
+}
@@ -0,0 +1,77 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package slack
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// minRetryBackoff is the delay before the first retry of a rate limited message
+// when Slack does not request a longer one. It doubles after every retry.
+const minRetryBackoff = time.Second
+
+// sleep is used to wait before retrying a rate limited message.
+var sleep = time.Sleep
+
+// RateLimitError is returned when a message could not be sent because Slack kept
+// rate limiting it after Config.MaxRetries retries. Callers can detect it using
+// errors.As and fall back to another way of notifying.
+type RateLimitError struct {
+	Attempts   int
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("slack rate limit exceeded after %d attempts, retry after %s", e.Attempts, e.RetryAfter)
+}
+
+// newClient returns a Slack client authenticated with the Config.
+func (c Config) newClient() *slack.Client {
+	if c.apiURL != "" {
+		return slack.New(c.Token, slack.OptionAPIURL(c.apiURL))
+	}
+	return slack.New(c.Token)
+}
+
+// sendWithRetry calls send until it is not rate limited by Slack, or until MaxRetries
+// retries were made. Each retry waits for the delay requested by Slack in the Retry-After
+// header, or for an exponential backoff if it is longer.
+func (c Config) sendWithRetry(send func() error) error {
+	backoff := minRetryBackoff
+	for attempt := 1; ; attempt++ {
+		err := send()
+		var rateLimited *slack.RateLimitedError
+		if !errors.As(err, &rateLimited) {
+			return err
+		}
+		if attempt > c.MaxRetries {
+			return &RateLimitError{Attempts: attempt, RetryAfter: rateLimited.RetryAfter}
+		}
+		delay := rateLimited.RetryAfter
+		if delay < backoff {
+			delay = backoff
+		}
+		sleep(delay)
+		backoff *= 2
+	}
+}
@@ -0,0 +1,101 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package slack
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/slack-go/slack"
+)
+
+func TestTextMessage_SendRateLimited(t *testing.T) {
+	tests := []struct {
+		name         string
+		rateLimited  int
+		maxRetries   int
+		wantRequests int
+		wantDelays   []time.Duration
+		wantErr      bool
+	}{
+		{name: "Not rate limited", rateLimited: 0, maxRetries: 3, wantRequests: 1},
+		{name: "Retried until sent", rateLimited: 2, maxRetries: 3, wantRequests: 3, wantDelays: []time.Duration{2 * time.Second, 2 * time.Second}},
+		{name: "Retries exhausted", rateLimited: 5, maxRetries: 2, wantRequests: 3, wantDelays: []time.Duration{2 * time.Second, 2 * time.Second}, wantErr: true},
+		{name: "No retry", rateLimited: 1, maxRetries: 0, wantRequests: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if requests <= tt.rateLimited {
+					w.Header().Set("Retry-After", "2")
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				fmt.Fprint(w, `{"ok": true, "channel": "channel", "ts": "1"}`)
+			}))
+			defer server.Close()
+
+			var delays []time.Duration
+			c.Patch(&sleep, func(d time.Duration) { delays = append(delays, d) })
+
+			config := Config{Token: "token", Channel: "channel", MaxRetries: tt.maxRetries, apiURL: server.URL + "/"}
+			err := TextMessage{Content: "content"}.Send(config)
+			c.Assert(requests, qt.Equals, tt.wantRequests)
+			c.Assert(delays, qt.DeepEquals, tt.wantDelays)
+			if !tt.wantErr {
+				c.Assert(err, qt.IsNil)
+				return
+			}
+			var rateLimitErr *RateLimitError
+			c.Assert(errors.As(err, &rateLimitErr), qt.IsTrue)
+			c.Assert(rateLimitErr.Attempts, qt.Equals, tt.wantRequests)
+		})
+	}
+}
+
+func TestConfig_sendWithRetry(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantDelays []time.Duration
+		wantErr    string
+	}{
+		{name: "Exponential backoff", err: &slack.RateLimitedError{}, wantDelays: []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}, wantErr: "slack rate limit exceeded after 4 attempts, retry after 0s"},
+		{name: "Other error", err: errors.New("invalid_auth"), wantErr: "invalid_auth"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			var delays []time.Duration
+			c.Patch(&sleep, func(d time.Duration) { delays = append(delays, d) })
+
+			err := Config{MaxRetries: 3}.sendWithRetry(func() error { return tt.err })
+			c.Assert(err, qt.ErrorMatches, tt.wantErr)
+			c.Assert(delays, qt.DeepEquals, tt.wantDelays)
+		})
+	}
+}
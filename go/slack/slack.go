@@ -28,7 +28,7 @@ func (f FileUploadMessage) Send(config Config) (err error) {
 		return errors.New(ErrorInvalidConfiguration)
 	}
 
-	api := slack.New(config.Token)
+	api := config.newClient()
 
 	if f.FileType == "" {
 		getFileType(&f)
@@ -42,7 +42,10 @@ func (f FileUploadMessage) Send(config Config) (err error) {
 		InitialComment: f.Comment,
 	}
 
-	_, err = api.UploadFile(params)
+	err = config.sendWithRetry(func() error {
+		_, err := api.UploadFile(params)
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -58,7 +61,7 @@ func getFileType(f *FileUploadMessage) {
 }
 
 func (t TextMessage) Send(config Config) (err error) {
-	api := slack.New(config.Token)
+	api := config.newClient()
 
 	msg := slack.MsgOptionBlocks(slack.SectionBlock{
 		Type:      slack.MBTSection,
@@ -67,7 +70,10 @@ func (t TextMessage) Send(config Config) (err error) {
 			Text:     t.Content,
 		},
 	})
-	_, _, err = api.PostMessage(config.Channel, msg)
+	err = config.sendWithRetry(func() error {
+		_, _, err := api.PostMessage(config.Channel, msg)
+		return err
+	})
 
 	if err != nil {
 		return err
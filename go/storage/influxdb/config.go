@@ -25,16 +25,38 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"regexp"
+	"time"
 )
 
 const (
-	flagInfluxHostname = "influx-hostname"
-	flagInfluxPort     = "influx-port"
-	flagInfluxUsername = "influx-username"
-	flagInfluxPassword = "influx-password"
-	flagInfluxDatabase = "influx-database"
+	flagInfluxHostname   = "influx-hostname"
+	flagInfluxPort       = "influx-port"
+	flagInfluxUsername   = "influx-username"
+	flagInfluxPassword   = "influx-password"
+	flagInfluxDatabase   = "influx-database"
+	flagInfluxPrecision  = "influx-precision"
+	flagInfluxDuplicates = "influx-duplicates"
+	flagInfluxMaxRetries = "influx-max-retries"
 )
 
+const (
+	// DuplicatesOverwrite makes a point replace the point written before with the same
+	// measurement, tags and timestamp, which is the default behavior of InfluxDB.
+	DuplicatesOverwrite = "overwrite"
+
+	// DuplicatesAppend makes every point carry the TagExecUUID tag, so that the points
+	// written by different executions are kept in distinct series.
+	DuplicatesAppend = "append"
+)
+
+// precisions maps the supported write precisions to their duration.
+var precisions = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+}
+
 // Config defines the required configuration used to authenticate
 // to an InfluxDB database.
 type Config struct {
@@ -43,6 +65,46 @@ type Config struct {
 	User     string
 	Password string
 	Database string
+
+	// Precision is the precision of the timestamps of the written points,
+	// either "ns", "us", "ms" or "s". Defaults to nanoseconds if empty.
+	Precision string
+
+	// Duplicates is the strategy handling the points written again, either
+	// DuplicatesOverwrite or DuplicatesAppend. Defaults to DuplicatesOverwrite if empty.
+	Duplicates string
+
+	// MaxRetries is the number of times NewClientWithRetry retries
+	// to reach InfluxDB before giving up.
+	MaxRetries int
+}
+
+// duplicates returns the Duplicates strategy of the Config.
+func (cfg Config) duplicates() (string, error) {
+	switch cfg.Duplicates {
+	case "":
+		return DuplicatesOverwrite, nil
+	case DuplicatesOverwrite, DuplicatesAppend:
+		return cfg.Duplicates, nil
+	}
+	return "", fmt.Errorf("invalid duplicates strategy %q, must be either %s or %s", cfg.Duplicates, DuplicatesOverwrite, DuplicatesAppend)
+}
+
+// precision returns the duration matching the Precision of the Config.
+func (cfg Config) precision() (time.Duration, error) {
+	if cfg.Precision == "" {
+		return time.Nanosecond, nil
+	}
+	return ParsePrecision(cfg.Precision)
+}
+
+// ParsePrecision returns the duration of the given precision, either "ns", "us", "ms" or "s".
+func ParsePrecision(precision string) (time.Duration, error) {
+	d, ok := precisions[precision]
+	if !ok {
+		return 0, fmt.Errorf("invalid precision %q, must be one of ns, us, ms or s", precision)
+	}
+	return d, nil
 }
 
 func (cfg Config) NewClient() (*Client, error) {
@@ -63,10 +125,22 @@ func (cfg Config) NewClient() (*Client, error) {
 		cfg.Host = "http://"+cfg.Host
 	}
 
+	precision, err := cfg.precision()
+	if err != nil {
+		return nil, err
+	}
+
+	duplicates, err := cfg.duplicates()
+	if err != nil {
+		return nil, err
+	}
+
 	client := Client{
-		Config: &cfg,
+		Config:     &cfg,
+		precision:  precision,
+		duplicates: duplicates,
 	}
-	influxclient := influxdb2.NewClient(cfg.Host+":"+cfg.Port, fmt.Sprintf("%s:%s", cfg.User, cfg.Password))
+	influxclient := influxdb2.NewClientWithOptions(cfg.Host+":"+cfg.Port, fmt.Sprintf("%s:%s", cfg.User, cfg.Password), influxdb2.DefaultOptions().SetPrecision(precision))
 	client.influx = influxclient
 	return &client, nil
 }
@@ -82,6 +156,9 @@ func (cfg *Config) AddToViper(v *viper.Viper) {
 	_ = v.UnmarshalKey(flagInfluxUsername, &cfg.User)
 	_ = v.UnmarshalKey(flagInfluxPassword, &cfg.Password)
 	_ = v.UnmarshalKey(flagInfluxDatabase, &cfg.Database)
+	_ = v.UnmarshalKey(flagInfluxPrecision, &cfg.Precision)
+	_ = v.UnmarshalKey(flagInfluxDuplicates, &cfg.Duplicates)
+	_ = v.UnmarshalKey(flagInfluxMaxRetries, &cfg.MaxRetries)
 }
 
 // AddToCommand adds Config to the given cobra.Command.
@@ -91,6 +168,9 @@ func (cfg *Config) AddToCommand(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&cfg.User, flagInfluxUsername, "", "Username used to connect to InfluxDB.")
 	cmd.Flags().StringVar(&cfg.Password, flagInfluxPassword, "", "Password used to connect to InfluxDB.")
 	cmd.Flags().StringVar(&cfg.Database, flagInfluxDatabase, "", "Name of the database to use in InfluxDB.")
+	cmd.Flags().StringVar(&cfg.Precision, flagInfluxPrecision, "ns", "Precision of the timestamps written to InfluxDB, either ns, us, ms or s.")
+	cmd.Flags().StringVar(&cfg.Duplicates, flagInfluxDuplicates, DuplicatesOverwrite, "Strategy handling the points written again to InfluxDB, either overwrite (the point with the same tags and timestamp is replaced) or append (the points are tagged with their execution).")
+	cmd.Flags().IntVar(&cfg.MaxRetries, flagInfluxMaxRetries, 3, "Number of times InfluxDB is retried, with an exponential backoff, when it cannot be reached while creating a client.")
 
 	_ = cmd.MarkFlagRequired(flagInfluxHostname)
 
@@ -99,4 +179,7 @@ func (cfg *Config) AddToCommand(cmd *cobra.Command) {
 	_ = viper.BindPFlag(flagInfluxUsername, cmd.Flags().Lookup(flagInfluxUsername))
 	_ = viper.BindPFlag(flagInfluxPassword, cmd.Flags().Lookup(flagInfluxPassword))
 	_ = viper.BindPFlag(flagInfluxDatabase, cmd.Flags().Lookup(flagInfluxDatabase))
+	_ = viper.BindPFlag(flagInfluxPrecision, cmd.Flags().Lookup(flagInfluxPrecision))
+	_ = viper.BindPFlag(flagInfluxDuplicates, cmd.Flags().Lookup(flagInfluxDuplicates))
+	_ = viper.BindPFlag(flagInfluxMaxRetries, cmd.Flags().Lookup(flagInfluxMaxRetries))
 }
@@ -19,8 +19,10 @@
 package influxdb
 
 import (
+	"errors"
 	qt "github.com/frankban/quicktest"
 	"testing"
+	"time"
 )
 
 func TestConfig_IsValid(t *testing.T) {
@@ -41,3 +43,102 @@ func TestConfig_IsValid(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_precision(t *testing.T) {
+	tests := []struct {
+		name      string
+		precision string
+		want      time.Duration
+		wantErr   bool
+	}{
+		{name: "Default precision", precision: "", want: time.Nanosecond},
+		{name: "Microseconds", precision: "us", want: time.Microsecond},
+		{name: "Seconds", precision: "s", want: time.Second},
+		{name: "Invalid precision", precision: "m", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			got, err := Config{Precision: tt.precision}.precision()
+			if tt.wantErr {
+				c.Assert(err, qt.Not(qt.IsNil))
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			c.Assert(got, qt.Equals, tt.want)
+		})
+	}
+}
+
+func TestClient_Truncate(t *testing.T) {
+	c := qt.New(t)
+	ts := time.Date(2021, 6, 1, 12, 0, 0, 123456789, time.UTC)
+
+	client, err := Config{Host: "localhost", Precision: "us"}.NewClient()
+	c.Assert(err, qt.IsNil)
+	defer client.Close()
+	c.Assert(client.Truncate(ts), qt.Equals, time.Date(2021, 6, 1, 12, 0, 0, 123456000, time.UTC))
+
+	_, err = Config{Host: "localhost", Precision: "m"}.NewClient()
+	c.Assert(err, qt.Not(qt.IsNil))
+}
+
+func TestConfig_duplicates(t *testing.T) {
+	tests := []struct {
+		name       string
+		duplicates string
+		want       string
+		wantErr    bool
+	}{
+		{name: "Default strategy", duplicates: "", want: DuplicatesOverwrite},
+		{name: "Overwrite", duplicates: "overwrite", want: DuplicatesOverwrite},
+		{name: "Append", duplicates: "append", want: DuplicatesAppend},
+		{name: "Invalid strategy", duplicates: "ignore", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			got, err := Config{Duplicates: tt.duplicates}.duplicates()
+			if tt.wantErr {
+				c.Assert(err, qt.ErrorMatches, `invalid duplicates strategy "ignore", must be either overwrite or append`)
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			c.Assert(got, qt.Equals, tt.want)
+		})
+	}
+}
+
+func TestClient_pointTags(t *testing.T) {
+	tests := []struct {
+		name       string
+		duplicates string
+		execUUID   string
+		tags       map[string]string
+		want       map[string]string
+		wantErr    error
+	}{
+		{name: "Overwrite", duplicates: DuplicatesOverwrite, execUUID: "uuid", tags: map[string]string{"event": "start"}, want: map[string]string{"event": "start"}},
+		{name: "Append", duplicates: DuplicatesAppend, execUUID: "uuid", tags: map[string]string{"event": "start"}, want: map[string]string{"event": "start", TagExecUUID: "uuid"}},
+		{name: "Append to a point of an execution", duplicates: DuplicatesAppend, execUUID: "uuid", tags: map[string]string{TagExecUUID: "other"}, want: map[string]string{TagExecUUID: "other"}},
+		{name: "Append to a point of an execution without execution", duplicates: DuplicatesAppend, tags: map[string]string{TagExecUUID: "other"}, want: map[string]string{TagExecUUID: "other"}},
+		{name: "Append without execution", duplicates: DuplicatesAppend, tags: map[string]string{"event": "start"}, wantErr: ErrNoExecution},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			client, err := Config{Host: "localhost", Duplicates: tt.duplicates}.NewClient()
+			c.Assert(err, qt.IsNil)
+			defer client.Close()
+			client.SetExecution(tt.execUUID)
+
+			got, err := client.pointTags(tt.tags)
+			if tt.wantErr != nil {
+				c.Assert(errors.Is(err, tt.wantErr), qt.IsTrue)
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			c.Assert(got, qt.DeepEquals, tt.want)
+		})
+	}
+}
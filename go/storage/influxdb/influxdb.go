@@ -20,18 +20,61 @@ package influxdb
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
+
 	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
 )
 
 const (
 	ErrorInvalidConfiguration = "invalid configuration"
+
+	// TagExecUUID is the tag holding the UUID of the execution a point belongs to.
+	TagExecUUID = "exec_uuid"
 )
 
+// ErrNoExecution is returned when writing a point without execution using the
+// DuplicatesAppend strategy, see Client.SetExecution.
+var ErrNoExecution = errors.New("the point has no execution, it is required to append duplicates")
+
 // Client used to query and interact with an influxdb server.
 type Client struct {
 	influx influxdb2.Client
 	Config *Config
+
+	// precision is the precision of the timestamps written by the Client.
+	precision time.Duration
+
+	// duplicates is the Config.Duplicates strategy of the Client, and execUUID
+	// the execution whose points are written, see SetExecution.
+	duplicates string
+	execUUID   string
+}
+
+// SetExecution sets the execution whose points are written by the Client. When using
+// the DuplicatesAppend strategy, the points that do not have the TagExecUUID tag are
+// tagged with it.
+func (c *Client) SetExecution(execUUID string) {
+	c.execUUID = execUUID
+}
+
+// pointTags returns the tags of a point written by the Client. With the DuplicatesAppend
+// strategy, the TagExecUUID tag is added to the tags if they do not have it yet.
+func (c *Client) pointTags(tags map[string]string) (map[string]string, error) {
+	if c.duplicates != DuplicatesAppend || tags[TagExecUUID] != "" {
+		return tags, nil
+	}
+	if c.execUUID == "" {
+		return nil, ErrNoExecution
+	}
+	appended := make(map[string]string, len(tags)+1)
+	for key, value := range tags {
+		appended[key] = value
+	}
+	appended[TagExecUUID] = c.execUUID
+	return appended, nil
 }
 
 // Select issues the given query to the Client and parses the results into a key/value
@@ -52,3 +95,45 @@ func (c *Client) Select(query string) ([]map[string]interface{}, error) {
 	}
 	return result, nil
 }
+
+// Write writes a single point to the given measurement of the Client's database.
+// The timestamp is truncated to the precision of the Client.
+func (c *Client) Write(measurement string, tags map[string]string, fields map[string]interface{}, ts time.Time) error {
+	tags, err := c.pointTags(tags)
+	if err != nil {
+		return err
+	}
+	writeAPI := c.influx.WriteAPIBlocking("", c.Config.Database)
+	return writeAPI.WritePoint(context.Background(), influxdb2.NewPoint(measurement, tags, fields, c.Truncate(ts)))
+}
+
+// WritePoints writes the given points to the Client's database in a single request.
+// The timestamps are truncated to the precision of the Client. As InfluxDB replaces a
+// point with the same measurement, tags and timestamp, writing the same points again
+// has no effect, unless they are tagged with another execution using DuplicatesAppend.
+func (c *Client) WritePoints(points []Point) error {
+	influxPoints := make([]*write.Point, 0, len(points))
+	for _, point := range points {
+		tags, err := c.pointTags(point.Tags)
+		if err != nil {
+			return err
+		}
+		influxPoints = append(influxPoints, influxdb2.NewPoint(point.Measurement, tags, point.Fields, c.Truncate(point.Time)))
+	}
+	writeAPI := c.influx.WriteAPIBlocking("", c.Config.Database)
+	return writeAPI.WritePoint(context.Background(), influxPoints...)
+}
+
+// Truncate truncates the given time to the precision of the Client, it must be
+// used on the timestamps compared with the ones of the points written by the Client.
+func (c *Client) Truncate(ts time.Time) time.Time {
+	if c.precision == 0 {
+		return ts
+	}
+	return ts.Truncate(c.precision)
+}
+
+// Close closes the connections of the Client.
+func (c *Client) Close() {
+	c.influx.Close()
+}
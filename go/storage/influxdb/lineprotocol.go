@@ -0,0 +1,159 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package influxdb
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errMissingTimestamp = errors.New("missing timestamp")
+
+// identifierUnescaper unescapes the measurements, the tag keys and values, and the field keys
+// of the line protocol.
+var identifierUnescaper = strings.NewReplacer(`\,`, `,`, `\ `, ` `, `\=`, `=`)
+
+// Point is a point of a measurement, as read from InfluxDB's line protocol.
+type Point struct {
+	Measurement string
+	Tags        map[string]string
+	Fields      map[string]interface{}
+	Time        time.Time
+}
+
+// ReadLineProtocol reads the points of an export in InfluxDB's line protocol, such as the
+// ones of influx_inspect export, and calls fn for each of them in order. The timestamps are
+// expressed in the given precision. The comments and the DDL statements of the export are
+// ignored, the points must have a timestamp so they keep it when written again.
+func ReadLineProtocol(r io.Reader, precision time.Duration, fn func(Point) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	lineNb := 0
+	for scanner.Scan() {
+		lineNb++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "CREATE ") {
+			continue
+		}
+		point, err := parseLine(line, precision)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", lineNb, err)
+		}
+		if err := fn(point); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// parseLine parses a line of the line protocol: the measurement and its tags, the fields
+// and the timestamp, separated by spaces.
+func parseLine(line string, precision time.Duration) (Point, error) {
+	sections := splitUnescaped(line, ' ', true)
+	if len(sections) != 3 {
+		if len(sections) == 2 {
+			return Point{}, errMissingTimestamp
+		}
+		return Point{}, fmt.Errorf("invalid point %q", line)
+	}
+
+	key := splitUnescaped(sections[0], ',', false)
+	point := Point{
+		Measurement: identifierUnescaper.Replace(key[0]),
+		Tags:        make(map[string]string, len(key)-1),
+		Fields:      map[string]interface{}{},
+	}
+	for _, tag := range key[1:] {
+		pair := splitUnescaped(tag, '=', false)
+		if len(pair) != 2 {
+			return Point{}, fmt.Errorf("invalid tag %q", tag)
+		}
+		point.Tags[identifierUnescaper.Replace(pair[0])] = identifierUnescaper.Replace(pair[1])
+	}
+
+	for _, field := range splitUnescaped(sections[1], ',', true) {
+		pair := splitUnescaped(field, '=', true)
+		if len(pair) != 2 {
+			return Point{}, fmt.Errorf("invalid field %q", field)
+		}
+		value, err := parseFieldValue(pair[1])
+		if err != nil {
+			return Point{}, err
+		}
+		point.Fields[identifierUnescaper.Replace(pair[0])] = value
+	}
+
+	ts, err := strconv.ParseInt(sections[2], 10, 64)
+	if err != nil {
+		return Point{}, fmt.Errorf("invalid timestamp %q", sections[2])
+	}
+	point.Time = time.Unix(0, ts*int64(precision)).UTC()
+	return point, nil
+}
+
+// parseFieldValue parses the value of a field: a string, an integer, an unsigned
+// integer, a boolean or a float.
+func parseFieldValue(value string) (interface{}, error) {
+	switch {
+	case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+		return strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(value[1 : len(value)-1]), nil
+	case strings.HasSuffix(value, "i"):
+		return strconv.ParseInt(strings.TrimSuffix(value, "i"), 10, 64)
+	case strings.HasSuffix(value, "u"):
+		return strconv.ParseUint(strings.TrimSuffix(value, "u"), 10, 64)
+	}
+	switch value {
+	case "t", "T", "true", "True", "TRUE":
+		return true, nil
+	case "f", "F", "false", "False", "FALSE":
+		return false, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid field value %q", value)
+	}
+	return f, nil
+}
+
+// splitUnescaped splits s around the occurrences of sep that are not escaped by a backslash,
+// nor enclosed in double quotes if quotes is set.
+func splitUnescaped(s string, sep byte, quotes bool) []string {
+	var parts []string
+	escaped, quoted := false, false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case escaped:
+			escaped = false
+		case s[i] == '\\':
+			escaped = true
+		case quotes && s[i] == '"':
+			quoted = !quoted
+		case s[i] == sep && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
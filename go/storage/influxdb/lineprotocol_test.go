@@ -0,0 +1,94 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package influxdb
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestReadLineProtocol(t *testing.T) {
+	c := qt.New(t)
+	export := `# DDL
+CREATE DATABASE arewefastyet WITH NAME autogen
+# DML
+# CONTEXT-DATABASE:arewefastyet
+
+cpu,exec_uuid=abc,host=vt\,gate\ 1 usage_user=12.5,cores=8i,count=3u,up=t,name="vt \"gate\"" 1622548800000000000
+disk\ io,exec_uuid=abc value=1 1622548801000000000
+`
+	var points []Point
+	err := ReadLineProtocol(strings.NewReader(export), time.Nanosecond, func(point Point) error {
+		points = append(points, point)
+		return nil
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(points, qt.DeepEquals, []Point{
+		{
+			Measurement: "cpu",
+			Tags:        map[string]string{"exec_uuid": "abc", "host": "vt,gate 1"},
+			Fields:      map[string]interface{}{"usage_user": 12.5, "cores": int64(8), "count": uint64(3), "up": true, "name": `vt "gate"`},
+			Time:        time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			Measurement: "disk io",
+			Tags:        map[string]string{"exec_uuid": "abc"},
+			Fields:      map[string]interface{}{"value": 1.0},
+			Time:        time.Date(2021, 6, 1, 12, 0, 1, 0, time.UTC),
+		},
+	})
+}
+
+func TestReadLineProtocolPrecision(t *testing.T) {
+	c := qt.New(t)
+	var point Point
+	err := ReadLineProtocol(strings.NewReader("cpu value=1 1622548800\n"), time.Second, func(p Point) error {
+		point = p
+		return nil
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(point.Time, qt.Equals, time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC))
+}
+
+func TestReadLineProtocolErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		export  string
+		wantErr string
+	}{
+		{name: "missing timestamp", export: "cpu value=1\n", wantErr: "line 1: missing timestamp"},
+		{name: "invalid tag", export: "# comment\ncpu,host value=1 1\n", wantErr: `line 2: invalid tag "host"`},
+		{name: "invalid field value", export: "cpu value=abc 1\n", wantErr: `line 1: invalid field value "abc"`},
+		{name: "invalid timestamp", export: "cpu value=1 now\n", wantErr: `line 1: invalid timestamp "now"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ReadLineProtocol(strings.NewReader(tt.export), time.Nanosecond, func(Point) error { return nil })
+			qt.Assert(t, err, qt.ErrorMatches, tt.wantErr)
+		})
+	}
+
+	errStop := errors.New("stop")
+	err := ReadLineProtocol(strings.NewReader("cpu value=1 1\n"), time.Nanosecond, func(Point) error { return errStop })
+	qt.Assert(t, errors.Is(err, errStop), qt.IsTrue)
+}
@@ -0,0 +1,90 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package influxdb
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// minRetryBackoff is the delay before the first retry of a client creation
+// when InfluxDB cannot be reached. It doubles after every retry.
+const minRetryBackoff = time.Second
+
+// pingTimeout is the time after which a ping of InfluxDB is considered failed.
+const pingTimeout = 10 * time.Second
+
+// sleep is used to wait before retrying a client creation.
+var sleep = time.Sleep
+
+// pingClient is the HTTP client pinging InfluxDB, it does not wait forever for an unresponsive host.
+var pingClient = &http.Client{Timeout: pingTimeout}
+
+// UnreachableError is returned when InfluxDB could not be reached after Config.MaxRetries
+// retries. Callers can detect it using errors.As and decide whether to skip or fail.
+type UnreachableError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *UnreachableError) Error() string {
+	return fmt.Sprintf("influxdb unreachable after %d attempts: %v", e.Attempts, e.Err)
+}
+
+func (e *UnreachableError) Unwrap() error {
+	return e.Err
+}
+
+// NewClientWithRetry works like NewClient and ensures InfluxDB can be reached before
+// returning the client. An unreachable InfluxDB is retried up to MaxRetries times with
+// an exponential backoff, after which an *UnreachableError is returned. An invalid
+// Config is not retried.
+func (cfg Config) NewClientWithRetry() (*Client, error) {
+	client, err := cfg.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	backoff := minRetryBackoff
+	for attempt := 1; ; attempt++ {
+		err = client.ping()
+		if err == nil {
+			return client, nil
+		}
+		if attempt > cfg.MaxRetries {
+			client.Close()
+			return nil, &UnreachableError{Attempts: attempt, Err: err}
+		}
+		sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// ping checks that InfluxDB is reachable through its /ping endpoint.
+func (c *Client) ping() error {
+	resp, err := pingClient.Get(c.Config.Host + ":" + c.Config.Port + "/ping")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected ping status: %s", resp.Status)
+	}
+	return nil
+}
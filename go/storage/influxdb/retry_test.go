@@ -0,0 +1,113 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package influxdb
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestConfig_NewClientWithRetry(t *testing.T) {
+	tests := []struct {
+		name       string
+		failures   int
+		maxRetries int
+		wantErr    bool
+		wantPings  int
+		wantDelays []time.Duration
+	}{
+		{name: "reachable", failures: 0, maxRetries: 3, wantPings: 1},
+		{name: "reachable after retries", failures: 2, maxRetries: 3, wantPings: 3, wantDelays: []time.Duration{time.Second, 2 * time.Second}},
+		{name: "retries exhausted", failures: 5, maxRetries: 2, wantErr: true, wantPings: 3, wantDelays: []time.Duration{time.Second, 2 * time.Second}},
+		{name: "no retry", failures: 1, maxRetries: 0, wantErr: true, wantPings: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			var delays []time.Duration
+			c.Patch(&sleep, func(d time.Duration) { delays = append(delays, d) })
+
+			pings := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				c.Check(r.URL.Path, qt.Equals, "/ping")
+				pings++
+				if pings <= tt.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer server.Close()
+
+			serverURL, err := url.Parse(server.URL)
+			c.Assert(err, qt.IsNil)
+			host, port, err := net.SplitHostPort(serverURL.Host)
+			c.Assert(err, qt.IsNil)
+
+			client, err := Config{Host: host, Port: port, MaxRetries: tt.maxRetries}.NewClientWithRetry()
+			if tt.wantErr {
+				var unreachable *UnreachableError
+				c.Assert(errors.As(err, &unreachable), qt.IsTrue)
+				c.Assert(unreachable.Attempts, qt.Equals, tt.wantPings)
+				c.Assert(client, qt.IsNil)
+			} else {
+				c.Assert(err, qt.IsNil)
+				client.Close()
+			}
+			c.Assert(pings, qt.Equals, tt.wantPings)
+			c.Assert(delays, qt.DeepEquals, tt.wantDelays)
+		})
+	}
+}
+
+func TestConfig_NewClientWithRetryInvalidConfig(t *testing.T) {
+	c := qt.New(t)
+	c.Patch(&sleep, func(time.Duration) { c.Fatal("an invalid configuration must not be retried") })
+
+	_, err := Config{MaxRetries: 3}.NewClientWithRetry()
+	c.Assert(err, qt.ErrorMatches, ErrorInvalidConfiguration)
+}
+
+func TestConfig_NewClientWithRetryUnresponsive(t *testing.T) {
+	c := qt.New(t)
+	c.Patch(&pingClient, &http.Client{Timeout: 10 * time.Millisecond})
+
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+
+	serverURL, err := url.Parse(server.URL)
+	c.Assert(err, qt.IsNil)
+	host, port, err := net.SplitHostPort(serverURL.Host)
+	c.Assert(err, qt.IsNil)
+
+	_, err = Config{Host: host, Port: port}.NewClientWithRetry()
+	var unreachable *UnreachableError
+	c.Assert(errors.As(err, &unreachable), qt.IsTrue)
+}
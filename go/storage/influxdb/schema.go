@@ -0,0 +1,140 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package influxdb
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// schemaHTTPClient is the client of the requests sent by VerifySchema to the InfluxQL
+// endpoint, an unresponsive InfluxDB must not block the executions verifying their schema.
+var schemaHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// VerifySchema ensures the database of Config exists and contains the given measurements.
+// If create is true, a missing database is created along with its default retention policy.
+// Measurements cannot be created beforehand since InfluxDB creates them on their first write,
+// missing measurements are thus always reported as an error. InfluxDB is retried as in NewClientWithRetry.
+func (cfg Config) VerifySchema(create bool, measurements ...string) error {
+	client, err := cfg.NewClientWithRetry()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	exists, err := client.databaseExists()
+	if err != nil {
+		return err
+	}
+	if !exists {
+		if !create {
+			return fmt.Errorf("database %s does not exist", cfg.Database)
+		}
+		err = client.createDatabase()
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(measurements) == 0 {
+		return nil
+	}
+	existing, err := client.measurements()
+	if err != nil {
+		return err
+	}
+	if missing := missingMeasurements(measurements, existing); len(missing) > 0 {
+		return fmt.Errorf("missing measurements in database %s: %s", cfg.Database, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// databaseExists returns true if a bucket, which is a database and retention
+// policy pair, exists for the Client's database.
+func (c *Client) databaseExists() (bool, error) {
+	results, err := c.Select(`buckets() |> keep(columns: ["name"])`)
+	if err != nil {
+		return false, err
+	}
+	for _, result := range results {
+		name, _ := result["name"].(string)
+		if name == c.Config.Database || strings.HasPrefix(name, c.Config.Database+"/") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// measurements returns the names of the measurements of the Client's database.
+func (c *Client) measurements() ([]string, error) {
+	results, err := c.Select(fmt.Sprintf(`import "influxdata/influxdb/schema"
+schema.measurements(bucket: "%s")`, c.Config.Database))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, result := range results {
+		if name, ok := result["_value"].(string); ok {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// createDatabase creates the Client's database through the InfluxQL endpoint,
+// which also creates the default retention policy of the database.
+func (c *Client) createDatabase() error {
+	form := url.Values{"q": {fmt.Sprintf(`CREATE DATABASE "%s"`, c.Config.Database)}}
+	req, err := http.NewRequest(http.MethodPost, c.Config.Host+":"+c.Config.Port+"/query", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if c.Config.User != "" {
+		req.SetBasicAuth(c.Config.User, c.Config.Password)
+	}
+	resp, err := schemaHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("could not create database %s: %s", c.Config.Database, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func missingMeasurements(expected, existing []string) []string {
+	exists := make(map[string]bool, len(existing))
+	for _, name := range existing {
+		exists[name] = true
+	}
+	var missing []string
+	for _, name := range expected {
+		if !exists[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
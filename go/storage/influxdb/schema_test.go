@@ -0,0 +1,97 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package influxdb
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestMissingMeasurements(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected []string
+		existing []string
+		want     []string
+	}{
+		{name: "All measurements exist", expected: []string{"events"}, existing: []string{"events", "go_memstats_alloc_bytes_total"}, want: nil},
+		{name: "Missing measurement", expected: []string{"events", "process_cpu_seconds_total"}, existing: []string{"events"}, want: []string{"process_cpu_seconds_total"}},
+		{name: "Empty database", expected: []string{"events"}, existing: nil, want: []string{"events"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qt.Assert(t, missingMeasurements(tt.expected, tt.existing), qt.DeepEquals, tt.want)
+		})
+	}
+}
+
+func TestClient_createDatabase(t *testing.T) {
+	c := qt.New(t)
+	var query, user string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.FormValue("q")
+		user, _, _ = r.BasicAuth()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	c.Assert(err, qt.IsNil)
+	host, port, err := net.SplitHostPort(serverURL.Host)
+	c.Assert(err, qt.IsNil)
+
+	client, err := Config{Host: host, Port: port, User: "user", Password: "password", Database: "stats"}.NewClient()
+	c.Assert(err, qt.IsNil)
+	defer client.Close()
+
+	c.Assert(client.createDatabase(), qt.IsNil)
+	c.Assert(query, qt.Equals, `CREATE DATABASE "stats"`)
+	c.Assert(user, qt.Equals, "user")
+}
+
+func TestClient_createDatabaseTimeout(t *testing.T) {
+	c := qt.New(t)
+	previous := schemaHTTPClient
+	schemaHTTPClient = &http.Client{Timeout: 50 * time.Millisecond}
+	c.Cleanup(func() { schemaHTTPClient = previous })
+
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	}))
+	defer server.Close()
+	defer close(unblock)
+
+	serverURL, err := url.Parse(server.URL)
+	c.Assert(err, qt.IsNil)
+	host, port, err := net.SplitHostPort(serverURL.Host)
+	c.Assert(err, qt.IsNil)
+
+	client, err := Config{Host: host, Port: port, Database: "stats"}.NewClient()
+	c.Assert(err, qt.IsNil)
+	defer client.Close()
+
+	c.Assert(client.createDatabase(), qt.ErrorMatches, `.*Client.Timeout exceeded.*`)
+}
@@ -21,6 +21,7 @@ package mysql
 import (
 	"database/sql"
 	"fmt"
+	"time"
 )
 
 type ConfigDB struct {
@@ -28,6 +29,18 @@ type ConfigDB struct {
 	User     string
 	Password string
 	Database string
+
+	// MaxOpenConns is the maximum number of open connections to the database.
+	// A value lower or equal to zero means there is no limit.
+	MaxOpenConns int
+
+	// MaxIdleConns is the maximum number of idle connections kept in the pool.
+	// A value lower or equal to zero keeps the default of database/sql.
+	MaxIdleConns int
+
+	// ConnMaxLifetime is the maximum amount of time a connection may be reused.
+	// A value lower or equal to zero means connections are never closed due to their age.
+	ConnMaxLifetime time.Duration
 }
 
 func (cfg ConfigDB) NewClient() (*Client, error) {
@@ -37,9 +50,24 @@ func (cfg ConfigDB) NewClient() (*Client, error) {
 	if err != nil {
 		return nil, err
 	}
+	cfg.configurePool(client.db)
 	return client, nil
 }
 
+// configurePool applies the connection pooling parameters of ConfigDB to db,
+// the parameters lower or equal to zero are left to their default.
+func (cfg ConfigDB) configurePool(db *sql.DB) {
+	if cfg.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	if cfg.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
+}
+
 func (cfg ConfigDB) IsValid() bool {
 	return !(cfg.Database == "" || cfg.User == "" || cfg.Host == "")
 }
@@ -21,6 +21,7 @@ package mysql
 import (
 	qt "github.com/frankban/quicktest"
 	"testing"
+	"time"
 )
 
 func TestConfigDB_IsValid(t *testing.T) {
@@ -43,3 +44,20 @@ func TestConfigDB_IsValid(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigDB_configurePool(t *testing.T) {
+	c := qt.New(t)
+	cfg := ConfigDB{Host: "host", User: "user", Database: "database", MaxOpenConns: 10, MaxIdleConns: 5, ConnMaxLifetime: time.Minute}
+
+	client, err := New(cfg)
+	c.Assert(err, qt.IsNil)
+	defer client.Close()
+
+	c.Assert(client.db.Stats().MaxOpenConnections, qt.Equals, 10)
+
+	// zero values keep the defaults of database/sql
+	client, err = New(ConfigDB{Host: "host", User: "user", Database: "database"})
+	c.Assert(err, qt.IsNil)
+	defer client.Close()
+	c.Assert(client.db.Stats().MaxOpenConnections, qt.Equals, 0)
+}
@@ -28,6 +28,10 @@ const (
 	flagDatabaseHost     = "db-host"
 	flagDatabasePassword = "db-password"
 	flagDatabaseUser     = "db-user"
+
+	flagDatabaseMaxOpenConns    = "db-max-open-conns"
+	flagDatabaseMaxIdleConns    = "db-max-idle-conns"
+	flagDatabaseConnMaxLifetime = "db-conn-max-lifetime"
 )
 
 func (cfg *ConfigDB) AddToViper(v *viper.Viper) {
@@ -35,6 +39,9 @@ func (cfg *ConfigDB) AddToViper(v *viper.Viper) {
 	_ = v.UnmarshalKey(flagDatabaseHost, &cfg.Host)
 	_ = v.UnmarshalKey(flagDatabasePassword, &cfg.Password)
 	_ = v.UnmarshalKey(flagDatabaseUser, &cfg.User)
+	_ = v.UnmarshalKey(flagDatabaseMaxOpenConns, &cfg.MaxOpenConns)
+	_ = v.UnmarshalKey(flagDatabaseMaxIdleConns, &cfg.MaxIdleConns)
+	_ = v.UnmarshalKey(flagDatabaseConnMaxLifetime, &cfg.ConnMaxLifetime)
 }
 
 func (cfg *ConfigDB) AddToCommand(cmd *cobra.Command) {
@@ -42,9 +49,15 @@ func (cfg *ConfigDB) AddToCommand(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&cfg.Host, flagDatabaseHost, "", "Hostname of the database")
 	cmd.Flags().StringVar(&cfg.Password, flagDatabasePassword, "", "Password to authenticate the database.")
 	cmd.Flags().StringVar(&cfg.User, flagDatabaseUser, "", "User used to connect to the database")
+	cmd.Flags().IntVar(&cfg.MaxOpenConns, flagDatabaseMaxOpenConns, 0, "Maximum number of open connections to the database. Zero means unlimited.")
+	cmd.Flags().IntVar(&cfg.MaxIdleConns, flagDatabaseMaxIdleConns, 0, "Maximum number of idle connections to the database. Zero keeps the default of database/sql.")
+	cmd.Flags().DurationVar(&cfg.ConnMaxLifetime, flagDatabaseConnMaxLifetime, 0, "Maximum amount of time a connection to the database may be reused. Zero means connections are reused forever.")
 
 	_ = viper.BindPFlag(flagDatabaseName, cmd.Flags().Lookup(flagDatabaseName))
 	_ = viper.BindPFlag(flagDatabaseHost, cmd.Flags().Lookup(flagDatabaseHost))
 	_ = viper.BindPFlag(flagDatabasePassword, cmd.Flags().Lookup(flagDatabasePassword))
 	_ = viper.BindPFlag(flagDatabaseUser, cmd.Flags().Lookup(flagDatabaseUser))
+	_ = viper.BindPFlag(flagDatabaseMaxOpenConns, cmd.Flags().Lookup(flagDatabaseMaxOpenConns))
+	_ = viper.BindPFlag(flagDatabaseMaxIdleConns, cmd.Flags().Lookup(flagDatabaseMaxIdleConns))
+	_ = viper.BindPFlag(flagDatabaseConnMaxLifetime, cmd.Flags().Lookup(flagDatabaseConnMaxLifetime))
 }
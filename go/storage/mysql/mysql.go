@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/vitessio/arewefastyet/go/storage"
 )
 
 const (
@@ -38,6 +39,7 @@ func New(config ConfigDB) (client *Client, err error) {
 	if err != nil {
 		return nil, err
 	}
+	config.configurePool(client.db)
 	return client, nil
 }
 
@@ -75,3 +77,10 @@ func (c *Client) Select(query string, args ...interface{}) (*sql.Rows, error) {
 	}
 	return rows, nil
 }
+
+func (c *Client) Transaction(f func(tx storage.SQLClient) error) error {
+	if c.db == nil {
+		return errors.New(ErrorClientConnectionNotInitialized)
+	}
+	return storage.Transaction(c.db, f)
+}
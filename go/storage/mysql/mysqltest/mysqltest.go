@@ -0,0 +1,87 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+// Package mysqltest provides ephemeral MySQL databases, holding the schema of
+// arewefastyet, to the tests that need a real database.
+package mysqltest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/vitessio/arewefastyet/go/storage/mysql"
+)
+
+const (
+	// EnvHost is the environment variable holding the address (host:port) of the MySQL
+	// server used by the tests. The tests needing a database are skipped if it is not set.
+	EnvHost = "MYSQL_TEST_HOST"
+
+	// EnvUser and EnvPassword are the environment variables holding the credentials used to
+	// connect to the MySQL server of EnvHost. The user defaults to root.
+	EnvUser     = "MYSQL_TEST_USER"
+	EnvPassword = "MYSQL_TEST_PASSWORD"
+)
+
+// MigrationsDir returns the directory of the repository holding the SQL migrations.
+func MigrationsDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "..", "..", "sql", "migration")
+}
+
+// New creates a database with a unique name on the MySQL server of EnvHost, applies the SQL
+// migrations of MigrationsDir to it, and returns a client connected to it. The database is
+// dropped once the test and its subtests completed. The test is skipped if EnvHost is not set.
+func New(t testing.TB) *mysql.Client {
+	t.Helper()
+	host := os.Getenv(EnvHost)
+	if host == "" {
+		t.Skipf("no MySQL server to test against, %s is not set", EnvHost)
+	}
+	user := os.Getenv(EnvUser)
+	if user == "" {
+		user = "root"
+	}
+	cfg := mysql.ConfigDB{
+		Host:     host,
+		User:     user,
+		Password: os.Getenv(EnvPassword),
+		Database: fmt.Sprintf("awfy_test_%x", uuid.New().ID()),
+	}
+	if err := cfg.CreateDatabase(); err != nil {
+		t.Fatalf("could not create the test database: %v", err)
+	}
+	client, err := cfg.NewClient()
+	if err != nil {
+		_ = cfg.DropDatabase()
+		t.Fatalf("could not connect to the test database: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = client.Close()
+		if err := cfg.DropDatabase(); err != nil {
+			t.Errorf("could not drop the test database %s: %v", cfg.Database, err)
+		}
+	})
+	if err := client.CreateSchema(MigrationsDir()); err != nil {
+		t.Fatalf("could not create the schema of the test database: %v", err)
+	}
+	return client
+}
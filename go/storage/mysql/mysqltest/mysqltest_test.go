@@ -0,0 +1,48 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+package mysqltest
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestNew(t *testing.T) {
+	c := qt.New(t)
+	client := New(t)
+
+	countTables := func() int {
+		rows, err := client.Select("SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE()")
+		c.Assert(err, qt.IsNil)
+		defer rows.Close()
+		var count int
+		c.Assert(rows.Next(), qt.IsTrue)
+		c.Assert(rows.Scan(&count), qt.IsNil)
+		return count
+	}
+
+	_, err := client.Insert("INSERT INTO execution(uuid, status, source, git_ref, type) VALUES ('a', 'created', 'cron', 'sha', 'oltp')")
+	c.Assert(err, qt.IsNil)
+	_, err = client.Insert("INSERT INTO execution_metadata(exec_uuid, metadata_key, metadata_value) VALUES ('a', 'key', 'value')")
+	c.Assert(err, qt.IsNil)
+	c.Assert(countTables() > 0, qt.IsTrue)
+
+	c.Assert(client.DropSchema(), qt.IsNil)
+	c.Assert(countTables(), qt.Equals, 0)
+}
@@ -0,0 +1,143 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+package mysql
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// sqlBlockComment and sqlLineComment match the comments of the SQL migrations.
+	sqlBlockComment = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	sqlLineComment  = regexp.MustCompile(`(?m)^\s*--.*$`)
+
+	// databaseStatement matches the statements of the SQL migrations that create, drop or select
+	// the database. They are skipped so that the migrations apply to the Client's database.
+	databaseStatement = regexp.MustCompile(`(?i)^(CREATE\s+DATABASE|DROP\s+DATABASE|USE)\b`)
+)
+
+// MigrationFiles returns the SQL migrations of dir, such as the sql/migration directory of the
+// repository, in the order in which they must be applied.
+func MigrationFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "[0-9]*.sql"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no SQL migration in %s", dir)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// migrationStatements splits the script of a SQL migration into its statements, without
+// their comments. The statements creating, dropping or selecting the database are skipped.
+func migrationStatements(script string) []string {
+	script = sqlBlockComment.ReplaceAllString(script, "")
+	script = sqlLineComment.ReplaceAllString(script, "")
+	var statements []string
+	for _, statement := range strings.Split(script, ";") {
+		statement = strings.TrimSpace(statement)
+		if statement == "" || databaseStatement.MatchString(statement) {
+			continue
+		}
+		statements = append(statements, statement)
+	}
+	return statements
+}
+
+// CreateSchema applies the SQL migrations of dir, see MigrationFiles, to the Client's database
+// to create the tables of arewefastyet. It is meant to set up the ephemeral databases of tests.
+func (c *Client) CreateSchema(dir string) error {
+	if c.db == nil {
+		return errors.New(ErrorClientConnectionNotInitialized)
+	}
+	files, err := MigrationFiles(dir)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		script, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		for _, statement := range migrationStatements(string(script)) {
+			if _, err := c.db.Exec(statement); err != nil {
+				return fmt.Errorf("migration %s: %w", filepath.Base(file), err)
+			}
+		}
+	}
+	return nil
+}
+
+// DropSchema drops all the tables of the Client's database, restoring it to an empty schema.
+func (c *Client) DropSchema() error {
+	if c.db == nil {
+		return errors.New(ErrorClientConnectionNotInitialized)
+	}
+	rows, err := c.db.Query("SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE()")
+	if err != nil {
+		return err
+	}
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			rows.Close()
+			return err
+		}
+		tables = append(tables, "`"+table+"`")
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(tables) == 0 {
+		return nil
+	}
+	_, err = c.db.Exec("DROP TABLE IF EXISTS " + strings.Join(tables, ", "))
+	return err
+}
+
+// CreateDatabase creates the database of ConfigDB if it does not exist yet.
+func (cfg ConfigDB) CreateDatabase() error {
+	return cfg.execWithoutDatabase(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s`", cfg.Database))
+}
+
+// DropDatabase drops the database of ConfigDB if it exists.
+func (cfg ConfigDB) DropDatabase() error {
+	return cfg.execWithoutDatabase(fmt.Sprintf("DROP DATABASE IF EXISTS `%s`", cfg.Database))
+}
+
+// execWithoutDatabase executes the given statement on a connection that selects no database.
+func (cfg ConfigDB) execWithoutDatabase(statement string) error {
+	db, err := sql.Open("mysql", fmt.Sprintf("%s:%s@tcp(%s)/", cfg.User, cfg.Password, cfg.Host))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	_, err = db.Exec(statement)
+	return err
+}
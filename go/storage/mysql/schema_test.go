@@ -0,0 +1,68 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+package mysql
+
+import (
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestMigrationStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "comments",
+			script: "/*\n * Copyright 2021 The Vitess Authors.\n * /\n */\n\n--\n-- Table structure\n--\nALTER TABLE execution ADD COLUMN `error` TEXT DEFAULT NULL;\n",
+			want:   []string{"ALTER TABLE execution ADD COLUMN `error` TEXT DEFAULT NULL"},
+		},
+		{
+			name:   "database statements",
+			script: "DROP DATABASE IF EXISTS benchmark;\n\nCREATE DATABASE benchmark;\n\nUSE benchmark;\n\nCREATE TABLE `benchmark` (\n  `test_no` int(11) NOT NULL\n) ENGINE=InnoDB;\n",
+			want:   []string{"CREATE TABLE `benchmark` (\n  `test_no` int(11) NOT NULL\n) ENGINE=InnoDB"},
+		},
+		{
+			name:   "multiple statements",
+			script: "DROP TABLE IF EXISTS `execution_label`;\nCREATE TABLE `execution_label` (`exec_uuid` VARCHAR(100));\nalter table execution add column user varchar(100)",
+			want:   []string{"DROP TABLE IF EXISTS `execution_label`", "CREATE TABLE `execution_label` (`exec_uuid` VARCHAR(100))", "alter table execution add column user varchar(100)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qt.Assert(t, migrationStatements(tt.script), qt.DeepEquals, tt.want)
+		})
+	}
+}
+
+func TestMigrationFiles(t *testing.T) {
+	c := qt.New(t)
+	files, err := MigrationFiles(filepath.Join("..", "..", "..", "sql", "migration"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(filepath.Base(files[0]), qt.Equals, "000_old_schema.sql")
+	c.Assert(filepath.Base(files[1]), qt.Equals, "001_New_parent_table_for_executions_125.sql")
+	for _, file := range files {
+		c.Assert(filepath.Ext(file), qt.Equals, ".sql")
+	}
+
+	_, err = MigrationFiles(t.TempDir())
+	c.Assert(err, qt.ErrorMatches, "no SQL migration in .*")
+}
@@ -22,8 +22,11 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
+
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"github.com/vitessio/arewefastyet/go/storage"
 )
 
 const (
@@ -34,6 +37,14 @@ const (
 	flagPsdbDatabase = "planetscale-db-database"
 	flagPsdbBranch   = "planetscale-db-branch"
 
+	flagPsdbReadHost     = "planetscale-db-read-host"
+	flagPsdbReadUser     = "planetscale-db-read-user"
+	flagPsdbReadPassword = "planetscale-db-read-password"
+
+	flagPsdbMaxOpenConns    = "planetscale-db-max-open-conns"
+	flagPsdbMaxIdleConns    = "planetscale-db-max-idle-conns"
+	flagPsdbConnMaxLifetime = "planetscale-db-conn-max-lifetime"
+
 	ErrorClientConnectionNotInitialized = "the client connection to the database is not initialized"
 )
 
@@ -45,6 +56,20 @@ type (
 		User string
 		Password string
 		Host string
+
+		// ReadHost is the hostname of an optional read replica of the database,
+		// used by the read paths that can tolerate replication lag. ReadUser and
+		// ReadPassword default to User and Password when they are empty.
+		ReadHost     string
+		ReadUser     string
+		ReadPassword string
+
+		// MaxOpenConns, MaxIdleConns and ConnMaxLifetime configure the pool of connections
+		// to the database and to its read replica. The default of database/sql is kept for
+		// the values lower or equal to zero.
+		MaxOpenConns    int
+		MaxIdleConns    int
+		ConnMaxLifetime time.Duration
 	}
 
 	Client struct {
@@ -64,6 +89,12 @@ func (cfg *Config) AddToViper(v *viper.Viper) {
 	_ = v.UnmarshalKey(flagPsdbUser, &cfg.User)
 	_ = v.UnmarshalKey(flagPsdbDatabase, &cfg.Database)
 	_ = v.UnmarshalKey(flagPsdbBranch, &cfg.Branch)
+	_ = v.UnmarshalKey(flagPsdbReadHost, &cfg.ReadHost)
+	_ = v.UnmarshalKey(flagPsdbReadUser, &cfg.ReadUser)
+	_ = v.UnmarshalKey(flagPsdbReadPassword, &cfg.ReadPassword)
+	_ = v.UnmarshalKey(flagPsdbMaxOpenConns, &cfg.MaxOpenConns)
+	_ = v.UnmarshalKey(flagPsdbMaxIdleConns, &cfg.MaxIdleConns)
+	_ = v.UnmarshalKey(flagPsdbConnMaxLifetime, &cfg.ConnMaxLifetime)
 }
 
 func (cfg *Config) AddToCommand(cmd *cobra.Command) {
@@ -73,6 +104,12 @@ func (cfg *Config) AddToCommand(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&cfg.Host, flagPsdbHost, "", "Hostname of the PlanetscaleDB database.")
 	cmd.Flags().StringVar(&cfg.Database, flagPsdbDatabase, "", "PlanetscaleDB database name.")
 	cmd.Flags().StringVar(&cfg.Branch, flagPsdbBranch, "main", "PlanetscaleDB branch to use.")
+	cmd.Flags().StringVar(&cfg.ReadHost, flagPsdbReadHost, "", "Hostname of a read replica of the PlanetscaleDB database, used to read results and run comparisons. If empty, the primary is used.")
+	cmd.Flags().StringVar(&cfg.ReadUser, flagPsdbReadUser, "", "Username used to authenticate to the read replica. Defaults to the username of the primary.")
+	cmd.Flags().StringVar(&cfg.ReadPassword, flagPsdbReadPassword, "", "Password used to authenticate to the read replica. Defaults to the password of the primary.")
+	cmd.Flags().IntVar(&cfg.MaxOpenConns, flagPsdbMaxOpenConns, 0, "Maximum number of open connections to PlanetscaleDB. Zero means unlimited.")
+	cmd.Flags().IntVar(&cfg.MaxIdleConns, flagPsdbMaxIdleConns, 0, "Maximum number of idle connections to PlanetscaleDB. Zero keeps the default of database/sql.")
+	cmd.Flags().DurationVar(&cfg.ConnMaxLifetime, flagPsdbConnMaxLifetime, 0, "Maximum amount of time a connection to PlanetscaleDB may be reused. Zero means connections are reused forever.")
 
 	_ = viper.BindPFlag(flagPsdbOrg, cmd.Flags().Lookup(flagPsdbOrg))
 	_ = viper.BindPFlag(flagPsdbHost, cmd.Flags().Lookup(flagPsdbHost))
@@ -80,6 +117,29 @@ func (cfg *Config) AddToCommand(cmd *cobra.Command) {
 	_ = viper.BindPFlag(flagPsdbPassword, cmd.Flags().Lookup(flagPsdbPassword))
 	_ = viper.BindPFlag(flagPsdbDatabase, cmd.Flags().Lookup(flagPsdbDatabase))
 	_ = viper.BindPFlag(flagPsdbBranch, cmd.Flags().Lookup(flagPsdbBranch))
+	_ = viper.BindPFlag(flagPsdbReadHost, cmd.Flags().Lookup(flagPsdbReadHost))
+	_ = viper.BindPFlag(flagPsdbReadUser, cmd.Flags().Lookup(flagPsdbReadUser))
+	_ = viper.BindPFlag(flagPsdbReadPassword, cmd.Flags().Lookup(flagPsdbReadPassword))
+	_ = viper.BindPFlag(flagPsdbMaxOpenConns, cmd.Flags().Lookup(flagPsdbMaxOpenConns))
+	_ = viper.BindPFlag(flagPsdbMaxIdleConns, cmd.Flags().Lookup(flagPsdbMaxIdleConns))
+	_ = viper.BindPFlag(flagPsdbConnMaxLifetime, cmd.Flags().Lookup(flagPsdbConnMaxLifetime))
+}
+
+// ReadConfig returns the configuration of the read replica, and false if no
+// read replica is configured.
+func (cfg Config) ReadConfig() (Config, bool) {
+	if cfg.ReadHost == "" {
+		return Config{}, false
+	}
+	readCfg := cfg
+	readCfg.Host = cfg.ReadHost
+	if cfg.ReadUser != "" {
+		readCfg.User = cfg.ReadUser
+	}
+	if cfg.ReadPassword != "" {
+		readCfg.Password = cfg.ReadPassword
+	}
+	return readCfg, true
 }
 
 func (cfg Config) NewClient() (*Client, error) {
@@ -91,10 +151,25 @@ func (cfg Config) NewClient() (*Client, error) {
 	if err != nil {
 		return nil, err
 	}
+	cfg.configurePool(db)
 	client.dial = db
 	return client, nil
 }
 
+// configurePool applies the connection pooling parameters of Config to db,
+// the parameters lower or equal to zero are left to their default.
+func (cfg Config) configurePool(db *sql.DB) {
+	if cfg.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	if cfg.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
+}
+
 func (c *Client) Close() error {
 	if c.dial == nil {
 		return errors.New(ErrorClientConnectionNotInitialized)
@@ -129,3 +204,10 @@ func (c *Client) Select(query string, args ...interface{}) (*sql.Rows, error) {
 	}
 	return rows, nil
 }
+
+func (c *Client) Transaction(f func(tx storage.SQLClient) error) error {
+	if c.dial == nil {
+		return errors.New(ErrorClientConnectionNotInitialized)
+	}
+	return storage.Transaction(c.dial, f)
+}
@@ -0,0 +1,91 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package psdb
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	_ "github.com/go-sql-driver/mysql"
+)
+
+func TestConfigReadConfig(t *testing.T) {
+	primary := Config{Org: "org", Database: "db", Branch: "main", User: "user", Password: "password", Host: "primary"}
+	tests := []struct {
+		name   string
+		cfg    func(cfg Config) Config
+		want   Config
+		wantOK bool
+	}{
+		{
+			name: "no read replica",
+			cfg:  func(cfg Config) Config { return cfg },
+		},
+		{
+			name: "read replica using the credentials of the primary",
+			cfg: func(cfg Config) Config {
+				cfg.ReadHost = "replica"
+				return cfg
+			},
+			want:   Config{Org: "org", Database: "db", Branch: "main", User: "user", Password: "password", Host: "replica", ReadHost: "replica"},
+			wantOK: true,
+		},
+		{
+			name: "read replica with its own credentials",
+			cfg: func(cfg Config) Config {
+				cfg.ReadHost = "replica"
+				cfg.ReadUser = "reader"
+				cfg.ReadPassword = "secret"
+				return cfg
+			},
+			want:   Config{Org: "org", Database: "db", Branch: "main", User: "reader", Password: "secret", Host: "replica", ReadHost: "replica", ReadUser: "reader", ReadPassword: "secret"},
+			wantOK: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.cfg(primary).ReadConfig()
+			qt.Assert(t, ok, qt.Equals, tt.wantOK)
+			qt.Assert(t, got, qt.DeepEquals, tt.want)
+		})
+	}
+}
+
+func TestConfig_configurePool(t *testing.T) {
+	c := qt.New(t)
+	cfg := Config{Org: "org", Database: "db", Branch: "main", User: "user", Password: "password", Host: "primary", MaxOpenConns: 10, MaxIdleConns: 5, ConnMaxLifetime: time.Minute}
+
+	client, err := cfg.NewClient()
+	c.Assert(err, qt.IsNil)
+	defer client.Close()
+	c.Assert(client.dial.Stats().MaxOpenConnections, qt.Equals, 10)
+
+	// the read replica uses the same pool settings
+	cfg.ReadHost = "replica"
+	readCfg, ok := cfg.ReadConfig()
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(readCfg.MaxOpenConns, qt.Equals, 10)
+
+	// zero values keep the defaults of database/sql
+	client, err = Config{Host: "primary"}.NewClient()
+	c.Assert(err, qt.IsNil)
+	defer client.Close()
+	c.Assert(client.dial.Stats().MaxOpenConnections, qt.Equals, 0)
+}
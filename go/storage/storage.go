@@ -24,3 +24,42 @@ type SQLClient interface {
 	Insert(query string, args ...interface{}) (int64, error)
 	Select(query string, args ...interface{}) (*sql.Rows, error)
 }
+
+// TxClient is a SQLClient able to run several queries in a single transaction.
+type TxClient interface {
+	SQLClient
+
+	// Transaction calls f with a SQLClient running its queries in a transaction,
+	// which is committed if f returns nil and rolled back otherwise.
+	Transaction(f func(tx SQLClient) error) error
+}
+
+// Transaction implements TxClient.Transaction on db.
+func Transaction(db *sql.DB, f func(tx SQLClient) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := f(txClient{tx: tx}); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// txClient is the SQLClient of a transaction, see Transaction.
+type txClient struct {
+	tx *sql.Tx
+}
+
+func (c txClient) Insert(query string, args ...interface{}) (int64, error) {
+	res, err := c.tx.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (c txClient) Select(query string, args ...interface{}) (*sql.Rows, error) {
+	return c.tx.Query(query, args...)
+}
@@ -0,0 +1,230 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+// Package federation compares the executions of two arewefastyet deployments,
+// each with its own database, using the comparison code of a single deployment.
+package federation
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/vitessio/arewefastyet/go/exec"
+	"github.com/vitessio/arewefastyet/go/storage"
+	"github.com/vitessio/arewefastyet/go/tools/macrobench"
+)
+
+var (
+	// ErrExecutionNotFound is returned when an execution does not exist in its deployment.
+	ErrExecutionNotFound = errors.New("execution not found")
+
+	// ErrTypeMismatch is returned when the executions are not of the same benchmark type.
+	ErrTypeMismatch = errors.New("the executions are not of the same benchmark type")
+
+	// ErrUnsupportedType is returned when the executions are not macrobenchmarks.
+	ErrUnsupportedType = errors.New("only macrobenchmark executions can be compared across deployments")
+)
+
+// Deployment is an arewefastyet deployment, reached through its database.
+type Deployment struct {
+	// Name identifies the deployment in the caveats.
+	Name string
+
+	// Client reads the database of the deployment. A read replica is enough.
+	Client storage.SQLClient
+}
+
+// Execution describes an execution of a deployment.
+type Execution struct {
+	Deployment    string            `json:"deployment"`
+	UUID          string            `json:"uuid"`
+	GitRef        string            `json:"git_ref"`
+	Source        string            `json:"source"`
+	Status        string            `json:"status"`
+	GolangVersion string            `json:"go_version"`
+	Metadata      map[string]string `json:"metadata"`
+}
+
+// Comparison is the comparison of a local execution, the reference, with a remote
+// execution. Caveats lists the differences of setup between both executions that
+// may explain a difference of results, like a different provider or configuration.
+type Comparison struct {
+	Type    macrobench.Type            `json:"type"`
+	Local   Execution                  `json:"local"`
+	Remote  Execution                  `json:"remote"`
+	Results macrobench.ComparisonArray `json:"results"`
+	Caveats []string                   `json:"caveats"`
+}
+
+// Compare compares the execution localUUID of the local deployment with the execution
+// remoteUUID of the remote deployment. Both executions must be macrobenchmarks of the
+// same type.
+func Compare(local, remote Deployment, localUUID, remoteUUID string) (*Comparison, error) {
+	localExec, err := getExecution(local, localUUID)
+	if err != nil {
+		return nil, err
+	}
+	remoteExec, err := getExecution(remote, remoteUUID)
+	if err != nil {
+		return nil, err
+	}
+	if localExec.TypeOf != remoteExec.TypeOf {
+		return nil, fmt.Errorf("%w: %s and %s", ErrTypeMismatch, localExec.TypeOf, remoteExec.TypeOf)
+	}
+	macroType := macrobench.Type(localExec.TypeOf)
+	if !isMacrobenchmark(macroType) {
+		return nil, fmt.Errorf("%w, got %s", ErrUnsupportedType, macroType)
+	}
+
+	localResults, err := macrobench.GetResultsForExecution(macroType, localUUID, local.Client)
+	if err != nil {
+		return nil, err
+	}
+	remoteResults, err := macrobench.GetResultsForExecution(macroType, remoteUUID, remote.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	localDesc, err := describeExecution(local, localExec)
+	if err != nil {
+		return nil, err
+	}
+	remoteDesc, err := describeExecution(remote, remoteExec)
+	if err != nil {
+		return nil, err
+	}
+	localConfig, err := exec.GetConfigSnapshot(local.Client, localUUID)
+	if err != nil {
+		return nil, err
+	}
+	remoteConfig, err := exec.GetConfigSnapshot(remote.Client, remoteUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Comparison{
+		Type:    macroType,
+		Local:   localDesc,
+		Remote:  remoteDesc,
+		Results: macrobench.CompareExecutionResults(localResults, remoteResults),
+		Caveats: caveats(localDesc, remoteDesc, exec.DiffConfigSnapshots(localConfig, remoteConfig)),
+	}, nil
+}
+
+// Markdown renders the Comparison as Markdown: the caveats, if any, followed
+// by the comparison tables.
+func (c Comparison) Markdown() string {
+	var b strings.Builder
+	if len(c.Caveats) > 0 {
+		b.WriteString("**Caveats**\n\n")
+		for _, caveat := range c.Caveats {
+			b.WriteString("- " + caveat + "\n")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(c.Results.Markdown())
+	return b.String()
+}
+
+func getExecution(d Deployment, execUUID string) (*exec.Exec, error) {
+	parsed, err := uuid.Parse(execUUID)
+	if err != nil {
+		return nil, err
+	}
+	e, err := exec.GetExecution(d.Client, parsed)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return nil, fmt.Errorf("%w: %s on %s", ErrExecutionNotFound, execUUID, d.Name)
+	}
+	return e, nil
+}
+
+func describeExecution(d Deployment, e *exec.Exec) (Execution, error) {
+	metadata, err := exec.GetMetadata(d.Client, e.UUID.String())
+	if err != nil {
+		return Execution{}, err
+	}
+	// The configuration snapshot is compared key by key, see caveats.
+	delete(metadata, exec.MetadataConfig)
+	return Execution{
+		Deployment:    d.Name,
+		UUID:          e.UUID.String(),
+		GitRef:        e.GitRef,
+		Source:        e.Source,
+		Status:        e.Status,
+		GolangVersion: e.GolangVersion,
+		Metadata:      metadata,
+	}, nil
+}
+
+func isMacrobenchmark(macroType macrobench.Type) bool {
+	for _, t := range macrobench.Types {
+		if t == macroType {
+			return true
+		}
+	}
+	return false
+}
+
+// caveats lists the differences of setup between the local and remote executions.
+// The provider comes first, the other differences are sorted.
+func caveats(local, remote Execution, configDiff map[string]exec.ConfigDiff) []string {
+	differ := func(what, left, right string) string {
+		return fmt.Sprintf("%s differs: %s on %s, %s on %s", what, orUnset(left), local.Deployment, orUnset(right), remote.Deployment)
+	}
+
+	result := []string{}
+	if local.Metadata[exec.MetadataProvider] != remote.Metadata[exec.MetadataProvider] {
+		result = append(result, differ("provider", local.Metadata[exec.MetadataProvider], remote.Metadata[exec.MetadataProvider]))
+	}
+
+	others := []string{}
+	if local.GolangVersion != remote.GolangVersion {
+		others = append(others, differ("go version", local.GolangVersion, remote.GolangVersion))
+	}
+	keys := map[string]bool{}
+	for key := range local.Metadata {
+		keys[key] = true
+	}
+	for key := range remote.Metadata {
+		keys[key] = true
+	}
+	for key := range keys {
+		if key == exec.MetadataProvider || local.Metadata[key] == remote.Metadata[key] {
+			continue
+		}
+		others = append(others, differ(key, local.Metadata[key], remote.Metadata[key]))
+	}
+	for key, diff := range configDiff {
+		others = append(others, differ("configuration key "+key, diff.Left, diff.Right))
+	}
+	sort.Strings(others)
+	return append(result, others...)
+}
+
+func orUnset(value string) string {
+	if value == "" {
+		return "unset"
+	}
+	return value
+}
@@ -0,0 +1,77 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package federation
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/vitessio/arewefastyet/go/exec"
+)
+
+func TestCaveats(t *testing.T) {
+	tests := []struct {
+		name       string
+		local      Execution
+		remote     Execution
+		configDiff map[string]exec.ConfigDiff
+		want       []string
+	}{
+		{
+			name:   "same setup",
+			local:  Execution{Deployment: "local", GolangVersion: "go1.17", Metadata: map[string]string{exec.MetadataProvider: "equinix"}},
+			remote: Execution{Deployment: "lab", GolangVersion: "go1.17", Metadata: map[string]string{exec.MetadataProvider: "equinix"}},
+			want:   []string{},
+		},
+		{
+			name:   "different provider and go version",
+			local:  Execution{Deployment: "local", GolangVersion: "go1.17", Metadata: map[string]string{exec.MetadataProvider: "equinix"}},
+			remote: Execution{Deployment: "lab", GolangVersion: "go1.18", Metadata: map[string]string{}},
+			want: []string{
+				"provider differs: equinix on local, unset on lab",
+				"go version differs: go1.17 on local, go1.18 on lab",
+			},
+		},
+		{
+			name:   "different metadata and configuration",
+			local:  Execution{Deployment: "local", Metadata: map[string]string{exec.MetadataClients: "16"}},
+			remote: Execution{Deployment: "lab", Metadata: map[string]string{exec.MetadataClients: "32", exec.MetadataMySQLConfig: "innodb_buffer_pool_size=1G"}},
+			configDiff: map[string]exec.ConfigDiff{
+				"ansible-inventory-files": {Left: "large.yml", Right: "small.yml"},
+			},
+			want: []string{
+				"clients differs: 16 on local, 32 on lab",
+				"configuration key ansible-inventory-files differs: large.yml on local, small.yml on lab",
+				"mysql_config differs: unset on local, innodb_buffer_pool_size=1G on lab",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qt.Assert(t, caveats(tt.local, tt.remote, tt.configDiff), qt.DeepEquals, tt.want)
+		})
+	}
+}
+
+func TestComparison_Markdown(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(Comparison{}.Markdown(), qt.Equals, "")
+	c.Assert(Comparison{Caveats: []string{"provider differs: equinix on local, aws on lab"}}.Markdown(), qt.Equals,
+		"**Caveats**\n\n- provider differs: equinix on local, aws on lab\n\n")
+}
@@ -0,0 +1,189 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+package genericbench
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vitessio/arewefastyet/go/storage"
+	awftmath "github.com/vitessio/arewefastyet/go/tools/math"
+)
+
+// Comparison is the comparison of a single metric of a generic benchmark
+// between two git refs.
+type Comparison struct {
+	Name string
+
+	// Reference and Compare are the medians of the metric for the
+	// reference and compared git refs respectively.
+	Reference float64
+	Compare   float64
+
+	// Diff is the change, in percentage, from Compare to Reference. Since the
+	// meaning of a generic metric is unknown, a positive difference is not
+	// necessarily an improvement.
+	Diff float64
+}
+
+// ComparisonArray is a slice of Comparison sorted by metric name.
+type ComparisonArray []Comparison
+
+// GetResultsForGitRef returns the results of the finished generic benchmarks of the given git ref.
+func GetResultsForGitRef(ref string, client storage.SQLClient) ([]Result, error) {
+	return GetResultsForGitRefWithPartial(ref, client, false)
+}
+
+// GetResultsForGitRefWithPartial works like GetResultsForGitRef, but also returns the partial
+// results of the generic benchmarks that are still running if includePartial is true.
+func GetResultsForGitRefWithPartial(ref string, client storage.SQLClient, includePartial bool) ([]Result, error) {
+	query := "SELECT g.name, g.value FROM execution e, genericbenchmark g WHERE g.git_ref = ? AND e.uuid = g.exec_uuid AND e.deleted_at IS NULL AND IFNULL(e.benchmark_exit_code, 0) = 0 AND " +
+		"((e.status = \"finished\" AND g.partial = 0)"
+	if includePartial {
+		query += " OR (e.status = \"started\" AND g.partial = 1)"
+	}
+	query += ")"
+	rows, err := client.Select(query, ref)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []Result
+	for rows.Next() {
+		var r Result
+		err = rows.Scan(&r.Name, &r.Value)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// GetResultsForExecution returns the final results of the generic benchmark run by the execution
+// execUUID, or its partial results if the benchmark is still running.
+func GetResultsForExecution(execUUID string, client storage.SQLClient) ([]Result, error) {
+	rows, err := client.Select("SELECT name, value FROM genericbenchmark WHERE exec_uuid = ? ORDER BY id", execUUID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []Result
+	for rows.Next() {
+		var r Result
+		err = rows.Scan(&r.Name, &r.Value)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// Compare reads the generic benchmark results of the reference and compare git
+// refs and compares their metrics by name.
+func Compare(client storage.SQLClient, reference, compare string) (ComparisonArray, error) {
+	return CompareWithPartial(client, reference, compare, false)
+}
+
+// CompareWithPartial works like Compare, but also compares the partial results
+// of the generic benchmarks that are still running if includePartial is true.
+func CompareWithPartial(client storage.SQLClient, reference, compare string, includePartial bool) (ComparisonArray, error) {
+	references, err := GetResultsForGitRefWithPartial(reference, client, includePartial)
+	if err != nil {
+		return nil, err
+	}
+	compares, err := GetResultsForGitRefWithPartial(compare, client, includePartial)
+	if err != nil {
+		return nil, err
+	}
+	return compareResults(references, compares), nil
+}
+
+// CompareExecutions works like Compare but reads the generic benchmark results
+// of the executions reference and compare instead of the ones of two git refs.
+func CompareExecutions(client storage.SQLClient, reference, compare string) (ComparisonArray, error) {
+	references, err := GetResultsForExecution(reference, client)
+	if err != nil {
+		return nil, err
+	}
+	compares, err := GetResultsForExecution(compare, client)
+	if err != nil {
+		return nil, err
+	}
+	return compareResults(references, compares), nil
+}
+
+// compareResults compares the median of each metric of references and compares.
+// Metrics that are missing on one side have a zero value and no difference.
+func compareResults(references, compares []Result) ComparisonArray {
+	referenceMedians := mediansByName(references)
+	compareMedians := mediansByName(compares)
+
+	names := map[string]bool{}
+	for name := range referenceMedians {
+		names[name] = true
+	}
+	for name := range compareMedians {
+		names[name] = true
+	}
+
+	res := make(ComparisonArray, 0, len(names))
+	for name := range names {
+		cmp := Comparison{
+			Name:      name,
+			Reference: referenceMedians[name],
+			Compare:   compareMedians[name],
+		}
+		if cmp.Compare != 0 && cmp.Reference != 0 {
+			cmp.Diff = (cmp.Reference - cmp.Compare) / cmp.Compare * 100
+		}
+		res = append(res, cmp)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+func mediansByName(results []Result) map[string]float64 {
+	values := map[string][]float64{}
+	for _, r := range results {
+		values[r.Name] = append(values[r.Name], r.Value)
+	}
+	medians := make(map[string]float64, len(values))
+	for name, v := range values {
+		medians[name] = awftmath.MedianFloat(v)
+	}
+	return medians
+}
+
+// Markdown renders the ComparisonArray as a Markdown table that can
+// be used in a GitHub comment. Each row corresponds to a metric.
+func (cmps ComparisonArray) Markdown() string {
+	var b strings.Builder
+	b.WriteString("| Metric | Reference | Compare | Diff |\n")
+	b.WriteString("|---|---:|---:|---:|\n")
+	for _, cmp := range cmps {
+		b.WriteString(fmt.Sprintf("| %s | %.2f | %.2f | %+.2f%% |\n", cmp.Name, cmp.Reference, cmp.Compare, cmp.Diff))
+	}
+	return b.String()
+}
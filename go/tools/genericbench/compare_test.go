@@ -0,0 +1,43 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+package genericbench
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestCompareResults(t *testing.T) {
+	c := qt.New(t)
+	references := []Result{{Name: "qps", Value: 1100}, {Name: "qps", Value: 1200}, {Name: "qps", Value: 1000}, {Name: "latency", Value: 9}}
+	compares := []Result{{Name: "qps", Value: 1000}, {Name: "latency", Value: 10}, {Name: "errors", Value: 2}}
+
+	got := compareResults(references, compares)
+	c.Assert(got, qt.DeepEquals, ComparisonArray{
+		{Name: "errors", Reference: 0, Compare: 2, Diff: 0},
+		{Name: "latency", Reference: 9, Compare: 10, Diff: -10},
+		{Name: "qps", Reference: 1100, Compare: 1000, Diff: 10},
+	})
+}
+
+func TestComparisonArray_Markdown(t *testing.T) {
+	c := qt.New(t)
+	cmps := ComparisonArray{{Name: "qps", Reference: 1100, Compare: 1000, Diff: 10}}
+	c.Assert(cmps.Markdown(), qt.Equals, "| Metric | Reference | Compare | Diff |\n|---|---:|---:|---:|\n| qps | 1100.00 | 1000.00 | +10.00% |\n")
+}
@@ -0,0 +1,91 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+package genericbench
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/vitessio/arewefastyet/go/storage/psdb"
+)
+
+const (
+	flagExecUUID    = "genericbench-exec-uuid"
+	flagGitRef      = "genericbench-git-ref"
+	flagCommand     = "genericbench-command"
+	flagResultRegex = "genericbench-result-regex"
+	flagRootDir     = "genericbench-root-dir"
+
+	flagPartialResultsInterval = "genericbench-partial-results-interval"
+)
+
+// Config defines the configuration of a generic benchmark. A generic benchmark
+// runs an arbitrary command and extracts its metrics from the command's output
+// using a regular expression.
+type Config struct {
+	// Command is the shell command running the benchmark.
+	Command string
+
+	// ResultRegex is the regular expression used to extract the metrics from the
+	// output of Command. Each named capturing group of the regular expression is
+	// a metric, its name being the name of the group. For instance, the regular
+	// expression "qps: (?P<qps>[0-9.]+)" extracts the metric "qps".
+	ResultRegex string
+
+	// RootDir is the directory from where Command is executed.
+	RootDir string
+
+	// PartialResultsInterval is the interval at which the results found so far in
+	// the output of Command are saved as partial results. Partial results are
+	// replaced by the final ones once Command exits. Zero disables partial results.
+	PartialResultsInterval time.Duration
+
+	// GitRef refers to the commit SHA pointing to the version
+	// of Vitess that we are currently benchmarking.
+	GitRef string
+
+	// DatabaseConfig used to save results to SQL. If this field
+	// is nil, saving results will be skipped and no error will
+	// be returned.
+	DatabaseConfig *psdb.Config
+
+	// execUUID refers to parent execution of the generic benchmark.
+	// If this field is empty, the corresponding column in SQL
+	// will be set to NULL.
+	execUUID string
+}
+
+// AddToCommand will add the different CLI flags used by Config into the given *cobra.Command.
+func (cfg *Config) AddToCommand(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&cfg.Command, flagCommand, "", "Shell command running the benchmark.")
+	cmd.Flags().StringVar(&cfg.ResultRegex, flagResultRegex, "", "Regular expression extracting the metrics from the output of the command. Each named capturing group is a metric.")
+	cmd.Flags().StringVar(&cfg.RootDir, flagRootDir, ".", "Directory from where the command is executed.")
+	cmd.Flags().DurationVar(&cfg.PartialResultsInterval, flagPartialResultsInterval, 0, "Interval at which the results found so far are saved as partial results, for long running commands. Zero disables partial results.")
+	cmd.Flags().StringVar(&cfg.GitRef, flagGitRef, "", "Git SHA referring to the benchmarked version of Vitess.")
+	cmd.Flags().StringVar(&cfg.execUUID, flagExecUUID, "", "UUID of the parent execution, an empty string will set to NULL.")
+
+	_ = viper.BindPFlag(flagCommand, cmd.Flags().Lookup(flagCommand))
+	_ = viper.BindPFlag(flagResultRegex, cmd.Flags().Lookup(flagResultRegex))
+	_ = viper.BindPFlag(flagRootDir, cmd.Flags().Lookup(flagRootDir))
+	_ = viper.BindPFlag(flagPartialResultsInterval, cmd.Flags().Lookup(flagPartialResultsInterval))
+	_ = viper.BindPFlag(flagGitRef, cmd.Flags().Lookup(flagGitRef))
+	_ = viper.BindPFlag(flagExecUUID, cmd.Flags().Lookup(flagExecUUID))
+
+	cfg.DatabaseConfig.AddToCommand(cmd)
+}
@@ -0,0 +1,227 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+package genericbench
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/vitessio/arewefastyet/go/exec/exitcode"
+	"github.com/vitessio/arewefastyet/go/exec/provision"
+	"github.com/vitessio/arewefastyet/go/storage"
+	"github.com/vitessio/arewefastyet/go/storage/psdb"
+)
+
+const (
+	errorMissingCommand = "the command of the generic benchmark is missing"
+	errorNoNamedGroup   = "the result regular expression has no named capturing group"
+	errorNoResult       = "no result was found in the output of the generic benchmark"
+)
+
+// Result is the value of a single metric of a generic benchmark.
+type Result struct {
+	Name  string
+	Value float64
+}
+
+// Run runs the command of the generic benchmark, parses its output using
+// the result regular expression and saves the metrics to SQL if a database
+// configuration is provided.
+func Run(cfg Config) error {
+	if cfg.Command == "" {
+		return errors.New(errorMissingCommand)
+	}
+	regex, err := compileResultRegex(cfg.ResultRegex)
+	if err != nil {
+		return err
+	}
+
+	var sqlClient *psdb.Client
+	if cfg.DatabaseConfig != nil && cfg.DatabaseConfig.IsValid() {
+		sqlClient, err = cfg.DatabaseConfig.NewClient()
+		if err != nil {
+			return err
+		}
+		defer sqlClient.Close()
+		// the infrastructure is provisioned once the benchmark starts
+		err = provision.RecordEnd(sqlClient, cfg.execUUID)
+		if err != nil {
+			return err
+		}
+	}
+
+	command := exec.Command("sh", "-c", cfg.Command)
+	command.Dir = cfg.RootDir
+	var out []byte
+	if cfg.PartialResultsInterval > 0 && sqlClient != nil && cfg.execUUID != "" {
+		out, err = runWithPartialResults(command, cfg.PartialResultsInterval, func(output string) {
+			partials, errParse := parseResults(regex, output)
+			if errParse != nil {
+				return
+			}
+			if errSave := replaceResults(sqlClient, cfg.execUUID, cfg.GitRef, partials, true); errSave != nil {
+				log.Printf("could not save partial results: %v\n", errSave)
+			}
+		})
+	} else {
+		out, err = command.CombinedOutput()
+	}
+	code, ok := exitcode.FromError(err)
+	if !ok {
+		return fmt.Errorf("%w: %s", err, out)
+	}
+	if code != 0 {
+		// the results of the benchmark are still saved, but they are not used by comparisons
+		log.Printf("benchmark command exited with code %d: %s\n", code, out)
+	}
+	if sqlClient != nil {
+		if err := exitcode.Record(sqlClient, cfg.execUUID, code); err != nil {
+			return err
+		}
+	}
+
+	results, err := parseResults(regex, string(out))
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		log.Printf("%s: %f\n", result.Name, result.Value)
+	}
+	if sqlClient != nil {
+		return replaceResults(sqlClient, cfg.execUUID, cfg.GitRef, results, false)
+	}
+	return nil
+}
+
+// lockedBuffer is a bytes.Buffer that is safe for concurrent use.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// runWithPartialResults runs the command and calls onPartial with the output
+// produced so far at every interval, until the command exits. The complete
+// output of the command is returned.
+func runWithPartialResults(command *exec.Cmd, interval time.Duration, onPartial func(output string)) ([]byte, error) {
+	var output lockedBuffer
+	command.Stdout = &output
+	command.Stderr = &output
+	if err := command.Start(); err != nil {
+		return nil, err
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- command.Wait()
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-done:
+			return []byte(output.String()), err
+		case <-ticker.C:
+			onPartial(output.String())
+		}
+	}
+}
+
+// replaceResults saves the results of the execution execUUID, replacing its partial results.
+// If partial is true, the results are saved as partial results. The results are replaced in
+// a single transaction, the comparisons never see the results of the execution missing.
+func replaceResults(client storage.TxClient, execUUID, gitRef string, results []Result, partial bool) error {
+	return client.Transaction(func(tx storage.SQLClient) error {
+		if execUUID != "" {
+			_, err := tx.Insert("DELETE FROM genericbenchmark WHERE exec_uuid = ? AND partial = 1", execUUID)
+			if err != nil {
+				return err
+			}
+		}
+		for _, result := range results {
+			err := result.insertToMySQL(tx, execUUID, gitRef, partial)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// compileResultRegex compiles the given regular expression and ensures
+// it has at least one named capturing group.
+func compileResultRegex(expr string) (*regexp.Regexp, error) {
+	regex, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range regex.SubexpNames() {
+		if name != "" {
+			return regex, nil
+		}
+	}
+	return nil, errors.New(errorNoNamedGroup)
+}
+
+// parseResults extracts the metrics from the output using the named capturing
+// groups of regex. Each match of regex produces one Result per named group.
+func parseResults(regex *regexp.Regexp, output string) ([]Result, error) {
+	var results []Result
+	names := regex.SubexpNames()
+	for _, match := range regex.FindAllStringSubmatch(output, -1) {
+		for i, name := range names {
+			if name == "" || match[i] == "" {
+				continue
+			}
+			value, err := strconv.ParseFloat(match[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for metric %s: %w", name, err)
+			}
+			results = append(results, Result{Name: name, Value: value})
+		}
+	}
+	if len(results) == 0 {
+		return nil, errors.New(errorNoResult)
+	}
+	return results, nil
+}
+
+func (r Result) insertToMySQL(client storage.SQLClient, execUUID, gitRef string, partial bool) error {
+	query := "INSERT INTO genericbenchmark(exec_uuid, git_ref, name, value, partial) VALUES(NULLIF(?, ''), ?, ?, ?, ?)"
+	_, err := client.Insert(query, execUUID, gitRef, r.Name, r.Value, partial)
+	return err
+}
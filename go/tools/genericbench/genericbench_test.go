@@ -0,0 +1,131 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+package genericbench
+
+import (
+	"os/exec"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/vitessio/arewefastyet/go/storage/mysql/mysqltest"
+)
+
+func TestCompileResultRegex(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		wantErr bool
+	}{
+		{name: "Named group", expr: `qps: (?P<qps>[0-9.]+)`},
+		{name: "No named group", expr: `qps: ([0-9.]+)`, wantErr: true},
+		{name: "Invalid regex", expr: `qps: (?P<qps>[0-9.]+`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			_, err := compileResultRegex(tt.expr)
+			if tt.wantErr {
+				c.Assert(err, qt.Not(qt.IsNil))
+				return
+			}
+			c.Assert(err, qt.IsNil)
+		})
+	}
+}
+
+func TestParseResults(t *testing.T) {
+	regex := regexp.MustCompile(`qps: (?P<qps>[0-9.]+)(, latency: (?P<latency>[0-9.]+)ms)?`)
+	tests := []struct {
+		name    string
+		output  string
+		want    []Result
+		wantErr bool
+	}{
+		{
+			name:   "Single line",
+			output: "starting\nqps: 1500.5, latency: 12ms\ndone",
+			want:   []Result{{Name: "qps", Value: 1500.5}, {Name: "latency", Value: 12}},
+		},
+		{
+			name:   "Multiple lines with optional metric",
+			output: "qps: 1000\nqps: 1200, latency: 10.5ms",
+			want:   []Result{{Name: "qps", Value: 1000}, {Name: "qps", Value: 1200}, {Name: "latency", Value: 10.5}},
+		},
+		{name: "No result", output: "nothing to see", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			got, err := parseResults(regex, tt.output)
+			if tt.wantErr {
+				c.Assert(err, qt.Not(qt.IsNil))
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			c.Assert(got, qt.DeepEquals, tt.want)
+		})
+	}
+}
+
+func TestRunWithPartialResults(t *testing.T) {
+	c := qt.New(t)
+	command := exec.Command("sh", "-c", "echo 'qps: 10'; sleep 0.3; echo 'qps: 20'")
+	var partials []string
+	out, err := runWithPartialResults(command, 100*time.Millisecond, func(output string) {
+		partials = append(partials, output)
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(out), qt.Equals, "qps: 10\nqps: 20\n")
+	c.Assert(partials, qt.Not(qt.HasLen), 0)
+	c.Assert(partials[0], qt.Equals, "qps: 10\n")
+}
+
+func TestReplaceResults(t *testing.T) {
+	c := qt.New(t)
+	client := mysqltest.New(t)
+	const execUUID = "exec"
+
+	results := func() map[string]bool {
+		rows, err := client.Select("SELECT name, partial FROM genericbenchmark WHERE exec_uuid = ?", execUUID)
+		c.Assert(err, qt.IsNil)
+		defer rows.Close()
+		got := map[string]bool{}
+		for rows.Next() {
+			var name string
+			var partial bool
+			c.Assert(rows.Scan(&name, &partial), qt.IsNil)
+			got[name] = partial
+		}
+		return got
+	}
+
+	c.Assert(replaceResults(client, execUUID, "abc", []Result{{Name: "qps", Value: 1}}, true), qt.IsNil)
+	c.Assert(replaceResults(client, execUUID, "abc", []Result{{Name: "qps", Value: 2}, {Name: "tps", Value: 2}}, true), qt.IsNil)
+	c.Assert(results(), qt.DeepEquals, map[string]bool{"qps": true, "tps": true})
+
+	// a failing insert rolls back the deletion of the partial results
+	invalid := []Result{{Name: "qps", Value: 3}, {Name: strings.Repeat("x", 300), Value: 3}}
+	c.Assert(replaceResults(client, execUUID, "abc", invalid, false), qt.Not(qt.IsNil))
+	c.Assert(results(), qt.DeepEquals, map[string]bool{"qps": true, "tps": true})
+
+	c.Assert(replaceResults(client, execUUID, "abc", []Result{{Name: "qps", Value: 3}}, false), qt.IsNil)
+	c.Assert(results(), qt.DeepEquals, map[string]bool{"qps": false})
+}
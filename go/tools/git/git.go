@@ -35,12 +35,21 @@ type Release struct {
 	RCnumber   int
 }
 
+// DefaultShortSHALength is the number of characters kept by ShortenSHA.
+const DefaultShortSHALength = 7
+
 var (
+	// regex pattern accepts hexadecimal strings such as commit SHAs
+	regexPatternSHA = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+
 	// regex pattern accepts v[Num].[Num].[Num] and v[Num].[Num]
 	regexPatternRelease = regexp.MustCompile(`^v(\d+)\.(\d+)(\.(\d+))?(-rc(\d+))?$`)
 
 	// regex pattern accepts refs/remotes/origin/release-[Num].[Num].[Num]
 	regexPatternReleaseBranch = regexp.MustCompile(`^refs/remotes/origin/release-(\d+)\.(\d+)$`)
+
+	// regex pattern accepts release tags, optionally followed by the suffix added by git describe
+	regexPatternReleaseLine = regexp.MustCompile(`^v(\d+)\.(\d+)(\.\d+)?(-rc\d+)?(-\d+-g[0-9a-f]+)?$`)
 )
 
 func GetPlannerVersionsForRelease(release *Release) []macrobench.PlannerVersion {
@@ -277,13 +286,56 @@ func GetCommitHash(repoDir string) (hash string, err error) {
 	return strings.TrimSpace(string(out)), err
 }
 
-// ShortenSHA will return the first 7 characters of a SHA.
+// GetParentCommitHash returns the commit hash of the first parent of the given commit.
+// For a merge commit, the first parent is the commit of the branch the changes were merged into.
+func GetParentCommitHash(repoDir, sha string) (hash string, err error) {
+	out, err := ExecCmd(repoDir, "git", "rev-parse", sha+"^1")
+	return strings.TrimSpace(string(out)), err
+}
+
+// GetChangedFiles returns the files changed by the commit sha since its merge base with the
+// commit base, the changes made on the side of base since then are not part of them.
+func GetChangedFiles(repoDir, base, sha string) ([]string, error) {
+	out, err := ExecCmd(repoDir, "git", "diff", "--name-only", base+"..."+sha)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// Describe returns the most recent tag reachable from the given commit, followed by the number
+// of commits on top of it and the abbreviated commit hash if the commit is not tagged itself
+// (e.g. v15.0.0-rc1-42-gabcdef1), as given by git describe.
+func Describe(repoDir, sha string) (string, error) {
+	out, err := ExecCmd(repoDir, "git", "describe", "--tags", sha)
+	return strings.TrimSpace(string(out)), err
+}
+
+// ReleaseBranchOfTag returns the release branch of the release line a tag, or the output of
+// Describe, belongs to (e.g. release-15.0 for v15.0.2). False is returned if the tag is not a
+// vitess release tag.
+func ReleaseBranchOfTag(tag string) (string, bool) {
+	matches := regexPatternReleaseLine.FindStringSubmatch(tag)
+	if matches == nil {
+		return "", false
+	}
+	return fmt.Sprintf("release-%s.%s", matches[1], matches[2]), true
+}
+
+// ShortenSHA will return the first DefaultShortSHALength characters of a SHA.
 // If the given SHA is too short, it will be returned untouched.
 func ShortenSHA(sha string) string {
-	if len(sha) > 7 {
-		return sha[:7]
+	return ShortenSHAN(sha, DefaultShortSHALength)
+}
+
+// ShortenSHAN will return the first n characters of a SHA.
+// If the given SHA is too short, or if the given ref is not
+// a SHA (i.e. a branch or a tag name), it will be returned untouched.
+func ShortenSHAN(sha string, n int) string {
+	if n <= 0 || len(sha) <= n || !regexPatternSHA.MatchString(sha) {
+		return sha
 	}
-	return sha
+	return sha[:n]
 }
 
 // ExecCmd is used to execute a git command in the given directory
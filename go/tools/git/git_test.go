@@ -38,6 +38,8 @@ func TestShortenSHA(t *testing.T) {
 		{name: "Short SHA", sha: "5a504473", want: "5a50447"},
 		{name: "Tiny SHA", sha: "5a50", want: "5a50"},
 		{name: "Empty", sha: "", want: ""},
+		{name: "Branch name", sha: "release-12.0", want: "release-12.0"},
+		{name: "Tag name", sha: "v12.0.0", want: "v12.0.0"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -48,6 +50,74 @@ func TestShortenSHA(t *testing.T) {
 	}
 }
 
+func TestShortenSHAN(t *testing.T) {
+	tests := []struct {
+		name string
+		sha  string
+		n    int
+		want string
+	}{
+		{name: "Regular SHA", sha: "5a504473aec6176b2523bf935ffe4217f61e9928", n: 12, want: "5a504473aec6"},
+		{name: "Tiny SHA", sha: "5a50", n: 12, want: "5a50"},
+		{name: "Zero length", sha: "5a504473aec6176b2523bf935ffe4217f61e9928", n: 0, want: "5a504473aec6176b2523bf935ffe4217f61e9928"},
+		{name: "Branch name", sha: "main", n: 2, want: "main"},
+		{name: "Empty", sha: "", n: 12, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			gotSHA := ShortenSHAN(tt.sha, tt.n)
+			c.Assert(gotSHA, qt.Equals, tt.want)
+		})
+	}
+}
+
+func TestReleaseBranchOfTag(t *testing.T) {
+	tests := []struct {
+		tag    string
+		want   string
+		wantOk bool
+	}{
+		{tag: "v15.0.2", want: "release-15.0", wantOk: true},
+		{tag: "v15.0", want: "release-15.0", wantOk: true},
+		{tag: "v16.0.0-rc1", want: "release-16.0", wantOk: true},
+		{tag: "v15.0.2-42-gabcdef1", want: "release-15.0", wantOk: true},
+		{tag: "v16.0.0-rc1-3-g5a50447", want: "release-16.0", wantOk: true},
+		{tag: "foo-1-gabcdef1"},
+		{tag: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			c := qt.New(t)
+			got, ok := ReleaseBranchOfTag(tt.tag)
+			c.Assert(ok, qt.Equals, tt.wantOk)
+			c.Assert(got, qt.Equals, tt.want)
+		})
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	c := qt.New(t)
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "release"},
+		{"tag", "v15.0.2"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "fix"},
+	} {
+		_, err := ExecCmd(dir, "git", args...)
+		c.Assert(err, qt.IsNil)
+	}
+
+	described, err := Describe(dir, "v15.0.2")
+	c.Assert(err, qt.IsNil)
+	c.Assert(described, qt.Equals, "v15.0.2")
+
+	described, err = Describe(dir, "HEAD")
+	c.Assert(err, qt.IsNil)
+	c.Assert(described, qt.Matches, `v15\.0\.2-1-g[0-9a-f]+`)
+}
+
 func TestGetAllVitessReleaseCommitHashOrdering(t *testing.T) {
 	tmpDir, vitessPath, err := createTemporaryVitessClone()
 	defer os.RemoveAll(tmpDir)
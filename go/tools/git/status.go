@@ -0,0 +1,80 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package git
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// States of a CommitStatus.
+const (
+	StatusStateSuccess = "success"
+	StatusStateFailure = "failure"
+)
+
+// githubAPIURL is the base URL of GitHub's REST API.
+var githubAPIURL = "https://api.github.com"
+
+// statusClient reports the commit statuses, its timeout prevents an unresponsive GitHub API
+// from blocking the execution queue, which reports the statuses once the comparisons are done.
+var statusClient = &http.Client{Timeout: 30 * time.Second}
+
+// CommitStatus is a status reported on a commit, displayed by GitHub along with the commit.
+type CommitStatus struct {
+	State       string `json:"state"`
+	TargetURL   string `json:"target_url,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	// Context identifies the status, a new status with the same context replaces the previous one.
+	Context string `json:"context"`
+}
+
+// SetCommitStatus reports the given status on the commit sha of repo using GitHub's statuses API,
+// authenticating with token. The format for repo is: "{USERNAME}/{REPO_NAME}", i.e "vitessio/vitess".
+func SetCommitStatus(token, repo, sha string, status CommitStatus) error {
+	body, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/repos/%s/statuses/%s", githubAPIURL, repo, sha)
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/vnd.github.v3+json")
+	request.Header.Set("Authorization", "token "+token)
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := statusClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusCreated {
+		msg, _ := ioutil.ReadAll(response.Body)
+		return fmt.Errorf("could not set the status of %s: %s: %s", sha, response.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
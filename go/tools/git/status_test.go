@@ -0,0 +1,69 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package git
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestSetCommitStatus(t *testing.T) {
+	c := qt.New(t)
+	var got CommitStatus
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, qt.Equals, http.MethodPost)
+		c.Check(r.URL.Path, qt.Equals, "/repos/vitessio/vitess/statuses/abcdef")
+		if r.Header.Get("Authorization") != "token secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message": "Bad credentials"}`))
+			return
+		}
+		c.Check(json.NewDecoder(r.Body).Decode(&got), qt.IsNil)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	c.Patch(&githubAPIURL, server.URL)
+
+	status := CommitStatus{State: StatusStateFailure, TargetURL: "https://benchmark.vitess.io/compare", Description: "Regression", Context: "arewefastyet/oltp"}
+	c.Assert(SetCommitStatus("secret", "vitessio/vitess", "abcdef", status), qt.IsNil)
+	c.Assert(got, qt.Equals, status)
+
+	err := SetCommitStatus("wrong", "vitessio/vitess", "abcdef", status)
+	c.Assert(err, qt.ErrorMatches, `could not set the status of abcdef: 401 Unauthorized: .*Bad credentials.*`)
+}
+
+func TestSetCommitStatus_Timeout(t *testing.T) {
+	c := qt.New(t)
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	}))
+	defer server.Close()
+	defer close(unblock)
+	c.Patch(&githubAPIURL, server.URL)
+	c.Patch(&statusClient, &http.Client{Timeout: 10 * time.Millisecond})
+
+	err := SetCommitStatus("secret", "vitessio/vitess", "abcdef", CommitStatus{State: StatusStateSuccess, Context: "arewefastyet/oltp"})
+	c.Assert(err, qt.ErrorMatches, `.*Client.Timeout exceeded.*`)
+}
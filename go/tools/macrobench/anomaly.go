@@ -0,0 +1,102 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package macrobench
+
+import (
+	"math"
+
+	"github.com/vitessio/arewefastyet/go/storage"
+	awftmath "github.com/vitessio/arewefastyet/go/tools/math"
+)
+
+// MinAnomalyHistory is the minimum number of previous executions needed
+// to detect anomalies, as fewer executions do not form a reliable series.
+const MinAnomalyHistory = 5
+
+// Anomaly is a metric of an execution whose value is more than Threshold
+// standard deviations away from the mean of the previous executions.
+type Anomaly struct {
+	Metric string
+	Value  float64
+	ZScore float64
+}
+
+// anomalyMetrics are the metrics checked for anomalies.
+var anomalyMetrics = []struct {
+	name  string
+	value func(Result) float64
+}{
+	{name: "total QPS", value: func(r Result) float64 { return r.QPS.Total }},
+	{name: "TPS", value: func(r Result) float64 { return r.TPS }},
+	{name: "latency", value: func(r Result) float64 { return r.Latency }},
+}
+
+// GetAnomaliesForExecution compares the results of the execution execUUID against the
+// executions of the same source and planner version from the last lastDays days, and
+// returns the metrics whose z-score exceeds threshold. Unlike a pairwise comparison,
+// this catches gradual drifts that no comparison against the previous execution shows.
+func GetAnomaliesForExecution(client storage.SQLClient, macroType Type, source string, planner PlannerVersion, execUUID string, lastDays int, threshold float64) ([]Anomaly, error) {
+	current, err := GetResultsForExecution(macroType, execUUID, client)
+	if err != nil || len(current) == 0 {
+		return nil, err
+	}
+	history, err := GetResultsForLastDays(macroType, source, planner, lastDays, client)
+	if err != nil {
+		return nil, err
+	}
+	return detectAnomalies(execUUID, current, history, threshold), nil
+}
+
+// detectAnomalies returns the anomalies of current against history, where the
+// runs of history are merged per execution and the runs of execUUID are ignored.
+func detectAnomalies(execUUID string, current, history DetailsArray, threshold float64) []Anomaly {
+	byExecution := map[string]ResultsArray{}
+	for _, details := range history {
+		if details.ExecUUID == execUUID {
+			continue
+		}
+		byExecution[details.ExecUUID] = append(byExecution[details.ExecUUID], details.Result)
+	}
+	if len(byExecution) < MinAnomalyHistory {
+		return nil
+	}
+	var series ResultsArray
+	for _, results := range byExecution {
+		series = append(series, results.mergeMedian())
+	}
+
+	var results ResultsArray
+	for _, details := range current {
+		results = append(results, details.Result)
+	}
+	result := results.mergeMedian()
+
+	var anomalies []Anomaly
+	for _, metric := range anomalyMetrics {
+		values := make([]float64, 0, len(series))
+		for _, r := range series {
+			values = append(values, metric.value(r))
+		}
+		z, ok := awftmath.ZScore(metric.value(result), values)
+		if ok && math.Abs(z) > threshold {
+			anomalies = append(anomalies, Anomaly{Metric: metric.name, Value: metric.value(result), ZScore: z})
+		}
+	}
+	return anomalies
+}
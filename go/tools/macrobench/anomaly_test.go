@@ -0,0 +1,61 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package macrobench
+
+import (
+	"fmt"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestDetectAnomalies(t *testing.T) {
+	newDetails := func(execUUID string, qps, tps, latency float64) Details {
+		return Details{BenchmarkID: BenchmarkID{ExecUUID: execUUID}, Result: *newResult(*newQPS(qps, qps, 0, 0), tps, latency, 0, 0, 0, 0)}
+	}
+	var history DetailsArray
+	for i, qps := range []float64{100, 102, 98, 101, 99} {
+		history = append(history, newDetails(fmt.Sprintf("exec-%d", i), qps, qps/10, 10+float64(i%2)))
+	}
+
+	tests := []struct {
+		name        string
+		current     DetailsArray
+		history     DetailsArray
+		threshold   float64
+		wantMetrics []string
+	}{
+		{name: "Not enough history", current: DetailsArray{newDetails("current", 200, 20, 10)}, history: history[:MinAnomalyHistory-1], threshold: 3},
+		{name: "Within the series", current: DetailsArray{newDetails("current", 101, 10.1, 10)}, history: history, threshold: 3},
+		{name: "Throughput drop", current: DetailsArray{newDetails("current", 80, 8, 10)}, history: history, threshold: 3, wantMetrics: []string{"total QPS", "TPS"}},
+		{name: "Latency spike", current: DetailsArray{newDetails("current", 100, 10, 20)}, history: history, threshold: 3, wantMetrics: []string{"latency"}},
+		{name: "Higher threshold", current: DetailsArray{newDetails("current", 105, 10.5, 10)}, history: history, threshold: 5},
+		{name: "Current execution in the history", current: DetailsArray{newDetails("exec-0", 80, 8, 10)}, history: history, threshold: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			var gotMetrics []string
+			for _, anomaly := range detectAnomalies(tt.current[0].ExecUUID, tt.current, tt.history, tt.threshold) {
+				gotMetrics = append(gotMetrics, anomaly.Metric)
+			}
+			c.Assert(gotMetrics, qt.DeepEquals, tt.wantMetrics)
+		})
+	}
+}
@@ -21,31 +21,105 @@ package macrobench
 import (
 	"fmt"
 	"github.com/vitessio/arewefastyet/go/storage"
+	awftmath "github.com/vitessio/arewefastyet/go/tools/math"
 )
 
 // CompareMacroBenchmarks takes in 3 arguments, the database, and 2 SHAs. It reads from the database, the macrobenchmark
 // results for the 2 SHAs and compares them. The result is a map with the key being the macrobenchmark name.
 func CompareMacroBenchmarks(client storage.SQLClient, reference, compare string, planner PlannerVersion) (map[Type]interface{}, error) {
+	return CompareMacroBenchmarksForComponent(client, reference, compare, planner, "")
+}
+
+// CompareMacroBenchmarksForComponent works like CompareMacroBenchmarks but only uses the results
+// of the executions that focused on the given vitess component. All results are used if component is empty.
+func CompareMacroBenchmarksForComponent(client storage.SQLClient, reference, compare string, planner PlannerVersion, component string) (map[Type]interface{}, error) {
+	return CompareMacroBenchmarksForPlanners(client, reference, compare, planner, planner, component)
+}
+
+// CompareMacroBenchmarksForPlanners works like CompareMacroBenchmarksForComponent but reads the results
+// of reference and compare using their own planner version. This allows to compare the same SHA against
+// itself using two different planner versions.
+func CompareMacroBenchmarksForPlanners(client storage.SQLClient, reference, compare string, referencePlanner, comparePlanner PlannerVersion, component string) (map[Type]interface{}, error) {
 	// Get macro benchmarks from all the different types
-	SHAs := []string{reference, compare}
+	sides := []struct {
+		sha     string
+		planner PlannerVersion
+	}{
+		{sha: reference, planner: referencePlanner},
+		{sha: compare, planner: comparePlanner},
+	}
 	var err error
-	macros := map[string]map[Type]DetailsArray{}
-	for _, sha := range SHAs {
-		macros[sha], err = GetDetailsArraysFromAllTypes(sha, planner, client)
+	runs := make([]map[Type]DetailsArray, len(sides))
+	for i, side := range sides {
+		runs[i], err = GetDetailsArraysFromAllTypes(side.sha, side.planner, client)
 		if err != nil {
 			return nil, err
 		}
-		for mtype := range macros[sha] {
-			macros[sha][mtype] = macros[sha][mtype].ReduceSimpleMedian()
+		for mtype := range runs[i] {
+			runs[i][mtype] = runs[i][mtype].FilterByComponent(component)
 		}
 	}
 	macrosMatrixes := map[Type]interface{}{}
 	for _, mtype := range Types {
-		macrosMatrixes[mtype] = CompareDetailsArrays(macros[reference][mtype], macros[compare][mtype])
+		// The p-values and the confidence are computed on every run of each side,
+		// the differences on their median.
+		pValue := computePValues(runs[0][mtype], runs[1][mtype])
+		confidence := computeConfidence(runs[0][mtype], runs[1][mtype])
+		comparisons := CompareDetailsArrays(runs[0][mtype].ReduceSimpleMedian(), runs[1][mtype].ReduceSimpleMedian())
+		for i := range comparisons {
+			comparisons[i].PValue = pValue
+			comparisons[i].Confidence = confidence
+		}
+		macrosMatrixes[mtype] = comparisons
 	}
 	return macrosMatrixes, nil
 }
 
+// CompareExecutions works like CompareMacroBenchmarks but reads the macrobenchmark
+// results of type macroType of the executions reference and compare instead of the
+// ones of two git refs.
+func CompareExecutions(client storage.SQLClient, macroType Type, reference, compare string) (ComparisonArray, error) {
+	references, err := GetResultsForExecution(macroType, reference, client)
+	if err != nil {
+		return nil, err
+	}
+	compares, err := GetResultsForExecution(macroType, compare, client)
+	if err != nil {
+		return nil, err
+	}
+	return CompareExecutionResults(references, compares), nil
+}
+
+// CompareExecutionResults compares the results of two executions, which may have been
+// read from different databases, the same way CompareExecutions does.
+func CompareExecutionResults(references, compares DetailsArray) ComparisonArray {
+	comparisons := CompareDetailsArrays(references.ReduceSimpleMedian(), compares.ReduceSimpleMedian())
+	for i := range comparisons {
+		comparisons[i].PValue = computePValues(references, compares)
+		comparisons[i].Confidence = computeConfidence(references, compares)
+	}
+	return comparisons
+}
+
+// computePValues computes the p-value of the main metrics of two sets of runs.
+func computePValues(references, compares DetailsArray) (pValue Result) {
+	metric := func(get func(r Result) float64) float64 {
+		var x, y []float64
+		for _, details := range references {
+			x = append(x, get(details.Result))
+		}
+		for _, details := range compares {
+			y = append(y, get(details.Result))
+		}
+		p, _ := awftmath.MannWhitneyUTest(x, y)
+		return p
+	}
+	pValue.TPS = metric(func(r Result) float64 { return r.TPS })
+	pValue.Latency = metric(func(r Result) float64 { return r.Latency })
+	pValue.QPS.Total = metric(func(r Result) float64 { return r.QPS.Total })
+	return pValue
+}
+
 // ComparePlanners takes in 2 arguments, the database, and a SHA. It reads from the database, the macrobenchmark
 // results for the 2 planners corresponding to the sha and compares them. The result is a map with the key being the macrobenchmark name.
 func ComparePlanners(client storage.SQLClient, sha string) (map[Type]interface{}, error) {
@@ -69,24 +143,75 @@ func ComparePlanners(client storage.SQLClient, sha string) (map[Type]interface{}
 }
 
 // Regression returns a string containing the reason of the regression, if no regression is found, the string
-// will be returned empty.
+// will be returned empty. TPS, QPS and latency regressions must also be statistically significant
+// when enough runs were available to compute their p-value.
 func (c Comparison) Regression() (reason string) {
-	if c.DiffMetrics.TotalComponentsCPUTime <= -5.00 {
-		reason += fmt.Sprintf("- Total CPU time increased by %.2f%% \n", c.DiffMetrics.TotalComponentsCPUTime*-1)
-	}
-	for key, value := range c.DiffMetrics.ComponentsCPUTime {
-		if value <= -5.00 {
-			reason += fmt.Sprintf("- %s CPU time increased by %.2f%% \n", key, value*-1)
-		}
-	}
-	if c.Diff.TPS <= -10 {
-		reason += fmt.Sprintf("- TPS decreased by %.2f%% \n", c.Diff.TPS*-1)
+	return c.RegressionOfMetrics(nil)
+}
+
+// Improvement returns a string containing the reason of the improvement, if no improvement is found, the string
+// will be returned empty. It is the opposite of Regression and uses the same thresholds.
+func (c Comparison) Improvement() (reason string) {
+	return c.ImprovementOfMetrics(nil)
+}
+
+// RegressionOfMetrics works like Regression but only reports the changes of the given
+// metrics, see ComparisonMetrics. All the metrics are reported if metrics is empty.
+func (c Comparison) RegressionOfMetrics(metrics []string) (reason string) {
+	return c.changes(metrics, false)
+}
+
+// ImprovementOfMetrics works like Improvement but only reports the changes of the given
+// metrics, see ComparisonMetrics. All the metrics are reported if metrics is empty.
+func (c Comparison) ImprovementOfMetrics(metrics []string) (reason string) {
+	return c.changes(metrics, true)
+}
+
+// ComparisonMetrics lists the metrics whose changes are reported by Regression and Improvement.
+// The "cpu_time" metric covers the total CPU time and the CPU time of each component.
+var ComparisonMetrics = []string{"cpu_time", "tps", "qps.total", "latency"}
+
+// changes returns the reason of the regressions, or of the improvements if improvement
+// is set, of the given metrics. The reasons follow the order of metrics.
+func (c Comparison) changes(metrics []string, improvement bool) (reason string) {
+	if len(metrics) == 0 {
+		metrics = ComparisonMetrics
 	}
-	if c.Diff.QPS.Total <= -10 {
-		reason += fmt.Sprintf("- QPS decreased by %.2f%% \n", c.Diff.QPS.Total*-1)
+	direction := 1.0
+	if !improvement {
+		direction = -1.0
 	}
-	if c.Diff.Latency <= -10 {
-		reason += fmt.Sprintf("- Latency increased by %.2f%% \n", c.Diff.Latency*-1)
+	for _, metric := range metrics {
+		switch metric {
+		case "cpu_time":
+			if c.DiffMetrics.TotalComponentsCPUTime*direction >= 5.00 {
+				reason += fmt.Sprintf("- Total CPU time %s by %.2f%% \n", changeVerb(improvement, "decreased", "increased"), c.DiffMetrics.TotalComponentsCPUTime*direction)
+			}
+			for key, value := range c.DiffMetrics.ComponentsCPUTime {
+				if value*direction >= 5.00 {
+					reason += fmt.Sprintf("- %s CPU time %s by %.2f%% \n", key, changeVerb(improvement, "decreased", "increased"), value*direction)
+				}
+			}
+		case "tps":
+			if c.Diff.TPS*direction >= 10 && awftmath.IsSignificant(c.PValue.TPS) {
+				reason += fmt.Sprintf("- TPS %s by %.2f%% \n", changeVerb(improvement, "increased", "decreased"), c.Diff.TPS*direction)
+			}
+		case "qps.total":
+			if c.Diff.QPS.Total*direction >= 10 && awftmath.IsSignificant(c.PValue.QPS.Total) {
+				reason += fmt.Sprintf("- QPS %s by %.2f%% \n", changeVerb(improvement, "increased", "decreased"), c.Diff.QPS.Total*direction)
+			}
+		case "latency":
+			if c.Diff.Latency*direction >= 10 && awftmath.IsSignificant(c.PValue.Latency) {
+				reason += fmt.Sprintf("- Latency %s by %.2f%% \n", changeVerb(improvement, "decreased", "increased"), c.Diff.Latency*direction)
+			}
+		}
 	}
 	return
 }
+
+func changeVerb(improvement bool, improved, regressed string) string {
+	if improvement {
+		return improved
+	}
+	return regressed
+}
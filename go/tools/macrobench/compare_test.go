@@ -19,8 +19,14 @@
 package macrobench
 
 import (
+	"fmt"
 	qt "github.com/frankban/quicktest"
+	"github.com/google/uuid"
 	"github.com/vitessio/arewefastyet/go/exec/metrics"
+	"github.com/vitessio/arewefastyet/go/storage"
+	"github.com/vitessio/arewefastyet/go/storage/mysql/mysqltest"
+	awftmath "github.com/vitessio/arewefastyet/go/tools/math"
+	"strings"
 	"testing"
 )
 
@@ -45,6 +51,7 @@ func TestComparison_Regression(t *testing.T) {
 		{name: "QPS decrease", cmp: Comparison{Diff: Result{QPS: QPS{Total: -10}}}, wantReason: "- QPS decreased by 10.00% \n"},
 		{name: "TPS and QPS decrease", cmp: Comparison{Diff: Result{TPS: -32.5, QPS: QPS{Total: -27.7}}}, wantReason: "- TPS decreased by 32.50% \n- QPS decreased by 27.70% \n"},
 		{name: "TPS, QPS decrease and Latency increase", cmp: Comparison{Diff: Result{Latency: -10, TPS: -32.5, QPS: QPS{Total: -27.7}}}, wantReason: "- TPS decreased by 32.50% \n- QPS decreased by 27.70% \n- Latency increased by 10.00% \n"},
+		{name: "Significant TPS decrease", cmp: Comparison{Diff: Result{TPS: -50}, PValue: Result{TPS: 0.01}}, wantReason: "- TPS decreased by 50.00% \n"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -54,3 +61,129 @@ func TestComparison_Regression(t *testing.T) {
 		})
 	}
 }
+
+func TestComparison_Improvement(t *testing.T) {
+	tests := []struct {
+		name       string
+		cmp        Comparison
+		wantReason string
+	}{
+		{name: "No improvement", cmp: Comparison{}, wantReason: ""},
+		{name: "Regression is not an improvement", cmp: Comparison{Diff: Result{TPS: -50}}, wantReason: ""},
+		{name: "Total CPU time decrease", cmp: Comparison{DiffMetrics: metrics.ExecutionMetrics{TotalComponentsCPUTime: 5}}, wantReason: "- Total CPU time decreased by 5.00% \n"},
+		{name: "VTGate time decrease", cmp: Comparison{DiffMetrics: metrics.ExecutionMetrics{ComponentsCPUTime: map[string]float64{"vtgate": 11.98}}}, wantReason: "- vtgate CPU time decreased by 11.98% \n"},
+		{name: "TPS, QPS increase and Latency decrease", cmp: Comparison{Diff: Result{Latency: 10, TPS: 32.5, QPS: QPS{Total: 27.7}}}, wantReason: "- TPS increased by 32.50% \n- QPS increased by 27.70% \n- Latency decreased by 10.00% \n"},
+		{name: "Not significant TPS increase", cmp: Comparison{Diff: Result{TPS: 50}, PValue: Result{TPS: 0.3}}, wantReason: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			c.Assert(tt.cmp.Improvement(), qt.Equals, tt.wantReason)
+		})
+	}
+}
+
+func TestComparison_RegressionNotSignificant(t *testing.T) {
+	c := qt.New(t)
+	cmp := Comparison{
+		Diff:   Result{Latency: -15, TPS: -50, QPS: QPS{Total: -20}},
+		PValue: Result{Latency: 0.2, TPS: 0.3, QPS: QPS{Total: 0.05}},
+	}
+	c.Assert(cmp.Regression(), qt.Equals, "")
+}
+
+func TestComparison_RegressionOfMetrics(t *testing.T) {
+	cmp := Comparison{
+		Diff:        Result{Latency: -15, TPS: -50, QPS: QPS{Total: -20}},
+		DiffMetrics: metrics.ExecutionMetrics{TotalComponentsCPUTime: -10},
+	}
+	tests := []struct {
+		name       string
+		metrics    []string
+		wantReason string
+	}{
+		{name: "All metrics", wantReason: "- Total CPU time increased by 10.00% \n- TPS decreased by 50.00% \n- QPS decreased by 20.00% \n- Latency increased by 15.00% \n"},
+		{name: "Single metric", metrics: []string{"qps.total"}, wantReason: "- QPS decreased by 20.00% \n"},
+		{name: "Order of the metrics", metrics: []string{"latency", "tps"}, wantReason: "- Latency increased by 15.00% \n- TPS decreased by 50.00% \n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			c.Assert(cmp.RegressionOfMetrics(tt.metrics), qt.Equals, tt.wantReason)
+		})
+	}
+}
+
+// insertRuns inserts one finished execution of sha focusing on component for each of the
+// given TPS, along with its OLTP results. The QPS of each run is twenty times its TPS.
+func insertRuns(c *qt.C, client storage.SQLClient, sha string, planner PlannerVersion, component string, tps ...float64) {
+	for _, value := range tps {
+		execUUID := uuid.NewString()
+		_, err := client.Insert("INSERT INTO execution(uuid, status, source, git_ref, type, component) VALUES(?, 'finished', 'cron', ?, 'oltp', NULLIF(?, ''))", execUUID, sha, component)
+		c.Assert(err, qt.IsNil)
+		cfg := Config{GitRef: sha, Source: "cron", VtgatePlannerVersion: string(planner), execUUID: execUUID}
+		macrobenchID, err := cfg.insertBenchmarkToSQL(client)
+		c.Assert(err, qt.IsNil)
+		result := Result{TPS: value, Latency: 10, Time: 60, Threads: 8, QPS: QPS{Total: value * 20, Reads: value * 14, Writes: value * 4, Other: value * 2}}
+		c.Assert(result.insertToMySQL(OLTP, macrobenchID, client), qt.IsNil)
+	}
+}
+
+func TestCompareMacroBenchmarksForPlanners(t *testing.T) {
+	client := mysqltest.New(t)
+
+	tests := []struct {
+		name                string
+		referenceTPS        []float64
+		compareTPS          []float64
+		referenceAgeDays    int
+		wantSignificant     bool
+		wantConfidence      Confidence
+		wantRegressionInTPS bool
+	}{
+		{name: "Significant regression", referenceTPS: []float64{50, 51, 49, 50, 50}, compareTPS: []float64{100, 101, 99, 100, 100}, wantSignificant: true, wantConfidence: ConfidenceHigh, wantRegressionInTPS: true},
+		{name: "Old reference", referenceTPS: []float64{50, 51, 49, 50, 50}, compareTPS: []float64{100, 101, 99, 100, 100}, referenceAgeDays: 20, wantSignificant: true, wantConfidence: ConfidenceMedium, wantRegressionInTPS: true},
+		{name: "Noisy runs", referenceTPS: []float64{60, 85, 88, 115, 150}, compareTPS: []float64{40, 95, 100, 105, 160}, wantConfidence: ConfidenceLow},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			reference, compare := fmt.Sprintf("reference%d", i), fmt.Sprintf("compare%d", i)
+			insertRuns(c, client, reference, Gen4FallbackPlanner, "", tt.referenceTPS...)
+			insertRuns(c, client, compare, V3Planner, "", tt.compareTPS...)
+			if tt.referenceAgeDays > 0 {
+				_, err := client.Insert("UPDATE macrobenchmark SET DateTime = NOW() - INTERVAL ? DAY WHERE commit = ?", tt.referenceAgeDays, reference)
+				c.Assert(err, qt.IsNil)
+			}
+
+			matrix, err := CompareMacroBenchmarksForPlanners(client, reference, compare, Gen4FallbackPlanner, V3Planner, "")
+			c.Assert(err, qt.IsNil)
+			comparisons := matrix[OLTP].(ComparisonArray)
+			c.Assert(comparisons, qt.HasLen, 1)
+			cmp := comparisons[0]
+
+			c.Assert(cmp.PValue.TPS > 0, qt.IsTrue, qt.Commentf("p-value: %f", cmp.PValue.TPS))
+			c.Assert(awftmath.IsSignificant(cmp.PValue.TPS), qt.Equals, tt.wantSignificant)
+			c.Assert(cmp.Confidence, qt.Equals, tt.wantConfidence)
+			c.Assert(strings.Contains(cmp.Regression(), "TPS decreased"), qt.Equals, tt.wantRegressionInTPS)
+		})
+	}
+}
+
+func TestCompareMacroBenchmarksForComponent(t *testing.T) {
+	c := qt.New(t)
+	client := mysqltest.New(t)
+
+	insertRuns(c, client, "reference", V3Planner, "vtgate", 100, 100, 100)
+	insertRuns(c, client, "reference", V3Planner, "vttablet", 10, 10, 10)
+	insertRuns(c, client, "compare", V3Planner, "vtgate", 80, 80, 80)
+	insertRuns(c, client, "compare", V3Planner, "vttablet", 20, 20, 20)
+
+	for component, wantTPS := range map[string][2]float64{"vtgate": {100, 80}, "vttablet": {10, 20}} {
+		matrix, err := CompareMacroBenchmarksForComponent(client, "reference", "compare", V3Planner, component)
+		c.Assert(err, qt.IsNil)
+		comparisons := matrix[OLTP].(ComparisonArray)
+		c.Assert(comparisons, qt.HasLen, 1, qt.Commentf("component: %s", component))
+		c.Assert([2]float64{comparisons[0].Reference.Result.TPS, comparisons[0].Compare.Result.TPS}, qt.Equals, wantTPS)
+	}
+}
@@ -0,0 +1,143 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package macrobench
+
+import (
+	"math"
+	"time"
+
+	awftmath "github.com/vitessio/arewefastyet/go/tools/math"
+)
+
+// Confidence tells how much the differences of a Comparison can be trusted.
+type Confidence string
+
+const (
+	ConfidenceHigh   Confidence = "high"
+	ConfidenceMedium Confidence = "medium"
+	ConfidenceLow    Confidence = "low"
+)
+
+// Thresholds used to rate each factor of the Confidence of a Comparison.
+const (
+	// highConfidenceRuns and mediumConfidenceRuns are the minimum number of
+	// runs on both sides of a comparison.
+	highConfidenceRuns   = 5
+	mediumConfidenceRuns = 3
+
+	// highConfidenceNoise and mediumConfidenceNoise are the maximum coefficient of
+	// variation of the total QPS of the runs on each side of a comparison.
+	highConfidenceNoise   = 0.02
+	mediumConfidenceNoise = 0.05
+
+	// highConfidenceAge and mediumConfidenceAge are the maximum age of the baseline
+	// relative to the results it is compared with.
+	highConfidenceAge   = 7 * 24 * time.Hour
+	mediumConfidenceAge = 30 * 24 * time.Hour
+)
+
+// confidenceLevels orders the Confidence levels from the lowest to the highest.
+var confidenceLevels = []Confidence{ConfidenceLow, ConfidenceMedium, ConfidenceHigh}
+
+// computeConfidence rates the comparison of the runs of references against the runs of the
+// baselines. The sample size, the noise of the runs and the age of the baseline are rated
+// independently, the Confidence being the lowest of these ratings.
+func computeConfidence(references, baselines DetailsArray) Confidence {
+	samples := len(references)
+	if len(baselines) < samples {
+		samples = len(baselines)
+	}
+	ratings := []int{
+		rate(float64(samples), highConfidenceRuns, mediumConfidenceRuns, true),
+		rate(runsNoise(references, baselines), highConfidenceNoise, mediumConfidenceNoise, false),
+	}
+	if age, ok := baselineAge(references, baselines); ok {
+		ratings = append(ratings, rate(age.Hours(), highConfidenceAge.Hours(), mediumConfidenceAge.Hours(), false))
+	}
+	level := len(confidenceLevels) - 1
+	for _, rating := range ratings {
+		if rating < level {
+			level = rating
+		}
+	}
+	return confidenceLevels[level]
+}
+
+// rate returns the index in confidenceLevels of the given value. If higherIsBetter is
+// set, the value must be at least high or medium to be rated as such, otherwise it must
+// be at most high or medium.
+func rate(value, high, medium float64, higherIsBetter bool) int {
+	if !higherIsBetter {
+		value, high, medium = -value, -high, -medium
+	}
+	switch {
+	case value >= high:
+		return 2
+	case value >= medium:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// runsNoise returns the highest coefficient of variation of the total QPS of the runs of
+// references and baselines. A side with too few runs to measure its noise is considered
+// infinitely noisy.
+func runsNoise(references, baselines DetailsArray) float64 {
+	var noise float64
+	for _, runs := range []DetailsArray{references, baselines} {
+		values := make([]float64, 0, len(runs))
+		for _, details := range runs {
+			values = append(values, details.Result.QPS.Total)
+		}
+		cv, ok := awftmath.CoefficientOfVariation(values)
+		if !ok {
+			return math.Inf(1)
+		}
+		if cv > noise {
+			noise = cv
+		}
+	}
+	return noise
+}
+
+// baselineAge returns the duration between the latest runs of references and baselines.
+// ok is false if the creation date of one of the sides is unknown.
+func baselineAge(references, baselines DetailsArray) (age time.Duration, ok bool) {
+	latestReference, latestBaseline := references.latestCreatedAt(), baselines.latestCreatedAt()
+	if latestReference == nil || latestBaseline == nil {
+		return 0, false
+	}
+	age = latestReference.Sub(*latestBaseline)
+	if age < 0 {
+		age = -age
+	}
+	return age, true
+}
+
+// latestCreatedAt returns the creation date of the most recent Details, or nil if unknown.
+func (mabd DetailsArray) latestCreatedAt() *time.Time {
+	var latest *time.Time
+	for _, details := range mabd {
+		if details.CreatedAt != nil && (latest == nil || details.CreatedAt.After(*latest)) {
+			latest = details.CreatedAt
+		}
+	}
+	return latest
+}
@@ -0,0 +1,90 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package macrobench
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func newConfidenceRuns(createdAt time.Time, qps ...float64) DetailsArray {
+	runs := make(DetailsArray, 0, len(qps))
+	for _, total := range qps {
+		runs = append(runs, Details{BenchmarkID: BenchmarkID{CreatedAt: &createdAt}, Result: Result{QPS: QPS{Total: total}}})
+	}
+	return runs
+}
+
+func TestComputeConfidence(t *testing.T) {
+	now := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+	stable := []float64{1000, 1001, 999, 1000, 1000}
+	tests := []struct {
+		name                  string
+		references, baselines DetailsArray
+		want                  Confidence
+	}{
+		{
+			name:       "many stable and recent runs",
+			references: newConfidenceRuns(now, stable...),
+			baselines:  newConfidenceRuns(now.Add(-24*time.Hour), stable...),
+			want:       ConfidenceHigh,
+		},
+		{
+			name:       "few runs",
+			references: newConfidenceRuns(now, 1000, 1001, 999),
+			baselines:  newConfidenceRuns(now.Add(-24*time.Hour), stable...),
+			want:       ConfidenceMedium,
+		},
+		{
+			name:       "single run",
+			references: newConfidenceRuns(now, 1000),
+			baselines:  newConfidenceRuns(now.Add(-24*time.Hour), stable...),
+			want:       ConfidenceLow,
+		},
+		{
+			name:       "noisy runs",
+			references: newConfidenceRuns(now, stable...),
+			baselines:  newConfidenceRuns(now.Add(-24*time.Hour), 900, 1100, 1000, 950, 1050),
+			want:       ConfidenceLow,
+		},
+		{
+			name:       "old baseline",
+			references: newConfidenceRuns(now, stable...),
+			baselines:  newConfidenceRuns(now.Add(-15*24*time.Hour), stable...),
+			want:       ConfidenceMedium,
+		},
+		{
+			name:       "unknown dates",
+			references: DetailsArray{{Result: Result{QPS: QPS{Total: 1000}}}, {Result: Result{QPS: QPS{Total: 1000}}}, {Result: Result{QPS: QPS{Total: 1000}}}},
+			baselines:  DetailsArray{{Result: Result{QPS: QPS{Total: 1000}}}, {Result: Result{QPS: QPS{Total: 1000}}}, {Result: Result{QPS: QPS{Total: 1000}}}},
+			want:       ConfidenceMedium,
+		},
+		{
+			name: "no runs",
+			want: ConfidenceLow,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qt.Assert(t, computeConfidence(tt.references, tt.baselines), qt.Equals, tt.want)
+		})
+	}
+}
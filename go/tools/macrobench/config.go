@@ -84,6 +84,39 @@ type Config struct {
 
 	// vtgateWebPorts lists web endpoint of each VTGate
 	vtgateWebPorts []string
+
+	// FieldMapping maps the canonical metrics to the fields emitted by the
+	// load generator, allowing to use load generators other than sysbench.
+	FieldMapping FieldMapping
+
+	// SmokeTime is the duration, in seconds, of a short benchmark run before the
+	// warm up and run steps, see checkSmokeResults. Zero disables it.
+	SmokeTime int
+
+	// SmokeMinQPS and SmokeMinTPS are the minimum total QPS and TPS the smoke
+	// benchmark must reach for the run to go on. Zero disables the check.
+	SmokeMinQPS float64
+	SmokeMinTPS float64
+
+	// CaptureQueryPlans stores the query plans of the VTGates at the end of the
+	// run, allowing to compare the plans of two executions, see DiffQueryPlans.
+	CaptureQueryPlans bool
+
+	// VtgateProfileFile is the file in which a CPU profile of the first VTGate is written.
+	// The profile is started right before the run step and lasts as long as it. No profile
+	// is collected if it is empty.
+	VtgateProfileFile string
+
+	// VerificationQuery is run against the benchmarked cluster once the run step is over,
+	// its result must match VerificationExpected, see verification.Check. A mismatch marks
+	// the execution as invalid. No verification is done if the query is empty.
+	VerificationQuery    string
+	VerificationExpected string
+
+	// WorkloadMix maps the path of sysbench workloads to their weight, see ParseWorkloadMix.
+	// If set, the workloads are run concurrently during the run step instead of WorkloadPath,
+	// which is still used by the other steps, for instance to prepare the tables.
+	WorkloadMix map[string]string
 }
 
 const (
@@ -97,6 +130,15 @@ const (
 	flagExecUUID             = "macrobench-exec-uuid"
 	flagVtgatePlannerVersion = "macrobench-vtgate-planner-version"
 	flagVtgateWebPorts       = "macrobench-vtgate-web-ports"
+	flagFieldMapping         = "macrobench-field-mapping"
+	flagSmokeTime            = "macrobench-smoke-time"
+	flagSmokeMinQPS          = "macrobench-smoke-min-qps"
+	flagSmokeMinTPS          = "macrobench-smoke-min-tps"
+	flagCaptureQueryPlans    = "macrobench-capture-query-plans"
+	flagVerificationQuery    = "macrobench-verification-query"
+	flagVerificationExpected = "macrobench-verification-expected"
+	flagWorkloadMix          = "macrobench-workload-mix"
+	flagVtgateProfileFile    = "macrobench-vtgate-profile-file"
 )
 
 // AddToCommand will add the different CLI flags used by MacroBenchConfig into
@@ -115,6 +157,15 @@ func (mabcfg *Config) AddToCommand(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&mabcfg.WorkingDirectory, flagWorkingDirectory, "", "Directory on which to execute sysbench.")
 	cmd.Flags().StringVar(&mabcfg.execUUID, flagExecUUID, "", "UUID of the parent execution, an empty string will set to NULL.")
 	cmd.Flags().StringSliceVar(&mabcfg.vtgateWebPorts, flagVtgateWebPorts, nil, "List of the web port for each VTGate.")
+	cmd.Flags().StringToStringVar((*map[string]string)(&mabcfg.FieldMapping), flagFieldMapping, map[string]string{}, "Mapping of the canonical metrics to the fields emitted by the load generator, with an optional scaling factor (e.g. tps=transactions,latency=latency_us*0.001).")
+	cmd.Flags().IntVar(&mabcfg.SmokeTime, flagSmokeTime, 0, "Duration, in seconds, of a smoke benchmark run before the warm up and run steps, failing fast if its results are implausible. Zero disables the smoke benchmark.")
+	cmd.Flags().Float64Var(&mabcfg.SmokeMinQPS, flagSmokeMinQPS, 1, "Minimum total QPS the smoke benchmark must reach. Zero disables the check.")
+	cmd.Flags().Float64Var(&mabcfg.SmokeMinTPS, flagSmokeMinTPS, 0, "Minimum TPS the smoke benchmark must reach. Zero disables the check.")
+	cmd.Flags().StringVar(&mabcfg.VerificationQuery, flagVerificationQuery, "", "SQL query run against the benchmarked cluster after the run step to verify that the benchmark exercised it, such as a row count. It must return a single value.")
+	cmd.Flags().StringVar(&mabcfg.VerificationExpected, flagVerificationExpected, "", "Expected result of the verification query, optionally prefixed by >=, <=, !=, >, < or = to compare it as a number (e.g. >0). A mismatch marks the execution as invalid.")
+	cmd.Flags().StringToStringVar(&mabcfg.WorkloadMix, flagWorkloadMix, map[string]string{}, "Sysbench workloads run concurrently during the run step with their weight (e.g. oltp_read_only=70,oltp_write_only=30). The threads are split between the workloads according to their weight, the results of each workload are stored along with the aggregated results. Executions running different mixes are not compared.")
+	cmd.Flags().BoolVar(&mabcfg.CaptureQueryPlans, flagCaptureQueryPlans, true, "Store the query plans of the VTGates at the end of the run, so that the plans of two executions can be compared.")
+	cmd.Flags().StringVar(&mabcfg.VtgateProfileFile, flagVtgateProfileFile, "", "File in which a CPU profile of the first VTGate, collected during the whole run step, is written. No profile is collected if empty.")
 
 	_ = viper.BindPFlag(flagSysbenchPath, cmd.Flags().Lookup(flagSysbenchPath))
 	_ = viper.BindPFlag(flagSysbenchExecutable, cmd.Flags().Lookup(flagSysbenchExecutable))
@@ -126,6 +177,15 @@ func (mabcfg *Config) AddToCommand(cmd *cobra.Command) {
 	_ = viper.BindPFlag(flagWorkingDirectory, cmd.Flags().Lookup(flagWorkingDirectory))
 	_ = viper.BindPFlag(flagExecUUID, cmd.Flags().Lookup(flagExecUUID))
 	_ = viper.BindPFlag(flagVtgateWebPorts, cmd.Flags().Lookup(flagVtgateWebPorts))
+	_ = viper.BindPFlag(flagFieldMapping, cmd.Flags().Lookup(flagFieldMapping))
+	_ = viper.BindPFlag(flagSmokeTime, cmd.Flags().Lookup(flagSmokeTime))
+	_ = viper.BindPFlag(flagSmokeMinQPS, cmd.Flags().Lookup(flagSmokeMinQPS))
+	_ = viper.BindPFlag(flagSmokeMinTPS, cmd.Flags().Lookup(flagSmokeMinTPS))
+	_ = viper.BindPFlag(flagCaptureQueryPlans, cmd.Flags().Lookup(flagCaptureQueryPlans))
+	_ = viper.BindPFlag(flagVerificationQuery, cmd.Flags().Lookup(flagVerificationQuery))
+	_ = viper.BindPFlag(flagVerificationExpected, cmd.Flags().Lookup(flagVerificationExpected))
+	_ = viper.BindPFlag(flagWorkloadMix, cmd.Flags().Lookup(flagWorkloadMix))
+	_ = viper.BindPFlag(flagVtgateProfileFile, cmd.Flags().Lookup(flagVtgateProfileFile))
 }
 
 func (mabcfg *Config) parseIntoMap(prefix string) {
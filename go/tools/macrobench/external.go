@@ -0,0 +1,141 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package macrobench
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/vitessio/arewefastyet/go/storage"
+)
+
+// defaultExternalBaselineName is the git ref given to an ExternalBaseline that has no name.
+const defaultExternalBaselineName = "external"
+
+// externalBaselineClient downloads the external baselines, its timeout prevents an unreachable
+// source from hanging the comparison that loads the baseline.
+var externalBaselineClient = &http.Client{Timeout: 30 * time.Second}
+
+// ExternalBaseline contains reference numbers that were not benchmarked by arewefastyet, such as the
+// ones published by a vendor. Metrics maps each macrobenchmark type to its metrics, keyed by their
+// canonical name (e.g. "tps", "latency" or "qps.total").
+type ExternalBaseline struct {
+	Name    string                      `json:"name"`
+	Metrics map[Type]map[string]float64 `json:"metrics"`
+}
+
+// LoadExternalBaseline reads and validates the ExternalBaseline found at source, which is either
+// the path to a JSON file or an HTTP(S) URL.
+func LoadExternalBaseline(source string) (baseline ExternalBaseline, err error) {
+	var content []byte
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		content, err = downloadExternalBaseline(source)
+	} else {
+		content, err = os.ReadFile(source)
+	}
+	if err != nil {
+		return ExternalBaseline{}, err
+	}
+	return parseExternalBaseline(content)
+}
+
+func downloadExternalBaseline(url string) ([]byte, error) {
+	resp, err := externalBaselineClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not download external baseline %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func parseExternalBaseline(content []byte) (ExternalBaseline, error) {
+	var baseline ExternalBaseline
+	err := json.Unmarshal(content, &baseline)
+	if err != nil {
+		return ExternalBaseline{}, fmt.Errorf("invalid external baseline: %w", err)
+	}
+	for macroType, metrics := range baseline.Metrics {
+		if macroType != OLTP && macroType != TPCC {
+			return ExternalBaseline{}, fmt.Errorf("unknown macrobenchmark type %q in external baseline", macroType)
+		}
+		for metric := range metrics {
+			if !canonicalFields[metric] {
+				return ExternalBaseline{}, fmt.Errorf("unknown metric %q in external baseline", metric)
+			}
+		}
+	}
+	if baseline.Name == "" {
+		baseline.Name = defaultExternalBaselineName
+	}
+	return baseline, nil
+}
+
+// CompareExternalBaseline compares the results of reference against the metrics of the given
+// ExternalBaseline. The metrics missing from the baseline take the value of reference, so that
+// they are not reported as a regression nor an improvement.
+func CompareExternalBaseline(client storage.SQLClient, macroType Type, reference string, planner PlannerVersion, baseline ExternalBaseline) (ComparisonArray, error) {
+	references, err := GetResultsForGitRefAndPlanner(macroType, reference, planner, client)
+	if err != nil {
+		return nil, err
+	}
+	metrics, ok := baseline.Metrics[macroType]
+	if !ok || len(references) == 0 {
+		return CompareDetailsArrays(references.ReduceSimpleMedian(), nil), nil
+	}
+	medians := references.ReduceSimpleMedian()
+	result, err := externalBaselineResult(medians[0].Result, metrics)
+	if err != nil {
+		return nil, err
+	}
+	return CompareDetailsArrays(medians, DetailsArray{{GitRef: baseline.Name, Result: result}}), nil
+}
+
+// externalBaselineResult returns a copy of reference whose metrics are replaced by the ones of the baseline.
+func externalBaselineResult(reference Result, metrics map[string]float64) (Result, error) {
+	content, err := json.Marshal(reference)
+	if err != nil {
+		return Result{}, err
+	}
+	var object map[string]interface{}
+	err = json.Unmarshal(content, &object)
+	if err != nil {
+		return Result{}, err
+	}
+	for metric, value := range metrics {
+		setField(object, strings.Split(metric, "."), value)
+	}
+	content, err = json.Marshal(object)
+	if err != nil {
+		return Result{}, err
+	}
+	var result Result
+	err = json.Unmarshal(content, &result)
+	if err != nil {
+		return Result{}, err
+	}
+	return result, nil
+}
@@ -0,0 +1,110 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package macrobench
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestLoadExternalBaseline(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    ExternalBaseline
+		wantErr string
+	}{
+		{
+			name:    "named baseline",
+			content: `{"name": "vendor", "metrics": {"oltp": {"tps": 100, "qps.total": 2000}}}`,
+			want:    ExternalBaseline{Name: "vendor", Metrics: map[Type]map[string]float64{OLTP: {"tps": 100, "qps.total": 2000}}},
+		},
+		{
+			name:    "unnamed baseline",
+			content: `{"metrics": {"tpcc": {"latency": 12.5}}}`,
+			want:    ExternalBaseline{Name: "external", Metrics: map[Type]map[string]float64{TPCC: {"latency": 12.5}}},
+		},
+		{
+			name:    "unknown type",
+			content: `{"metrics": {"tpch": {"tps": 100}}}`,
+			wantErr: `unknown macrobenchmark type "tpch" in external baseline`,
+		},
+		{
+			name:    "unknown metric",
+			content: `{"metrics": {"oltp": {"qps": 100}}}`,
+			wantErr: `unknown metric "qps" in external baseline`,
+		},
+		{
+			name:    "invalid JSON",
+			content: `{"metrics": [`,
+			wantErr: `invalid external baseline: .*`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			file := path.Join(t.TempDir(), "baseline.json")
+			c.Assert(os.WriteFile(file, []byte(tt.content), 0644), qt.IsNil)
+
+			got, err := LoadExternalBaseline(file)
+			if tt.wantErr != "" {
+				c.Assert(err, qt.ErrorMatches, tt.wantErr)
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			c.Assert(got, qt.DeepEquals, tt.want)
+		})
+	}
+}
+
+func TestLoadExternalBaseline_URL(t *testing.T) {
+	c := qt.New(t)
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-unblock
+		}
+		_, _ = w.Write([]byte(`{"name": "vendor", "metrics": {"oltp": {"tps": 100}}}`))
+	}))
+	defer server.Close()
+	defer close(unblock)
+	c.Patch(&externalBaselineClient, &http.Client{Timeout: 10 * time.Millisecond})
+
+	got, err := LoadExternalBaseline(server.URL + "/baseline.json")
+	c.Assert(err, qt.IsNil)
+	c.Assert(got, qt.DeepEquals, ExternalBaseline{Name: "vendor", Metrics: map[Type]map[string]float64{OLTP: {"tps": 100}}})
+
+	_, err = LoadExternalBaseline(server.URL + "/slow")
+	c.Assert(err, qt.ErrorMatches, `.*Client.Timeout exceeded.*`)
+}
+
+func Test_externalBaselineResult(t *testing.T) {
+	c := qt.New(t)
+	reference := Result{QPS: QPS{Total: 1000, Reads: 800, Writes: 200}, TPS: 50, Latency: 10, Time: 60, Threads: 8}
+
+	got, err := externalBaselineResult(reference, map[string]float64{"tps": 40, "qps.reads": 900, "latency": 12})
+	c.Assert(err, qt.IsNil)
+	c.Assert(got, qt.DeepEquals, Result{QPS: QPS{Total: 1000, Reads: 900, Writes: 200}, TPS: 40, Latency: 12, Time: 60, Threads: 8})
+}
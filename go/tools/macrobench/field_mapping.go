@@ -0,0 +1,138 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package macrobench
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// canonicalFields lists the canonical metrics of a Result, as named in its JSON representation.
+// Nested fields are separated by a dot.
+var canonicalFields = map[string]bool{
+	"qps.total":  true,
+	"qps.reads":  true,
+	"qps.writes": true,
+	"qps.other":  true,
+	"tps":        true,
+	"latency":    true,
+	"errors":     true,
+	"reconnects": true,
+	"time":       true,
+	"threads":    true,
+}
+
+// FieldMapping maps the canonical metrics of a Result to the fields emitted by a load generator.
+// The key is the canonical metric and the value the field of the load generator, nested fields
+// being separated by a dot. The field can be followed by "*<factor>" to scale its value, which is
+// useful when the load generator uses another unit (e.g. latency=latency_us*0.001).
+type FieldMapping map[string]string
+
+// fieldSource is the load generator field of a canonical metric and the factor applied to its value.
+type fieldSource struct {
+	path   []string
+	factor float64
+}
+
+// parse validates the FieldMapping and returns the fieldSource of each canonical metric.
+func (fm FieldMapping) parse() (map[string]fieldSource, error) {
+	sources := make(map[string]fieldSource, len(fm))
+	for canonical, source := range fm {
+		if !canonicalFields[canonical] {
+			return nil, fmt.Errorf("unknown canonical metric %q in field mapping", canonical)
+		}
+		fs := fieldSource{factor: 1}
+		if i := strings.LastIndex(source, "*"); i != -1 {
+			factor, err := strconv.ParseFloat(source[i+1:], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid factor for metric %q in field mapping: %w", canonical, err)
+			}
+			fs.factor = factor
+			source = source[:i]
+		}
+		if source == "" {
+			return nil, fmt.Errorf("empty field for metric %q in field mapping", canonical)
+		}
+		fs.path = strings.Split(source, ".")
+		sources[canonical] = fs
+	}
+	return sources, nil
+}
+
+// normalize rewrites the JSON results of a load generator so that the fields of the
+// FieldMapping are renamed to their canonical metric, allowing them to be unmarshalled
+// in a slice of Result. The results are returned untouched if the FieldMapping is empty.
+func (fm FieldMapping) normalize(resStr []byte) ([]byte, error) {
+	if len(fm) == 0 {
+		return resStr, nil
+	}
+	sources, err := fm.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []map[string]interface{}
+	err = json.Unmarshal(resStr, &results)
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		for canonical, source := range sources {
+			value, ok := lookupField(result, source.path)
+			if !ok {
+				continue
+			}
+			number, ok := value.(float64)
+			if !ok {
+				return nil, fmt.Errorf("field %q of metric %q is not a number", strings.Join(source.path, "."), canonical)
+			}
+			setField(result, strings.Split(canonical, "."), number*source.factor)
+		}
+	}
+	return json.Marshal(results)
+}
+
+// lookupField returns the value found under the given path of a JSON object.
+func lookupField(object map[string]interface{}, path []string) (interface{}, bool) {
+	value, ok := object[path[0]]
+	if !ok || len(path) == 1 {
+		return value, ok
+	}
+	child, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	return lookupField(child, path[1:])
+}
+
+// setField sets the value under the given path of a JSON object, creating the intermediate objects if needed.
+func setField(object map[string]interface{}, path []string, value interface{}) {
+	if len(path) == 1 {
+		object[path[0]] = value
+		return
+	}
+	child, ok := object[path[0]].(map[string]interface{})
+	if !ok {
+		child = map[string]interface{}{}
+		object[path[0]] = child
+	}
+	setField(child, path[1:], value)
+}
@@ -0,0 +1,94 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package macrobench
+
+import (
+	"encoding/json"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestFieldMapping_normalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping FieldMapping
+		input   string
+		want    Result
+		wantErr bool
+	}{
+		{
+			name:  "No mapping",
+			input: `[{"tps": 10, "latency": 2.5, "qps": {"total": 100}}]`,
+			want:  Result{TPS: 10, Latency: 2.5, QPS: QPS{Total: 100}},
+		},
+		{
+			name:    "Renamed fields",
+			mapping: FieldMapping{"tps": "transactions", "qps.total": "queries.all"},
+			input:   `[{"transactions": 10, "queries": {"all": 100}}]`,
+			want:    Result{TPS: 10, QPS: QPS{Total: 100}},
+		},
+		{
+			name:    "Scaled field",
+			mapping: FieldMapping{"latency": "latency_us*0.001"},
+			input:   `[{"latency_us": 2500}]`,
+			want:    Result{Latency: 2.5},
+		},
+		{
+			name:    "Missing field",
+			mapping: FieldMapping{"tps": "transactions"},
+			input:   `[{"tps": 10}]`,
+			want:    Result{TPS: 10},
+		},
+		{
+			name:    "Unknown canonical metric",
+			mapping: FieldMapping{"throughput": "transactions"},
+			input:   `[{"transactions": 10}]`,
+			wantErr: true,
+		},
+		{
+			name:    "Invalid factor",
+			mapping: FieldMapping{"latency": "latency_us*abc"},
+			input:   `[{"latency_us": 2500}]`,
+			wantErr: true,
+		},
+		{
+			name:    "Field is not a number",
+			mapping: FieldMapping{"tps": "transactions"},
+			input:   `[{"transactions": "ten"}]`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			got, err := tt.mapping.normalize([]byte(tt.input))
+			if tt.wantErr {
+				c.Assert(err, qt.Not(qt.IsNil))
+				return
+			}
+			c.Assert(err, qt.IsNil)
+
+			var results []Result
+			c.Assert(json.Unmarshal(got, &results), qt.IsNil)
+			c.Assert(results, qt.HasLen, 1)
+			c.Assert(results[0], qt.DeepEquals, tt.want)
+		})
+	}
+}
@@ -0,0 +1,133 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+package macrobench
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/vitessio/arewefastyet/go/storage"
+	"github.com/vitessio/arewefastyet/go/storage/mysql"
+)
+
+type (
+	// HistogramBucket is a single bucket of a latency histogram. It contains
+	// the number of queries that were executed with the given latency (ms).
+	HistogramBucket struct {
+		Latency float64 `json:"latency"`
+		Count   int     `json:"count"`
+	}
+
+	// Histogram is the latency distribution of the queries executed during
+	// a macro benchmark, as reported by sysbench when using --histogram.
+	Histogram []HistogramBucket
+
+	// sysbenchHistogram is used to parse the histogram from sysbench's JSON output.
+	sysbenchHistogram struct {
+		Histogram Histogram `json:"histogram"`
+	}
+)
+
+// insertToMySQL inserts each bucket of the Histogram for the given macrobenchmark.
+func (h Histogram) insertToMySQL(macrobenchmarkID int, client storage.SQLClient) error {
+	if client == nil {
+		return errors.New(mysql.ErrorClientConnectionNotInitialized)
+	}
+	for _, bucket := range h {
+		_, err := client.Insert("INSERT INTO macrobenchmark_histogram(macrobenchmark_id, latency, count) VALUES(?, ?, ?)", macrobenchmarkID, bucket.Latency, bucket.Count)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetHistogramForGitRefAndPlanner returns the latency histogram of all the finished
+// macro benchmarks of the given type, git ref and planner version. The buckets of
+// the different benchmarks are summed, and sorted by latency.
+func GetHistogramForGitRefAndPlanner(macroType Type, ref string, planner PlannerVersion, client storage.SQLClient) (Histogram, error) {
+	if macroType != OLTP && macroType != TPCC {
+		return nil, errors.New(IncorrectMacroBenchmarkType)
+	}
+	query := "SELECT h.latency, SUM(h.count) FROM execution AS e, macrobenchmark AS b, $(MBTYPE) AS macrotype, macrobenchmark_histogram AS h " +
+		"WHERE e.uuid = b.exec_uuid AND e.status = \"finished\" AND e.deleted_at IS NULL AND IFNULL(e.benchmark_exit_code, 0) = 0 AND b.commit = ? AND b.vtgate_planner_version = ? " +
+		"AND b.macrobenchmark_id = macrotype.macrobenchmark_id AND b.macrobenchmark_id = h.macrobenchmark_id " +
+		"GROUP BY h.latency ORDER BY h.latency"
+	query = strings.ReplaceAll(query, "$(MBTYPE)", macroType.ToUpper().String())
+
+	result, err := client.Select(query, ref, planner)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	var h Histogram
+	for result.Next() {
+		var bucket HistogramBucket
+		err = result.Scan(&bucket.Latency, &bucket.Count)
+		if err != nil {
+			return nil, err
+		}
+		h = append(h, bucket)
+	}
+	return h, nil
+}
+
+// WassersteinDistance computes the first Wasserstein distance (earth mover's distance)
+// between the latency distributions of h and other, in milliseconds. The histograms
+// are normalized first, a distance of zero means both distributions have the same shape.
+func (h Histogram) WassersteinDistance(other Histogram) float64 {
+	total := func(hist Histogram) (t float64) {
+		for _, bucket := range hist {
+			t += float64(bucket.Count)
+		}
+		return t
+	}
+	totalH, totalOther := total(h), total(other)
+	if totalH == 0 || totalOther == 0 {
+		return 0
+	}
+
+	// walk through the latencies of both histograms in order and integrate
+	// the absolute difference between their cumulative distributions
+	type weight struct {
+		latency float64
+		delta   float64
+	}
+	var weights []weight
+	for _, bucket := range h {
+		weights = append(weights, weight{latency: bucket.Latency, delta: float64(bucket.Count) / totalH})
+	}
+	for _, bucket := range other {
+		weights = append(weights, weight{latency: bucket.Latency, delta: -float64(bucket.Count) / totalOther})
+	}
+	sort.SliceStable(weights, func(i, j int) bool {
+		return weights[i].latency < weights[j].latency
+	})
+
+	var distance, cdfDiff float64
+	for i, w := range weights {
+		if i > 0 {
+			distance += math.Abs(cdfDiff) * (w.latency - weights[i-1].latency)
+		}
+		cdfDiff += w.delta
+	}
+	return distance
+}
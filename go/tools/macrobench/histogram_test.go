@@ -0,0 +1,45 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+package macrobench
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestHistogram_WassersteinDistance(t *testing.T) {
+	tests := []struct {
+		name        string
+		left, right Histogram
+		want        float64
+	}{
+		{name: "Empty histograms", want: 0},
+		{name: "Same histograms", left: Histogram{{Latency: 1, Count: 10}, {Latency: 2, Count: 5}}, right: Histogram{{Latency: 1, Count: 10}, {Latency: 2, Count: 5}}, want: 0},
+		{name: "Same shape, different counts", left: Histogram{{Latency: 1, Count: 10}, {Latency: 2, Count: 10}}, right: Histogram{{Latency: 1, Count: 1}, {Latency: 2, Count: 1}}, want: 0},
+		{name: "Shifted histogram", left: Histogram{{Latency: 1, Count: 10}}, right: Histogram{{Latency: 3, Count: 10}}, want: 2},
+		{name: "Half shifted histogram", left: Histogram{{Latency: 1, Count: 10}, {Latency: 2, Count: 10}}, right: Histogram{{Latency: 1, Count: 10}, {Latency: 4, Count: 10}}, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			c.Assert(tt.left.WassersteinDistance(tt.right), qt.Equals, tt.want)
+			c.Assert(tt.right.WassersteinDistance(tt.left), qt.Equals, tt.want)
+		})
+	}
+}
@@ -19,12 +19,16 @@
 package macrobench
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/vitessio/arewefastyet/go/exec/exitcode"
 	"github.com/vitessio/arewefastyet/go/exec/metrics"
+	"github.com/vitessio/arewefastyet/go/exec/provision"
 	"github.com/vitessio/arewefastyet/go/storage/influxdb"
 	"github.com/vitessio/arewefastyet/go/storage/psdb"
+	"log"
 	"os"
 	"os/exec"
 	"strings"
@@ -48,6 +52,17 @@ var (
 	}
 )
 
+// PreviousPlannerVersion returns the planner version that precedes the given one
+// in PlannerVersions. The boolean is false if there is no previous planner version.
+func PreviousPlannerVersion(planner PlannerVersion) (PlannerVersion, bool) {
+	for i, version := range PlannerVersions {
+		if version == planner && i > 0 {
+			return PlannerVersions[i-1], true
+		}
+	}
+	return "", false
+}
+
 func buildSysbenchArgString(m map[string]string, step string) []string {
 	output := map[string]string{}
 	for k, v := range m {
@@ -89,6 +104,11 @@ func buildSysbenchArgString(m map[string]string, step string) []string {
 // Regular Sysbench: https://github.com/planetscale/sysbench
 // Sysbench-TPCC: https://github.com/planetscale/sysbench-tpcc
 func Run(mabcfg Config) error {
+	mix, err := ParseWorkloadMix(mabcfg.WorkloadMix)
+	if err != nil {
+		return err
+	}
+
 	// get sql database client
 	sqlClient, err := createSQLClient(mabcfg.DatabaseConfig)
 	if err != nil {
@@ -96,9 +116,12 @@ func Run(mabcfg Config) error {
 	}
 	defer sqlClient.Close()
 
-	// get metrics database client
+	// get metrics database client, the metrics are skipped if it cannot be reached
 	metricsClient, err := createMetricsDatabaseClient(mabcfg.MetricsDatabaseConfig)
-	if err != nil {
+	var unreachable *influxdb.UnreachableError
+	if errors.As(err, &unreachable) {
+		log.Printf("skipping the execution metrics: %v\n", err)
+	} else if err != nil {
 		return err
 	}
 
@@ -109,6 +132,15 @@ func Run(mabcfg Config) error {
 		if err != nil {
 			return err
 		}
+		// the infrastructure is provisioned once the benchmark starts
+		err = provision.RecordEnd(sqlClient, mabcfg.execUUID)
+		if err != nil {
+			return err
+		}
+		err = recordWorkloadMix(sqlClient, mabcfg.execUUID, mix)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Prepare
@@ -116,33 +148,127 @@ func Run(mabcfg Config) error {
 		mabcfg.WorkingDirectory, _ = os.Getwd()
 	}
 	mabcfg.parseIntoMap(prefixMacroBenchSysbenchConfig)
-	newSteps := skipSteps(steps, mabcfg.SkipSteps)
+	skip := mabcfg.SkipSteps
+	if mabcfg.SmokeTime <= 0 {
+		skip = append(skip, stepSmoke)
+	}
+	newSteps := skipSteps(steps, skip)
 
 	// Execution
 	var resStr []byte
+	var workloadOuts [][]byte
+	var workloadCodes []int
 	for _, step := range newSteps {
 		args := buildSysbenchArgString(mabcfg.M, step.Name)
-		args = append(args, mabcfg.WorkloadPath, step.SysbenchName)
-		command := exec.Command(mabcfg.SysbenchExec, args...)
-		command.Dir = mabcfg.WorkingDirectory
-		out, err := command.Output()
-		if err != nil {
-			return fmt.Errorf("%s:\n%s", err.Error(), string(out))
+		if step.Name == stepSmoke {
+			args = mabcfg.smokeArgs()
+		}
+		var out []byte
+		var err error
+		var profile <-chan error
+		if step.Name == stepRun && mabcfg.VtgateProfileFile != "" && len(mabcfg.vtgateWebPorts) > 0 {
+			profile = startVTGateProfile(mabcfg.vtgateWebPorts[0], runTime(mabcfg.M), mabcfg.VtgateProfileFile)
+		}
+		if step.Name == stepRun && len(mix) > 0 {
+			workloadOuts, workloadCodes, err = mabcfg.runWorkloadMix(mix, args, step.SysbenchName)
+			out = bytes.Join(workloadOuts, []byte("\n"))
+			for i, code := range workloadCodes {
+				if code != 0 {
+					log.Printf("sysbench workload %s exited with code %d\n", mix[i].Path, code)
+				}
+			}
+		} else {
+			args = append(args, mabcfg.WorkloadPath, step.SysbenchName)
+			command := exec.Command(mabcfg.SysbenchExec, args...)
+			command.Dir = mabcfg.WorkingDirectory
+			out, err = command.Output()
+		}
+		if profile != nil {
+			// the profile is not required, the benchmark itself succeeded
+			if errProfile := <-profile; errProfile != nil {
+				log.Printf("could not collect the CPU profile of vtgate: %v\n", errProfile)
+			}
 		}
 		if step.Name == stepRun {
+			// a run that exits with a non-zero code may still produce results,
+			// they are saved but are not used by comparisons
+			code, ok := exitcode.FromError(err)
+			if ok && len(mix) > 0 {
+				// the mix exits with the code of its first failed workload,
+				// the results of the whole mix are not used by comparisons
+				code = exitCode(workloadCodes)
+			}
+			if ok && sqlClient != nil {
+				if errRecord := exitcode.Record(sqlClient, mabcfg.execUUID, code); errRecord != nil {
+					return errRecord
+				}
+			}
+			if ok && code != 0 {
+				log.Printf("sysbench exited with code %d:\n%s\n", code, string(out))
+				err = nil
+			}
 			resStr = out
 		}
+		if err != nil {
+			return fmt.Errorf("%s:\n%s", err.Error(), string(out))
+		}
+		if step.Name == stepSmoke {
+			// the expensive steps are skipped if the smoke benchmark shows the build is broken
+			err = mabcfg.checkSmokeResults(out)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if mabcfg.VerificationQuery != "" {
+		err = mabcfg.verify(sqlClient)
+		if err != nil {
+			return err
+		}
 	}
 
-	err = handleResults(mabcfg, resStr, sqlClient, metricsClient, macrobenchID)
+	if len(mix) > 0 {
+		err = handleWorkloadMixResults(mabcfg, mix, workloadOuts, workloadCodes, sqlClient, metricsClient, macrobenchID)
+	} else {
+		err = handleResults(mabcfg, resStr, sqlClient, metricsClient, macrobenchID)
+	}
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// handleWorkloadMixResults stores the result of each workload of the mix, and handles the
+// aggregated result and latency histogram of the mix like the ones of a single workload.
+func handleWorkloadMixResults(mabcfg Config, mix WorkloadMix, workloadOuts [][]byte, workloadCodes []int, sqlClient *psdb.Client, metricsClient *influxdb.Client, macrobenchID int) error {
+	results, err := mix.workloadResults(workloadOuts, workloadCodes, mabcfg.FieldMapping)
+	if err != nil {
+		return err
+	}
+	if sqlClient != nil {
+		for _, result := range results {
+			err = result.insertToMySQL(macrobenchID, sqlClient)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	resStr, err := json.Marshal([]workloadMixResult{{Result: aggregateWorkloadResults(results), Histogram: aggregateWorkloadHistograms(results)}})
+	if err != nil {
+		return err
+	}
+	// the aggregated result is already normalized
+	mabcfg.FieldMapping = nil
+	return handleResults(mabcfg, resStr, sqlClient, metricsClient, macrobenchID)
+}
+
 func handleResults(mabcfg Config, resStr []byte, sqlClient *psdb.Client, metricsClient *influxdb.Client, macrobenchID int) error {
-	err := handleSysBenchResults(resStr, sqlClient, mabcfg.Type, macrobenchID)
+	resStr, err := mabcfg.FieldMapping.normalize(resStr)
+	if err != nil {
+		return fmt.Errorf("normalize results: %w", err)
+	}
+	err = handleSysBenchResults(resStr, sqlClient, mabcfg.Type, macrobenchID)
 	if err != nil {
 		return err
 	}
@@ -150,6 +276,9 @@ func handleResults(mabcfg Config, resStr []byte, sqlClient *psdb.Client, metrics
 	if err != nil {
 		return err
 	}
+	if !mabcfg.CaptureQueryPlans {
+		return nil
+	}
 	err = handleVTGateResults(mabcfg.vtgateWebPorts, sqlClient, mabcfg.execUUID, macrobenchID)
 	if err != nil {
 		return err
@@ -169,7 +298,7 @@ func createSQLClient(dbConfig *psdb.Config) (client *psdb.Client, err error) {
 
 func createMetricsDatabaseClient(dbConfig *influxdb.Config) (client *influxdb.Client, err error) {
 	if dbConfig != nil && dbConfig.IsValid() {
-		client, err = dbConfig.NewClient()
+		client, err = dbConfig.NewClientWithRetry()
 		if err != nil {
 			return
 		}
@@ -186,6 +315,9 @@ func handleVTGateResults(ports []string, sqlClient *psdb.Client, execUUID string
 }
 
 func handleMetricsResults(client *influxdb.Client, sqlClient *psdb.Client, execUUID string) error {
+	if client == nil {
+		return nil
+	}
 	execMetrics, err := metrics.GetExecutionMetrics(*client, execUUID)
 	if err != nil {
 		return err
@@ -215,5 +347,18 @@ func handleSysBenchResults(resStr []byte, sqlClient *psdb.Client, macrobenchType
 			return err
 		}
 	}
+
+	// Save the latency histogram, it is only present if sysbench was run with --histogram
+	var histograms []sysbenchHistogram
+	err = json.Unmarshal(resStr, &histograms)
+	if err != nil {
+		return fmt.Errorf("unmarshal histogram: %+v\n", err)
+	}
+	if sqlClient != nil && len(histograms[0].Histogram) > 0 {
+		err = histograms[0].Histogram.insertToMySQL(macrobenchID, sqlClient)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -51,3 +51,23 @@ func TestBuildSysbenchArgString(t *testing.T) {
 		})
 	}
 }
+
+func TestPreviousPlannerVersion(t *testing.T) {
+	tts := []struct {
+		planner PlannerVersion
+		want    PlannerVersion
+		ok      bool
+	}{
+		{planner: Gen4FallbackPlanner, want: V3Planner, ok: true},
+		{planner: V3Planner, want: "", ok: false},
+		{planner: "unknown", want: "", ok: false},
+	}
+	for _, tt := range tts {
+		t.Run(string(tt.planner), func(t *testing.T) {
+			c := qt.New(t)
+			got, ok := PreviousPlannerVersion(tt.planner)
+			c.Assert(ok, qt.Equals, tt.ok)
+			c.Assert(got, qt.Equals, tt.want)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+package macrobench
+
+import (
+	"fmt"
+	"strings"
+
+	awftmath "github.com/vitessio/arewefastyet/go/tools/math"
+)
+
+// Markdown renders the Comparison as a Markdown table that can be
+// used in a GitHub comment.
+func (c Comparison) Markdown() string {
+	var b strings.Builder
+	b.WriteString("| Metric | " + c.Reference.GitRef + " | " + c.Compare.GitRef + " | Diff |\n")
+	b.WriteString("|---|---:|---:|---:|\n")
+	rows := []struct {
+		name               string
+		reference, compare string
+		diff               float64
+	}{
+		{name: "QPS Total", reference: c.Reference.Result.QPS.TotalStr(), compare: c.Compare.Result.QPS.TotalStr(), diff: c.Diff.QPS.Total},
+		{name: "QPS Reads", reference: c.Reference.Result.QPS.ReadsStr(), compare: c.Compare.Result.QPS.ReadsStr(), diff: c.Diff.QPS.Reads},
+		{name: "QPS Writes", reference: c.Reference.Result.QPS.WritesStr(), compare: c.Compare.Result.QPS.WritesStr(), diff: c.Diff.QPS.Writes},
+		{name: "QPS Other", reference: c.Reference.Result.QPS.OtherStr(), compare: c.Compare.Result.QPS.OtherStr(), diff: c.Diff.QPS.Other},
+		{name: "TPS", reference: c.Reference.Result.TPSStr(), compare: c.Compare.Result.TPSStr(), diff: c.Diff.TPS},
+		{name: "Latency", reference: c.Reference.Result.LatencyStr(), compare: c.Compare.Result.LatencyStr(), diff: c.Diff.Latency},
+		{name: "Errors", reference: c.Reference.Result.ErrorsStr(), compare: c.Compare.Result.ErrorsStr(), diff: c.Diff.Errors},
+		{name: "Total CPU time", reference: fmt.Sprintf("%.2f", c.Reference.Metrics.TotalComponentsCPUTime), compare: fmt.Sprintf("%.2f", c.Compare.Metrics.TotalComponentsCPUTime), diff: c.DiffMetrics.TotalComponentsCPUTime},
+	}
+	for _, row := range rows {
+		b.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", row.name, row.reference, row.compare, awftmath.FormatDiff(row.diff)))
+	}
+	return b.String()
+}
+
+// Markdown renders each Comparison of the ComparisonArray as a
+// Markdown table, separated by an empty line.
+func (ca ComparisonArray) Markdown() string {
+	tables := make([]string, 0, len(ca))
+	for _, c := range ca {
+		tables = append(tables, c.Markdown())
+	}
+	return strings.Join(tables, "\n")
+}
@@ -0,0 +1,136 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package macrobench
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/vitessio/arewefastyet/go/storage"
+	awftmath "github.com/vitessio/arewefastyet/go/tools/math"
+)
+
+// ComparePercentileBaseline compares the results of reference against a baseline aggregated from
+// the last window executions of the source that benchmarked baseline, up to and including baseline.
+// Each metric of the aggregated baseline is the given percentile, between 0 and 100, of the window
+// in terms of performance: the p25 baseline performs better than a quarter of the window, making it
+// an optimistic baseline that triggers fewer regressions, while the p75 baseline is a pessimistic one.
+func ComparePercentileBaseline(client storage.SQLClient, macroType Type, reference, baseline string, referencePlanner, baselinePlanner PlannerVersion, window int, percentile float64) (ComparisonArray, error) {
+	references, err := GetResultsForGitRefAndPlanner(macroType, reference, referencePlanner, client)
+	if err != nil {
+		return nil, err
+	}
+	baselines, err := GetResultsForGitRefAndPlanner(macroType, baseline, baselinePlanner, client)
+	if err != nil {
+		return nil, err
+	}
+	if len(baselines) == 0 {
+		return CompareDetailsArrays(references.ReduceSimpleMedian(), nil), nil
+	}
+	latest := baselines[0]
+	for _, details := range baselines {
+		if details.ID > latest.ID {
+			latest = details
+		}
+	}
+	runs, err := getResultsForLastExecutions(macroType, latest.Source, baselinePlanner, latest.ID, window, client)
+	if err != nil {
+		return nil, err
+	}
+	aggregated := Details{GitRef: baseline, Result: percentileBaseline(runs, percentile)}
+	comparisons := CompareDetailsArrays(references.ReduceSimpleMedian(), DetailsArray{aggregated})
+	for i := range comparisons {
+		comparisons[i].PValue = computePValues(references, runs)
+		comparisons[i].Confidence = computeConfidence(references, runs)
+	}
+	return comparisons, nil
+}
+
+// percentileBaseline merges the runs of each execution, and returns the given percentile of each
+// metric across the executions. The percentile of the latency and the errors is inverted, as a
+// higher value means a lower performance.
+func percentileBaseline(runs DetailsArray, percentile float64) Result {
+	byExecution := map[string]ResultsArray{}
+	for _, details := range runs {
+		byExecution[details.ExecUUID] = append(byExecution[details.ExecUUID], details.Result)
+	}
+	var series ResultsArray
+	for _, results := range byExecution {
+		series = append(series, results.mergeMedian())
+	}
+	metric := func(get func(r Result) float64, p float64) float64 {
+		values := make([]float64, 0, len(series))
+		for _, r := range series {
+			values = append(values, get(r))
+		}
+		return awftmath.Percentile(values, p)
+	}
+	var result Result
+	result.QPS.Total = metric(func(r Result) float64 { return r.QPS.Total }, percentile)
+	result.QPS.Reads = metric(func(r Result) float64 { return r.QPS.Reads }, percentile)
+	result.QPS.Writes = metric(func(r Result) float64 { return r.QPS.Writes }, percentile)
+	result.QPS.Other = metric(func(r Result) float64 { return r.QPS.Other }, percentile)
+	result.TPS = metric(func(r Result) float64 { return r.TPS }, percentile)
+	result.Latency = metric(func(r Result) float64 { return r.Latency }, 100-percentile)
+	result.Errors = metric(func(r Result) float64 { return r.Errors }, 100-percentile)
+	result.Reconnects = metric(func(r Result) float64 { return r.Reconnects }, 100-percentile)
+	result.Time = int(metric(func(r Result) float64 { return float64(r.Time) }, percentile))
+	result.Threads = metric(func(r Result) float64 { return r.Threads }, percentile)
+	return result
+}
+
+// getResultsForLastExecutions returns the results of the last window executions of the given
+// source and planner version, up to and including the macrobenchmark lastID.
+func getResultsForLastExecutions(macroType Type, source string, planner PlannerVersion, lastID, window int, client storage.SQLClient) (macrodetails DetailsArray, err error) {
+	if macroType != OLTP && macroType != TPCC {
+		return nil, errors.New(IncorrectMacroBenchmarkType)
+	}
+	upperMacroType := macroType.ToUpper().String()
+	query := "SELECT b.macrobenchmark_id, b.commit, b.source, b.DateTime, IFNULL(b.exec_uuid, ''), " +
+		"macrotype.tps, macrotype.latency, macrotype.errors, macrotype.reconnects, macrotype.time, macrotype.threads, " +
+		"qps.qps_no, qps.total_qps, qps.reads_qps, qps.writes_qps, qps.other_qps " +
+		"FROM execution AS e, macrobenchmark AS b, $(MBTYPE) AS macrotype, qps AS qps " +
+		"WHERE e.uuid = b.exec_uuid AND e.status = \"finished\" AND e.deleted_at IS NULL AND IFNULL(e.benchmark_exit_code, 0) = 0 " +
+		"AND b.source = ? AND b.vtgate_planner_version = ? AND b.macrobenchmark_id <= ? AND b.macrobenchmark_id = macrotype.macrobenchmark_id AND macrotype.$(MBTYPE)_no = qps.$(MBTYPE)_no " +
+		"ORDER BY b.macrobenchmark_id DESC"
+
+	query = strings.ReplaceAll(query, "$(MBTYPE)", upperMacroType)
+
+	result, err := client.Select(query, source, planner, lastID)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+	executions := map[string]bool{}
+	for result.Next() {
+		var res Details
+		err = result.Scan(&res.ID, &res.GitRef, &res.Source, &res.CreatedAt, &res.ExecUUID, &res.Result.TPS, &res.Result.Latency,
+			&res.Result.Errors, &res.Result.Reconnects, &res.Result.Time, &res.Result.Threads, &res.Result.QPS.ID,
+			&res.Result.QPS.Total, &res.Result.QPS.Reads, &res.Result.QPS.Writes, &res.Result.QPS.Other)
+		if err != nil {
+			return nil, err
+		}
+		if !executions[res.ExecUUID] && len(executions) == window {
+			break
+		}
+		executions[res.ExecUUID] = true
+		macrodetails = append(macrodetails, res)
+	}
+	return macrodetails, nil
+}
@@ -0,0 +1,59 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package macrobench
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestPercentileBaseline(t *testing.T) {
+	newRun := func(execUUID string, tps, latency float64) Details {
+		return Details{BenchmarkID: BenchmarkID{ExecUUID: execUUID}, Result: *newResult(*newQPS(tps*20, 0, 0, 0), tps, latency, 0, 0, 0, 0)}
+	}
+	runs := DetailsArray{
+		newRun("exec-1", 100, 10),
+		newRun("exec-1", 110, 12),
+		newRun("exec-2", 200, 20),
+		newRun("exec-3", 300, 30),
+		newRun("exec-4", 400, 40),
+		newRun("exec-5", 500, 50),
+	}
+	tests := []struct {
+		name        string
+		percentile  float64
+		wantTPS     float64
+		wantLatency float64
+	}{
+		{name: "Median", percentile: 50, wantTPS: 300, wantLatency: 30},
+		{name: "Optimistic baseline", percentile: 25, wantTPS: 200, wantLatency: 40},
+		{name: "Pessimistic baseline", percentile: 75, wantTPS: 400, wantLatency: 20},
+		{name: "Worst execution", percentile: 0, wantTPS: 105, wantLatency: 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			got := percentileBaseline(runs, tt.percentile)
+			c.Assert(got.TPS, qt.Equals, tt.wantTPS)
+			c.Assert(got.QPS.Total, qt.Equals, tt.wantTPS*20)
+			c.Assert(got.Latency, qt.Equals, tt.wantLatency)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package macrobench
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+// defaultSysbenchTime is the duration, in seconds, of a sysbench step without time option.
+const defaultSysbenchTime = 10
+
+// runTime returns the duration, in seconds, of the run step configured in m.
+func runTime(m map[string]string) int {
+	for _, key := range []string{stepRun + "_time", "all_time"} {
+		if seconds, err := strconv.Atoi(m[key]); err == nil && seconds > 0 {
+			return seconds
+		}
+	}
+	return defaultSysbenchTime
+}
+
+// startVTGateProfile starts collecting, in the background, a CPU profile of the vtgate
+// listening on the given web port for the given number of seconds, and writes it to file.
+// The returned channel receives the outcome of the collection once it is done.
+func startVTGateProfile(port string, seconds int, file string) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		done <- collectVTGateProfile(fmt.Sprintf("http://127.0.0.1:%s/debug/pprof/profile?seconds=%d", port, seconds), file)
+	}()
+	return done
+}
+
+func collectVTGateProfile(url, file string) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected profile status: %s", resp.Status)
+	}
+	out, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, resp.Body)
+	if errClose := out.Close(); err == nil {
+		err = errClose
+	}
+	return err
+}
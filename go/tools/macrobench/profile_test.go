@@ -0,0 +1,69 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package macrobench
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestRunTime(t *testing.T) {
+	tests := []struct {
+		name string
+		m    map[string]string
+		want int
+	}{
+		{name: "run time", m: map[string]string{"run_time": "900", "all_time": "60", "warmup_time": "10"}, want: 900},
+		{name: "all steps time", m: map[string]string{"all_time": "60", "warmup_time": "10"}, want: 60},
+		{name: "sysbench default", m: map[string]string{"warmup_time": "10"}, want: defaultSysbenchTime},
+		{name: "invalid time", m: map[string]string{"run_time": "forever"}, want: defaultSysbenchTime},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qt.Assert(t, runTime(tt.m), qt.Equals, tt.want)
+		})
+	}
+}
+
+func TestStartVTGateProfile(t *testing.T) {
+	c := qt.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, qt.Equals, "/debug/pprof/profile")
+		c.Check(r.URL.Query().Get("seconds"), qt.Equals, "900")
+		_, _ = w.Write([]byte("profile"))
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	c.Assert(err, qt.IsNil)
+	_, port, err := net.SplitHostPort(serverURL.Host)
+	c.Assert(err, qt.IsNil)
+
+	file := path.Join(c.TempDir(), "vtgate.pprof")
+	c.Assert(<-startVTGateProfile(port, 900, file), qt.IsNil)
+	content, err := os.ReadFile(file)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(content), qt.Equals, "profile")
+}
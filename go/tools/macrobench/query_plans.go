@@ -0,0 +1,153 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package macrobench
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/vitessio/arewefastyet/go/storage"
+)
+
+const (
+	// QueryPlanChanged, QueryPlanAdded and QueryPlanRemoved are the statuses of a QueryPlanChange.
+	QueryPlanChanged = "changed"
+	QueryPlanAdded   = "added"
+	QueryPlanRemoved = "removed"
+
+	// queryPlansSeparator separates the distinct plans a query got during a single execution.
+	queryPlansSeparator = "\n----\n"
+)
+
+// QueryPlanChange is the change of the query plan of a query between two executions.
+// Diff lists the lines of both plans, prefixed with "-" if they are only in the left
+// plan, "+" if they are only in the right plan, and " " if they are in both.
+type QueryPlanChange struct {
+	Key    string   `json:"key"`
+	Status string   `json:"status"`
+	Left   string   `json:"left,omitempty"`
+	Right  string   `json:"right,omitempty"`
+	Diff   []string `json:"diff,omitempty"`
+}
+
+// GetQueryPlansForExecution returns the query plans captured by the execution execUUID,
+// indexed by their query. An execution captures the plans of each of its macrobenchmark runs,
+// if a query got different plans across the runs, its distinct plans are sorted and separated
+// by queryPlansSeparator so that none of them is lost when diffing the plans of two executions.
+func GetQueryPlansForExecution(execUUID string, client storage.SQLClient) (map[string]string, error) {
+	result, err := client.Select("SELECT DISTINCT `key`, plan FROM query_plans WHERE exec_uuid = ? ORDER BY `key`, plan", execUUID)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	plans := map[string]string{}
+	for result.Next() {
+		var key, plan string
+		err = result.Scan(&key, &plan)
+		if err != nil {
+			return nil, err
+		}
+		addQueryPlan(plans, key, plan)
+	}
+	return plans, nil
+}
+
+// addQueryPlan adds the plan of the query key to plans, after the plans the query already got.
+func addQueryPlan(plans map[string]string, key, plan string) {
+	if previous, ok := plans[key]; ok {
+		plan = previous + queryPlansSeparator + plan
+	}
+	plans[key] = plan
+}
+
+// DiffQueryPlans returns the changes between the query plans left and right, sorted by query.
+// The queries whose plan did not change are omitted.
+func DiffQueryPlans(left, right map[string]string) []QueryPlanChange {
+	changes := []QueryPlanChange{}
+	for key, leftPlan := range left {
+		rightPlan, ok := right[key]
+		if !ok {
+			changes = append(changes, QueryPlanChange{Key: key, Status: QueryPlanRemoved, Left: leftPlan})
+			continue
+		}
+		if leftPlan != rightPlan {
+			changes = append(changes, QueryPlanChange{
+				Key:    key,
+				Status: QueryPlanChanged,
+				Left:   leftPlan,
+				Right:  rightPlan,
+				Diff:   diffLines(strings.Split(leftPlan, "\n"), strings.Split(rightPlan, "\n")),
+			})
+		}
+	}
+	for key, rightPlan := range right {
+		if _, ok := left[key]; !ok {
+			changes = append(changes, QueryPlanChange{Key: key, Status: QueryPlanAdded, Right: rightPlan})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
+
+// diffLines returns the line diff of left and right, based on their longest common subsequence.
+func diffLines(left, right []string) []string {
+	// lcs[i][j] is the length of the longest common subsequence of left[i:] and right[j:]
+	lcs := make([][]int, len(left)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(right)+1)
+	}
+	for i := len(left) - 1; i >= 0; i-- {
+		for j := len(right) - 1; j >= 0; j-- {
+			if left[i] == right[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var diff []string
+	i, j := 0, 0
+	for i < len(left) && j < len(right) {
+		switch {
+		case left[i] == right[j]:
+			diff = append(diff, " "+left[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, "-"+left[i])
+			i++
+		default:
+			diff = append(diff, "+"+right[j])
+			j++
+		}
+	}
+	for ; i < len(left); i++ {
+		diff = append(diff, "-"+left[i])
+	}
+	for ; j < len(right); j++ {
+		diff = append(diff, "+"+right[j])
+	}
+	return diff
+}
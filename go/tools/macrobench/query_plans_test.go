@@ -0,0 +1,80 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package macrobench
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestAddQueryPlan(t *testing.T) {
+	plans := map[string]string{}
+	addQueryPlan(plans, "select a from t", "Route")
+	addQueryPlan(plans, "select b from t", "EqualUnique")
+	addQueryPlan(plans, "select b from t", "Scatter")
+	qt.Assert(t, plans, qt.DeepEquals, map[string]string{
+		"select a from t": "Route",
+		"select b from t": "EqualUnique" + queryPlansSeparator + "Scatter",
+	})
+}
+
+func TestDiffQueryPlans(t *testing.T) {
+	left := map[string]string{
+		"select a from t":    "{\n\t\"OperatorType\": \"Route\",\n\t\"Variant\": \"EqualUnique\"\n}",
+		"select b from t":    "{\n\t\"OperatorType\": \"Route\"\n}",
+		"select c from t":    "{\n\t\"OperatorType\": \"Route\"\n}",
+		"select same from t": "{}",
+	}
+	right := map[string]string{
+		"select a from t":    "{\n\t\"OperatorType\": \"Route\",\n\t\"Variant\": \"Scatter\"\n}",
+		"select c from t":    "{\n\t\"OperatorType\": \"Route\"\n}",
+		"select d from t":    "{}",
+		"select same from t": "{}",
+	}
+	qt.Assert(t, DiffQueryPlans(left, right), qt.DeepEquals, []QueryPlanChange{
+		{
+			Key:    "select a from t",
+			Status: QueryPlanChanged,
+			Left:   left["select a from t"],
+			Right:  right["select a from t"],
+			Diff:   []string{" {", " \t\"OperatorType\": \"Route\",", "-\t\"Variant\": \"EqualUnique\"", "+\t\"Variant\": \"Scatter\"", " }"},
+		},
+		{Key: "select b from t", Status: QueryPlanRemoved, Left: left["select b from t"]},
+		{Key: "select d from t", Status: QueryPlanAdded, Right: "{}"},
+	})
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name        string
+		left, right []string
+		want        []string
+	}{
+		{name: "equal", left: []string{"a", "b"}, right: []string{"a", "b"}, want: []string{" a", " b"}},
+		{name: "insertion", left: []string{"a", "c"}, right: []string{"a", "b", "c"}, want: []string{" a", "+b", " c"}},
+		{name: "deletion", left: []string{"a", "b", "c"}, right: []string{"a", "c"}, want: []string{" a", "-b", " c"}},
+		{name: "trailing", left: []string{"a"}, right: []string{"b", "c"}, want: []string{"-a", "+b", "+c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qt.Assert(t, diffLines(tt.left, tt.right), qt.DeepEquals, tt.want)
+		})
+	}
+}
@@ -79,6 +79,9 @@ type (
 		GitRef  string
 		Result  Result
 		Metrics metrics.ExecutionMetrics
+
+		// Component is the vitess component the execution of this benchmark focused on.
+		Component string
 	}
 
 	// Comparison contains two Details and their difference in a
@@ -87,6 +90,15 @@ type (
 		Reference, Compare Details
 		Diff               Result
 		DiffMetrics        metrics.ExecutionMetrics
+
+		// PValue contains the p-value of the Mann-Whitney U test of each metric
+		// of Diff, computed from the individual runs of Reference and Compare.
+		// A p-value of zero means there was not enough runs to compute it.
+		PValue Result
+
+		// Confidence tells how much Diff can be trusted, see computeConfidence.
+		// It is empty if the individual runs of Reference and Compare are unknown.
+		Confidence Confidence `json:",omitempty"`
 	}
 
 	ResultsArray []Result
@@ -123,18 +135,7 @@ func CompareDetailsArrays(references, compares DetailsArray) (compared Compariso
 			cmp.Compare = compares[i]
 		}
 		if cmp.Compare.GitRef != "" && cmp.Reference.GitRef != "" {
-			cmp.Diff.QPS.Total = (cmp.Reference.Result.QPS.Total - cmp.Compare.Result.QPS.Total) / cmp.Reference.Result.QPS.Total * 100
-			cmp.Diff.QPS.Reads = (cmp.Reference.Result.QPS.Reads - cmp.Compare.Result.QPS.Reads) / cmp.Reference.Result.QPS.Reads * 100
-			cmp.Diff.QPS.Writes = (cmp.Reference.Result.QPS.Writes - cmp.Compare.Result.QPS.Writes) / cmp.Reference.Result.QPS.Writes * 100
-			cmp.Diff.QPS.Other = (cmp.Reference.Result.QPS.Other - cmp.Compare.Result.QPS.Other) / cmp.Reference.Result.QPS.Other * 100
-			cmp.Diff.TPS = (cmp.Reference.Result.TPS - cmp.Compare.Result.TPS) / cmp.Reference.Result.TPS * 100
-			cmp.Diff.Latency = (cmp.Compare.Result.Latency - cmp.Reference.Result.Latency) / cmp.Compare.Result.Latency * 100
-			cmp.Diff.Reconnects = (cmp.Reference.Result.Reconnects - cmp.Compare.Result.Reconnects) / cmp.Reference.Result.Reconnects * 100
-			cmp.Diff.Errors = (cmp.Compare.Result.Errors - cmp.Reference.Result.Errors) / cmp.Compare.Result.Errors * 100
-			cmp.Diff.Time = int((float64(cmp.Reference.Result.Time) - float64(cmp.Compare.Result.Time)) / float64(cmp.Reference.Result.Time) * 100)
-			cmp.Diff.Threads = (cmp.Reference.Result.Threads - cmp.Compare.Result.Threads) / cmp.Reference.Result.Threads * 100
-			awftmath.CheckForNaN(&cmp.Diff, 0)
-			awftmath.CheckForNaN(&cmp.Diff.QPS, 0)
+			cmp.Diff = diffResults(cmp.Reference.Result, cmp.Compare.Result)
 			cmp.DiffMetrics = metrics.CompareTwo(cmp.Compare.Metrics, cmp.Reference.Metrics)
 		}
 		compared = append(compared, cmp)
@@ -142,6 +143,24 @@ func CompareDetailsArrays(references, compares DetailsArray) (compared Compariso
 	return compared
 }
 
+// diffResults returns the difference, in percentage, of each metric of the two Results.
+// Latency and errors are relative to compare, the other metrics are relative to reference.
+func diffResults(reference, compare Result) (diff Result) {
+	diff.QPS.Total = (reference.QPS.Total - compare.QPS.Total) / reference.QPS.Total * 100
+	diff.QPS.Reads = (reference.QPS.Reads - compare.QPS.Reads) / reference.QPS.Reads * 100
+	diff.QPS.Writes = (reference.QPS.Writes - compare.QPS.Writes) / reference.QPS.Writes * 100
+	diff.QPS.Other = (reference.QPS.Other - compare.QPS.Other) / reference.QPS.Other * 100
+	diff.TPS = (reference.TPS - compare.TPS) / reference.TPS * 100
+	diff.Latency = (compare.Latency - reference.Latency) / compare.Latency * 100
+	diff.Reconnects = (reference.Reconnects - compare.Reconnects) / reference.Reconnects * 100
+	diff.Errors = (compare.Errors - reference.Errors) / compare.Errors * 100
+	diff.Time = int((float64(reference.Time) - float64(compare.Time)) / float64(reference.Time) * 100)
+	diff.Threads = (reference.Threads - compare.Threads) / reference.Threads * 100
+	awftmath.CheckForNaN(&diff, 0)
+	awftmath.CheckForNaN(&diff.QPS, 0)
+	return diff
+}
+
 // mergeMedian will merge a ResultsArray into a single Result
 // by calculating the median of all elements in the array.
 func (mrs ResultsArray) mergeMedian() (mergedResult Result) {
@@ -184,6 +203,21 @@ func (mrs ResultsArray) mergeMedian() (mergedResult Result) {
 	return mergedResult
 }
 
+// FilterByComponent returns the Details of the DetailsArray that focused on the given
+// vitess component. The DetailsArray is returned untouched if component is empty.
+func (mabd DetailsArray) FilterByComponent(component string) DetailsArray {
+	if component == "" {
+		return mabd
+	}
+	var filtered DetailsArray
+	for _, details := range mabd {
+		if details.Component == component {
+			filtered = append(filtered, details)
+		}
+	}
+	return filtered
+}
+
 // ReduceSimpleMedian reduces the given DetailsArray by
 // merging altogether the elements that share the same GitRef.
 // During the reduce, the math.MedianFloat and math.MedianInt methods
@@ -253,6 +287,8 @@ func (qps QPS) OtherStr() string {
 }
 
 // GetDetailsArraysFromAllTypes returns a slice of Details based on the given git ref and Types.
+// Every run of the git ref is returned, callers needing one result per git ref must reduce
+// them, see DetailsArray.ReduceSimpleMedian.
 func GetDetailsArraysFromAllTypes(sha string, planner PlannerVersion, dbclient storage.SQLClient) (map[Type]DetailsArray, error) {
 	macros := map[Type]DetailsArray{}
 	for _, mtype := range Types {
@@ -269,7 +305,7 @@ func GetDetailsArraysFromAllTypes(sha string, planner PlannerVersion, dbclient s
 			}
 		}
 
-		macros[mtype] = macro.ReduceSimpleMedian()
+		macros[mtype] = macro
 	}
 	return macros, nil
 }
@@ -283,11 +319,11 @@ func GetResultsForLastDays(macroType Type, source string, planner PlannerVersion
 	}
 
 	upperMacroType := macroType.ToUpper().String()
-	query := "SELECT b.macrobenchmark_id, b.commit, b.source, b.DateTime, IFNULL(b.exec_uuid, ''), " +
+	query := "SELECT b.macrobenchmark_id, b.commit, b.source, b.DateTime, IFNULL(b.exec_uuid, ''), IFNULL(e.component, ''), " +
 		"macrotype.tps, macrotype.latency, macrotype.errors, macrotype.reconnects, macrotype.time, macrotype.threads, " +
 		"qps.qps_no, qps.total_qps, qps.reads_qps, qps.writes_qps, qps.other_qps " +
 		"FROM execution AS e, macrobenchmark AS b, $(MBTYPE) AS macrotype, qps AS qps " +
-		"WHERE e.uuid = b.exec_uuid AND e.status = \"finished\" AND b.DateTime BETWEEN DATE(NOW()) - INTERVAL ? DAY AND DATE(NOW()) " +
+		"WHERE e.uuid = b.exec_uuid AND e.status = \"finished\" AND e.deleted_at IS NULL AND IFNULL(e.benchmark_exit_code, 0) = 0 AND b.DateTime BETWEEN DATE(NOW()) - INTERVAL ? DAY AND DATE(NOW()) " +
 		"AND b.source = ? AND b.vtgate_planner_version = ? AND b.macrobenchmark_id = macrotype.macrobenchmark_id AND macrotype.$(MBTYPE)_no = qps.$(MBTYPE)_no"
 
 	query = strings.ReplaceAll(query, "$(MBTYPE)", upperMacroType)
@@ -299,7 +335,7 @@ func GetResultsForLastDays(macroType Type, source string, planner PlannerVersion
 	defer result.Close()
 	for result.Next() {
 		var res Details
-		err = result.Scan(&res.ID, &res.GitRef, &res.Source, &res.CreatedAt, &res.ExecUUID, &res.Result.TPS, &res.Result.Latency,
+		err = result.Scan(&res.ID, &res.GitRef, &res.Source, &res.CreatedAt, &res.ExecUUID, &res.Component, &res.Result.TPS, &res.Result.Latency,
 			&res.Result.Errors, &res.Result.Reconnects, &res.Result.Time, &res.Result.Threads, &res.Result.QPS.ID,
 			&res.Result.QPS.Total, &res.Result.QPS.Reads, &res.Result.QPS.Writes, &res.Result.QPS.Other)
 		if err != nil {
@@ -317,11 +353,11 @@ func GetResultsForGitRefAndPlanner(macroType Type, ref string, planner PlannerVe
 		return nil, errors.New(IncorrectMacroBenchmarkType)
 	}
 	upperMacroType := macroType.ToUpper().String()
-	query := "SELECT b.macrobenchmark_id, b.commit, b.source, b.DateTime, IFNULL(b.exec_uuid, ''), " +
+	query := "SELECT b.macrobenchmark_id, b.commit, b.source, b.DateTime, IFNULL(b.exec_uuid, ''), IFNULL(e.component, ''), " +
 		"macrotype.tps, macrotype.latency, macrotype.errors, macrotype.reconnects, macrotype.time, macrotype.threads, " +
 		"qps.qps_no, qps.total_qps, qps.reads_qps, qps.writes_qps, qps.other_qps " +
 		"FROM execution AS e, macrobenchmark AS b, $(MBTYPE) AS macrotype, qps AS qps " +
-		"WHERE e.uuid = b.exec_uuid AND e.status = \"finished\" AND b.commit = ? AND b.vtgate_planner_version = ? AND b.macrobenchmark_id = macrotype.macrobenchmark_id AND macrotype.$(MBTYPE)_no = qps.$(MBTYPE)_no"
+		"WHERE e.uuid = b.exec_uuid AND e.status = \"finished\" AND e.deleted_at IS NULL AND IFNULL(e.benchmark_exit_code, 0) = 0 AND b.commit = ? AND b.vtgate_planner_version = ? AND b.macrobenchmark_id = macrotype.macrobenchmark_id AND macrotype.$(MBTYPE)_no = qps.$(MBTYPE)_no"
 
 	query = strings.ReplaceAll(query, "$(MBTYPE)", upperMacroType)
 
@@ -330,6 +366,40 @@ func GetResultsForGitRefAndPlanner(macroType Type, ref string, planner PlannerVe
 		return nil, err
 	}
 	defer result.Close()
+	for result.Next() {
+		var res Details
+		err = result.Scan(&res.ID, &res.GitRef, &res.Source, &res.CreatedAt, &res.ExecUUID, &res.Component, &res.Result.TPS, &res.Result.Latency,
+			&res.Result.Errors, &res.Result.Reconnects, &res.Result.Time, &res.Result.Threads, &res.Result.QPS.ID,
+			&res.Result.QPS.Total, &res.Result.QPS.Reads, &res.Result.QPS.Writes, &res.Result.QPS.Other)
+		if err != nil {
+			return nil, err
+		}
+		macrodetails = append(macrodetails, res)
+	}
+	return macrodetails, nil
+}
+
+// GetResultsForExecution returns the results of the macro benchmarks of the given Type that
+// were run by the execution execUUID, regardless of the status of the execution, along with
+// their execution metrics. The type must be either OLTP or TPCC.
+func GetResultsForExecution(macroType Type, execUUID string, client storage.SQLClient) (macrodetails DetailsArray, err error) {
+	if macroType != OLTP && macroType != TPCC {
+		return nil, errors.New(IncorrectMacroBenchmarkType)
+	}
+	upperMacroType := macroType.ToUpper().String()
+	query := "SELECT b.macrobenchmark_id, b.commit, b.source, b.DateTime, IFNULL(b.exec_uuid, ''), " +
+		"macrotype.tps, macrotype.latency, macrotype.errors, macrotype.reconnects, macrotype.time, macrotype.threads, " +
+		"qps.qps_no, qps.total_qps, qps.reads_qps, qps.writes_qps, qps.other_qps " +
+		"FROM macrobenchmark AS b, $(MBTYPE) AS macrotype, qps AS qps " +
+		"WHERE b.exec_uuid = ? AND b.macrobenchmark_id = macrotype.macrobenchmark_id AND macrotype.$(MBTYPE)_no = qps.$(MBTYPE)_no"
+
+	query = strings.ReplaceAll(query, "$(MBTYPE)", upperMacroType)
+
+	result, err := client.Select(query, execUUID)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
 	for result.Next() {
 		var res Details
 		err = result.Scan(&res.ID, &res.GitRef, &res.Source, &res.CreatedAt, &res.ExecUUID, &res.Result.TPS, &res.Result.Latency,
@@ -340,6 +410,12 @@ func GetResultsForGitRefAndPlanner(macroType Type, ref string, planner PlannerVe
 		}
 		macrodetails = append(macrodetails, res)
 	}
+	for i := range macrodetails {
+		macrodetails[i].Metrics, err = metrics.GetExecutionMetricsSQL(client, execUUID)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return macrodetails, nil
 }
 
@@ -211,3 +211,26 @@ func TestCompareDetailsArrays(t *testing.T) {
 		})
 	}
 }
+
+func TestDetailsArray_FilterByComponent(t *testing.T) {
+	details := DetailsArray{
+		{GitRef: "1", Component: "vtgate"},
+		{GitRef: "2", Component: "vttablet"},
+		{GitRef: "3"},
+	}
+	tests := []struct {
+		name      string
+		component string
+		want      DetailsArray
+	}{
+		{name: "No component", component: "", want: details},
+		{name: "VTGate", component: "vtgate", want: DetailsArray{{GitRef: "1", Component: "vtgate"}}},
+		{name: "Unknown component", component: "vtctld", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			c.Assert(details.FilterByComponent(tt.component), qt.DeepEquals, tt.want)
+		})
+	}
+}
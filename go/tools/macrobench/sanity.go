@@ -0,0 +1,109 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package macrobench
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SanityBound is the range of plausible values of a canonical metric of a Result.
+type SanityBound struct {
+	Min, Max float64
+}
+
+// ParseSanityBounds parses the sanity bounds of canonical metrics given as "min:max",
+// such as "1:" for qps.total or ":1000" for latency. An omitted bound is not checked.
+func ParseSanityBounds(bounds map[string]string) (map[string]SanityBound, error) {
+	parsed := make(map[string]SanityBound, len(bounds))
+	for metric, value := range bounds {
+		if !canonicalFields[metric] {
+			return nil, fmt.Errorf("unknown metric %q in sanity bounds", metric)
+		}
+		i := strings.Index(value, ":")
+		if i == -1 {
+			return nil, fmt.Errorf("invalid sanity bound %q for metric %q, expected min:max", value, metric)
+		}
+		bound := SanityBound{Min: math.Inf(-1), Max: math.Inf(1)}
+		var err error
+		if min := strings.TrimSpace(value[:i]); min != "" {
+			bound.Min, err = strconv.ParseFloat(min, 64)
+		}
+		if max := strings.TrimSpace(value[i+1:]); err == nil && max != "" {
+			bound.Max, err = strconv.ParseFloat(max, 64)
+		}
+		if err != nil || bound.Min > bound.Max {
+			return nil, fmt.Errorf("invalid sanity bound %q for metric %q", value, metric)
+		}
+		parsed[metric] = bound
+	}
+	return parsed, nil
+}
+
+// CheckSanityBounds returns a description of each metric of the DetailsArray whose value is out
+// of its bound. Having no result at all is reported too, as long as some bounds are given.
+func (mabd DetailsArray) CheckSanityBounds(bounds map[string]SanityBound) ([]string, error) {
+	if len(bounds) == 0 {
+		return nil, nil
+	}
+	if len(mabd) == 0 {
+		return []string{"no results"}, nil
+	}
+	var violations []string
+	for _, details := range mabd {
+		content, err := json.Marshal(details.Result)
+		if err != nil {
+			return nil, err
+		}
+		var object map[string]interface{}
+		err = json.Unmarshal(content, &object)
+		if err != nil {
+			return nil, err
+		}
+		for metric, bound := range bounds {
+			value, ok := lookupField(object, strings.Split(metric, "."))
+			if !ok {
+				continue
+			}
+			number, ok := value.(float64)
+			if !ok {
+				continue
+			}
+			switch {
+			case number < bound.Min:
+				violations = append(violations, fmt.Sprintf("%s is %g, below %g", metric, number, bound.Min))
+			case number > bound.Max:
+				violations = append(violations, fmt.Sprintf("%s is %g, above %g", metric, number, bound.Max))
+			}
+		}
+	}
+	// the runs of a misconfigured execution usually report the same implausible values
+	sort.Strings(violations)
+	unique := violations[:0]
+	for i, violation := range violations {
+		if i == 0 || violation != violations[i-1] {
+			unique = append(unique, violation)
+		}
+	}
+	return unique, nil
+}
@@ -0,0 +1,105 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package macrobench
+
+import (
+	"math"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestParseSanityBounds(t *testing.T) {
+	tests := []struct {
+		name    string
+		bounds  map[string]string
+		want    map[string]SanityBound
+		wantErr string
+	}{
+		{name: "no bounds", bounds: map[string]string{}, want: map[string]SanityBound{}},
+		{
+			name:   "open bounds",
+			bounds: map[string]string{"qps.total": "1:", "latency": ":1000", "tps": "10:5000"},
+			want: map[string]SanityBound{
+				"qps.total": {Min: 1, Max: math.Inf(1)},
+				"latency":   {Min: math.Inf(-1), Max: 1000},
+				"tps":       {Min: 10, Max: 5000},
+			},
+		},
+		{name: "unknown metric", bounds: map[string]string{"qps": "1:"}, wantErr: `unknown metric "qps" in sanity bounds`},
+		{name: "missing separator", bounds: map[string]string{"tps": "1"}, wantErr: `invalid sanity bound "1" for metric "tps", expected min:max`},
+		{name: "invalid number", bounds: map[string]string{"tps": "a:"}, wantErr: `invalid sanity bound "a:" for metric "tps"`},
+		{name: "min above max", bounds: map[string]string{"tps": "10:1"}, wantErr: `invalid sanity bound "10:1" for metric "tps"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			got, err := ParseSanityBounds(tt.bounds)
+			if tt.wantErr != "" {
+				c.Assert(err, qt.ErrorMatches, tt.wantErr)
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			c.Assert(got, qt.DeepEquals, tt.want)
+		})
+	}
+}
+
+func TestDetailsArray_CheckSanityBounds(t *testing.T) {
+	bounds := map[string]SanityBound{
+		"qps.total": {Min: 1, Max: math.Inf(1)},
+		"latency":   {Min: math.Inf(-1), Max: 1000},
+	}
+	tests := []struct {
+		name    string
+		details DetailsArray
+		bounds  map[string]SanityBound
+		want    []string
+	}{
+		{name: "no bounds", details: DetailsArray{}, bounds: map[string]SanityBound{}},
+		{name: "no results", details: DetailsArray{}, bounds: bounds, want: []string{"no results"}},
+		{
+			name:    "plausible results",
+			details: DetailsArray{{Result: Result{QPS: QPS{Total: 500}, Latency: 20}}},
+			bounds:  bounds,
+			want:    []string{},
+		},
+		{
+			name: "implausible results",
+			details: DetailsArray{
+				{Result: Result{QPS: QPS{Total: 0}, Latency: 20}},
+				{Result: Result{QPS: QPS{Total: 0}, Latency: 5000}},
+			},
+			bounds: bounds,
+			want:   []string{"latency is 5000, above 1000", "qps.total is 0, below 1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			got, err := tt.details.CheckSanityBounds(tt.bounds)
+			c.Assert(err, qt.IsNil)
+			if len(tt.want) == 0 {
+				c.Assert(got, qt.HasLen, 0)
+				return
+			}
+			c.Assert(got, qt.DeepEquals, tt.want)
+		})
+	}
+}
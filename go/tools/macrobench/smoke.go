@@ -0,0 +1,61 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package macrobench
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// smokeArgs returns the sysbench arguments of the smoke benchmark: the ones of
+// the run step, whose duration is replaced by SmokeTime.
+func (mabcfg Config) smokeArgs() []string {
+	m := make(map[string]string, len(mabcfg.M)+1)
+	for k, v := range mabcfg.M {
+		m[k] = v
+	}
+	m[stepRun+"_time"] = strconv.Itoa(mabcfg.SmokeTime)
+	return buildSysbenchArgString(m, stepRun)
+}
+
+// checkSmokeResults returns an error if the results of the smoke benchmark are
+// implausible, such as a zero QPS, meaning the full run is not worth executing.
+func (mabcfg Config) checkSmokeResults(resStr []byte) error {
+	resStr, err := mabcfg.FieldMapping.normalize(resStr)
+	if err != nil {
+		return fmt.Errorf("smoke benchmark: normalize results: %w", err)
+	}
+	var results []Result
+	err = json.Unmarshal(resStr, &results)
+	if err != nil {
+		return fmt.Errorf("smoke benchmark: unmarshal results: %w", err)
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("smoke benchmark: %s", ErrorNoSysBenchResult)
+	}
+	result := ResultsArray(results).mergeMedian()
+	if result.QPS.Total < mabcfg.SmokeMinQPS {
+		return fmt.Errorf("smoke benchmark: total QPS %.2f is below the minimum of %.2f", result.QPS.Total, mabcfg.SmokeMinQPS)
+	}
+	if result.TPS < mabcfg.SmokeMinTPS {
+		return fmt.Errorf("smoke benchmark: TPS %.2f is below the minimum of %.2f", result.TPS, mabcfg.SmokeMinTPS)
+	}
+	return nil
+}
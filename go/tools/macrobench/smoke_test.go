@@ -0,0 +1,64 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package macrobench
+
+import (
+	"sort"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestConfig_smokeArgs(t *testing.T) {
+	c := qt.New(t)
+	mabcfg := Config{SmokeTime: 10, M: map[string]string{"all_threads": "100", "run_time": "900", "run_report_json": "true", "warmup_time": "60"}}
+	got := mabcfg.smokeArgs()
+	sort.Strings(got)
+	c.Assert(got, qt.DeepEquals, []string{"--report_json=true", "--threads=100", "--time=10"})
+	c.Assert(mabcfg.M["run_time"], qt.Equals, "900")
+}
+
+func TestConfig_checkSmokeResults(t *testing.T) {
+	tests := []struct {
+		name    string
+		minQPS  float64
+		minTPS  float64
+		results string
+		wantErr string
+	}{
+		{name: "Plausible results", minQPS: 1, results: `[{"qps":{"total":1500},"tps":75}]`},
+		{name: "Zero QPS", minQPS: 1, results: `[{"qps":{"total":0},"tps":0}]`, wantErr: "smoke benchmark: total QPS 0.00 is below the minimum of 1.00"},
+		{name: "TPS below the minimum", minQPS: 1, minTPS: 100, results: `[{"qps":{"total":1500},"tps":75}]`, wantErr: "smoke benchmark: TPS 75.00 is below the minimum of 100.00"},
+		{name: "Checks disabled", results: `[{"qps":{"total":0},"tps":0}]`},
+		{name: "No results", minQPS: 1, results: `[]`, wantErr: "smoke benchmark: " + ErrorNoSysBenchResult},
+		{name: "Invalid results", minQPS: 1, results: `FATAL: error`, wantErr: "smoke benchmark: unmarshal results: .*"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			mabcfg := Config{SmokeMinQPS: tt.minQPS, SmokeMinTPS: tt.minTPS}
+			err := mabcfg.checkSmokeResults([]byte(tt.results))
+			if tt.wantErr == "" {
+				c.Assert(err, qt.IsNil)
+				return
+			}
+			c.Assert(err, qt.ErrorMatches, tt.wantErr)
+		})
+	}
+}
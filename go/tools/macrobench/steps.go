@@ -25,6 +25,7 @@ type step struct {
 
 const (
 	stepPrepare = "prepare"
+	stepSmoke   = "smoke"
 	stepWarmUp  = "warmup"
 	stepRun     = "run"
 )
@@ -32,6 +33,7 @@ const (
 var (
 	steps = []step{
 		{Name: stepPrepare, SysbenchName: stepPrepare},
+		{Name: stepSmoke, SysbenchName: stepRun},
 		{Name: stepWarmUp, SysbenchName: stepRun},
 		{Name: stepRun, SysbenchName: stepRun},
 	}
@@ -0,0 +1,75 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package macrobench
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/vitessio/arewefastyet/go/exec/verification"
+	"github.com/vitessio/arewefastyet/go/storage/psdb"
+)
+
+// defaultClusterUser is the user sysbench connects to the benchmarked cluster with,
+// unless the mysql-user option is set.
+const defaultClusterUser = "sbtest"
+
+// verify runs the VerificationQuery against the benchmarked cluster and records on the
+// execution why its result does not match VerificationExpected, if it does not.
+func (mabcfg Config) verify(sqlClient *psdb.Client) error {
+	failure, err := mabcfg.verificationFailure()
+	if err != nil || failure == "" {
+		return err
+	}
+	log.Println(failure)
+	if sqlClient == nil {
+		return nil
+	}
+	return verification.Record(sqlClient, mabcfg.execUUID, failure)
+}
+
+// verificationFailure returns why the verification failed, or an empty string if it passed.
+// A verification query that cannot be run is a failure of the verification.
+func (mabcfg Config) verificationFailure() (string, error) {
+	db, err := sql.Open("mysql", mabcfg.clusterDSN())
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+
+	result, err := verification.Run(db, mabcfg.VerificationQuery)
+	if err != nil {
+		return fmt.Sprintf("the verification query failed: %v", err), nil
+	}
+	return verification.Check(result, mabcfg.VerificationExpected)
+}
+
+// clusterDSN returns the DSN of the benchmarked cluster, built from the MySQL
+// connection options used by sysbench in all its steps.
+func (mabcfg Config) clusterDSN() string {
+	option := func(name, defaultValue string) string {
+		if value := mabcfg.M["all_mysql-"+name]; value != "" {
+			return value
+		}
+		return defaultValue
+	}
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s", option("user", defaultClusterUser), option("password", ""),
+		option("host", "127.0.0.1"), option("port", "3306"), option("db", "sbtest"))
+}
@@ -0,0 +1,45 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package macrobench
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestConfig_clusterDSN(t *testing.T) {
+	tests := []struct {
+		name string
+		m    map[string]string
+		want string
+	}{
+		{name: "defaults", m: map[string]string{}, want: "sbtest:@tcp(127.0.0.1:3306)/sbtest"},
+		{
+			name: "sysbench options",
+			m:    map[string]string{"all_mysql-host": "10.0.0.1", "all_mysql-port": "13306", "all_mysql-db": "main", "all_mysql-user": "root", "all_mysql-password": "pass", "run_mysql-host": "ignored"},
+			want: "root:pass@tcp(10.0.0.1:13306)/main",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qt.Assert(t, Config{M: tt.m}.clusterDSN(), qt.Equals, tt.want)
+		})
+	}
+}
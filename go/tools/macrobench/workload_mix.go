@@ -0,0 +1,361 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package macrobench
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/vitessio/arewefastyet/go/exec/exitcode"
+	"github.com/vitessio/arewefastyet/go/storage"
+	"github.com/vitessio/arewefastyet/go/storage/mysql"
+)
+
+// MetadataWorkloadMix is the metadata key storing the workload mix an execution
+// ran, see WorkloadMix.String. It is not set for the executions without a mix.
+const MetadataWorkloadMix = "workload_mix"
+
+type (
+	// Workload is a sysbench workload of a WorkloadMix and its share of the mix.
+	Workload struct {
+		Path  string  `json:"workload"`
+		Ratio float64 `json:"ratio"`
+	}
+
+	// WorkloadMix is a set of sysbench workloads run concurrently during the run step,
+	// such as a read-only and a write-only workload. The threads of the run step are split
+	// between the workloads according to their Ratio.
+	WorkloadMix []Workload
+
+	// WorkloadResult is the Result of a single Workload of a WorkloadMix, along with
+	// the exit code of its sysbench process and its latency histogram, if any.
+	WorkloadResult struct {
+		Workload
+		Result    Result    `json:"result"`
+		ExitCode  int       `json:"exit_code"`
+		Histogram Histogram `json:"histogram,omitempty"`
+	}
+
+	// workloadMixResult is the aggregated result of a WorkloadMix, formatted like the
+	// JSON output of sysbench.
+	workloadMixResult struct {
+		Result
+		Histogram Histogram `json:"histogram,omitempty"`
+	}
+
+	// WorkloadComparison compares the results of the same workload in two executions.
+	// The mix ratio of both executions is given along with the difference of their results,
+	// computed like in CompareDetailsArrays. A workload that was only run by one of the
+	// executions has an empty Diff.
+	WorkloadComparison struct {
+		Workload       string  `json:"workload"`
+		ReferenceRatio float64 `json:"reference_ratio"`
+		CompareRatio   float64 `json:"compare_ratio"`
+		Reference      Result  `json:"reference"`
+		Compare        Result  `json:"compare"`
+		Diff           Result  `json:"diff"`
+	}
+)
+
+// ParseWorkloadMix parses a workload mix given as the weight of each workload path, such as
+// {"oltp_read_only.lua": "70", "oltp_write_only.lua": "30"}. Weights are normalized into
+// ratios summing to one. The workloads are sorted by path.
+func ParseWorkloadMix(weights map[string]string) (WorkloadMix, error) {
+	var mix WorkloadMix
+	var sum float64
+	for path, weight := range weights {
+		w, err := strconv.ParseFloat(weight, 64)
+		if err != nil || w <= 0 {
+			return nil, fmt.Errorf("invalid weight %q for workload %s, it must be a positive number", weight, path)
+		}
+		mix = append(mix, Workload{Path: path, Ratio: w})
+		sum += w
+	}
+	for i := range mix {
+		mix[i].Ratio /= sum
+	}
+	sort.Slice(mix, func(i, j int) bool {
+		return mix[i].Path < mix[j].Path
+	})
+	return mix, nil
+}
+
+// String formats the mix as a sorted list of workload=ratio pairs, making two
+// equal mixes have the same representation.
+func (mix WorkloadMix) String() string {
+	pairs := make([]string, 0, len(mix))
+	for _, workload := range mix {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", workload.Path, strconv.FormatFloat(workload.Ratio, 'f', -1, 64)))
+	}
+	return strings.Join(pairs, ",")
+}
+
+// recordWorkloadMix stores the mix in the metadata of the execution execUUID. Nothing is
+// stored if the benchmark is not linked to an execution or if it does not run a mix.
+func recordWorkloadMix(client storage.SQLClient, execUUID string, mix WorkloadMix) error {
+	if execUUID == "" || len(mix) == 0 {
+		return nil
+	}
+	query := "INSERT INTO execution_metadata(exec_uuid, metadata_key, metadata_value) VALUES(?, ?, ?) ON DUPLICATE KEY UPDATE metadata_value = ?"
+	_, err := client.Insert(query, execUUID, MetadataWorkloadMix, mix.String(), mix.String())
+	return err
+}
+
+// threads splits total threads between the workloads of the mix according to their ratio,
+// each workload getting at least one thread.
+func (mix WorkloadMix) threads(total int) []int {
+	threads := make([]int, len(mix))
+	for i, workload := range mix {
+		threads[i] = int(math.Round(workload.Ratio * float64(total)))
+		if threads[i] < 1 {
+			threads[i] = 1
+		}
+	}
+	return threads
+}
+
+// args returns the sysbench arguments of each workload of the mix from the arguments
+// of the run step, replacing the --threads argument by the share of the workload.
+func (mix WorkloadMix) args(args []string, sysbenchName string) [][]string {
+	total := 1
+	var common []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--threads=") {
+			if n, err := strconv.Atoi(strings.TrimPrefix(arg, "--threads=")); err == nil {
+				total = n
+			}
+			continue
+		}
+		common = append(common, arg)
+	}
+	threads := mix.threads(total)
+	workloadArgs := make([][]string, len(mix))
+	for i, workload := range mix {
+		workloadArgs[i] = append(append([]string{}, common...), fmt.Sprintf("--threads=%d", threads[i]), workload.Path, sysbenchName)
+	}
+	return workloadArgs
+}
+
+// runWorkloadMix runs all the workloads of the mix concurrently and returns the output and
+// the exit code of each of them. A workload exiting with a non-zero code is not an error,
+// the error is the one of the first workload that could not run, if any.
+func (mabcfg Config) runWorkloadMix(mix WorkloadMix, args []string, sysbenchName string) ([][]byte, []int, error) {
+	outs := make([][]byte, len(mix))
+	codes := make([]int, len(mix))
+	errs := make([]error, len(mix))
+	var wg sync.WaitGroup
+	for i, workloadArgs := range mix.args(args, sysbenchName) {
+		wg.Add(1)
+		go func(i int, workloadArgs []string) {
+			defer wg.Done()
+			command := exec.Command(mabcfg.SysbenchExec, workloadArgs...)
+			command.Dir = mabcfg.WorkingDirectory
+			outs[i], errs[i] = command.Output()
+		}(i, workloadArgs)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		code, ok := exitcode.FromError(err)
+		if !ok {
+			return outs, codes, fmt.Errorf("workload %s: %w", mix[i].Path, err)
+		}
+		codes[i] = code
+	}
+	return outs, codes, nil
+}
+
+// exitCode returns the exit code of the whole mix: the first non-zero exit code of its
+// workloads, or zero if all of them succeeded.
+func exitCode(codes []int) int {
+	for _, code := range codes {
+		if code != 0 {
+			return code
+		}
+	}
+	return 0
+}
+
+// workloadResults parses the output of each workload of the mix, normalized by the FieldMapping,
+// along with their latency histogram. The exit code of each workload is taken from codes.
+func (mix WorkloadMix) workloadResults(outs [][]byte, codes []int, fm FieldMapping) ([]WorkloadResult, error) {
+	results := make([]WorkloadResult, 0, len(mix))
+	for i, workload := range mix {
+		out, err := fm.normalize(outs[i])
+		if err != nil {
+			return nil, fmt.Errorf("normalize results of workload %s: %w", workload.Path, err)
+		}
+		var res []Result
+		err = json.Unmarshal(out, &res)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshal results of workload %s: %w", workload.Path, err)
+		}
+		if len(res) == 0 {
+			return nil, fmt.Errorf("%s: %s", ErrorNoSysBenchResult, workload.Path)
+		}
+		var histograms []sysbenchHistogram
+		err = json.Unmarshal(out, &histograms)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshal histogram of workload %s: %w", workload.Path, err)
+		}
+		wr := WorkloadResult{Workload: workload, Result: res[0], Histogram: histograms[0].Histogram}
+		if i < len(codes) {
+			wr.ExitCode = codes[i]
+		}
+		results = append(results, wr)
+	}
+	return results, nil
+}
+
+// aggregateWorkloadResults returns the Result of the whole mix: throughputs, errors, reconnects
+// and threads are summed, the latency is the average of the latencies of the workloads weighted
+// by their TPS, and the time is the longest of the workloads.
+func aggregateWorkloadResults(results []WorkloadResult) Result {
+	var aggregate Result
+	var weightedLatency float64
+	for _, res := range results {
+		aggregate.QPS.Total += res.Result.QPS.Total
+		aggregate.QPS.Reads += res.Result.QPS.Reads
+		aggregate.QPS.Writes += res.Result.QPS.Writes
+		aggregate.QPS.Other += res.Result.QPS.Other
+		aggregate.TPS += res.Result.TPS
+		aggregate.Errors += res.Result.Errors
+		aggregate.Reconnects += res.Result.Reconnects
+		aggregate.Threads += res.Result.Threads
+		weightedLatency += res.Result.Latency * res.Result.TPS
+		if res.Result.Time > aggregate.Time {
+			aggregate.Time = res.Result.Time
+		}
+	}
+	if aggregate.TPS > 0 {
+		aggregate.Latency = weightedLatency / aggregate.TPS
+	}
+	return aggregate
+}
+
+// aggregateWorkloadHistograms returns the latency histogram of the whole mix, the counts of
+// the buckets of the workloads with the same latency are summed. The buckets are sorted by latency.
+func aggregateWorkloadHistograms(results []WorkloadResult) Histogram {
+	counts := map[float64]int{}
+	for _, res := range results {
+		for _, bucket := range res.Histogram {
+			counts[bucket.Latency] += bucket.Count
+		}
+	}
+	aggregate := make(Histogram, 0, len(counts))
+	for latency, count := range counts {
+		aggregate = append(aggregate, HistogramBucket{Latency: latency, Count: count})
+	}
+	sort.Slice(aggregate, func(i, j int) bool {
+		return aggregate[i].Latency < aggregate[j].Latency
+	})
+	return aggregate
+}
+
+// insertToMySQL inserts the result of the workload for the given macrobenchmark.
+func (wr WorkloadResult) insertToMySQL(macrobenchmarkID int, client storage.SQLClient) error {
+	if client == nil {
+		return errors.New(mysql.ErrorClientConnectionNotInitialized)
+	}
+	_, err := client.Insert("INSERT INTO macrobenchmark_workload(macrobenchmark_id, workload, ratio, tps, latency, errors, reconnects, time, threads, total_qps, reads_qps, writes_qps, other_qps, exit_code) "+
+		"VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		macrobenchmarkID, wr.Path, wr.Ratio, wr.Result.TPS, wr.Result.Latency, wr.Result.Errors, wr.Result.Reconnects,
+		wr.Result.Time, wr.Result.Threads, wr.Result.QPS.Total, wr.Result.QPS.Reads, wr.Result.QPS.Writes, wr.Result.QPS.Other, wr.ExitCode)
+	return err
+}
+
+// GetWorkloadResultsForExecution returns the result of each workload of the mix run by the
+// execution execUUID, sorted by workload. No result is returned if the execution did not
+// run a workload mix.
+func GetWorkloadResultsForExecution(execUUID string, client storage.SQLClient) ([]WorkloadResult, error) {
+	query := "SELECT w.workload, w.ratio, w.tps, w.latency, w.errors, w.reconnects, w.time, w.threads, w.total_qps, w.reads_qps, w.writes_qps, w.other_qps, IFNULL(w.exit_code, 0) " +
+		"FROM macrobenchmark AS b, macrobenchmark_workload AS w " +
+		"WHERE b.exec_uuid = ? AND b.macrobenchmark_id = w.macrobenchmark_id ORDER BY w.workload"
+	result, err := client.Select(query, execUUID)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	var results []WorkloadResult
+	for result.Next() {
+		var wr WorkloadResult
+		err = result.Scan(&wr.Path, &wr.Ratio, &wr.Result.TPS, &wr.Result.Latency, &wr.Result.Errors, &wr.Result.Reconnects,
+			&wr.Result.Time, &wr.Result.Threads, &wr.Result.QPS.Total, &wr.Result.QPS.Reads, &wr.Result.QPS.Writes, &wr.Result.QPS.Other, &wr.ExitCode)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, wr)
+	}
+	return results, nil
+}
+
+// CompareWorkloadResults compares the per-workload results of two executions, matching
+// their workloads by path. The comparisons are sorted by workload.
+func CompareWorkloadResults(references, compares []WorkloadResult) []WorkloadComparison {
+	byWorkload := map[string]*WorkloadComparison{}
+	var workloads []string
+	get := func(path string) *WorkloadComparison {
+		cmp, ok := byWorkload[path]
+		if !ok {
+			cmp = &WorkloadComparison{Workload: path}
+			byWorkload[path] = cmp
+			workloads = append(workloads, path)
+		}
+		return cmp
+	}
+	for _, reference := range references {
+		cmp := get(reference.Path)
+		cmp.ReferenceRatio = reference.Ratio
+		cmp.Reference = reference.Result
+	}
+	for _, compare := range compares {
+		cmp := get(compare.Path)
+		cmp.CompareRatio = compare.Ratio
+		cmp.Compare = compare.Result
+	}
+	sort.Strings(workloads)
+
+	comparisons := make([]WorkloadComparison, 0, len(workloads))
+	for _, workload := range workloads {
+		cmp := byWorkload[workload]
+		if cmp.ReferenceRatio != 0 && cmp.CompareRatio != 0 {
+			cmp.Diff = diffResults(cmp.Reference, cmp.Compare)
+		}
+		comparisons = append(comparisons, *cmp)
+	}
+	return comparisons
+}
+
+// SameMix returns true if both executions ran the same workloads with the same ratios,
+// otherwise the differences between their aggregated results may come from the mix itself.
+func SameMix(comparisons []WorkloadComparison) bool {
+	for _, cmp := range comparisons {
+		if cmp.ReferenceRatio != cmp.CompareRatio {
+			return false
+		}
+	}
+	return true
+}
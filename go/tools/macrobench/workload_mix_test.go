@@ -0,0 +1,153 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package macrobench
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestParseWorkloadMix(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights map[string]string
+		want    WorkloadMix
+		wantErr string
+	}{
+		{name: "no mix", weights: map[string]string{}},
+		{name: "weights are normalized", weights: map[string]string{"oltp_write_only": "30", "oltp_read_only": "70"}, want: WorkloadMix{{Path: "oltp_read_only", Ratio: 0.7}, {Path: "oltp_write_only", Ratio: 0.3}}},
+		{name: "invalid weight", weights: map[string]string{"oltp_read_only": "a lot"}, wantErr: `invalid weight "a lot" for workload oltp_read_only, it must be a positive number`},
+		{name: "zero weight", weights: map[string]string{"oltp_read_only": "0"}, wantErr: `invalid weight "0" for workload oltp_read_only, it must be a positive number`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			got, err := ParseWorkloadMix(tt.weights)
+			if tt.wantErr != "" {
+				c.Assert(err, qt.ErrorMatches, tt.wantErr)
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			c.Assert(got, qt.DeepEquals, tt.want)
+		})
+	}
+}
+
+func TestWorkloadMix_String(t *testing.T) {
+	c := qt.New(t)
+	mix := WorkloadMix{{Path: "oltp_read_only", Ratio: 0.7}, {Path: "oltp_write_only", Ratio: 0.3}}
+	c.Assert(mix.String(), qt.Equals, "oltp_read_only=0.7,oltp_write_only=0.3")
+	c.Assert(WorkloadMix(nil).String(), qt.Equals, "")
+}
+
+func TestExitCode(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(exitCode(nil), qt.Equals, 0)
+	c.Assert(exitCode([]int{0, 0}), qt.Equals, 0)
+	c.Assert(exitCode([]int{0, 2, 1}), qt.Equals, 2)
+}
+
+func TestWorkloadMix_args(t *testing.T) {
+	c := qt.New(t)
+	mix := WorkloadMix{{Path: "oltp_read_only", Ratio: 0.75}, {Path: "oltp_write_only", Ratio: 0.25}}
+	c.Assert(mix.args([]string{"--time=60", "--threads=8"}, "run"), qt.DeepEquals, [][]string{
+		{"--time=60", "--threads=6", "oltp_read_only", "run"},
+		{"--time=60", "--threads=2", "oltp_write_only", "run"},
+	})
+
+	// each workload gets at least one thread
+	c.Assert(mix.threads(1), qt.DeepEquals, []int{1, 1})
+}
+
+func TestWorkloadMix_workloadResults(t *testing.T) {
+	c := qt.New(t)
+	mix := WorkloadMix{{Path: "oltp_read_only", Ratio: 0.5}, {Path: "oltp_write_only", Ratio: 0.5}}
+	outs := [][]byte{
+		[]byte(`[{"tps": 10, "latency_us": 2000, "histogram": [{"latency": 1.5, "count": 3}]}]`),
+		[]byte(`[{"tps": 30, "latency_us": 6000}]`),
+	}
+	results, err := mix.workloadResults(outs, []int{0, 2}, FieldMapping{"latency": "latency_us*0.001"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(results, qt.DeepEquals, []WorkloadResult{
+		{Workload: mix[0], Result: Result{TPS: 10, Latency: 2}, Histogram: Histogram{{Latency: 1.5, Count: 3}}},
+		{Workload: mix[1], Result: Result{TPS: 30, Latency: 6}, ExitCode: 2},
+	})
+
+	_, err = mix.workloadResults([][]byte{[]byte(`[]`), []byte(`[]`)}, nil, nil)
+	c.Assert(err, qt.ErrorMatches, ErrorNoSysBenchResult+": oltp_read_only")
+}
+
+func TestAggregateWorkloadResults(t *testing.T) {
+	c := qt.New(t)
+	results := []WorkloadResult{
+		{Result: Result{QPS: QPS{Total: 100, Reads: 100}, TPS: 10, Latency: 2, Errors: 1, Time: 60, Threads: 6}},
+		{Result: Result{QPS: QPS{Total: 90, Writes: 60, Other: 30}, TPS: 30, Latency: 6, Reconnects: 2, Time: 61, Threads: 2}},
+	}
+	c.Assert(aggregateWorkloadResults(results), qt.DeepEquals, Result{
+		QPS:        QPS{Total: 190, Reads: 100, Writes: 60, Other: 30},
+		TPS:        40,
+		Latency:    5,
+		Errors:     1,
+		Reconnects: 2,
+		Time:       61,
+		Threads:    8,
+	})
+	c.Assert(aggregateWorkloadResults(nil), qt.DeepEquals, Result{})
+}
+
+func TestAggregateWorkloadHistograms(t *testing.T) {
+	c := qt.New(t)
+	results := []WorkloadResult{
+		{Histogram: Histogram{{Latency: 1, Count: 2}, {Latency: 3, Count: 1}}},
+		{Histogram: Histogram{{Latency: 2, Count: 4}, {Latency: 3, Count: 5}}},
+		{},
+	}
+	c.Assert(aggregateWorkloadHistograms(results), qt.DeepEquals, Histogram{
+		{Latency: 1, Count: 2},
+		{Latency: 2, Count: 4},
+		{Latency: 3, Count: 6},
+	})
+	c.Assert(aggregateWorkloadHistograms(nil), qt.HasLen, 0)
+}
+
+func TestCompareWorkloadResults(t *testing.T) {
+	c := qt.New(t)
+	references := []WorkloadResult{
+		{Workload: Workload{Path: "oltp_read_only", Ratio: 0.7}, Result: Result{TPS: 100}},
+		{Workload: Workload{Path: "oltp_write_only", Ratio: 0.3}, Result: Result{TPS: 50}},
+	}
+	compares := []WorkloadResult{
+		{Workload: Workload{Path: "oltp_read_only", Ratio: 0.7}, Result: Result{TPS: 90}},
+		{Workload: Workload{Path: "oltp_write_only", Ratio: 0.3}, Result: Result{TPS: 50}},
+	}
+	comparisons := CompareWorkloadResults(references, compares)
+	c.Assert(comparisons, qt.HasLen, 2)
+	c.Assert(comparisons[0].Workload, qt.Equals, "oltp_read_only")
+	c.Assert(comparisons[0].Diff.TPS, qt.Equals, 10.0)
+	c.Assert(comparisons[1].Diff.TPS, qt.Equals, 0.0)
+	c.Assert(SameMix(comparisons), qt.IsTrue)
+
+	// a workload missing on one side is reported without difference
+	comparisons = CompareWorkloadResults(references, compares[:1])
+	c.Assert(comparisons, qt.HasLen, 2)
+	c.Assert(comparisons[1].CompareRatio, qt.Equals, 0.0)
+	c.Assert(comparisons[1].Diff, qt.DeepEquals, Result{})
+	c.Assert(SameMix(comparisons), qt.IsFalse)
+}
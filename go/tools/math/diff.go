@@ -0,0 +1,34 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package math
+
+import "fmt"
+
+// FormatDiff formats the given difference, expressed in percentage, with
+// an indicator telling whether the difference is an improvement or a regression.
+// A positive difference is always an improvement.
+func FormatDiff(diff float64) string {
+	indicator := "⚪"
+	if diff > 0 {
+		indicator = "🟢"
+	} else if diff < 0 {
+		indicator = "🔴"
+	}
+	return fmt.Sprintf("%s %+.2f%%", indicator, diff)
+}
@@ -0,0 +1,42 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package math
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestFormatDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		diff float64
+		want string
+	}{
+		{name: "Improvement", diff: 12.345, want: "🟢 +12.35%"},
+		{name: "Regression", diff: -3.2, want: "🔴 -3.20%"},
+		{name: "No difference", diff: 0, want: "⚪ +0.00%"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qt.Assert(t, FormatDiff(tt.diff), qt.Equals, tt.want)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package math
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		p      float64
+		want   float64
+	}{
+		{name: "No element array", values: nil, p: 50, want: 0},
+		{name: "Single element array", values: []float64{5}, p: 25, want: 5},
+		{name: "Median of odd number of elements", values: []float64{3, 1, 2}, p: 50, want: 2},
+		{name: "Median of even number of elements", values: []float64{4, 1, 3, 2}, p: 50, want: 2.5},
+		{name: "First quartile", values: []float64{10, 20, 30, 40, 50}, p: 25, want: 20},
+		{name: "Third quartile", values: []float64{10, 20, 30, 40, 50}, p: 75, want: 40},
+		{name: "Interpolated percentile", values: []float64{10, 20}, p: 25, want: 12.5},
+		{name: "Minimum", values: []float64{3, 1, 2}, p: 0, want: 1},
+		{name: "Maximum", values: []float64{3, 1, 2}, p: 100, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			c.Assert(Percentile(tt.values, tt.p), qt.Equals, tt.want)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+package math
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// MinSamplesForSignificance is the minimum number of samples required in each
+	// group for MannWhitneyUTest to compute a p-value. With fewer samples, the test
+	// cannot reach a meaningful significance level.
+	MinSamplesForSignificance = 5
+
+	// SignificanceLevel is the p-value under which a difference is considered
+	// statistically significant.
+	SignificanceLevel = 0.05
+)
+
+// MannWhitneyUTest performs a two-sided Mann-Whitney U test on the samples x and y,
+// using the normal approximation with tie and continuity corrections.
+// It returns the p-value of the test, and false if x or y does not contain
+// at least MinSamplesForSignificance samples.
+func MannWhitneyUTest(x, y []float64) (pValue float64, ok bool) {
+	n1, n2 := len(x), len(y)
+	if n1 < MinSamplesForSignificance || n2 < MinSamplesForSignificance {
+		return 0, false
+	}
+
+	type sample struct {
+		value float64
+		fromX bool
+	}
+	samples := make([]sample, 0, n1+n2)
+	for _, v := range x {
+		samples = append(samples, sample{value: v, fromX: true})
+	}
+	for _, v := range y {
+		samples = append(samples, sample{value: v})
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].value < samples[j].value
+	})
+
+	// rank the samples, tied values get the average of their ranks
+	var rankSumX, tieCorrection float64
+	for i := 0; i < len(samples); {
+		j := i
+		for j < len(samples) && samples[j].value == samples[i].value {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if samples[k].fromX {
+				rankSumX += rank
+			}
+		}
+		t := float64(j - i)
+		tieCorrection += t*t*t - t
+		i = j
+	}
+
+	n := float64(n1 + n2)
+	u := rankSumX - float64(n1*(n1+1))/2
+	mu := float64(n1*n2) / 2
+	sigma := math.Sqrt(float64(n1*n2) / 12 * ((n + 1) - tieCorrection/(n*(n-1))))
+	if sigma == 0 {
+		return 1, true
+	}
+	z := math.Max(math.Abs(u-mu)-0.5, 0) / sigma
+	return math.Erfc(z / math.Sqrt2), true
+}
+
+// IsSignificant returns true if the given p-value is under SignificanceLevel.
+// A p-value of zero, meaning it could not be computed, is considered significant
+// so that callers fall back on their own threshold.
+func IsSignificant(pValue float64) bool {
+	return pValue < SignificanceLevel
+}
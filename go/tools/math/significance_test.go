@@ -0,0 +1,46 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+package math
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestMannWhitneyUTest(t *testing.T) {
+	tests := []struct {
+		name     string
+		x, y     []float64
+		wantOk   bool
+		wantSign bool
+	}{
+		{name: "Not enough samples", x: []float64{1, 2, 3}, y: []float64{4, 5, 6}, wantOk: false, wantSign: true},
+		{name: "Identical samples", x: []float64{1, 1, 1, 1, 1}, y: []float64{1, 1, 1, 1, 1}, wantOk: true, wantSign: false},
+		{name: "Overlapping samples", x: []float64{1, 3, 5, 7, 9}, y: []float64{2, 4, 6, 8, 10}, wantOk: true, wantSign: false},
+		{name: "Distinct samples", x: []float64{1, 2, 3, 4, 5}, y: []float64{10, 11, 12, 13, 14}, wantOk: true, wantSign: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			p, ok := MannWhitneyUTest(tt.x, tt.y)
+			c.Assert(ok, qt.Equals, tt.wantOk)
+			c.Assert(IsSignificant(p), qt.Equals, tt.wantSign)
+		})
+	}
+}
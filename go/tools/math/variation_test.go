@@ -0,0 +1,48 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package math
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestCoefficientOfVariation(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   float64
+		wantOk bool
+	}{
+		{name: "Empty series", values: nil},
+		{name: "Single element series", values: []float64{1}},
+		{name: "Zero mean", values: []float64{-1, 1}},
+		{name: "Constant series", values: []float64{5, 5, 5}, want: 0, wantOk: true},
+		{name: "Noisy series", values: []float64{90, 100, 110}, want: 0.1, wantOk: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			got, ok := CoefficientOfVariation(tt.values)
+			c.Assert(ok, qt.Equals, tt.wantOk)
+			c.Assert(got, qt.Equals, tt.want)
+		})
+	}
+}
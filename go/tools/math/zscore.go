@@ -0,0 +1,44 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package math
+
+import "math"
+
+// ZScore computes the number of standard deviations separating value from the
+// mean of series, using the sample standard deviation of series. ok is false if
+// series has less than two values or no variation, the z-score being undefined.
+func ZScore(value float64, series []float64) (z float64, ok bool) {
+	if len(series) < 2 {
+		return 0, false
+	}
+	var sum float64
+	for _, v := range series {
+		sum += v
+	}
+	mean := sum / float64(len(series))
+	var squares float64
+	for _, v := range series {
+		squares += (v - mean) * (v - mean)
+	}
+	stdDev := math.Sqrt(squares / float64(len(series)-1))
+	if stdDev == 0 {
+		return 0, false
+	}
+	return (value - mean) / stdDev, true
+}
@@ -0,0 +1,50 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package math
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestZScore(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  float64
+		series []float64
+		want   float64
+		wantOk bool
+	}{
+		{name: "Empty series", value: 1, series: nil},
+		{name: "Single element series", value: 1, series: []float64{1}},
+		{name: "Constant series", value: 2, series: []float64{1, 1, 1}},
+		{name: "Value at the mean", value: 2, series: []float64{1, 2, 3}, want: 0, wantOk: true},
+		{name: "Value above the mean", value: 4, series: []float64{1, 2, 3}, want: 2, wantOk: true},
+		{name: "Value below the mean", value: -1, series: []float64{1, 2, 3}, want: -3, wantOk: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			got, ok := ZScore(tt.value, tt.series)
+			c.Assert(ok, qt.Equals, tt.wantOk)
+			c.Assert(got, qt.Equals, tt.want)
+		})
+	}
+}
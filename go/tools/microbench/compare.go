@@ -21,47 +21,177 @@ package microbench
 import (
 	"fmt"
 	"github.com/vitessio/arewefastyet/go/storage"
+	"github.com/vitessio/arewefastyet/go/tools/math"
 )
 
+// DefaultRegressionThreshold is the decrease, in percentage, from which
+// a microbenchmark metric is considered as a regression.
+const DefaultRegressionThreshold = 10.0
+
 // Compare takes in 3 arguments, the database, and 2 SHAs. It reads from the database, the microbenchmark
 // results for the 2 SHAs and compares them. The result is a comparison array.
 func Compare(client storage.SQLClient, reference string, compare string) (ComparisonArray, error) {
+	return CompareForComponent(client, reference, compare, "")
+}
+
+// CompareForComponent works like Compare but only uses the results of the executions
+// that focused on the given vitess component. All results are used if component is empty.
+func CompareForComponent(client storage.SQLClient, reference, compare, component string) (ComparisonArray, error) {
 	// compare micro benchmarks
 	SHAs := []string{reference, compare}
 	micros := map[string]DetailsArray{}
+	runs := map[string]DetailsArray{}
 	for _, sha := range SHAs {
 		micro, err := GetResultsForGitRef(sha, client)
 		if err != nil {
 			return nil, err
 		}
+		micro = micro.FilterByComponent(component)
+		runs[sha] = append(DetailsArray{}, micro...)
 		micros[sha] = micro.ReduceSimpleMedianByName()
 	}
 	microsMatrix := MergeDetails(micros[reference], micros[compare])
+	for i := range microsMatrix {
+		microsMatrix[i].PValue = computePValues(microsMatrix[i].BenchmarkId, runs[reference], runs[compare])
+	}
 	// The result of the merge will be sorted by the package name and then the benchmark name
 	return microsMatrix, nil
 }
 
+// CompareExecutions works like Compare but reads the microbenchmark results of the
+// executions reference and compare instead of the ones of two git refs.
+func CompareExecutions(client storage.SQLClient, reference, compare string) (ComparisonArray, error) {
+	references, err := GetResultsForExecution(reference, client)
+	if err != nil {
+		return nil, err
+	}
+	compares, err := GetResultsForExecution(compare, client)
+	if err != nil {
+		return nil, err
+	}
+	microsMatrix := MergeDetails(
+		append(DetailsArray{}, references...).ReduceSimpleMedianByName(),
+		append(DetailsArray{}, compares...).ReduceSimpleMedianByName(),
+	)
+	for i := range microsMatrix {
+		microsMatrix[i].PValue = computePValues(microsMatrix[i].BenchmarkId, references, compares)
+	}
+	return microsMatrix, nil
+}
+
+// computePValues computes the p-value of each metric of the given benchmark
+// using its individual runs in currents and lasts.
+func computePValues(id BenchmarkId, currents, lasts DetailsArray) (pValue Result) {
+	metric := func(get func(r Result) float64) float64 {
+		var x, y []float64
+		for _, details := range currents {
+			if details.BenchmarkId == id {
+				x = append(x, get(details.Result))
+			}
+		}
+		for _, details := range lasts {
+			if details.BenchmarkId == id {
+				y = append(y, get(details.Result))
+			}
+		}
+		p, _ := math.MannWhitneyUTest(x, y)
+		return p
+	}
+	pValue.Ops = metric(func(r Result) float64 { return r.Ops })
+	pValue.NSPerOp = metric(func(r Result) float64 { return r.NSPerOp })
+	pValue.MBPerSec = metric(func(r Result) float64 { return r.MBPerSec })
+	pValue.BytesPerOp = metric(func(r Result) float64 { return r.BytesPerOp })
+	pValue.AllocsPerOp = metric(func(r Result) float64 { return r.AllocsPerOp })
+	return pValue
+}
+
 // Regression returns a string containing the reason of the regression of the given ComparisonArray,
-// if no regression was evaluated, the reason will be an empty string.
+// if no regression was evaluated, the reason will be an empty string. A decrease is only reported if
+// it is statistically significant, or if there was not enough runs to compute its p-value.
 // The format of a single benchmark regression's reason is like this:
 //
 // "- {pkg name}/{benchmark name} decreased by {decrease percentage}%\n"
 //
+// The global DefaultRegressionThreshold is used for all benchmarks.
 func (microsMatrix ComparisonArray) Regression() (reason string) {
+	return microsMatrix.RegressionWithThresholds(nil)
+}
+
+// RegressionWithThresholds works like Regression, except that the threshold of each
+// benchmark can be overridden using thresholds. Thresholds maps the name or sub benchmark
+// name of a benchmark to its threshold, as a decrease in percentage. Benchmarks that are
+// not listed use DefaultRegressionThreshold.
+func (microsMatrix ComparisonArray) RegressionWithThresholds(thresholds map[string]float64) (reason string) {
+	return microsMatrix.changesWithThresholds(thresholds, nil, false)
+}
+
+// Improvement returns a string containing the reason of the improvement of the given ComparisonArray,
+// if no improvement was evaluated, the reason will be an empty string. It is the opposite of Regression
+// and uses the same thresholds.
+func (microsMatrix ComparisonArray) Improvement() (reason string) {
+	return microsMatrix.ImprovementWithThresholds(nil)
+}
+
+// ImprovementWithThresholds works like Improvement, except that the threshold of each
+// benchmark can be overridden using thresholds, like in RegressionWithThresholds.
+func (microsMatrix ComparisonArray) ImprovementWithThresholds(thresholds map[string]float64) (reason string) {
+	return microsMatrix.changesWithThresholds(thresholds, nil, true)
+}
+
+// RegressionOfMetrics works like RegressionWithThresholds but only reports the changes of
+// the given metrics, see ComparisonMetrics. All the metrics are reported if metrics is empty.
+func (microsMatrix ComparisonArray) RegressionOfMetrics(thresholds map[string]float64, metrics []string) (reason string) {
+	return microsMatrix.changesWithThresholds(thresholds, metrics, false)
+}
+
+// ImprovementOfMetrics works like ImprovementWithThresholds but only reports the changes of
+// the given metrics, see ComparisonMetrics. All the metrics are reported if metrics is empty.
+func (microsMatrix ComparisonArray) ImprovementOfMetrics(thresholds map[string]float64, metrics []string) (reason string) {
+	return microsMatrix.changesWithThresholds(thresholds, metrics, true)
+}
+
+// ComparisonMetrics lists the metrics whose changes are reported by Regression and Improvement.
+var ComparisonMetrics = []string{"ops", "ns/op", "bytes/op", "mb/s", "allocs/op"}
+
+// changesWithThresholds returns the reason of the regressions, or of the improvements if
+// improvement is set, of the given metrics of the ComparisonArray. All the metrics are
+// reported if metrics is empty.
+func (microsMatrix ComparisonArray) changesWithThresholds(thresholds map[string]float64, metrics []string, improvement bool) (reason string) {
+	reported := make(map[string]bool, len(metrics))
+	for _, metric := range metrics {
+		reported[metric] = true
+	}
 	for _, micro := range microsMatrix {
+		threshold := DefaultRegressionThreshold
+		if t, ok := thresholds[micro.SubBenchmarkName]; ok {
+			threshold = t
+		} else if t, ok := thresholds[micro.Name]; ok {
+			threshold = t
+		}
+
 		m := []struct{
 			value float64
+			pValue float64
 			name string
+			metric string
 		}{
-			{name: "total operation", value: micro.Diff.Ops},
-			{name: "nanosecond per operation", value: micro.Diff.NSPerOp},
-			{name: "bytes per operation", value: micro.Diff.BytesPerOp},
-			{name: "MB per second", value: micro.Diff.MBPerSec},
-			{name: "allocations per operation", value: micro.Diff.AllocsPerOp},
+			{name: "total operation", metric: "ops", value: micro.Diff.Ops, pValue: micro.PValue.Ops},
+			{name: "nanosecond per operation", metric: "ns/op", value: micro.Diff.NSPerOp, pValue: micro.PValue.NSPerOp},
+			{name: "bytes per operation", metric: "bytes/op", value: micro.Diff.BytesPerOp, pValue: micro.PValue.BytesPerOp},
+			{name: "MB per second", metric: "mb/s", value: micro.Diff.MBPerSec, pValue: micro.PValue.MBPerSec},
+			{name: "allocations per operation", metric: "allocs/op", value: micro.Diff.AllocsPerOp, pValue: micro.PValue.AllocsPerOp},
 		}
 
 		for _, s := range m {
-			if s.value < -10 {
+			if len(reported) > 0 && !reported[s.metric] {
+				continue
+			}
+			if !math.IsSignificant(s.pValue) {
+				continue
+			}
+			if improvement && s.value > threshold {
+				reason += fmt.Sprintf("- %s/%s: metric: %s, improved by %.2f%%\n", micro.PkgName, micro.SubBenchmarkName, s.name, s.value)
+			} else if !improvement && s.value < -threshold {
 				reason += fmt.Sprintf("- %s/%s: metric: %s, decreased by %.2f%%\n", micro.PkgName, micro.SubBenchmarkName, s.name, -1*s.value)
 			}
 		}
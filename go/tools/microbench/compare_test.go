@@ -59,3 +59,58 @@ func TestMicroBenchmarkComparisonArray_Regression(t *testing.T) {
 		})
 	}
 }
+
+func TestMicroBenchmarkComparisonArray_Markdown(t *testing.T) {
+	c := qt.New(t)
+	microsMatrix := ComparisonArray{
+		{BenchmarkId: BenchmarkId{PkgName: "pkg1", Name: "bench1", SubBenchmarkName: "bench1-pkg1"}, Current: Result{NSPerOp: 100, BytesPerOp: 2000, AllocsPerOp: 3}, Diff: Result{NSPerOp: 12.5, BytesPerOp: -20}},
+	}
+	c.Assert(microsMatrix.Markdown(), qt.Equals, "| Package | Benchmark | ns/op | Diff | B/op | Diff | allocs/op | Diff |\n"+
+		"|---|---|---:|---:|---:|---:|---:|---:|\n"+
+		"| pkg1 | bench1-pkg1 | 100.0 | 🟢 +12.50% | 2.0 kB/op | 🔴 -20.00% | 3  | ⚪ +0.00% |\n")
+}
+
+func TestMicroBenchmarkComparisonArray_RegressionWithThresholds(t *testing.T) {
+	microsMatrix := ComparisonArray{
+		{BenchmarkId: BenchmarkId{PkgName: "pkg1", Name: "bench1", SubBenchmarkName: "bench1-pkg1"}, Diff: Result{NSPerOp: -15}},
+		{BenchmarkId: BenchmarkId{PkgName: "pkg1", Name: "bench2", SubBenchmarkName: "bench2-pkg1"}, Diff: Result{NSPerOp: -15}},
+	}
+	tests := []struct {
+		name       string
+		thresholds map[string]float64
+		wantReason string
+	}{
+		{name: "Default threshold", thresholds: nil, wantReason: "- pkg1/bench1-pkg1: metric: nanosecond per operation, decreased by 15.00%\n- pkg1/bench2-pkg1: metric: nanosecond per operation, decreased by 15.00%\n"},
+		{name: "Threshold by name", thresholds: map[string]float64{"bench1": 20}, wantReason: "- pkg1/bench2-pkg1: metric: nanosecond per operation, decreased by 15.00%\n"},
+		{name: "Threshold by sub benchmark name", thresholds: map[string]float64{"bench2-pkg1": 20}, wantReason: "- pkg1/bench1-pkg1: metric: nanosecond per operation, decreased by 15.00%\n"},
+		{name: "Sub benchmark name has precedence", thresholds: map[string]float64{"bench1": 20, "bench1-pkg1": 5, "bench2": 20}, wantReason: "- pkg1/bench1-pkg1: metric: nanosecond per operation, decreased by 15.00%\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			c.Assert(microsMatrix.RegressionWithThresholds(tt.thresholds), qt.Equals, tt.wantReason)
+		})
+	}
+}
+
+func TestMicroBenchmarkComparisonArray_ImprovementWithThresholds(t *testing.T) {
+	microsMatrix := ComparisonArray{
+		{BenchmarkId: BenchmarkId{PkgName: "pkg1", Name: "bench1", SubBenchmarkName: "bench1-pkg1"}, Diff: Result{NSPerOp: 15}},
+		{BenchmarkId: BenchmarkId{PkgName: "pkg1", Name: "bench2", SubBenchmarkName: "bench2-pkg1"}, Diff: Result{NSPerOp: -15, BytesPerOp: 10}},
+	}
+	tests := []struct {
+		name       string
+		thresholds map[string]float64
+		wantReason string
+	}{
+		{name: "Default threshold", thresholds: nil, wantReason: "- pkg1/bench1-pkg1: metric: nanosecond per operation, improved by 15.00%\n"},
+		{name: "Threshold by name", thresholds: map[string]float64{"bench1": 20}, wantReason: ""},
+		{name: "Lower threshold", thresholds: map[string]float64{"bench2-pkg1": 5}, wantReason: "- pkg1/bench1-pkg1: metric: nanosecond per operation, improved by 15.00%\n- pkg1/bench2-pkg1: metric: bytes per operation, improved by 10.00%\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			c.Assert(microsMatrix.ImprovementWithThresholds(tt.thresholds), qt.Equals, tt.wantReason)
+		})
+	}
+}
@@ -19,11 +19,15 @@ package microbench
 import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"github.com/vitessio/arewefastyet/go/exec/stats"
+	"github.com/vitessio/arewefastyet/go/storage/influxdb"
 	"github.com/vitessio/arewefastyet/go/storage/psdb"
 )
 
 const (
-	flagExecUUID = "microbench-exec-uuid"
+	flagExecUUID          = "microbench-exec-uuid"
+	flagParserParallelism = "microbench-parser-parallelism"
+	flagResultStores      = "microbench-result-stores"
 )
 
 type Config struct {
@@ -42,6 +46,19 @@ type Config struct {
 	// be returned.
 	DatabaseConfig *psdb.Config
 
+	// ParserParallelism is the number of chunks the output of a benchmark is
+	// split in and parsed concurrently. The output is parsed serially if it is
+	// lower than two.
+	ParserParallelism int
+
+	// ResultStores lists the stores the results are written to, either StoreMySQL or
+	// StoreInfluxDB. StoreMySQL requires DatabaseConfig and StoreInfluxDB requires
+	// StatsRemoteDBConfig, a store that is not configured is skipped.
+	ResultStores []string
+
+	// StatsRemoteDBConfig is the configuration of the stats remote database used by StoreInfluxDB.
+	StatsRemoteDBConfig stats.RemoteDBConfig
+
 	// execUUID refers to parent execution of the microbenchmark.
 	// If this field is empty, the corresponding column in SQL
 	// will be set to NULL.
@@ -50,8 +67,47 @@ type Config struct {
 
 func (mbc *Config) AddToCommand(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&mbc.execUUID, flagExecUUID, "", "UUID of the parent execution, an empty string will set to NULL.")
+	cmd.Flags().IntVar(&mbc.ParserParallelism, flagParserParallelism, 1, "Number of chunks the output of a benchmark is parsed in concurrently, useful for benchmarks emitting many lines.")
 
 	_ = viper.BindPFlag(flagExecUUID, cmd.Flags().Lookup(flagExecUUID))
+	cmd.Flags().StringSliceVar(&mbc.ResultStores, flagResultStores, []string{StoreMySQL}, "Stores the results are written to: mysql and influxdb, which writes them to the stats remote database so that they can be queried like the metrics of the macrobenchmarks.")
+
+	_ = viper.BindPFlag(flagParserParallelism, cmd.Flags().Lookup(flagParserParallelism))
+	_ = viper.BindPFlag(flagResultStores, cmd.Flags().Lookup(flagResultStores))
 
 	mbc.DatabaseConfig.AddToCommand(cmd)
+	mbc.StatsRemoteDBConfig.AddToCommand(cmd)
+}
+
+// newStores returns the ResultStores of the Config that are configured, along with a function
+// closing them. The MySQL store uses the given client, it is skipped if the client is nil.
+func (mbc Config) newStores(sqlClient *psdb.Client) ([]ResultStore, func(), error) {
+	var stores []ResultStore
+	var influxClients []*influxdb.Client
+	closeStores := func() {
+		for _, client := range influxClients {
+			client.Close()
+		}
+	}
+	for _, store := range mbc.ResultStores {
+		switch store {
+		case StoreMySQL:
+			if sqlClient != nil {
+				stores = append(stores, NewMySQLStore(sqlClient))
+			}
+		case StoreInfluxDB:
+			if !mbc.StatsRemoteDBConfig.IsValid() {
+				continue
+			}
+			client, err := mbc.StatsRemoteDBConfig.NewInfluxClient()
+			if err != nil {
+				closeStores()
+				return nil, nil, err
+			}
+			client.SetExecution(mbc.execUUID)
+			influxClients = append(influxClients, client)
+			stores = append(stores, NewInfluxStore(client))
+		}
+	}
+	return stores, closeStores, nil
 }
@@ -0,0 +1,42 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+package microbench
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vitessio/arewefastyet/go/tools/math"
+)
+
+// Markdown renders the ComparisonArray as a Markdown table that can
+// be used in a GitHub comment. Each row corresponds to a benchmark.
+func (microsMatrix ComparisonArray) Markdown() string {
+	var b strings.Builder
+	b.WriteString("| Package | Benchmark | ns/op | Diff | B/op | Diff | allocs/op | Diff |\n")
+	b.WriteString("|---|---|---:|---:|---:|---:|---:|---:|\n")
+	for _, micro := range microsMatrix {
+		b.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %s | %s | %s |\n",
+			micro.PkgName, micro.SubBenchmarkName,
+			micro.Current.NSPerOpStr(), math.FormatDiff(micro.Diff.NSPerOp),
+			micro.Current.BytesPerOpStr(), math.FormatDiff(micro.Diff.BytesPerOp),
+			micro.Current.AllocsPerOpStr(), math.FormatDiff(micro.Diff.AllocsPerOp),
+		))
+	}
+	return b.String()
+}
@@ -17,10 +17,10 @@ limitations under the License.
 package microbench
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/vitessio/arewefastyet/go/storage"
+	"github.com/vitessio/arewefastyet/go/exec/exitcode"
+	"github.com/vitessio/arewefastyet/go/exec/provision"
 	"github.com/vitessio/arewefastyet/go/storage/psdb"
 	"github.com/vitessio/arewefastyet/go/tools/git"
 	"go.uber.org/multierr"
@@ -41,23 +41,23 @@ const (
 )
 
 type benchmark struct {
-	id               int64
 	filePath         string
 	name             string
 	pkgPath, pkgName string
-	sql              *psdb.Client
+	stores           []ResultStore
 	gitHash          string
 	execUUID         string
+
+	// exitCode is the exit code of the command that ran the benchmark.
+	exitCode int
+
+	// parserParallelism is the number of chunks the output of the benchmark is parsed in concurrently.
+	parserParallelism int
 }
 
-func (b *benchmark) registerToMySQL(client storage.SQLClient) error {
-	query := "INSERT INTO microbenchmark(exec_uuid, pkg_name, name, git_ref) VALUES(NULLIF(?, ''), ?, ?, ?)"
-	res, err := client.Insert(query, b.execUUID, b.pkgName, b.name, b.gitHash)
-	if err != nil {
-		return err
-	}
-	b.id = res
-	return nil
+// info returns the Benchmark under which the results of the benchmark are stored.
+func (b *benchmark) info() Benchmark {
+	return Benchmark{ExecUUID: b.execUUID, PkgName: b.pkgName, Name: b.name, GitRef: b.gitHash, ExitCode: b.exitCode}
 }
 
 func (b *benchmark) execute(rootDir string, w *os.File) error {
@@ -65,41 +65,28 @@ func (b *benchmark) execute(rootDir string, w *os.File) error {
 	command.Dir = rootDir
 	out, err := command.Output()
 
-	if err != nil {
+	// a benchmark that exits with a non-zero code may still produce results,
+	// they are saved along with the exit code but are not used by comparisons
+	code, ok := exitcode.FromError(err)
+	if !ok {
 		return err
 	}
-
-	if b.sql != nil {
-		if err := b.registerToMySQL(b.sql); err != nil {
-			return err
-		}
+	b.exitCode = code
+
+	benchLines, parseErr := parseOutput(out, b.parserParallelism)
+	results := make([]RunResult, 0, len(benchLines))
+	for _, benchLine := range benchLines {
+		log.Printf("%s - %s %f ns/op\n", b.pkgName, benchLine.name, benchLine.results.NanosecondPerOp)
+		fmt.Fprintf(w, "%s - %s %f ns/op\n", b.pkgName, benchLine.name, benchLine.results.NanosecondPerOp)
+		results = append(results, benchLine.runResult())
 	}
-
-	lines := strings.Split(string(out), "\n")
-	for _, line := range lines {
-		var benchLine lineRun
-		err := json.Unmarshal([]byte(line), &benchLine)
-		if err != nil || benchLine.Output == "" {
-			continue
-		}
-
-		err = benchLine.Parse()
+	for _, store := range b.stores {
+		err = store.StoreResults(b.info(), results)
 		if err != nil {
 			return err
 		}
-
-		if benchLine.benchType != "" {
-			log.Printf("%s - %s %f ns/op\n", b.pkgName, benchLine.name, benchLine.results.NanosecondPerOp)
-			fmt.Fprintf(w, "%s - %s %f ns/op\n", b.pkgName, benchLine.name, benchLine.results.NanosecondPerOp)
-			if b.sql != nil {
-				err = benchLine.InsertToMySQL(b.id, b.sql)
-				if err != nil {
-					return err
-				}
-			}
-		}
 	}
-	return nil
+	return parseErr
 }
 
 func (b benchmark) executeProfile(rootDir, profileType string, w *os.File) error {
@@ -126,14 +113,30 @@ func Run(cfg Config) error {
 	var sqlClient *psdb.Client
 	var err error
 
+	err = validateStores(cfg.ResultStores)
+	if err != nil {
+		return err
+	}
+
 	if cfg.DatabaseConfig != nil && cfg.DatabaseConfig.IsValid() {
 		sqlClient, err = cfg.DatabaseConfig.NewClient()
 		if err != nil {
 			return err
 		}
 		defer sqlClient.Close()
+		// the infrastructure is provisioned once the benchmarks start
+		err = provision.RecordEnd(sqlClient, cfg.execUUID)
+		if err != nil {
+			return err
+		}
 	}
 
+	stores, closeStores, err := cfg.newStores(sqlClient)
+	if err != nil {
+		return err
+	}
+	defer closeStores()
+
 	loaded, err := packages.Load(&packages.Config{
 		Mode:  packages.NeedName | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedDeps | packages.NeedImports | packages.NeedModule,
 		Tests: true,
@@ -160,8 +163,9 @@ func Run(cfg Config) error {
 			return err
 		}
 		benchmark.gitHash = hash
-		benchmark.sql = sqlClient
+		benchmark.stores = stores
 		benchmark.execUUID = cfg.execUUID
+		benchmark.parserParallelism = cfg.ParserParallelism
 
 		log.Println(benchmark.pkgPath)
 
@@ -170,6 +174,9 @@ func Run(cfg Config) error {
 			// not stopping execution on error
 			log.Println(err.Error())
 		}
+		if benchmark.exitCode != 0 {
+			log.Printf("%s exited with code %d\n", benchmark.name, benchmark.exitCode)
+		}
 
 		profiles := []string{profileMem, profileCPU}
 		for _, profile := range profiles {
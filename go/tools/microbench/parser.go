@@ -0,0 +1,90 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package microbench
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+// parseOutput parses the JSON output of "go test -bench" and returns the lines that
+// contain the result of a benchmark, in their order of appearance. If parallelism is
+// greater than one, the output is split in as many chunks that are parsed concurrently
+// and merged, giving the same result as parsing the output serially. If a line cannot
+// be parsed, the lines that precede it are returned along with the error.
+func parseOutput(out []byte, parallelism int) ([]lineRun, error) {
+	lines := strings.Split(string(out), "\n")
+	if parallelism <= 1 || len(lines) < parallelism {
+		return parseLines(lines)
+	}
+
+	chunkSize := (len(lines) + parallelism - 1) / parallelism
+	type chunkResult struct {
+		runs []lineRun
+		err  error
+	}
+	results := make([]chunkResult, parallelism)
+	var wg sync.WaitGroup
+	for i := 0; i < parallelism; i++ {
+		start, end := i*chunkSize, (i+1)*chunkSize
+		if start >= len(lines) {
+			break
+		}
+		if end > len(lines) {
+			end = len(lines)
+		}
+		wg.Add(1)
+		go func(i int, chunk []string) {
+			defer wg.Done()
+			runs, err := parseLines(chunk)
+			results[i] = chunkResult{runs: runs, err: err}
+		}(i, lines[start:end])
+	}
+	wg.Wait()
+
+	var runs []lineRun
+	for _, result := range results {
+		runs = append(runs, result.runs...)
+		if result.err != nil {
+			return runs, result.err
+		}
+	}
+	return runs, nil
+}
+
+// parseLines parses the given lines serially, stopping at the first line that cannot be parsed.
+func parseLines(lines []string) (runs []lineRun, err error) {
+	for _, line := range lines {
+		var benchLine lineRun
+		err := json.Unmarshal([]byte(line), &benchLine)
+		if err != nil || benchLine.Output == "" {
+			continue
+		}
+
+		err = benchLine.Parse()
+		if err != nil {
+			return runs, err
+		}
+		if benchLine.benchType != "" {
+			runs = append(runs, benchLine)
+		}
+	}
+	return runs, nil
+}
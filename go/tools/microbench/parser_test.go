@@ -0,0 +1,100 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package microbench
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// generateOutput returns the JSON output of "go test -bench" for nbBenchmarks benchmarks,
+// interleaved with lines that are not results. If malformedAt is positive, the result of
+// the benchmark malformedAt has an invalid number of operations.
+func generateOutput(t *testing.T, nbBenchmarks, malformedAt int) []byte {
+	var lines []string
+	addLine := func(output string) {
+		line, err := json.Marshal(map[string]string{"Action": "output", "Package": "vitess.io/vitess/go/vt/sqlparser", "Output": output})
+		qt.Assert(t, err, qt.IsNil)
+		lines = append(lines, string(line))
+	}
+	addLine("goos: linux\n")
+	for i := 1; i <= nbBenchmarks; i++ {
+		ops := fmt.Sprintf("%d", 1000+i)
+		if i == malformedAt {
+			ops = "99999999999999999999999"
+		}
+		addLine(fmt.Sprintf("BenchmarkParse%d-8 \t%s \t%d.5 ns/op \t%d B/op \t%d allocs/op\n", i, ops, i, i*2, i%7))
+		if i%3 == 0 {
+			addLine("PASS\n")
+			lines = append(lines, "not a JSON line")
+		}
+	}
+	addLine("ok  \tvitess.io/vitess/go/vt/sqlparser\t1.234s\n")
+	return []byte(strings.Join(lines, "\n"))
+}
+
+type parsedResult struct {
+	Name      string
+	BenchType microType
+	Results   lineResult
+}
+
+// parsedResults returns the parsed fields of the given lines, allowing them to be compared.
+func parsedResults(runs []lineRun) []parsedResult {
+	results := make([]parsedResult, 0, len(runs))
+	for _, run := range runs {
+		results = append(results, parsedResult{Name: run.name, BenchType: run.benchType, Results: run.results})
+	}
+	return results
+}
+
+func TestParseOutputParallel(t *testing.T) {
+	out := generateOutput(t, 1000, 0)
+	serial, err := parseOutput(out, 1)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, serial, qt.HasLen, 1000)
+	qt.Assert(t, serial[999].results, qt.Equals, lineResult{Op: 2000, NanosecondPerOp: 1000.5, BytesPerOp: 2000, AllocsPerOp: 6})
+
+	for _, parallelism := range []int{2, 3, 7, 16, 5000} {
+		t.Run(fmt.Sprintf("parallelism %d", parallelism), func(t *testing.T) {
+			parallel, err := parseOutput(out, parallelism)
+			qt.Assert(t, err, qt.IsNil)
+			qt.Assert(t, parsedResults(parallel), qt.DeepEquals, parsedResults(serial))
+		})
+	}
+}
+
+func TestParseOutputParallelError(t *testing.T) {
+	out := generateOutput(t, 300, 200)
+	serial, serialErr := parseOutput(out, 1)
+	qt.Assert(t, serialErr, qt.ErrorMatches, ErrorLineMalformed+": .*")
+	qt.Assert(t, serial, qt.HasLen, 199)
+
+	for _, parallelism := range []int{2, 4, 9} {
+		t.Run(fmt.Sprintf("parallelism %d", parallelism), func(t *testing.T) {
+			parallel, err := parseOutput(out, parallelism)
+			qt.Assert(t, err, qt.ErrorMatches, serialErr.Error())
+			qt.Assert(t, parsedResults(parallel), qt.DeepEquals, parsedResults(serial))
+		})
+	}
+}
@@ -54,6 +54,9 @@ type (
 		GitRef    string
 		StartedAt string
 		Result    Result
+
+		// Component is the vitess component the execution of this benchmark focused on.
+		Component string
 	}
 
 	// Comparison allows comparison of two Result
@@ -64,6 +67,11 @@ type (
 
 		// Difference between Current and Last.
 		Diff Result
+
+		// PValue contains the p-value of the Mann-Whitney U test of each metric
+		// of Diff, computed from the individual runs of Current and Last.
+		// A p-value of zero means there was not enough runs to compute it.
+		PValue Result
 	}
 
 	DetailsArray    []Details
@@ -125,6 +133,21 @@ func MergeDetails(currentMbd, lastMbd DetailsArray) (compareMbs ComparisonArray)
 	return compareMbs
 }
 
+// FilterByComponent returns the Details of the DetailsArray that focused on the given
+// vitess component. The DetailsArray is returned untouched if component is empty.
+func (mbd DetailsArray) FilterByComponent(component string) DetailsArray {
+	if component == "" {
+		return mbd
+	}
+	var filtered DetailsArray
+	for _, details := range mbd {
+		if details.Component == component {
+			filtered = append(filtered, details)
+		}
+	}
+	return filtered
+}
+
 // ReduceSimpleMedianByName reduces a DetailsArray by merging
 // all Details with the same benchmark name into a single
 // one. The results of each Details correspond to the median
@@ -207,8 +230,8 @@ func (mbd DetailsArray) mergeUsingCondition(compareCondition func(i, j int) bool
 // containing all the Details linked to the given git commit SHA.
 func GetResultsForGitRef(ref string, client storage.SQLClient) (mrs DetailsArray, err error) {
 	result, err := client.Select("select m.pkg_name, m.name, md.name, md.n, md.ns_per_op, md.bytes_per_op,"+
-		" md.allocs_per_op, md.mb_per_sec FROM execution e, microbenchmark m, microbenchmark_details md where m.git_ref = ? AND "+
-		"md.microbenchmark_no = m.microbenchmark_no and e.uuid = m.exec_uuid and e.status = \"finished\" order by m.microbenchmark_no desc", ref)
+		" md.allocs_per_op, md.mb_per_sec, IFNULL(e.component, '') FROM execution e, microbenchmark m, microbenchmark_details md where m.git_ref = ? AND "+
+		"md.microbenchmark_no = m.microbenchmark_no and e.uuid = m.exec_uuid and e.status = \"finished\" and e.deleted_at is null and ifnull(e.benchmark_exit_code, 0) = 0 and ifnull(m.exit_code, 0) = 0 order by m.microbenchmark_no desc", ref)
 	if err != nil {
 		return nil, err
 	}
@@ -218,6 +241,29 @@ func GetResultsForGitRef(ref string, client storage.SQLClient) (mrs DetailsArray
 		var res Details
 		res.GitRef = ref
 		err = result.Scan(&res.PkgName, &res.Name, &res.SubBenchmarkName, &res.Result.Ops, &res.Result.NSPerOp, &res.Result.BytesPerOp,
+			&res.Result.AllocsPerOp, &res.Result.MBPerSec, &res.Component)
+		if err != nil {
+			return nil, err
+		}
+		mrs = append(mrs, res)
+	}
+	return mrs, nil
+}
+
+// GetResultsForExecution returns the results of the microbenchmarks of the execution
+// execUUID, regardless of the status of the execution.
+func GetResultsForExecution(execUUID string, client storage.SQLClient) (mrs DetailsArray, err error) {
+	result, err := client.Select("select m.pkg_name, m.name, md.name, m.git_ref, md.n, md.ns_per_op, md.bytes_per_op,"+
+		" md.allocs_per_op, md.mb_per_sec FROM microbenchmark m, microbenchmark_details md where m.exec_uuid = ? AND "+
+		"md.microbenchmark_no = m.microbenchmark_no order by m.microbenchmark_no", execUUID)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	for result.Next() {
+		var res Details
+		err = result.Scan(&res.PkgName, &res.Name, &res.SubBenchmarkName, &res.GitRef, &res.Result.Ops, &res.Result.NSPerOp, &res.Result.BytesPerOp,
 			&res.Result.AllocsPerOp, &res.Result.MBPerSec)
 		if err != nil {
 			return nil, err
@@ -232,7 +278,7 @@ func GetResultsForGitRef(ref string, client storage.SQLClient) (mrs DetailsArray
 func GetLatestResultsFor(name, subBenchmarkName string, count int, client storage.SQLClient) (mrs DetailsArray, err error) {
 	query := "select m.pkg_name, m.name, md.name, m.git_ref , md.n, md.ns_per_op, md.bytes_per_op," +
 		" md.allocs_per_op, md.mb_per_sec, m.started_at  from (select microbenchmark_no, pkg_name, name, microbenchmark.git_ref, started_at" +
-		" from microbenchmark join execution on exec_uuid = uuid where name = ? and source = \"cron\" and status = \"finished\" order by started_at desc limit ?) m, " +
+		" from microbenchmark join execution on exec_uuid = uuid where name = ? and source = \"cron\" and status = \"finished\" and deleted_at is null order by started_at desc limit ?) m, " +
 		"microbenchmark_details md where md.microbenchmark_no = m.microbenchmark_no and md.name = ?"
 	rows, err := client.Select(query, name, count, subBenchmarkName)
 	if err != nil {
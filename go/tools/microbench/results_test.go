@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	qt "github.com/frankban/quicktest"
+	"github.com/vitessio/arewefastyet/go/storage/mysql/mysqltest"
 )
 
 func TestMicroBenchmarkResults_ReduceSimpleMedianByName(t *testing.T) {
@@ -330,3 +331,22 @@ func TestHumanReadableStrings(t *testing.T) {
 	c.Assert(r.NSPerOpStr(), qt.Equals, "2.5")
 	c.Assert(r.NSPerOpToDurationStr(), qt.Equals, "2.00 ns")
 }
+
+func TestGetResultsForGitRefExcludesFailedBenchmarks(t *testing.T) {
+	c := qt.New(t)
+	client := mysqltest.New(t)
+
+	_, err := client.Insert("INSERT INTO execution(uuid, status, source, git_ref, type) VALUES('uuid', 'finished', 'cron', 'abcdef', 'micro')")
+	c.Assert(err, qt.IsNil)
+	store := NewMySQLStore(client)
+	passed := Benchmark{ExecUUID: "uuid", PkgName: "sqlparser", Name: "BenchmarkParse", GitRef: "abcdef"}
+	failed := Benchmark{ExecUUID: "uuid", PkgName: "planbuilder", Name: "BenchmarkPlan", GitRef: "abcdef", ExitCode: 1}
+	c.Assert(store.StoreResults(passed, []RunResult{{Name: "BenchmarkParse", Ops: 100, NSPerOp: 10}}), qt.IsNil)
+	c.Assert(store.StoreResults(failed, []RunResult{{Name: "BenchmarkPlan", Ops: 100, NSPerOp: 20}}), qt.IsNil)
+
+	// the failed package does not remove the rest of the execution from the results
+	results, err := GetResultsForGitRef("abcdef", client)
+	c.Assert(err, qt.IsNil)
+	c.Assert(results, qt.HasLen, 1)
+	c.Assert(results[0].PkgName, qt.Equals, "sqlparser")
+}
@@ -0,0 +1,168 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package microbench
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/vitessio/arewefastyet/go/storage"
+	"github.com/vitessio/arewefastyet/go/storage/influxdb"
+)
+
+const (
+	// StoreMySQL stores the results in the MySQL database of arewefastyet, it is the
+	// store used by comparisons and the default one.
+	StoreMySQL = "mysql"
+
+	// StoreInfluxDB stores the results in the stats remote database, a time-series
+	// store, so that they can be queried like the metrics of the macrobenchmarks.
+	StoreInfluxDB = "influxdb"
+
+	// measurementMicrobenchmark is the InfluxDB measurement of the microbenchmark results.
+	measurementMicrobenchmark = "microbenchmark"
+)
+
+type (
+	// Benchmark identifies a microbenchmark whose results are stored in a ResultStore.
+	Benchmark struct {
+		ExecUUID string
+		PkgName  string
+		Name     string
+		GitRef   string
+
+		// ExitCode is the exit code of the command that ran the benchmark. The results
+		// of a benchmark that exited with a non-zero code are not used by comparisons.
+		ExitCode int
+	}
+
+	// RunResult is the result of a single run of a Benchmark or of one of its sub-benchmarks,
+	// as given by a line of the output of go test.
+	RunResult struct {
+		// Name is the full name of the benchmark that was run, including its sub-benchmark.
+		Name        string
+		Time        time.Time
+		Ops         int
+		NSPerOp     float64
+		MBPerSec    float64
+		BytesPerOp  float64
+		AllocsPerOp float64
+	}
+
+	// ResultStore stores the results of microbenchmarks. Run writes the results of each
+	// benchmark to all the stores of its Config.
+	ResultStore interface {
+		StoreResults(benchmark Benchmark, results []RunResult) error
+	}
+
+	mysqlStore struct {
+		client storage.SQLClient
+	}
+
+	influxStore struct {
+		client *influxdb.Client
+	}
+)
+
+// NewMySQLStore returns a ResultStore writing the results to the microbenchmark
+// and microbenchmark_details tables using the given client.
+func NewMySQLStore(client storage.SQLClient) ResultStore {
+	return mysqlStore{client: client}
+}
+
+// StoreResults registers the benchmark, even if it has no result, and stores its results.
+func (s mysqlStore) StoreResults(benchmark Benchmark, results []RunResult) error {
+	res, err := s.client.Insert("INSERT INTO microbenchmark(exec_uuid, pkg_name, name, git_ref, exit_code) VALUES(NULLIF(?, ''), ?, ?, ?, ?)",
+		benchmark.ExecUUID, benchmark.PkgName, benchmark.Name, benchmark.GitRef, benchmark.ExitCode)
+	if err != nil {
+		return err
+	}
+	query := "INSERT INTO microbenchmark_details(microbenchmark_no, name, bench_type, n, ns_per_op, mb_per_sec, bytes_per_op, allocs_per_op) VALUES(?, ?, ?, ?, ?, ?, ?, ?)"
+	for _, result := range results {
+		_, err = s.client.Insert(query, res, result.Name, GeneralBenchmark, result.Ops, result.NSPerOp, result.MBPerSec, result.BytesPerOp, result.AllocsPerOp)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewInfluxStore returns a ResultStore writing the results to the microbenchmark
+// measurement of InfluxDB using the given client.
+func NewInfluxStore(client *influxdb.Client) ResultStore {
+	return influxStore{client: client}
+}
+
+// StoreResults writes a point per result, tagged with the benchmark and its execution.
+// The points are timestamped with the time of their result so that the runs of a
+// benchmark do not replace each other.
+func (s influxStore) StoreResults(benchmark Benchmark, results []RunResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+	points := make([]influxdb.Point, 0, len(results))
+	for _, result := range results {
+		ts := result.Time
+		if ts.IsZero() {
+			ts = time.Now()
+		}
+		points = append(points, influxdb.Point{
+			Measurement: measurementMicrobenchmark,
+			Tags: map[string]string{
+				influxdb.TagExecUUID: benchmark.ExecUUID,
+				"pkg_name":           benchmark.PkgName,
+				"benchmark":          benchmark.Name,
+				"name":               result.Name,
+				"git_ref":            benchmark.GitRef,
+			},
+			Fields: map[string]interface{}{
+				"ops":           result.Ops,
+				"ns_per_op":     result.NSPerOp,
+				"mb_per_sec":    result.MBPerSec,
+				"bytes_per_op":  result.BytesPerOp,
+				"allocs_per_op": result.AllocsPerOp,
+			},
+			Time: ts,
+		})
+	}
+	return s.client.WritePoints(points)
+}
+
+// validateStores returns an error if one of the stores is unknown.
+func validateStores(stores []string) error {
+	for _, store := range stores {
+		if store != StoreMySQL && store != StoreInfluxDB {
+			return fmt.Errorf("unknown result store %s, must be either %s or %s", store, StoreMySQL, StoreInfluxDB)
+		}
+	}
+	return nil
+}
+
+// runResult returns the RunResult of the parsed line.
+func (line lineRun) runResult() RunResult {
+	return RunResult{
+		Name:        line.name,
+		Time:        line.Time,
+		Ops:         line.results.Op,
+		NSPerOp:     line.results.NanosecondPerOp,
+		MBPerSec:    line.results.MBs,
+		BytesPerOp:  line.results.BytesPerOp,
+		AllocsPerOp: line.results.AllocsPerOp,
+	}
+}
@@ -0,0 +1,129 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+package microbench
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/vitessio/arewefastyet/go/storage/influxdb"
+)
+
+// insertRecorder is a storage.SQLClient recording the queries it inserts.
+type insertRecorder struct {
+	queries []string
+	args    [][]interface{}
+}
+
+func (r *insertRecorder) Insert(query string, args ...interface{}) (int64, error) {
+	r.queries = append(r.queries, query)
+	r.args = append(r.args, args)
+	return int64(len(r.queries)), nil
+}
+
+func (r *insertRecorder) Select(string, ...interface{}) (*sql.Rows, error) {
+	return nil, nil
+}
+
+var storeTestBenchmark = Benchmark{ExecUUID: "uuid", PkgName: "sqlparser", Name: "BenchmarkParse", GitRef: "abcdef"}
+
+func TestMySQLStore_StoreResults(t *testing.T) {
+	c := qt.New(t)
+	recorder := &insertRecorder{}
+	results := []RunResult{
+		{Name: "BenchmarkParse/short", Ops: 100, NSPerOp: 10},
+		{Name: "BenchmarkParse/long", Ops: 50, NSPerOp: 20, AllocsPerOp: 3},
+	}
+
+	c.Assert(NewMySQLStore(recorder).StoreResults(storeTestBenchmark, results), qt.IsNil)
+	c.Assert(recorder.queries, qt.HasLen, 3)
+	c.Assert(recorder.args[0], qt.DeepEquals, []interface{}{"uuid", "sqlparser", "BenchmarkParse", "abcdef", 0})
+	c.Assert(recorder.args[2], qt.DeepEquals, []interface{}{int64(1), "BenchmarkParse/long", GeneralBenchmark, 50, 20.0, 0.0, 0.0, 3.0})
+
+	// the exit code of a failed benchmark is stored along with its results
+	recorder = &insertRecorder{}
+	failed := storeTestBenchmark
+	failed.ExitCode = 2
+	c.Assert(NewMySQLStore(recorder).StoreResults(failed, results), qt.IsNil)
+	c.Assert(recorder.args[0], qt.DeepEquals, []interface{}{"uuid", "sqlparser", "BenchmarkParse", "abcdef", 2})
+
+	// a benchmark without results is registered nonetheless
+	recorder = &insertRecorder{}
+	c.Assert(NewMySQLStore(recorder).StoreResults(storeTestBenchmark, nil), qt.IsNil)
+	c.Assert(recorder.queries, qt.HasLen, 1)
+}
+
+func TestInfluxStore_StoreResults(t *testing.T) {
+	c := qt.New(t)
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	c.Assert(err, qt.IsNil)
+	host, port, err := net.SplitHostPort(serverURL.Host)
+	c.Assert(err, qt.IsNil)
+	client, err := influxdb.Config{Host: host, Port: port, Database: "stats"}.NewClient()
+	c.Assert(err, qt.IsNil)
+	defer client.Close()
+
+	ts := time.Unix(1600000000, 0)
+	results := []RunResult{{Name: "BenchmarkParse/short", Time: ts, Ops: 100, NSPerOp: 10}}
+	c.Assert(NewInfluxStore(client).StoreResults(storeTestBenchmark, results), qt.IsNil)
+	c.Assert(strings.HasPrefix(body, "microbenchmark,"), qt.IsTrue)
+	c.Assert(body, qt.Contains, "benchmark=BenchmarkParse")
+	c.Assert(body, qt.Contains, `name=BenchmarkParse/short`)
+	c.Assert(body, qt.Contains, "exec_uuid=uuid")
+	c.Assert(body, qt.Contains, "ns_per_op=10")
+	c.Assert(body, qt.Contains, "ops=100i")
+	c.Assert(strings.TrimSpace(body), qt.Matches, `.* 1600000000000000000`)
+
+	// no point is written without results
+	body = ""
+	c.Assert(NewInfluxStore(client).StoreResults(storeTestBenchmark, nil), qt.IsNil)
+	c.Assert(body, qt.Equals, "")
+}
+
+func TestValidateStores(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(validateStores([]string{StoreMySQL, StoreInfluxDB}), qt.IsNil)
+	c.Assert(validateStores(nil), qt.IsNil)
+	c.Assert(validateStores([]string{"prometheus"}), qt.ErrorMatches, "unknown result store prometheus, must be either mysql or influxdb")
+}
+
+func TestConfig_newStores(t *testing.T) {
+	c := qt.New(t)
+	// the stores that are not configured are skipped
+	stores, closeStores, err := Config{ResultStores: []string{StoreMySQL, StoreInfluxDB}}.newStores(nil)
+	c.Assert(err, qt.IsNil)
+	c.Assert(stores, qt.HasLen, 0)
+	closeStores()
+}
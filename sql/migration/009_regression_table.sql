@@ -0,0 +1,38 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+--
+-- Table structure for table `regression`
+--
+
+DROP TABLE IF EXISTS `regression`;
+CREATE TABLE `regression` (
+                              `id` INT(11) NOT NULL AUTO_INCREMENT,
+                              `source` VARCHAR(100) DEFAULT NULL,
+                              `type` VARCHAR(100) DEFAULT NULL,
+                              `planner_version` VARCHAR(100) DEFAULT '',
+                              `exec_uuid` VARCHAR(100) DEFAULT NULL,
+                              `git_ref` VARCHAR(100) DEFAULT NULL,
+                              `baseline_exec_uuid` VARCHAR(100) DEFAULT NULL,
+                              `baseline_git_ref` VARCHAR(100) DEFAULT NULL,
+                              `metrics` TEXT DEFAULT NULL,
+                              `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP,
+                              `resolved_at` DATETIME DEFAULT NULL,
+                              `resolved_by_exec_uuid` VARCHAR(100) DEFAULT NULL,
+                              PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
@@ -0,0 +1,31 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+--
+-- Table structure for table `macrobenchmark_histogram`
+--
+
+DROP TABLE IF EXISTS `macrobenchmark_histogram`;
+CREATE TABLE `macrobenchmark_histogram` (
+                                            `id` INT(11) NOT NULL AUTO_INCREMENT,
+                                            `macrobenchmark_id` INT(11) NOT NULL,
+                                            `latency` DECIMAL(14,3) NOT NULL,
+                                            `count` INT(11) NOT NULL,
+                                            PRIMARY KEY (`id`),
+                                            KEY `macrobenchmark_id` (`macrobenchmark_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
@@ -0,0 +1,28 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+DROP TABLE IF EXISTS `genericbenchmark`;
+CREATE TABLE `genericbenchmark` (
+                                    `id` INT(11) NOT NULL AUTO_INCREMENT,
+                                    `exec_uuid` VARCHAR(100) DEFAULT NULL,
+                                    `git_ref` VARCHAR(100) DEFAULT NULL,
+                                    `name` VARCHAR(255) NOT NULL,
+                                    `value` DECIMAL(20,6) NOT NULL,
+                                    PRIMARY KEY (`id`),
+                                    KEY `git_ref` (`git_ref`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
@@ -0,0 +1,38 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+--
+-- Table structure for table `queue`
+--
+
+DROP TABLE IF EXISTS `queue`;
+CREATE TABLE `queue` (
+                         `git_ref` VARCHAR(100) NOT NULL,
+                         `source` VARCHAR(100) NOT NULL,
+                         `type` VARCHAR(100) NOT NULL,
+                         `planner_version` VARCHAR(100) NOT NULL DEFAULT '',
+                         `pull_nb` INT(11) NOT NULL DEFAULT 0,
+                         `config` VARCHAR(255) DEFAULT NULL,
+                         `retry` INT(11) DEFAULT 0,
+                         `attempt` INT(11) DEFAULT 0,
+                         `compare_with` TEXT DEFAULT NULL,
+                         `notify_always` TINYINT(1) DEFAULT 0,
+                         `executing` TINYINT(1) DEFAULT 0,
+                         `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP,
+                         PRIMARY KEY (`git_ref`, `source`, `type`, `planner_version`, `pull_nb`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
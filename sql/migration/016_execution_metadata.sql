@@ -0,0 +1,29 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+--
+-- Table structure for table `execution_metadata`
+--
+
+DROP TABLE IF EXISTS `execution_metadata`;
+CREATE TABLE `execution_metadata` (
+                                      `exec_uuid` VARCHAR(100) NOT NULL,
+                                      `metadata_key` VARCHAR(100) NOT NULL,
+                                      `metadata_value` TEXT DEFAULT NULL,
+                                      PRIMARY KEY (`exec_uuid`, `metadata_key`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
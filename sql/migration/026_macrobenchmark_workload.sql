@@ -0,0 +1,41 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+--
+-- Table structure for table `macrobenchmark_workload`
+--
+
+DROP TABLE IF EXISTS `macrobenchmark_workload`;
+CREATE TABLE `macrobenchmark_workload` (
+                                           `id` INT(11) NOT NULL AUTO_INCREMENT,
+                                           `macrobenchmark_id` INT(11) NOT NULL,
+                                           `workload` VARCHAR(255) NOT NULL,
+                                           `ratio` DECIMAL(6,5) NOT NULL,
+                                           `tps` DECIMAL(14,6) DEFAULT NULL,
+                                           `latency` DECIMAL(14,6) DEFAULT NULL,
+                                           `errors` DECIMAL(14,6) DEFAULT NULL,
+                                           `reconnects` DECIMAL(14,6) DEFAULT NULL,
+                                           `time` INT(11) DEFAULT NULL,
+                                           `threads` DECIMAL(14,6) DEFAULT NULL,
+                                           `total_qps` DECIMAL(14,6) DEFAULT NULL,
+                                           `reads_qps` DECIMAL(14,6) DEFAULT NULL,
+                                           `writes_qps` DECIMAL(14,6) DEFAULT NULL,
+                                           `other_qps` DECIMAL(14,6) DEFAULT NULL,
+                                           PRIMARY KEY (`id`),
+                                           KEY `macrobenchmark_id` (`macrobenchmark_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
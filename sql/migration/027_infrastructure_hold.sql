@@ -0,0 +1,26 @@
+/*
+ *
+ * Copyright 2021 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ * /
+ */
+
+DROP TABLE IF EXISTS `infrastructure_hold`;
+CREATE TABLE `infrastructure_hold` (
+                                       `exec_uuid` VARCHAR(100) NOT NULL,
+                                       `type` VARCHAR(100) NOT NULL,
+                                       `release_at` DATETIME DEFAULT NULL,
+                                       `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP,
+                                       PRIMARY KEY (`exec_uuid`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
@@ -20,4 +20,28 @@ mysql -u root < ./003_add_type_to_execution.sql
 mysql -u root < ./004_add_pull_request_to_execution.sql
 mysql -u root < ./005_add_vtgate_planner_version_to_macrobenchmarks.sql
 mysql -u root < ./006_drop_foreign_key_constraints.sql
-mysql -u root < ./007_metrics_table.sql
\ No newline at end of file
+mysql -u root < ./007_metrics_table.sql
+mysql -u root < ./008_golang_version.sql
+mysql -u root < ./009_regression_table.sql
+mysql -u root < ./010_macrobenchmark_histogram.sql
+mysql -u root < ./011_execution_component.sql
+mysql -u root < ./012_execution_label.sql
+mysql -u root < ./013_execution_retry.sql
+mysql -u root < ./014_genericbenchmark.sql
+mysql -u root < ./015_execution_queue.sql
+mysql -u root < ./016_execution_metadata.sql
+mysql -u root < ./017_queue_baseline_only.sql
+mysql -u root < ./018_genericbenchmark_partial.sql
+mysql -u root < ./019_queue_noop.sql
+mysql -u root < ./020_execution_error.sql
+mysql -u root < ./021_execution_soft_delete.sql
+mysql -u root < ./022_execution_benchmark_exit_code.sql
+mysql -u root < ./023_execution_profile.sql
+mysql -u root < ./024_execution_source_type_index.sql
+mysql -u root < ./025_execution_provision_duration.sql
+mysql -u root < ./026_macrobenchmark_workload.sql
+mysql -u root < ./027_infrastructure_hold.sql
+mysql -u root < ./028_microbenchmark_exit_code.sql
+mysql -u root < ./029_queue_low_priority.sql
+mysql -u root < ./030_queue_batch_id.sql
+mysql -u root < ./031_macrobenchmark_workload_exit_code.sql
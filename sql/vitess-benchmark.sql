@@ -31,7 +31,16 @@ CREATE TABLE `execution` (
                              `type` varchar(100) DEFAULT '',
                              `pull_nb` int(11) DEFAULT 0,
                              `go_version` varchar(16) DEFAULT NULL,
-                             PRIMARY KEY (`uuid`)
+                             `component` varchar(100) DEFAULT NULL,
+                             `attempt` int(11) DEFAULT 1,
+                             `retries_left` int(11) DEFAULT 0,
+                             `error` TEXT DEFAULT NULL,
+                             `deleted_at` datetime DEFAULT NULL,
+                             `benchmark_exit_code` int(11) DEFAULT NULL,
+                             `provision_duration` int(11) DEFAULT NULL,
+                             PRIMARY KEY (`uuid`),
+                             KEY `source` (`source`),
+                             KEY `type` (`type`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8;
 
 --
@@ -123,6 +132,7 @@ CREATE TABLE `microbenchmark` (
                                   `name` varchar(255) DEFAULT NULL,
                                   `git_ref` varchar(255) DEFAULT NULL,
                                   `exec_uuid` varchar(100) DEFAULT NULL,
+                                  `exit_code` int(11) DEFAULT NULL,
                                   PRIMARY KEY (`microbenchmark_no`)
 ) ENGINE=InnoDB AUTO_INCREMENT=14619 DEFAULT CHARSET=utf8;
 
@@ -143,3 +153,155 @@ CREATE TABLE `microbenchmark_details` (
                                           `allocs_per_op` decimal(22,5) DEFAULT NULL,
                                           PRIMARY KEY (`detail_no`)
 ) ENGINE=InnoDB AUTO_INCREMENT=358174 DEFAULT CHARSET=utf8;
+
+--
+-- Table structure for table `regression`
+--
+
+DROP TABLE IF EXISTS `regression`;
+CREATE TABLE `regression` (
+                              `id` INT(11) NOT NULL AUTO_INCREMENT,
+                              `source` VARCHAR(100) DEFAULT NULL,
+                              `type` VARCHAR(100) DEFAULT NULL,
+                              `planner_version` VARCHAR(100) DEFAULT '',
+                              `exec_uuid` VARCHAR(100) DEFAULT NULL,
+                              `git_ref` VARCHAR(100) DEFAULT NULL,
+                              `baseline_exec_uuid` VARCHAR(100) DEFAULT NULL,
+                              `baseline_git_ref` VARCHAR(100) DEFAULT NULL,
+                              `metrics` TEXT DEFAULT NULL,
+                              `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP,
+                              `resolved_at` DATETIME DEFAULT NULL,
+                              `resolved_by_exec_uuid` VARCHAR(100) DEFAULT NULL,
+                              PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+--
+-- Table structure for table `macrobenchmark_histogram`
+--
+
+DROP TABLE IF EXISTS `macrobenchmark_histogram`;
+CREATE TABLE `macrobenchmark_histogram` (
+                                            `id` INT(11) NOT NULL AUTO_INCREMENT,
+                                            `macrobenchmark_id` INT(11) NOT NULL,
+                                            `latency` DECIMAL(14,3) NOT NULL,
+                                            `count` INT(11) NOT NULL,
+                                            PRIMARY KEY (`id`),
+                                            KEY `macrobenchmark_id` (`macrobenchmark_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+--
+-- Table structure for table `execution_label`
+--
+
+DROP TABLE IF EXISTS `execution_label`;
+CREATE TABLE `execution_label` (
+                                   `exec_uuid` VARCHAR(100) NOT NULL,
+                                   `label_key` VARCHAR(100) NOT NULL,
+                                   `label_value` VARCHAR(255) NOT NULL DEFAULT '',
+                                   PRIMARY KEY (`exec_uuid`, `label_key`),
+                                   KEY `label` (`label_key`, `label_value`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+--
+-- Table structure for table `genericbenchmark`
+--
+
+DROP TABLE IF EXISTS `genericbenchmark`;
+CREATE TABLE `genericbenchmark` (
+                                    `id` INT(11) NOT NULL AUTO_INCREMENT,
+                                    `exec_uuid` VARCHAR(100) DEFAULT NULL,
+                                    `git_ref` VARCHAR(100) DEFAULT NULL,
+                                    `name` VARCHAR(255) NOT NULL,
+                                    `value` DECIMAL(20,6) NOT NULL,
+                                    `partial` TINYINT(1) DEFAULT 0,
+                                    PRIMARY KEY (`id`),
+                                    KEY `git_ref` (`git_ref`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+--
+-- Table structure for table `queue`
+--
+
+DROP TABLE IF EXISTS `queue`;
+CREATE TABLE `queue` (
+                         `git_ref` VARCHAR(100) NOT NULL,
+                         `source` VARCHAR(100) NOT NULL,
+                         `type` VARCHAR(100) NOT NULL,
+                         `planner_version` VARCHAR(100) NOT NULL DEFAULT '',
+                         `pull_nb` INT(11) NOT NULL DEFAULT 0,
+                         `config` VARCHAR(255) DEFAULT NULL,
+                         `retry` INT(11) DEFAULT 0,
+                         `attempt` INT(11) DEFAULT 0,
+                         `compare_with` TEXT DEFAULT NULL,
+                         `notify_always` TINYINT(1) DEFAULT 0,
+                         `executing` TINYINT(1) DEFAULT 0,
+                         `baseline_only` TINYINT(1) DEFAULT 0,
+                         `noop` TINYINT(1) DEFAULT 0,
+                         `low_priority` TINYINT(1) DEFAULT 0,
+                         `batch_id` VARCHAR(100) NOT NULL DEFAULT '',
+                         `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP,
+                         PRIMARY KEY (`git_ref`, `source`, `type`, `planner_version`, `pull_nb`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+--
+-- Table structure for table `execution_metadata`
+--
+
+DROP TABLE IF EXISTS `execution_metadata`;
+CREATE TABLE `execution_metadata` (
+                                      `exec_uuid` VARCHAR(100) NOT NULL,
+                                      `metadata_key` VARCHAR(100) NOT NULL,
+                                      `metadata_value` TEXT DEFAULT NULL,
+                                      PRIMARY KEY (`exec_uuid`, `metadata_key`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+--
+-- Table structure for table `execution_profile`
+--
+
+DROP TABLE IF EXISTS `execution_profile`;
+CREATE TABLE `execution_profile` (
+                                     `exec_uuid` VARCHAR(100) NOT NULL,
+                                     `name` VARCHAR(100) NOT NULL,
+                                     `content` MEDIUMBLOB NOT NULL,
+                                     `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP,
+                                     PRIMARY KEY (`exec_uuid`, `name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+--
+-- Table structure for table `macrobenchmark_workload`
+--
+
+DROP TABLE IF EXISTS `macrobenchmark_workload`;
+CREATE TABLE `macrobenchmark_workload` (
+                                           `id` INT(11) NOT NULL AUTO_INCREMENT,
+                                           `macrobenchmark_id` INT(11) NOT NULL,
+                                           `workload` VARCHAR(255) NOT NULL,
+                                           `ratio` DECIMAL(6,5) NOT NULL,
+                                           `tps` DECIMAL(14,6) DEFAULT NULL,
+                                           `latency` DECIMAL(14,6) DEFAULT NULL,
+                                           `errors` DECIMAL(14,6) DEFAULT NULL,
+                                           `reconnects` DECIMAL(14,6) DEFAULT NULL,
+                                           `time` INT(11) DEFAULT NULL,
+                                           `threads` DECIMAL(14,6) DEFAULT NULL,
+                                           `total_qps` DECIMAL(14,6) DEFAULT NULL,
+                                           `reads_qps` DECIMAL(14,6) DEFAULT NULL,
+                                           `writes_qps` DECIMAL(14,6) DEFAULT NULL,
+                                           `other_qps` DECIMAL(14,6) DEFAULT NULL,
+                                           `exit_code` INT(11) DEFAULT NULL,
+                                           PRIMARY KEY (`id`),
+                                           KEY `macrobenchmark_id` (`macrobenchmark_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+--
+-- Table structure for table `infrastructure_hold`
+--
+
+DROP TABLE IF EXISTS `infrastructure_hold`;
+CREATE TABLE `infrastructure_hold` (
+                                       `exec_uuid` VARCHAR(100) NOT NULL,
+                                       `type` VARCHAR(100) NOT NULL,
+                                       `release_at` DATETIME DEFAULT NULL,
+                                       `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP,
+                                       PRIMARY KEY (`exec_uuid`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;